@@ -15,6 +15,7 @@ import (
 	"github.com/shinjuwu/TheNuts/internal/auth"
 	"github.com/shinjuwu/TheNuts/internal/game/adapter/ws"
 	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
 	"github.com/shinjuwu/TheNuts/pkg/di"
 	"go.uber.org/zap"
 )
@@ -29,6 +30,9 @@ func main() {
 	// 2. 接線
 	app.SessionManager.SetTableNotifier(app.TableManager)
 	app.WSHandler.SetAllowedOrigins(app.Config.Server.AllowedOrigins)
+	if err := auth.SetTrustedProxies(app.Config.Server.TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
 	app.TableManager.SetLogger(app.Logger)
 	app.TableManager.SetOnSessionChipsUpdate(func(playerID string, chips int64) {
 		playerUUID, err := uuid.Parse(playerID)
@@ -39,12 +43,18 @@ func main() {
 			session.UpdateChips(chips)
 		}
 	})
-	app.TableManager.SetOnTableEvent(func(event domain.TableEvent) {
-		resp := ws.Response{
-			Type:      string(event.Type),
-			Payload:   event.Data,
-			Timestamp: time.Now(),
+	app.TableManager.SetOnSessionCashedOut(func(playerID string) {
+		playerUUID, err := uuid.Parse(playerID)
+		if err != nil {
+			return
 		}
+		if session, exists := app.SessionManager.GetSession(playerUUID); exists {
+			session.LeaveTable()
+			session.SetGameSession(uuid.Nil, 0)
+		}
+	})
+	app.TableManager.SetOnTableEvent(func(event domain.TableEvent) {
+		resp := ws.NewEventResponse(event)
 		if event.TargetPlayerID != "" {
 			playerUUID, err := uuid.Parse(event.TargetPlayerID)
 			if err == nil {
@@ -55,6 +65,33 @@ func main() {
 		}
 	})
 
+	// 崩潰復原：依策略重建或兌現上次運行中斷的牌桌（須在接受連線前完成）
+	recoverCtx, cancelRecover := context.WithTimeout(context.Background(), 30*time.Second)
+	report, err := app.RecoveryService.Recover(recoverCtx)
+	cancelRecover()
+	if err != nil {
+		app.Logger.Fatal("failed to recover tables", zap.Error(err))
+	}
+	for _, rt := range report.Tables {
+		if !rt.Restore {
+			continue
+		}
+		if _, err := app.TableManager.RestoreTable(rt.Snapshot); err != nil {
+			app.Logger.Error("failed to restore table",
+				zap.String("table_id", rt.Snapshot.TableID), zap.Error(err))
+		}
+	}
+	app.RecoveryService.Start()
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	go app.TableManager.RunSnapshots(backgroundCtx, app.Config.Game.Recovery.GetSnapshotInterval())
+
+	// 孤兒會話對帳：啟動時（牌桌復原後）執行一次，之後定期執行
+	app.SessionReconciler.SetSeatLocator(app.TableManager)
+	if _, err := app.SessionReconciler.Reconcile(backgroundCtx); err != nil {
+		app.Logger.Error("startup session reconciliation failed", zap.Error(err))
+	}
+	go app.SessionReconciler.Run(backgroundCtx, app.Config.Game.Reconciliation.GetInterval())
+
 	// 3. 啟動背景服務
 	go app.Hub.Run()
 	app.Logger.Info("server started",
@@ -65,13 +102,71 @@ func main() {
 	// 3. 設定 HTTP 路由
 	mux := http.NewServeMux()
 
-	// 認證路由（公開）
-	mux.HandleFunc("/api/auth/register", app.AuthHandler.HandleRegister)
-	mux.HandleFunc("/api/auth/login", app.AuthHandler.HandleLogin)
+	// 認證路由（公開，依 IP 限流）
+	authLimit := rateLimit(app.RateLimiters.Auth, auth.RateLimitByIP)
+	mux.Handle("/api/auth/register", authLimit(http.HandlerFunc(app.AuthHandler.HandleRegister)))
+	mux.Handle("/api/auth/login", authLimit(http.HandlerFunc(app.AuthHandler.HandleLogin)))
+	mux.Handle("/api/auth/login/2fa", authLimit(http.HandlerFunc(app.AuthHandler.HandleLoginTwoFactor)))
+	mux.Handle("/api/auth/refresh", authLimit(http.HandlerFunc(app.AuthHandler.HandleRefresh)))
+	mux.Handle("/api/auth/verify-email", authLimit(http.HandlerFunc(app.AuthHandler.HandleVerifyEmail)))
+	mux.Handle("/api/auth/forgot-password", authLimit(http.HandlerFunc(app.AuthHandler.HandleForgotPassword)))
+	mux.Handle("/api/auth/reset-password", authLimit(http.HandlerFunc(app.AuthHandler.HandleResetPassword)))
+	mux.Handle("/.well-known/jwks.json", auth.NewJWKSHandler(app.JWTService))
+
+	// 票券、登出、登入裝置管理與修改密碼（需要 JWT 認證，且登入會話未被撤銷；依玩家限流）
+	jwtMiddleware := auth.JWTMiddleware(app.JWTService, app.SessionService)
+	accountLimit := rateLimit(app.RateLimiters.Account, auth.RateLimitByPlayer)
+	account := func(pattern string, handler http.HandlerFunc) {
+		mux.Handle(pattern, jwtMiddleware(accountLimit(handler)))
+	}
+	account("/api/auth/ticket", app.AuthHandler.HandleGetTicket)
+	account("/api/auth/logout", app.AuthHandler.HandleLogout)
+	account("/api/auth/logout-all", app.AuthHandler.HandleLogoutAll)
+	account("GET /api/auth/sessions", app.AuthHandler.HandleListSessions)
+	account("DELETE /api/auth/sessions/{session_id}", app.AuthHandler.HandleRevokeSession)
+	account("/api/auth/password", app.AuthHandler.HandleChangePassword)
+	account("/api/auth/verify-email/resend", app.AuthHandler.HandleResendVerification)
+	account("/api/auth/2fa/enroll", app.AuthHandler.HandleTwoFactorEnroll)
+	account("/api/auth/2fa/confirm", app.AuthHandler.HandleTwoFactorConfirm)
+	account("/api/auth/2fa/disable", app.AuthHandler.HandleTwoFactorDisable)
+	account("/api/auth/2fa/recovery-codes", app.AuthHandler.HandleRegenerateRecoveryCodes)
+
+	// 手牌歷史下載（需要 JWT 認證，僅能下載自己參與的手牌）
+	mux.Handle("/api/hands/{hand_id}", jwtMiddleware(http.HandlerFunc(app.HandHistoryHandler.HandleExportHand)))
+	mux.Handle("/api/sessions/{session_id}/hands", jwtMiddleware(http.HandlerFunc(app.HandHistoryHandler.HandleExportSession)))
+
+	// 錢包查詢（需要 JWT 認證，僅能查詢自己的錢包）
+	mux.Handle("/api/wallet", jwtMiddleware(http.HandlerFunc(app.WalletHandler.HandleGetBalance)))
+	mux.Handle("/api/wallet/transactions", jwtMiddleware(http.HandlerFunc(app.WalletHandler.HandleListTransactions)))
+	mux.Handle("/api/wallet/statement", jwtMiddleware(http.HandlerFunc(app.WalletHandler.HandleGetStatement)))
+
+	// 返水查詢（需要 JWT 認證）
+	mux.Handle("/api/rakeback", jwtMiddleware(http.HandlerFunc(app.RakeHandler.HandleGetRakeback)))
+
+	// 管理路由（需要 JWT 認證 + 帳號角色，且登入時通過兩步驟驗證）
+	admin := func(pattern string, handler http.HandlerFunc, roles ...string) {
+		mux.Handle(pattern, jwtMiddleware(auth.RequireRole(roles...)(handler)))
+	}
 
-	// 票券路由（需要 JWT 認證）
-	jwtMiddleware := auth.JWTMiddleware(app.JWTService)
-	mux.Handle("/api/auth/ticket", jwtMiddleware(http.HandlerFunc(app.AuthHandler.HandleGetTicket)))
+	// 牌桌管理
+	admin("GET /api/admin/tables", app.TableAdminHandler.HandleList, repository.RoleAdmin, repository.RoleSupport)
+	admin("GET /api/admin/tables/{table_id}", app.TableAdminHandler.HandleGet, repository.RoleAdmin, repository.RoleSupport)
+	admin("/api/admin/tables/{table_id}/kick", app.TableAdminHandler.HandleKick, repository.RoleAdmin, repository.RoleSupport)
+	admin("/api/admin/hands/{hand_id}/replay", app.HandHistoryHandler.HandleReplayHand, repository.RoleAdmin, repository.RoleSupport)
+
+	// 玩家查詢與帳號管理
+	admin("GET /api/admin/players", app.AccountAdminHandler.HandleSearch, repository.RoleAdmin, repository.RoleSupport, repository.RoleFinance)
+	admin("GET /api/admin/players/{player_id}", app.AccountAdminHandler.HandleGetPlayer, repository.RoleAdmin, repository.RoleSupport, repository.RoleFinance)
+	admin("/api/admin/accounts/{account_id}/status", app.AccountAdminHandler.HandleSetStatus, repository.RoleAdmin, repository.RoleSupport)
+	admin("/api/admin/accounts/{account_id}/roles", app.AccountAdminHandler.HandleSetRoles, repository.RoleAdmin)
+	admin("/api/admin/audit-logs", app.AuditHandler.HandleList, repository.RoleAdmin, repository.RoleSupport)
+
+	// 錢包調整與營收
+	admin("POST /api/admin/wallet-adjustments", app.AdjustmentHandler.HandleCreate, repository.RoleAdmin, repository.RoleFinance)
+	admin("GET /api/admin/wallet-adjustments", app.AdjustmentHandler.HandleList, repository.RoleAdmin, repository.RoleFinance)
+	admin("/api/admin/wallet-adjustments/{id}/approve", app.AdjustmentHandler.HandleApprove, repository.RoleAdmin, repository.RoleFinance)
+	admin("/api/admin/wallet-adjustments/{id}/reject", app.AdjustmentHandler.HandleReject, repository.RoleAdmin, repository.RoleFinance)
+	admin("/api/admin/revenue", app.RakeHandler.HandleGetRevenue, repository.RoleAdmin, repository.RoleFinance)
 
 	// WebSocket 路由（需要票券）
 	mux.Handle("/ws", app.WSHandler)
@@ -127,6 +222,15 @@ func main() {
 		app.Logger.Fatal("server forced to shutdown", zap.Error(err))
 	}
 
+	stopBackground()
 	app.Stop(ctx)
 	app.Logger.Info("server exited")
 }
+
+// rateLimit 建立限流中介層，未設定限流器時直接放行
+func rateLimit(limiter auth.RateLimiter, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	if limiter == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return auth.RateLimitMiddleware(limiter, keyFunc)
+}
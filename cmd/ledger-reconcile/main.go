@@ -0,0 +1,103 @@
+// ledger-reconcile 對帳命令：比對錢包餘額與複式記帳分錄，發現差異時以非 0 狀態碼結束
+//
+//	go run ./cmd/ledger-reconcile -config config.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/config"
+	"github.com/shinjuwu/TheNuts/internal/infra/database"
+	"github.com/shinjuwu/TheNuts/internal/infra/logger"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository/postgres"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to config file")
+	timeout := flag.Duration("timeout", time.Minute, "reconciliation timeout")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	zapLogger, err := logger.NewLogger()
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	defer zapLogger.Sync()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db, err := database.NewPostgresPool(ctx, cfg.Database.Postgres, zapLogger)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	defer db.Close()
+
+	walletRepo := postgres.NewWalletRepository(db.Pool, postgres.NewTransactionRepository(db.Pool))
+	ledgerService := service.NewLedgerService(postgres.NewLedgerRepository(db.Pool), walletRepo, zapLogger)
+	ledgerService.SetGamePoolTolerance(cfg.Wallet.GamePoolTolerance)
+
+	report, err := ledgerService.Reconcile(ctx)
+	if err != nil {
+		log.Fatalf("failed to reconcile ledger: %v", err)
+	}
+
+	printReport(report)
+	if !report.Balanced() {
+		os.Exit(1)
+	}
+}
+
+// printReport 輸出對帳報告（金額單位：分）
+func printReport(r *service.LedgerReconciliation) {
+	fmt.Printf("Ledger reconciliation at %s\n\n", r.GeneratedAt.Format(time.RFC3339))
+	for _, c := range r.Currencies {
+		fmt.Printf("[%s]\n", c.Currency)
+		fmt.Printf("  wallet balances      %14d\n", c.TotalWallets)
+		fmt.Printf("  table escrow         %14d\n", c.TotalEscrow)
+		fmt.Printf("  house rake           %14d\n", c.HouseRake)
+		fmt.Printf("  = actual             %14d\n\n", c.Actual())
+		fmt.Printf("  deposits             %14d\n", c.Deposits)
+		fmt.Printf("  - withdrawals        %14d\n", c.Withdrawals)
+		fmt.Printf("  + bonuses            %14d\n", c.Bonuses)
+		fmt.Printf("  - game pool          %14d\n", c.GamePool)
+		fmt.Printf("  = expected           %14d\n\n", c.Expected())
+
+		if diff := c.Actual() - c.Expected(); diff != 0 {
+			fmt.Printf("TOTALS MISMATCH (%s): actual - expected = %d\n\n", c.Currency, diff)
+		}
+		if c.GamePoolStranded() {
+			fmt.Printf("STRANDED GAME POOL (%s): %d exceeds tolerance %d\n\n", c.Currency, c.GamePool, c.GamePoolTolerance)
+		}
+	}
+
+	if len(r.UnbalancedJournals) > 0 {
+		fmt.Printf("UNBALANCED JOURNALS (%d):\n", len(r.UnbalancedJournals))
+		for _, id := range r.UnbalancedJournals {
+			fmt.Printf("  %s\n", id)
+		}
+	}
+
+	if len(r.Discrepancies) > 0 {
+		fmt.Printf("PLAYER DISCREPANCIES (%d):\n", len(r.Discrepancies))
+		fmt.Printf("  %-36s %-8s %12s %12s %12s %12s\n", "player_id", "currency", "balance", "ledger", "locked", "ledger_esc")
+		for _, d := range r.Discrepancies {
+			fmt.Printf("  %-36s %-8s %12d %12d %12d %12d\n",
+				d.PlayerID, d.Currency, d.WalletBalance, d.LedgerWallet, d.LockedBalance, d.LedgerEscrow)
+		}
+	}
+
+	if r.Balanced() {
+		fmt.Println("OK: ledger is balanced")
+	}
+}
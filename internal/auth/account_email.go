@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidEmailToken 邮件 token 无效、已使用或已过期
+	ErrInvalidEmailToken = errors.New("invalid or expired token")
+	// ErrEmailAlreadyVerified 邮箱已验证
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+const (
+	// EmailVerificationTTL 邮箱验证链接有效期
+	EmailVerificationTTL = 24 * time.Hour
+	// PasswordResetTTL 密码重设链接有效期
+	PasswordResetTTL = time.Hour
+)
+
+// 邮箱验证与密码重设的审计动作
+const (
+	AuditActionEmailVerified          = "email_verified"
+	AuditActionPasswordResetRequested = "password_reset_requested"
+	AuditActionPasswordReset          = "password_reset"
+)
+
+// SetAccountTokenRepository 设定账号一次性 token 仓储（邮箱验证、密码重设）
+func (s *AuthService) SetAccountTokenRepository(tokenRepo repository.AccountTokenRepository) {
+	s.tokenRepo = tokenRepo
+}
+
+// SetMailer 设定寄信器与邮件链接的前端网址（例如 https://thenuts.example.com）
+func (s *AuthService) SetMailer(mailer Mailer, linkBaseURL string) {
+	s.mailer = mailer
+	s.linkBaseURL = strings.TrimRight(linkBaseURL, "/")
+}
+
+// SendEmailVerification 寄出邮箱验证链接（旧链接同时失效）
+func (s *AuthService) SendEmailVerification(ctx context.Context, account *repository.Account) error {
+	token, err := s.issueAccountToken(ctx, account, repository.AccountTokenEmailVerification, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.sendMail(ctx, Mail{
+		To:      account.Email,
+		Subject: "Verify your TheNuts email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			account.Username, s.link("/verify-email", token), int(EmailVerificationTTL.Hours())),
+	})
+}
+
+// ResendEmailVerification 重新寄出邮箱验证链接
+func (s *AuthService) ResendEmailVerification(ctx context.Context, playerID uuid.UUID) error {
+	account, err := s.accountByPlayerID(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if account.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	return s.SendEmailVerification(ctx, account)
+}
+
+// VerifyEmail 以邮件中的 token 完成邮箱验证（token 单次有效）
+func (s *AuthService) VerifyEmail(ctx context.Context, token, ipAddress, userAgent string) (*repository.Account, error) {
+	record, account, err := s.consumeAccountToken(ctx, token, repository.AccountTokenEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+	// 签发后邮箱已变更：旧邮箱的链接不能验证新邮箱
+	if !strings.EqualFold(account.Email, record.Email) {
+		return nil, ErrInvalidEmailToken
+	}
+
+	if !account.EmailVerified {
+		account.EmailVerified = true
+		if err := s.accountRepo.Update(ctx, account); err != nil {
+			return nil, fmt.Errorf("failed to update account: %w", err)
+		}
+		s.audit(ctx, account.ID, AuditActionEmailVerified, ipAddress, userAgent,
+			map[string]interface{}{"email_verified": false},
+			map[string]interface{}{"email_verified": true, "email": account.Email},
+		)
+		s.logger.Info("email verified",
+			zap.String("account_id", account.ID.String()),
+		)
+	}
+
+	return account, nil
+}
+
+// RequestPasswordReset 寄出密码重设链接
+// 邮箱不存在时同样回传 nil，避免泄露哪些邮箱已注册
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email, ipAddress, userAgent string) error {
+	account, err := s.accountRepo.GetByEmail(ctx, email)
+	if err != nil || account == nil {
+		s.logger.Info("password reset requested for unknown email", zap.String("ip", ipAddress))
+		return nil
+	}
+	if account.Status == "banned" {
+		s.logger.Warn("password reset requested for banned account",
+			zap.String("account_id", account.ID.String()),
+		)
+		return nil
+	}
+
+	token, err := s.issueAccountToken(ctx, account, repository.AccountTokenPasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	s.audit(ctx, account.ID, AuditActionPasswordResetRequested, ipAddress, userAgent, nil, nil)
+
+	return s.sendMail(ctx, Mail{
+		To:      account.Email,
+		Subject: "Reset your TheNuts password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %d minutes. If you did not request this, you can ignore this email.\n",
+			account.Username, s.link("/reset-password", token), int(PasswordResetTTL.Minutes())),
+	})
+}
+
+// ResetPassword 以邮件中的 token 重设密码（token 单次有效）
+// 成功后解除锁定并撤销玩家所有登入会话
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword, ipAddress, userAgent string) error {
+	if newPassword == "" {
+		return ErrPasswordRequired
+	}
+
+	record, account, err := s.consumeAccountToken(ctx, token, repository.AccountTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), BcryptCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	account.PasswordHash = string(passwordHash)
+	// 能收到重设邮件即证明拥有该邮箱
+	if strings.EqualFold(account.Email, record.Email) {
+		account.EmailVerified = true
+	}
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if err := s.accountRepo.ResetFailedAttempts(ctx, account.ID); err != nil {
+		s.logger.Error("failed to reset failed attempts", zap.Error(err))
+	}
+
+	if s.sessions != nil {
+		player, err := s.playerRepo.GetByAccountID(ctx, account.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get player: %w", err)
+		}
+		if err := s.sessions.RevokeAll(ctx, player.ID, RevokeReasonPasswordReset); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	s.audit(ctx, account.ID, AuditActionPasswordReset, ipAddress, userAgent, nil, map[string]interface{}{
+		"sessions_revoked": s.sessions != nil,
+	})
+	s.logger.Info("password reset",
+		zap.String("account_id", account.ID.String()),
+	)
+
+	return nil
+}
+
+// issueAccountToken 产生一次性 token 并保存其哈希，回传明文 token
+func (s *AuthService) issueAccountToken(ctx context.Context, account *repository.Account, purpose string, ttl time.Duration) (string, error) {
+	if s.tokenRepo == nil {
+		return "", errors.New("account token repository not configured")
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = s.tokenRepo.Create(ctx, &repository.AccountToken{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     account.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save %s token: %w", purpose, err)
+	}
+	return token, nil
+}
+
+// consumeAccountToken 取用一次性 token 并查询对应账号
+func (s *AuthService) consumeAccountToken(ctx context.Context, token, purpose string) (*repository.AccountToken, *repository.Account, error) {
+	if token == "" || s.tokenRepo == nil {
+		return nil, nil, ErrInvalidEmailToken
+	}
+
+	record, err := s.tokenRepo.Consume(ctx, hashToken(token), purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record == nil {
+		return nil, nil, ErrInvalidEmailToken
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, record.AccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return record, account, nil
+}
+
+// accountByPlayerID 通过玩家 ID 查询账号
+func (s *AuthService) accountByPlayerID(ctx context.Context, playerID uuid.UUID) (*repository.Account, error) {
+	player, err := s.playerRepo.GetByID(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+	account, err := s.accountRepo.GetByID(ctx, player.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, nil
+}
+
+// sendMail 寄出邮件（未设定寄信器时只记录日志）
+func (s *AuthService) sendMail(ctx context.Context, mail Mail) error {
+	if s.mailer == nil {
+		s.logger.Warn("mailer not configured, email not sent", zap.String("subject", mail.Subject))
+		return nil
+	}
+	return s.mailer.Send(ctx, mail)
+}
+
+// link 组合邮件中的前端链接
+func (s *AuthService) link(path, token string) string {
+	return s.linkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryAccountTokenRepo 以記憶體保存帳號一次性 Token
+type memoryAccountTokenRepo struct {
+	tokens []*repository.AccountToken
+}
+
+func (r *memoryAccountTokenRepo) Create(ctx context.Context, token *repository.AccountToken) error {
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.AccountID == token.AccountID && t.Purpose == token.Purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *memoryAccountTokenRepo) Consume(ctx context.Context, tokenHash, purpose string) (*repository.AccountToken, error) {
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash && t.Purpose == purpose && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			t.UsedAt = &now
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+var mailTokenPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastMailToken 取出最後一封郵件連結中的 token
+func lastMailToken(t *testing.T, mailer *MemoryMailer) string {
+	t.Helper()
+	sent := mailer.Sent()
+	if len(sent) == 0 {
+		t.Fatal("expected an email to be sent")
+	}
+	match := mailTokenPattern.FindStringSubmatch(sent[len(sent)-1].Body)
+	if match == nil {
+		t.Fatalf("no token link in email: %q", sent[len(sent)-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func newEmailFlowFixture(t *testing.T) (*AuthService, *memoryAccountRepo, *MemoryMailer, *memoryAuditLogRepo) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountRepo := &memoryAccountRepo{account: &repository.Account{
+		ID:           uuid.New(),
+		Username:     "alice",
+		Email:        "alice@example.com",
+		PasswordHash: string(hash),
+		Status:       "active",
+	}}
+	mailer := NewMemoryMailer()
+	auditRepo := &memoryAuditLogRepo{}
+	svc := NewAuthService(accountRepo, memoryPlayerRepo{}, nil, zap.NewNop())
+	svc.SetAuditLogRepository(auditRepo)
+	svc.SetAccountTokenRepository(&memoryAccountTokenRepo{})
+	svc.SetMailer(mailer, "https://thenuts.example.com/")
+	return svc, accountRepo, mailer, auditRepo
+}
+
+// TestVerifyEmail_SingleUseToken 驗證連結單次有效，重新寄送後舊連結失效
+func TestVerifyEmail_SingleUseToken(t *testing.T) {
+	ctx := context.Background()
+	svc, accountRepo, mailer, auditRepo := newEmailFlowFixture(t)
+
+	if err := svc.SendEmailVerification(ctx, accountRepo.account); err != nil {
+		t.Fatalf("SendEmailVerification failed: %v", err)
+	}
+	stale := lastMailToken(t, mailer)
+	if err := svc.SendEmailVerification(ctx, accountRepo.account); err != nil {
+		t.Fatalf("SendEmailVerification failed: %v", err)
+	}
+	token := lastMailToken(t, mailer)
+	if sent := mailer.Sent(); sent[1].To != "alice@example.com" {
+		t.Fatalf("unexpected recipient %q", sent[1].To)
+	}
+
+	if _, err := svc.VerifyEmail(ctx, stale, "", ""); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("expected superseded token to be rejected, got %v", err)
+	}
+	if _, err := svc.VerifyEmail(ctx, token, "198.51.100.1", "browser"); err != nil {
+		t.Fatalf("VerifyEmail failed: %v", err)
+	}
+	if !accountRepo.account.EmailVerified {
+		t.Fatal("expected account to be verified")
+	}
+	if _, err := svc.VerifyEmail(ctx, token, "", ""); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("expected used token to be rejected, got %v", err)
+	}
+	if got := auditRepo.actions(); len(got) != 1 || got[0] != AuditActionEmailVerified {
+		t.Fatalf("expected email_verified audit, got %v", got)
+	}
+}
+
+// TestVerifyEmail_RejectsTokenForChangedEmail 簽發後信箱已變更，舊信箱的連結不能驗證新信箱
+func TestVerifyEmail_RejectsTokenForChangedEmail(t *testing.T) {
+	ctx := context.Background()
+	svc, accountRepo, mailer, _ := newEmailFlowFixture(t)
+
+	if err := svc.SendEmailVerification(ctx, accountRepo.account); err != nil {
+		t.Fatal(err)
+	}
+	accountRepo.account.Email = "mallory@example.com"
+
+	if _, err := svc.VerifyEmail(ctx, lastMailToken(t, mailer), "", ""); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("expected ErrInvalidEmailToken, got %v", err)
+	}
+	if accountRepo.account.EmailVerified {
+		t.Fatal("changed email must not be verified")
+	}
+}
+
+// TestResetPassword_RevokesSessionsAndUnlocks 重設密碼後舊密碼失效、解除鎖定並撤銷所有會話
+func TestResetPassword_RevokesSessionsAndUnlocks(t *testing.T) {
+	ctx := context.Background()
+	svc, accountRepo, mailer, auditRepo := newEmailFlowFixture(t)
+	player := &repository.Player{ID: uuid.New(), AccountID: accountRepo.account.ID}
+	svc.playerRepo = memoryPlayerRepo{player: player}
+	sessions := NewSessionService(newMemorySessionRepo(), NewJWTService("test-secret"), zap.NewNop())
+	svc.SetSessionService(sessions)
+
+	existing, _ := sessions.Start(ctx, SessionIdentity{PlayerID: player.ID, Username: "alice"}, "", "phone")
+	lockedUntil := time.Now().Add(time.Hour)
+	accountRepo.account.LockedUntil = &lockedUntil
+	accountRepo.account.FailedLoginAttempts = MaxFailedAttempts
+
+	// 未註冊的信箱同樣回傳成功，但不寄信
+	if err := svc.RequestPasswordReset(ctx, "nobody@example.com", "", ""); err != nil {
+		t.Fatalf("expected unknown email to be silently ignored, got %v", err)
+	}
+	if len(mailer.Sent()) != 0 {
+		t.Fatal("expected no email for unknown address")
+	}
+
+	if err := svc.RequestPasswordReset(ctx, "alice@example.com", "198.51.100.1", "browser"); err != nil {
+		t.Fatalf("RequestPasswordReset failed: %v", err)
+	}
+	token := lastMailToken(t, mailer)
+
+	if err := svc.ResetPassword(ctx, "bogus", "new-password", "", ""); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("expected ErrInvalidEmailToken, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "new-password", "198.51.100.1", "browser"); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "another-password", "", ""); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("expected reset token to be single-use, got %v", err)
+	}
+
+	if active, _ := sessions.IsSessionActive(ctx, existing.SessionID); active {
+		t.Fatal("expected existing sessions to be revoked")
+	}
+	if !accountRepo.account.EmailVerified {
+		t.Error("expected reset via email to verify the address")
+	}
+	if _, _, err := svc.Authenticate(ctx, "alice", "new-password", "", ""); err != nil {
+		t.Fatalf("expected login with new password after unlock, got %v", err)
+	}
+
+	want := []string{AuditActionPasswordResetRequested, AuditActionPasswordReset, AuditActionLogin}
+	got := auditRepo.actions()
+	if len(got) != len(want) {
+		t.Fatalf("expected actions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected actions %v, got %v", want, got)
+		}
+	}
+}
@@ -3,29 +3,36 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
 	"go.uber.org/zap"
 )
 
 // Handler 認證相關的 HTTP Handler
 type Handler struct {
-	jwtService  *JWTService
-	ticketStore TicketStore
-	authService *AuthService
-	logger      *zap.Logger
-	ticketTTL   time.Duration // 票券有效期（預設 30 秒）
+	sessionService *SessionService
+	ticketStore    TicketStore
+	authService    *AuthService
+	logger         *zap.Logger
+	ticketTTL      time.Duration // 票券有效期（預設 30 秒）
 }
 
 // NewHandler 創建認證 Handler
-func NewHandler(jwtService *JWTService, ticketStore TicketStore, authService *AuthService, logger *zap.Logger) *Handler {
+func NewHandler(sessionService *SessionService, ticketStore TicketStore, authService *AuthService, logger *zap.Logger) *Handler {
 	return &Handler{
-		jwtService:  jwtService,
-		ticketStore: ticketStore,
-		authService: authService,
-		logger:      logger,
-		ticketTTL:   30 * time.Second, // 預設 30 秒
+		sessionService: sessionService,
+		ticketStore:    ticketStore,
+		authService:    authService,
+		logger:         logger,
+		ticketTTL:      30 * time.Second, // 預設 30 秒
 	}
 }
 
@@ -42,11 +49,79 @@ type LoginRequest struct {
 
 // LoginResponse 登入回應
 type LoginResponse struct {
-	Token       string `json:"token"`
-	PlayerID    string `json:"player_id"`
-	AccountID   string `json:"account_id"`
-	Username    string `json:"username"`
-	DisplayName string `json:"display_name"`
+	Token        string   `json:"token"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpiresIn    int      `json:"expires_in"` // Access Token 有效秒數
+	SessionID    string   `json:"session_id"`
+	PlayerID     string   `json:"player_id"`
+	AccountID    string   `json:"account_id"`
+	Username     string   `json:"username"`
+	DisplayName  string   `json:"display_name"`
+	Roles        []string `json:"roles,omitempty"` // 管理角色
+}
+
+// TwoFactorChallengeResponse 已啟用兩步驗證時的登入回應
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+// LoginTwoFactorRequest 登入第二步請求（code 可為 6 位數驗證碼或恢復碼）
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorCodeRequest 需要驗證碼確認的兩步驗證操作
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorEnrollResponse 開始綁定兩步驗證的回應
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse 恢復碼（只顯示一次）
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshRequest 刷新 Token 請求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse 刷新 Token 或修改密碼後的新憑證
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	SessionID    string `json:"session_id"`
+}
+
+// SessionResponse 登入會話（裝置）
+type SessionResponse struct {
+	ID         string     `json:"id"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"` // 目前請求使用的會話
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// SessionListResponse 登入會話列表
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// ChangePasswordRequest 修改密碼請求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
 }
 
 // RegisterRequest 注册请求
@@ -64,6 +139,27 @@ type RegisterResponse struct {
 	Message   string `json:"message"`
 }
 
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest 重设密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// MessageResponse 一般讯息回应
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
 // ErrorResponse 错误回应
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -104,47 +200,306 @@ func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 获取客户端 IP 地址
-	ipAddress := getClientIP(r)
+	ipAddress := ClientIP(r)
 
 	// 使用 AuthService 进行身份验证
-	account, player, err := h.authService.Authenticate(r.Context(), req.Username, req.Password, ipAddress)
+	account, player, err := h.authService.Authenticate(r.Context(), req.Username, req.Password, ipAddress, r.UserAgent())
 	if err != nil {
-		// 根据错误类型返回不同的状态码
-		switch err {
-		case ErrInvalidCredentials:
-			h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Invalid username or password")
-		case ErrAccountLocked:
-			h.writeErrorResponse(w, http.StatusForbidden, "account_locked", "Account is temporarily locked due to too many failed login attempts")
-		case ErrAccountSuspended:
-			h.writeErrorResponse(w, http.StatusForbidden, "account_suspended", "Account has been suspended")
-		case ErrAccountBanned:
-			h.writeErrorResponse(w, http.StatusForbidden, "account_banned", "Account has been banned")
+		// 已启用两步验证：回传挑战 token，由 /api/auth/login/2fa 完成登录
+		var challenge *TwoFactorRequiredError
+		if errors.As(err, &challenge) {
+			h.writeJSON(w, http.StatusOK, TwoFactorChallengeResponse{
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge.ChallengeToken,
+				ExpiresIn:         int(challenge.ExpiresIn.Seconds()),
+			})
+			return
+		}
+		h.writeLoginError(w, err)
+		return
+	}
+
+	h.writeLoginResponse(w, r, account, player, false, ipAddress)
+}
+
+// HandleLoginTwoFactor 登录第二步：以挑战 token 与验证码（或恢复码）完成登录
+func (h *Handler) HandleLoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LoginTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.ChallengeToken == "" || req.Code == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Challenge token and code are required")
+		return
+	}
+
+	ipAddress := ClientIP(r)
+	account, player, err := h.authService.CompleteTwoFactorLogin(r.Context(), req.ChallengeToken, req.Code, ipAddress, r.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidChallenge):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_challenge", "Login challenge is invalid or has expired; please login again")
+		case errors.Is(err, ErrInvalidTwoFactorCode):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_two_factor_code", "Invalid two-factor code")
 		default:
-			h.logger.Error("authentication failed", zap.Error(err))
-			h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+			h.writeLoginError(w, err)
 		}
 		return
 	}
 
-	// 生成 JWT Token（有效期 24 小時）
-	token, err := h.jwtService.GenerateToken(player.ID.String(), account.Username, 24*time.Hour)
+	h.writeLoginResponse(w, r, account, player, true, ipAddress)
+}
+
+// writeLoginError 根据错误类型返回不同的状态码
+func (h *Handler) writeLoginError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Invalid username or password")
+	case errors.Is(err, ErrAccountLocked):
+		h.writeErrorResponse(w, http.StatusForbidden, "account_locked", "Account is temporarily locked due to too many failed login attempts")
+	case errors.Is(err, ErrAccountSuspended):
+		h.writeErrorResponse(w, http.StatusForbidden, "account_suspended", "Account has been suspended")
+	case errors.Is(err, ErrAccountBanned):
+		h.writeErrorResponse(w, http.StatusForbidden, "account_banned", "Account has been banned")
+	default:
+		h.logger.Error("authentication failed", zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// writeLoginResponse 建立登入會話並返回 Token 和用户信息
+func (h *Handler) writeLoginResponse(w http.ResponseWriter, r *http.Request, account *repository.Account, player *repository.Player, mfa bool, ipAddress string) {
+	tokens, err := h.sessionService.Start(r.Context(), SessionIdentity{
+		PlayerID: player.ID,
+		Username: account.Username,
+		Roles:    account.Roles,
+		MFA:      mfa,
+	}, ipAddress, r.UserAgent())
 	if err != nil {
-		h.logger.Error("failed to generate token", zap.Error(err))
+		h.logger.Error("failed to start session", zap.Error(err))
 		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to generate token")
 		return
 	}
 
-	// 返回 Token 和用户信息
-	resp := LoginResponse{
-		Token:       token,
-		PlayerID:    player.ID.String(),
-		AccountID:   account.ID.String(),
-		Username:    account.Username,
-		DisplayName: player.DisplayName,
+	h.writeJSON(w, http.StatusOK, LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.AccessExpiresIn.Seconds()),
+		SessionID:    tokens.SessionID,
+		PlayerID:     player.ID.String(),
+		AccountID:    account.ID.String(),
+		Username:     account.Username,
+		DisplayName:  player.DisplayName,
+		Roles:        account.Roles,
+	})
+}
+
+// HandleRefresh 以 Refresh Token 換發新的 Access Token（Refresh Token 同時輪替）
+func (h *Handler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.RefreshToken == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Refresh token is required")
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), req.RefreshToken, ClientIP(r), r.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidRefreshToken):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_refresh_token", "Refresh token is invalid or expired")
+		case errors.Is(err, ErrRefreshTokenReused):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "refresh_token_reused", "Refresh token has already been used; please login again")
+		case errors.Is(err, ErrSessionRevoked):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "session_revoked", "Session has been revoked; please login again")
+		default:
+			h.logger.Error("failed to refresh token", zap.Error(err))
+			h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		}
+		return
+	}
+
+	h.writeTokenResponse(w, tokens)
+}
+
+// HandleLogout 登出：撤銷目前的登入會話（需要 JWT）
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID, ok := GetSessionIDFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Session not found in token")
+		return
+	}
+
+	if err := h.sessionService.Revoke(r.Context(), sessionID, RevokeReasonLogout); err != nil {
+		h.logger.Error("failed to revoke session", zap.String("session_id", sessionID), zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleLogoutAll 登出所有裝置：撤銷玩家所有登入會話（需要 JWT）
+func (h *Handler) HandleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := h.playerIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeAll(r.Context(), playerID, RevokeReasonLogoutAll); err != nil {
+		h.logger.Error("failed to revoke sessions", zap.String("player_id", playerID.String()), zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListSessions 列出玩家目前登入中的裝置（需要 JWT）
+func (h *Handler) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := h.playerIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	currentID, _ := GetSessionIDFromContext(r.Context())
+
+	sessions, err := h.sessionService.ListActive(r.Context(), playerID)
+	if err != nil {
+		h.logger.Error("failed to list sessions", zap.String("player_id", playerID.String()), zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	resp := SessionListResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, SessionResponse{
+			ID:         session.ID,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleRevokeSession 撤銷玩家自己的某個登入會話，該裝置的 WebSocket 連線會被斷開（需要 JWT）
+func (h *Handler) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := h.playerIDFromContext(w, r)
+	if !ok {
+		return
+	}
+	sessionID := r.PathValue("session_id")
+
+	err := h.sessionService.RevokeOwned(r.Context(), playerID, sessionID, RevokeReasonUserRevoked)
+	if errors.Is(err, ErrSessionNotFound) {
+		h.writeErrorResponse(w, http.StatusNotFound, "not_found", "Session not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to revoke session", zap.String("session_id", sessionID), zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleChangePassword 修改密碼（需要 JWT）
+// 成功後撤銷玩家所有會話，並為目前的請求簽發新憑證
+func (h *Handler) HandleChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerIDStr, ok := GetPlayerIDFromContext(r.Context())
+	if !ok {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Player not found in token")
+		return
+	}
+	playerID, err := uuid.Parse(playerIDStr)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Invalid player ID in token")
+		return
+	}
+	username, _ := GetUsernameFromContext(r.Context())
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Current and new password are required")
+		return
+	}
+
+	ipAddress := ClientIP(r)
+	err = h.authService.ChangePassword(r.Context(), playerID, req.CurrentPassword, req.NewPassword, ipAddress, r.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			h.writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Current password is incorrect")
+		case errors.Is(err, ErrPasswordRequired):
+			h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "New password is required")
+		default:
+			h.logger.Error("failed to change password", zap.String("player_id", playerIDStr), zap.Error(err))
+			h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		}
+		return
+	}
+
+	// 新會話沿用目前會話的角色與兩步驗證狀態
+	tokens, err := h.sessionService.Start(r.Context(), SessionIdentity{
+		PlayerID: playerID,
+		Username: username,
+		Roles:    GetRolesFromContext(r.Context()),
+		MFA:      GetMFAFromContext(r.Context()),
+	}, ipAddress, r.UserAgent())
+	if err != nil {
+		h.logger.Error("failed to start session", zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to generate token")
+		return
+	}
+
+	h.writeTokenResponse(w, tokens)
 }
 
 // HandleGetTicket 處理獲取 WebSocket 票券的請求
@@ -169,8 +524,9 @@ func (h *Handler) HandleGetTicket(w http.ResponseWriter, r *http.Request) {
 		req = TicketRequest{}
 	}
 
-	// 生成一次性票券
-	ticket, err := h.ticketStore.Generate(context.Background(), playerID, h.ticketTTL)
+	// 生成一次性票券（關聯目前的登入會話，會話撤銷時斷開 WebSocket 連線）
+	sessionID, _ := GetSessionIDFromContext(r.Context())
+	ticket, err := h.ticketStore.Generate(context.Background(), TicketClaims{PlayerID: playerID, SessionID: sessionID}, h.ticketTTL)
 	if err != nil {
 		h.logger.Error("failed to generate ticket",
 			zap.String("player_id", playerID),
@@ -224,7 +580,7 @@ func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 注册用户
-	account, player, err := h.authService.Register(r.Context(), req.Username, req.Email, req.Password)
+	account, player, err := h.authService.Register(r.Context(), req.Username, req.Email, req.Password, ClientIP(r), r.UserAgent())
 	if err != nil {
 		switch err {
 		case ErrUsernameExists:
@@ -243,7 +599,7 @@ func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 		AccountID: account.ID.String(),
 		PlayerID:  player.ID.String(),
 		Username:  account.Username,
-		Message:   "Registration successful. Please login.",
+		Message:   "Registration successful. Please check your email to verify your address.",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -251,6 +607,250 @@ func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// HandleVerifyEmail 以邮件中的 token 验证邮箱
+func (h *Handler) HandleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Token is required")
+		return
+	}
+
+	if _, err := h.authService.VerifyEmail(r.Context(), req.Token, ClientIP(r), r.UserAgent()); err != nil {
+		if errors.Is(err, ErrInvalidEmailToken) {
+			h.writeErrorResponse(w, http.StatusBadRequest, "invalid_token", "Verification link is invalid or has expired")
+			return
+		}
+		h.logger.Error("failed to verify email", zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, MessageResponse{Message: "Email verified."})
+}
+
+// HandleResendVerification 重新寄出邮箱验证链接（需要 JWT）
+func (h *Handler) HandleResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerIDStr, _ := GetPlayerIDFromContext(r.Context())
+	playerID, err := uuid.Parse(playerIDStr)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Invalid player ID in token")
+		return
+	}
+
+	if err := h.authService.ResendEmailVerification(r.Context(), playerID); err != nil {
+		if errors.Is(err, ErrEmailAlreadyVerified) {
+			h.writeErrorResponse(w, http.StatusConflict, "email_already_verified", "Email is already verified")
+			return
+		}
+		h.logger.Error("failed to resend verification email", zap.String("player_id", playerIDStr), zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, MessageResponse{Message: "Verification email sent."})
+}
+
+// HandleForgotPassword 寄出密码重设链接
+// 无论邮箱是否存在都回传 202，避免泄露哪些邮箱已注册
+func (h *Handler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Email is required")
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), req.Email, ClientIP(r), r.UserAgent()); err != nil {
+		h.logger.Error("failed to send password reset email", zap.Error(err))
+	}
+
+	h.writeJSON(w, http.StatusAccepted, MessageResponse{
+		Message: "If an account exists for this email, a password reset link has been sent.",
+	})
+}
+
+// HandleResetPassword 以邮件中的 token 重设密码（所有设备需重新登录）
+func (h *Handler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Token and new password are required")
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.NewPassword, ClientIP(r), r.UserAgent()); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmailToken):
+			h.writeErrorResponse(w, http.StatusBadRequest, "invalid_token", "Reset link is invalid or has expired")
+		case errors.Is(err, ErrPasswordRequired):
+			h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "New password is required")
+		default:
+			h.logger.Error("failed to reset password", zap.Error(err))
+			h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, MessageResponse{Message: "Password has been reset. Please login."})
+}
+
+// HandleTwoFactorEnroll 开始绑定两步验证，回传金钥与 otpauth URI（需要 JWT）
+func (h *Handler) HandleTwoFactorEnroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := h.playerIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.authService.BeginTwoFactorEnrollment(r.Context(), playerID)
+	if err != nil {
+		h.writeTwoFactorError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, TwoFactorEnrollResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// HandleTwoFactorConfirm 以验证码确认绑定，回传恢复码（需要 JWT）
+func (h *Handler) HandleTwoFactorConfirm(w http.ResponseWriter, r *http.Request) {
+	h.handleTwoFactorCode(w, r, func(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+		return h.authService.ConfirmTwoFactorEnrollment(ctx, playerID, code, ClientIP(r), r.UserAgent())
+	})
+}
+
+// HandleTwoFactorDisable 停用两步验证（需要 JWT 与目前的验证码）
+func (h *Handler) HandleTwoFactorDisable(w http.ResponseWriter, r *http.Request) {
+	h.handleTwoFactorCode(w, r, func(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+		return nil, h.authService.DisableTwoFactor(ctx, playerID, code, ClientIP(r), r.UserAgent())
+	})
+}
+
+// HandleRegenerateRecoveryCodes 重新产生恢复码（需要 JWT 与目前的验证码）
+func (h *Handler) HandleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	h.handleTwoFactorCode(w, r, func(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+		return h.authService.RegenerateRecoveryCodes(ctx, playerID, code, ClientIP(r), r.UserAgent())
+	})
+}
+
+// handleTwoFactorCode 解析验证码请求并执行操作，有恢复码时回传恢复码，否则回传 204
+func (h *Handler) handleTwoFactorCode(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, playerID uuid.UUID, code string) ([]string, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := h.playerIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Code is required")
+		return
+	}
+
+	codes, err := action(r.Context(), playerID, req.Code)
+	if err != nil {
+		h.writeTwoFactorError(w, err)
+		return
+	}
+	if codes == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// writeTwoFactorError 两步验证操作的错误响应
+func (h *Handler) writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid_two_factor_code", "Invalid two-factor code")
+	case errors.Is(err, ErrTwoFactorAlreadyEnabled):
+		h.writeErrorResponse(w, http.StatusConflict, "two_factor_already_enabled", "Two-factor authentication is already enabled")
+	case errors.Is(err, ErrTwoFactorNotEnabled):
+		h.writeErrorResponse(w, http.StatusConflict, "two_factor_not_enabled", "Two-factor authentication is not enabled")
+	case errors.Is(err, ErrTwoFactorNotEnrolled):
+		h.writeErrorResponse(w, http.StatusConflict, "two_factor_not_enrolled", "Start two-factor enrollment first")
+	default:
+		h.logger.Error("two-factor operation failed", zap.Error(err))
+		h.writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// playerIDFromContext 从 JWT 取得玩家 ID，失败时写入 401
+func (h *Handler) playerIDFromContext(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	playerIDStr, _ := GetPlayerIDFromContext(r.Context())
+	playerID, err := uuid.Parse(playerIDStr)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Invalid player ID in token")
+		return uuid.Nil, false
+	}
+	return playerID, true
+}
+
+// NewJWKSHandler 公開 JWT 驗證公鑰（JWKS），供其他服務以 kid 驗證 EdDSA Token
+func NewJWKSHandler(jwtService *JWTService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(jwtService.PublicKeys())
+	}
+}
+
+// writeTokenResponse 写入新凭证
+func (h *Handler) writeTokenResponse(w http.ResponseWriter, tokens *TokenPair) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.AccessExpiresIn.Seconds()),
+		SessionID:    tokens.SessionID,
+	})
+}
+
+// writeJSON 写入 JSON 响应
+func (h *Handler) writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
 // writeErrorResponse 写入错误响应
 func (h *Handler) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -261,20 +861,87 @@ func (h *Handler) writeErrorResponse(w http.ResponseWriter, statusCode int, erro
 	})
 }
 
-// getClientIP 获取客户端 IP 地址
-func getClientIP(r *http.Request) string {
-	// 尝试从 X-Forwarded-For 获取（如果使用了代理）
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
+// trustedProxies 可信任的反向代理网段（只有来自这些地址的请求才采信代理标头）
+var trustedProxies struct {
+	mu    sync.RWMutex
+	cidrs []*net.IPNet
+}
+
+// SetTrustedProxies 设定可信任的反向代理（IP 或 CIDR），应在启动时呼叫
+// 未设定时忽略 X-Forwarded-For 与 X-Real-IP，一律使用连线的来源地址
+func SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		cidrs = append(cidrs, cidr)
 	}
 
-	// 尝试从 X-Real-IP 获取
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+	trustedProxies.mu.Lock()
+	trustedProxies.cidrs = cidrs
+	trustedProxies.mu.Unlock()
+	return nil
+}
+
+// isTrustedProxy 地址是否属于可信任的反向代理
+func isTrustedProxy(ip net.IP) bool {
+	trustedProxies.mu.RLock()
+	defer trustedProxies.mu.RUnlock()
+	for _, cidr := range trustedProxies.cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
 	}
+	return false
+}
 
-	// 直接从 RemoteAddr 获取
+// remoteHost 连线来源地址（去掉端口）
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
 	return r.RemoteAddr
 }
+
+// ClientIP 获取客户端 IP 地址
+// 只有连线来自可信任的代理时才采信代理标头：X-Forwarded-For 由右往左取第一个非代理地址，
+// 其次为 X-Real-IP；否则使用连线的来源地址。无法解析为 IP 时回传空字串
+func ClientIP(r *http.Request) string {
+	remote := net.ParseIP(remoteHost(r))
+	if remote == nil {
+		return ""
+	}
+	if !isTrustedProxy(remote) {
+		return remote.String()
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break // 无法解析的地址之前的内容不可信
+			}
+			if !isTrustedProxy(ip) || i == 0 {
+				return ip.String()
+			}
+		}
+	} else if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP.String()
+	}
+	return remote.String()
+}
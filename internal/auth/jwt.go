@@ -1,49 +1,99 @@
 package auth
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
 // JWTClaims JWT 聲明
 type JWTClaims struct {
-	PlayerID string `json:"player_id"`
-	Username string `json:"username,omitempty"`
-	Exp      int64  `json:"exp"` // 過期時間（Unix timestamp）
-	Iat      int64  `json:"iat"` // 簽發時間（Unix timestamp）
+	PlayerID  string   `json:"player_id"`
+	Username  string   `json:"username,omitempty"`
+	SessionID string   `json:"sid,omitempty"`   // 登入會話 ID（會話撤銷後 Token 失效）
+	Roles     []string `json:"roles,omitempty"` // 管理角色（admin、support、finance）
+	MFA       bool     `json:"mfa,omitempty"`   // 登入時通過兩步驟驗證
+	Purpose   string   `json:"pur,omitempty"`   // 非空時不是 Access Token（例如兩步驟驗證的挑戰 Token）
+	Exp       int64    `json:"exp"`             // 過期時間（Unix timestamp）
+	Iat       int64    `json:"iat"`             // 簽發時間（Unix timestamp）
 }
 
 // JWTService JWT 服務
+// 以 kid 識別多把金鑰：一把啟用中的簽名金鑰，其餘僅用於驗證（輪替期間舊 Token 仍有效）
 type JWTService struct {
-	secretKey []byte
+	active *SigningKey
+	keys   map[string]*SigningKey
 }
 
-// NewJWTService 創建 JWT 服務
+// legacyKeyID 單一密鑰設定時使用的 kid
+const legacyKeyID = "default"
+
+// NewJWTService 創建 JWT 服務（單一 HS256 密鑰）
 func NewJWTService(secretKey string) *JWTService {
+	key := &SigningKey{ID: legacyKeyID, Algorithm: AlgHS256, secret: []byte(secretKey)}
 	return &JWTService{
-		secretKey: []byte(secretKey),
+		active: key,
+		keys:   map[string]*SigningKey{key.ID: key},
 	}
 }
 
-// GenerateToken 生成 JWT Token
-func (s *JWTService) GenerateToken(playerID, username string, ttl time.Duration) (string, error) {
-	now := time.Now()
-	claims := JWTClaims{
-		PlayerID: playerID,
-		Username: username,
-		Iat:      now.Unix(),
-		Exp:      now.Add(ttl).Unix(),
+// NewJWTServiceWithKeys 創建支援金鑰輪替的 JWT 服務
+// activeKID 指定簽名用的金鑰，其餘金鑰僅用於驗證
+func NewJWTServiceWithKeys(activeKID string, keys ...*SigningKey) (*JWTService, error) {
+	s := &JWTService{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, exists := s.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		s.keys[key.ID] = key
 	}
 
-	// 構建 Header
-	header := map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
+	active, ok := s.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active key %q: %w", activeKID, ErrVerifyOnlyKey)
+	}
+	s.active = active
+	return s, nil
+}
+
+// ActiveKeyID 目前簽名使用的 kid
+func (s *JWTService) ActiveKeyID() string {
+	return s.active.ID
+}
+
+// jwtHeader JWT Header
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+// GenerateToken 生成綁定登入會話的 JWT Access Token（以啟用中的金鑰簽名）
+func (s *JWTService) GenerateToken(playerID, username, sessionID string, ttl time.Duration) (string, error) {
+	return s.GenerateTokenWithClaims(JWTClaims{
+		PlayerID:  playerID,
+		Username:  username,
+		SessionID: sessionID,
+	}, ttl)
+}
+
+// GenerateTokenWithClaims 以指定聲明生成 JWT（Iat 與 Exp 由 ttl 計算）
+func (s *JWTService) GenerateTokenWithClaims(claims JWTClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Iat = now.Unix()
+	claims.Exp = now.Add(ttl).Unix()
+
+	// 構建 Header（kid 用於驗證時選擇金鑰）
+	header := jwtHeader{
+		Alg: s.active.Algorithm,
+		Typ: "JWT",
+		Kid: s.active.ID,
 	}
 
 	headerJSON, err := json.Marshal(header)
@@ -63,7 +113,10 @@ func (s *JWTService) GenerateToken(playerID, username string, ttl time.Duration)
 
 	// 簽名
 	message := headerB64 + "." + payloadB64
-	signature := s.sign(message)
+	signature, err := s.active.sign(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
 	signatureB64 := base64.RawURLEncoding.EncodeToString(signature)
 
 	// 組合 Token
@@ -84,12 +137,30 @@ func (s *JWTService) ValidateToken(token string) (*JWTClaims, error) {
 	payloadB64 := parts[1]
 	signatureB64 := parts[2]
 
-	// 驗證簽名
-	message := headerB64 + "." + payloadB64
-	expectedSignature := s.sign(message)
-	expectedSignatureB64 := base64.RawURLEncoding.EncodeToString(expectedSignature)
+	// 解碼 Header，依 kid 選擇金鑰
+	headerJSON, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header encoding: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("invalid header format: %w", err)
+	}
+	key, ok := s.keys[header.Kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", header.Kid)
+	}
+	// 演算法必須與金鑰一致，避免演算法混淆攻擊
+	if header.Alg != key.Algorithm {
+		return nil, fmt.Errorf("algorithm mismatch for key %q", header.Kid)
+	}
 
-	if signatureB64 != expectedSignatureB64 {
+	// 驗證簽名
+	signature, err := base64.RawURLEncoding.DecodeString(signatureB64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !key.verify(headerB64+"."+payloadB64, signature) {
 		return nil, fmt.Errorf("invalid signature")
 	}
 
@@ -112,9 +183,37 @@ func (s *JWTService) ValidateToken(token string) (*JWTClaims, error) {
 	return &claims, nil
 }
 
-// sign 使用 HMAC-SHA256 簽名
-func (s *JWTService) sign(message string) []byte {
-	h := hmac.New(sha256.New, s.secretKey)
-	h.Write([]byte(message))
-	return h.Sum(nil)
+// JWK 公開金鑰（RFC 8037 OKP 格式）
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	X   string `json:"x"`
+}
+
+// JWKSet 公開金鑰集合（/.well-known/jwks.json）
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys 回傳所有 EdDSA 公鑰，供其他服務驗證 Token（HS256 密鑰不公開）
+func (s *JWTService) PublicKeys() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if key.Algorithm != AlgEdDSA {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			Kid: key.ID,
+			Alg: AlgEdDSA,
+			Use: "sig",
+			X:   base64.RawURLEncoding.EncodeToString(key.publicKey),
+		})
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
 }
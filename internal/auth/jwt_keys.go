@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// JWT 簽名演算法
+const (
+	AlgHS256 = "HS256" // HMAC-SHA256（對稱金鑰）
+	AlgEdDSA = "EdDSA" // Ed25519（非對稱金鑰，其他服務只需公鑰即可驗證）
+)
+
+// ErrVerifyOnlyKey 僅有公鑰的金鑰無法簽名
+var ErrVerifyOnlyKey = errors.New("signing key is verify-only")
+
+// SigningKey JWT 簽名金鑰，以 kid 識別
+// HS256 使用 secret；EdDSA 使用 privateKey 簽名、publicKey 驗證（只有公鑰時為驗證專用）
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewHMACKey 創建 HS256 金鑰
+func NewHMACKey(kid, secret string) (*SigningKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("key %s: secret is required", kid)
+	}
+	return &SigningKey{ID: kid, Algorithm: AlgHS256, secret: []byte(secret)}, nil
+}
+
+// NewEd25519Key 創建 EdDSA 簽名金鑰
+func NewEd25519Key(kid string, privateKey ed25519.PrivateKey) (*SigningKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("key %s: invalid ed25519 private key", kid)
+	}
+	return &SigningKey{
+		ID:         kid,
+		Algorithm:  AlgEdDSA,
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// NewEd25519VerifyKey 創建僅用於驗證的 EdDSA 金鑰（只有公鑰）
+func NewEd25519VerifyKey(kid string, publicKey ed25519.PublicKey) (*SigningKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("key %s: invalid ed25519 public key", kid)
+	}
+	return &SigningKey{ID: kid, Algorithm: AlgEdDSA, publicKey: publicKey}, nil
+}
+
+// CanSign 金鑰是否可用於簽名
+func (k *SigningKey) CanSign() bool {
+	return k.Algorithm == AlgHS256 || k.privateKey != nil
+}
+
+// PublicKey 回傳 EdDSA 公鑰（HS256 金鑰回傳 nil）
+func (k *SigningKey) PublicKey() ed25519.PublicKey {
+	return k.publicKey
+}
+
+// sign 對訊息簽名
+func (k *SigningKey) sign(message string) ([]byte, error) {
+	switch k.Algorithm {
+	case AlgHS256:
+		h := hmac.New(sha256.New, k.secret)
+		h.Write([]byte(message))
+		return h.Sum(nil), nil
+	case AlgEdDSA:
+		if k.privateKey == nil {
+			return nil, ErrVerifyOnlyKey
+		}
+		return ed25519.Sign(k.privateKey, []byte(message)), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+}
+
+// verify 驗證簽名
+func (k *SigningKey) verify(message string, signature []byte) bool {
+	switch k.Algorithm {
+	case AlgHS256:
+		expected, _ := k.sign(message)
+		return hmac.Equal(signature, expected)
+	case AlgEdDSA:
+		return ed25519.Verify(k.publicKey, []byte(message), signature)
+	default:
+		return false
+	}
+}
+
+// ParseEd25519PrivateKeyPEM 解析 PKCS#8 PEM 格式的 Ed25519 私鑰
+// （openssl genpkey -algorithm ed25519）
+func ParseEd25519PrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not ed25519")
+	}
+	return privateKey, nil
+}
+
+// ParseEd25519PublicKeyPEM 解析 PKIX PEM 格式的 Ed25519 公鑰
+// （openssl pkey -pubout）
+func ParseEd25519PublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not ed25519")
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestJWTService_KeyRotation 切換簽名金鑰後，舊金鑰簽發的 Token 仍可驗證直到金鑰移除
+func TestJWTService_KeyRotation(t *testing.T) {
+	oldKey, err := NewHMACKey("2026-09", "old-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := NewHMACKey("2026-10", "new-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := NewJWTServiceWithKeys("2026-09", oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.GenerateToken("player-1", "alice", "session-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := NewJWTServiceWithKeys("2026-10", oldKey, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotated.ValidateToken(oldToken); err != nil {
+		t.Fatalf("expected token signed with previous key to validate, got %v", err)
+	}
+	newToken, err := rotated.GenerateToken("player-1", "alice", "session-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenHeader(t, newToken).Kid; kid != "2026-10" {
+		t.Fatalf("expected new tokens to use kid 2026-10, got %q", kid)
+	}
+
+	// 舊金鑰移除後，舊 Token 失效
+	retired, err := NewJWTServiceWithKeys("2026-10", newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := retired.ValidateToken(oldToken); err == nil {
+		t.Fatal("expected token signed with retired key to be rejected")
+	}
+	if _, err := retired.ValidateToken(newToken); err != nil {
+		t.Fatalf("expected current token to validate, got %v", err)
+	}
+}
+
+// TestJWTService_Ed25519PublicKeyVerification 其他服務只持有公鑰即可驗證 EdDSA Token
+func TestJWTService_Ed25519PublicKeyVerification(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 透過 PEM 往返，與設定檔載入的路徑一致
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedPrivate, err := ParseEd25519PrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedPublic, err := ParseEd25519PublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signingKey, err := NewEd25519Key("ed-1", parsedPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := NewJWTServiceWithKeys("ed-1", signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := issuer.GenerateToken("player-1", "alice", "session-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alg := tokenHeader(t, token).Alg; alg != AlgEdDSA {
+		t.Fatalf("expected alg EdDSA, got %q", alg)
+	}
+
+	verifyKey, err := NewEd25519VerifyKey("ed-1", parsedPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJWTServiceWithKeys("ed-1", verifyKey); err == nil {
+		t.Fatal("expected verify-only key to be rejected as active signing key")
+	}
+
+	// 驗證端：啟用自己的 HMAC 金鑰，並加入簽發端的公鑰
+	localKey, _ := NewHMACKey("local", "local-secret")
+	verifier, err := NewJWTServiceWithKeys("local", localKey, verifyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("expected EdDSA token to validate with public key, got %v", err)
+	}
+	if claims.PlayerID != "player-1" || claims.SessionID != "session-1" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	jwks := issuer.PublicKeys()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "ed-1" ||
+		jwks.Keys[0].X != base64.RawURLEncoding.EncodeToString(publicKey) {
+		t.Fatalf("unexpected JWKS: %+v", jwks)
+	}
+	if len(verifier.PublicKeys().Keys) != 1 {
+		t.Fatal("expected HMAC keys to be excluded from JWKS")
+	}
+}
+
+// TestJWTService_RejectsForgedHeaders 未知 kid 與演算法不符的 Token 一律拒絕
+func TestJWTService_RejectsForgedHeaders(t *testing.T) {
+	key, _ := NewHMACKey("k1", "secret")
+	svc, err := NewJWTServiceWithKeys("k1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := svc.GenerateToken("player-1", "alice", "session-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	for name, header := range map[string]jwtHeader{
+		"unknown kid":  {Alg: AlgHS256, Typ: "JWT", Kid: "k2"},
+		"missing kid":  {Alg: AlgHS256, Typ: "JWT"},
+		"alg mismatch": {Alg: AlgEdDSA, Typ: "JWT", Kid: "k1"},
+		"alg none":     {Alg: "none", Typ: "JWT", Kid: "k1"},
+	} {
+		headerJSON, _ := json.Marshal(header)
+		forged := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + parts[1] + "." + parts[2]
+		if _, err := svc.ValidateToken(forged); err == nil {
+			t.Errorf("%s: expected forged token to be rejected", name)
+		}
+	}
+
+	if _, err := NewJWTServiceWithKeys("k1", key, key); err == nil {
+		t.Error("expected duplicate key id to be rejected")
+	}
+}
+
+func tokenHeader(t *testing.T, token string) jwtHeader {
+	t.Helper()
+	headerJSON, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mail 一封純文字郵件
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 寄送郵件（電子郵件驗證、密碼重設）
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// SMTPMailer 透過 SMTP 寄信（生產環境）
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer 創建 SMTP 寄信器（username 為空時不做 SMTP 認證）
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Send 寄出郵件
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{mail.To}, formatMail(m.from, mail)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// FileMailer 將郵件寫入目錄（開發環境，不實際寄出）
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer 創建檔案寄信器
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send 將郵件寫成 .eml 檔
+func (m *FileMailer) Send(ctx context.Context, mail Mail) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitizeMailName(mail.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatMail(m.from, mail), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
+
+// MemoryMailer 將郵件保存在記憶體（測試用）
+type MemoryMailer struct {
+	mu    sync.Mutex
+	mails []Mail
+}
+
+// NewMemoryMailer 創建記憶體寄信器
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send 保存郵件
+func (m *MemoryMailer) Send(ctx context.Context, mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+// Sent 回傳已寄出的郵件
+func (m *MemoryMailer) Sent() []Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Mail(nil), m.mails...)
+}
+
+// formatMail 組合 RFC 5322 郵件內容
+func formatMail(from string, mail Mail) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(from) + "\r\n")
+	b.WriteString("To: " + headerValue(mail.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue(mail.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue 移除換行，避免標頭注入
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
+
+// sanitizeMailName 將收件人轉為安全的檔名
+func sanitizeMailName(to string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, to)
+}
@@ -14,10 +14,22 @@ const (
 	PlayerIDKey contextKey = "player_id"
 	// UsernameKey context 中的使用者名稱鍵
 	UsernameKey contextKey = "username"
+	// SessionIDKey context 中的登入會話 ID 鍵
+	SessionIDKey contextKey = "session_id"
+	// MFAKey context 中的兩步驟驗證狀態鍵
+	MFAKey contextKey = "mfa"
+	// RolesKey context 中的角色鍵
+	RolesKey contextKey = "roles"
 )
 
+// SessionValidator 查詢登入會話是否仍有效（由 SessionService 實現）
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
 // JWTMiddleware JWT 驗證中介層
-func JWTMiddleware(jwtService *JWTService) func(http.Handler) http.Handler {
+// sessions 不為 nil 時，Token 必須帶有 sid 且對應的會話未撤銷、未過期
+func JWTMiddleware(jwtService *JWTService, sessions SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// 從 Authorization Header 取得 Token
@@ -42,10 +54,35 @@ func JWTMiddleware(jwtService *JWTService) func(http.Handler) http.Handler {
 				http.Error(w, "invalid token: "+err.Error(), http.StatusUnauthorized)
 				return
 			}
+			// 挑戰 Token 等非 Access Token 不得用於存取 API
+			if claims.Purpose != "" {
+				http.Error(w, "invalid token: not an access token", http.StatusUnauthorized)
+				return
+			}
+
+			// 檢查會話是否已撤銷（登出、修改密碼、refresh token 重放）
+			if sessions != nil {
+				if claims.SessionID == "" {
+					http.Error(w, "invalid token: missing session", http.StatusUnauthorized)
+					return
+				}
+				active, err := sessions.IsSessionActive(r.Context(), claims.SessionID)
+				if err != nil {
+					http.Error(w, "failed to verify session", http.StatusInternalServerError)
+					return
+				}
+				if !active {
+					http.Error(w, "invalid token: session revoked", http.StatusUnauthorized)
+					return
+				}
+			}
 
 			// 將玩家資訊存入 context
 			ctx := context.WithValue(r.Context(), PlayerIDKey, claims.PlayerID)
 			ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+			ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+			ctx = context.WithValue(ctx, MFAKey, claims.MFA)
+			ctx = context.WithValue(ctx, RolesKey, claims.Roles)
 
 			// 繼續處理請求
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -64,3 +101,55 @@ func GetUsernameFromContext(ctx context.Context) (string, bool) {
 	username, ok := ctx.Value(UsernameKey).(string)
 	return username, ok
 }
+
+// GetSessionIDFromContext 從 context 取得登入會話 ID
+func GetSessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	return sessionID, ok && sessionID != ""
+}
+
+// GetRolesFromContext 從 context 取得角色
+func GetRolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(RolesKey).([]string)
+	return roles
+}
+
+// GetMFAFromContext 登入時是否通過兩步驟驗證
+func GetMFAFromContext(ctx context.Context) bool {
+	mfa, _ := ctx.Value(MFAKey).(bool)
+	return mfa
+}
+
+// RequireRole 角色權限中介層（需放在 JWTMiddleware 之後）
+// 具有任一指定角色即可通過；管理角色的登入必須通過兩步驟驗證
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetPlayerIDFromContext(r.Context()); !ok {
+				http.Error(w, "unauthorized: player_id not found in context", http.StatusUnauthorized)
+				return
+			}
+			if !HasAnyRole(r.Context(), roles...) {
+				http.Error(w, "forbidden: insufficient role", http.StatusForbidden)
+				return
+			}
+			if !GetMFAFromContext(r.Context()) {
+				http.Error(w, "forbidden: two-factor authentication required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasAnyRole 目前請求的 Token 是否具有任一指定角色
+func HasAnyRole(ctx context.Context, roles ...string) bool {
+	for _, have := range GetRolesFromContext(ctx) {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// RateLimit 令牌桶參數：每秒補充 Rate 個令牌，最多累積 Burst 個
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// refillDuration 空桶補滿所需的時間（超過此時間未使用的桶等同全新的桶）
+func (l RateLimit) refillDuration() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// retryAfter 目前令牌數補到 1 個所需的時間
+func (l RateLimit) retryAfter(tokens float64) time.Duration {
+	return time.Duration(math.Ceil((1 - tokens) / l.Rate * float64(time.Second)))
+}
+
+// RateLimiter 以 key（IP、玩家 ID）為單位的令牌桶限流器
+type RateLimiter interface {
+	// Allow 消耗一個令牌；不允許時回傳需等待的時間
+	Allow(ctx context.Context, key string) (bool, time.Duration, error)
+}
+
+// MemoryRateLimiter 記憶體版本的限流器（單一實例部署或測試用）
+// 多實例部署時各實例分別計算，建議使用 RedisRateLimiter
+type MemoryRateLimiter struct {
+	limit     RateLimit
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryRateLimiter 創建記憶體限流器
+func NewMemoryRateLimiter(limit RateLimit) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		limit:   limit,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow 消耗一個令牌
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(l.limit.Burst), updatedAt: now}
+		l.buckets[key] = bucket
+	} else if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+		bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+elapsed.Seconds()*l.limit.Rate)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+	return false, l.limit.retryAfter(bucket.tokens), nil
+}
+
+// sweep 每分鐘清理一次已補滿的桶，避免大量 IP 佔用記憶體
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	idle := l.limit.refillDuration()
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updatedAt) >= idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitScript 原子地補充並消耗令牌，以 Redis 伺服器時間計算避免各實例時鐘誤差
+// 回傳 {是否允許, 需等待的毫秒數}
+var rateLimitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000))
+return {allowed, wait}
+`)
+
+// RedisRateLimiter 使用 Redis 的限流器，多個伺服器實例共用同一組令牌桶
+type RedisRateLimiter struct {
+	client *redis.Client
+	name   string
+	limit  RateLimit
+}
+
+// NewRedisRateLimiter 建立 Redis 限流器，name 區分不同用途的令牌桶（例如 auth、ws）
+func NewRedisRateLimiter(client *redis.Client, name string, limit RateLimit) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client, name: name, limit: limit}
+}
+
+// Allow 消耗一個令牌
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	redisKey := rateLimitKeyPrefix + l.name + ":" + key
+	result, err := rateLimitScript.Run(ctx, l.client, []string{redisKey}, l.limit.Rate, l.limit.Burst).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result: %v", result)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// RateLimitByIP 以客戶端 IP 作為限流 key
+// 無法解析時改用去掉端口的來源地址，避免每條連線各自一個令牌桶
+func RateLimitByIP(r *http.Request) string {
+	ip := ClientIP(r)
+	if ip == "" {
+		ip = remoteHost(r)
+	}
+	return "ip:" + ip
+}
+
+// RateLimitByPlayer 以玩家 ID 作為限流 key（需放在 JWTMiddleware 之後，未登入時改用 IP）
+func RateLimitByPlayer(r *http.Request) string {
+	if playerID, ok := GetPlayerIDFromContext(r.Context()); ok && playerID != "" {
+		return "player:" + playerID
+	}
+	return RateLimitByIP(r)
+}
+
+// RateLimitMiddleware 限流中介層，超過限制時回傳 429 與 Retry-After
+// 限流器故障（例如 Redis 無法連線）時放行請求，由帳號鎖定等機制繼續把關
+func RateLimitMiddleware(limiter RateLimiter, keyFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := limiter.Allow(r.Context(), keyFunc(r))
+			if err == nil && !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(ErrorResponse{
+					Error:   "rate_limited",
+					Message: "Too many requests, please retry later",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
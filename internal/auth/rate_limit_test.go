@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMemoryRateLimiter_TokenBucket 用完突發額度後拒絕，依速率補充令牌；不同 key 互不影響
+func TestMemoryRateLimiter_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryRateLimiter(RateLimit{Rate: 2, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := limiter.Allow(ctx, "ip:198.51.100.1"); !allowed {
+			t.Fatalf("expected request %d within burst to be allowed", i+1)
+		}
+	}
+	allowed, retryAfter, err := limiter.Allow(ctx, "ip:198.51.100.1")
+	if err != nil || allowed {
+		t.Fatalf("expected request over burst to be rejected, got allowed=%v err=%v", allowed, err)
+	}
+	if retryAfter != 500*time.Millisecond {
+		t.Fatalf("expected retry after 500ms, got %v", retryAfter)
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "ip:198.51.100.2"); !allowed {
+		t.Fatal("expected other key to have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if allowed, _, _ := limiter.Allow(ctx, "ip:198.51.100.1"); !allowed {
+		t.Fatal("expected one token to be refilled")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "ip:198.51.100.1"); allowed {
+		t.Fatal("expected bucket to be empty again")
+	}
+
+	// 閒置到補滿的桶會被清理
+	now = now.Add(2 * time.Minute)
+	limiter.Allow(ctx, "ip:198.51.100.3")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be swept, got %d buckets", len(limiter.buckets))
+	}
+}
+
+// TestRateLimitMiddleware_RejectsWithRetryAfter 超過限制回傳 429 與 Retry-After，依 IP 分開計算
+func TestRateLimitMiddleware_RejectsWithRetryAfter(t *testing.T) {
+	limiter := NewMemoryRateLimiter(RateLimit{Rate: 0.1, Burst: 1})
+	handler := RateLimitMiddleware(limiter, RateLimitByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	call := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+		req.RemoteAddr = ip + ":40000"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call("198.51.100.1"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected first request to pass, got %d", rec.Code)
+	}
+	rec := call("198.51.100.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "10" {
+		t.Fatalf("expected Retry-After 10, got %q", rec.Header().Get("Retry-After"))
+	}
+	if rec := call("198.51.100.2"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected other IP to pass, got %d", rec.Code)
+	}
+}
+
+// TestClientIP_TrustedProxies 只有來自可信任代理的連線才採信代理標頭，來源地址一律去掉端口
+func TestClientIP_TrustedProxies(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"}); err != nil {
+		t.Fatalf("SetTrustedProxies failed: %v", err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+
+	request := func(remoteAddr string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct client", "198.51.100.7:40000", nil, "198.51.100.7"},
+		{"spoofed header from untrusted peer", "198.51.100.7:40000", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "198.51.100.7"},
+		{"spoofed real ip from untrusted peer", "198.51.100.7:40000", map[string]string{"X-Real-IP": "203.0.113.9"}, "198.51.100.7"},
+		{"trusted proxy", "10.1.2.3:40000", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "203.0.113.9"},
+		{"client-supplied hop before proxy chain", "10.1.2.3:40000", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.9, 192.0.2.1"}, "203.0.113.9"},
+		{"trusted proxy with real ip", "192.0.2.1:40000", map[string]string{"X-Real-IP": "203.0.113.9"}, "203.0.113.9"},
+		{"trusted proxy without headers", "10.1.2.3:40000", nil, "10.1.2.3"},
+		{"ipv6 peer", "[2001:db8::1]:40000", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "2001:db8::1"},
+	}
+	for _, tc := range cases {
+		if got := ClientIP(request(tc.remoteAddr, tc.headers)); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	// 同一 IP 的不同連線共用令牌桶
+	if a, b := RateLimitByIP(request("198.51.100.7:40000", nil)), RateLimitByIP(request("198.51.100.7:40001", nil)); a != b || a != "ip:198.51.100.7" {
+		t.Errorf("expected port-independent key, got %s and %s", a, b)
+	}
+
+	if err := SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("expected invalid trusted proxy to be rejected")
+	}
+}
+
+func TestRedisRateLimiter_Allow(t *testing.T) {
+	client := newTestRedisClient(t)
+	limiter := NewRedisRateLimiter(client, "test", RateLimit{Rate: 1, Burst: 2})
+
+	ctx := context.Background()
+	key := "player:" + time.Now().Format(time.RFC3339Nano)
+	t.Cleanup(func() {
+		client.Del(context.Background(), rateLimitKeyPrefix+"test:"+key)
+	})
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(ctx, key)
+		if err != nil {
+			t.Fatalf("Allow failed: %v", err)
+		}
+		if !allowed {
+			t.Fatalf("expected request %d within burst to be allowed", i+1)
+		}
+	}
+	allowed, retryAfter, err := limiter.Allow(ctx, key)
+	if err != nil {
+		t.Fatalf("Allow failed: %v", err)
+	}
+	if allowed || retryAfter <= 0 || retryAfter > time.Second {
+		t.Fatalf("expected rejection with retry-after <= 1s, got allowed=%v retryAfter=%v", allowed, retryAfter)
+	}
+}
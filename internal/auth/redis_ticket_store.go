@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -21,14 +22,18 @@ func NewRedisTicketStore(client *redis.Client) *RedisTicketStore {
 }
 
 // Generate 生成票券並存入 Redis（帶 TTL）
-func (s *RedisTicketStore) Generate(ctx context.Context, playerID string, ttl time.Duration) (string, error) {
+func (s *RedisTicketStore) Generate(ctx context.Context, claims TicketClaims, ttl time.Duration) (string, error) {
 	ticket, err := generateRandomTicket(32)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate ticket: %w", err)
 	}
+	value, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ticket claims: %w", err)
+	}
 
 	key := ticketKeyPrefix + ticket
-	if err := s.client.Set(ctx, key, playerID, ttl).Err(); err != nil {
+	if err := s.client.Set(ctx, key, value, ttl).Err(); err != nil {
 		return "", fmt.Errorf("failed to store ticket in redis: %w", err)
 	}
 
@@ -36,17 +41,21 @@ func (s *RedisTicketStore) Generate(ctx context.Context, playerID string, ttl ti
 }
 
 // Validate 驗證並銷毀票券（原子操作）
-func (s *RedisTicketStore) Validate(ctx context.Context, ticket string) (string, error) {
+func (s *RedisTicketStore) Validate(ctx context.Context, ticket string) (*TicketClaims, error) {
 	key := ticketKeyPrefix + ticket
-	playerID, err := s.client.GetDel(ctx, key).Result()
+	value, err := s.client.GetDel(ctx, key).Bytes()
 	if err == redis.Nil {
-		return "", fmt.Errorf("invalid ticket: not found or expired")
+		return nil, fmt.Errorf("invalid ticket: not found or expired")
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to validate ticket: %w", err)
+		return nil, fmt.Errorf("failed to validate ticket: %w", err)
 	}
 
-	return playerID, nil
+	var claims TicketClaims
+	if err := json.Unmarshal(value, &claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ticket claims: %w", err)
+	}
+	return &claims, nil
 }
 
 // Close 關閉資源（Redis client 由外部管理，此處為 no-op）
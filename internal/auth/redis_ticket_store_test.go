@@ -38,7 +38,7 @@ func TestRedisTicketStore_GenerateAndValidate(t *testing.T) {
 	ctx := context.Background()
 	playerID := "player-123"
 
-	ticket, err := store.Generate(ctx, playerID, 30*time.Second)
+	ticket, err := store.Generate(ctx, TicketClaims{PlayerID: playerID, SessionID: "session-123"}, 30*time.Second)
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
@@ -50,8 +50,8 @@ func TestRedisTicketStore_GenerateAndValidate(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Validate failed: %v", err)
 	}
-	if got != playerID {
-		t.Fatalf("expected playerID %q, got %q", playerID, got)
+	if got.PlayerID != playerID || got.SessionID != "session-123" {
+		t.Fatalf("expected playerID %q and session-123, got %+v", playerID, got)
 	}
 }
 
@@ -74,7 +74,7 @@ func TestRedisTicketStore_ValidateConsumedTicket(t *testing.T) {
 	ctx := context.Background()
 	playerID := "player-456"
 
-	ticket, err := store.Generate(ctx, playerID, 30*time.Second)
+	ticket, err := store.Generate(ctx, TicketClaims{PlayerID: playerID}, 30*time.Second)
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
@@ -100,7 +100,7 @@ func TestRedisTicketStore_TTLExpiry(t *testing.T) {
 	playerID := "player-789"
 
 	// 使用 1 秒 TTL
-	ticket, err := store.Generate(ctx, playerID, 1*time.Second)
+	ticket, err := store.Generate(ctx, TicketClaims{PlayerID: playerID}, 1*time.Second)
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
 	}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -25,6 +26,8 @@ var (
 	ErrUsernameExists = errors.New("username already exists")
 	// ErrEmailExists 邮箱已存在
 	ErrEmailExists = errors.New("email already exists")
+	// ErrPasswordRequired 新密码为空
+	ErrPasswordRequired = errors.New("new password is required")
 )
 
 const (
@@ -36,12 +39,35 @@ const (
 	BcryptCost = 12
 )
 
+// 账号相关的审计动作
+const (
+	AuditActionRegister        = "register"
+	AuditActionLogin           = "login"
+	AuditActionLoginFailed     = "login_failed"
+	AuditActionLoginRejected   = "login_rejected" // 账号锁定、暂停或封禁时尝试登录
+	AuditActionAccountLocked   = "account_locked" // 连续登录失败导致锁定
+	AuditActionPasswordChanged = "password_changed"
+)
+
+// InitialWallet 注册时为新玩家开立的钱包（金额单位为分）
+type InitialWallet struct {
+	Currency string
+	Balance  int64
+}
+
 // AuthService 认证服务，处理用户认证逻辑
 type AuthService struct {
-	accountRepo repository.AccountRepository
-	playerRepo  repository.PlayerRepository
-	walletRepo  repository.WalletRepository
-	logger      *zap.Logger
+	accountRepo    repository.AccountRepository
+	playerRepo     repository.PlayerRepository
+	walletRepo     repository.WalletRepository
+	auditRepo      repository.AuditLogRepository
+	sessions       *SessionService
+	tokenRepo      repository.AccountTokenRepository
+	mailer         Mailer
+	linkBaseURL    string
+	twoFactorRepo  repository.TwoFactorRepository
+	initialWallets []InitialWallet
+	logger         *zap.Logger
 }
 
 // NewAuthService 创建认证服务
@@ -52,15 +78,31 @@ func NewAuthService(
 	logger *zap.Logger,
 ) *AuthService {
 	return &AuthService{
-		accountRepo: accountRepo,
-		playerRepo:  playerRepo,
-		walletRepo:  walletRepo,
-		logger:      logger,
+		accountRepo:    accountRepo,
+		playerRepo:     playerRepo,
+		walletRepo:     walletRepo,
+		initialWallets: []InitialWallet{{Currency: "USD", Balance: 100000}}, // 1000.00
+		logger:         logger,
 	}
 }
 
+// SetInitialWallets 设定注册时开立的钱包（每个币别一个，例如真钱与游戏币）
+func (s *AuthService) SetInitialWallets(wallets []InitialWallet) {
+	s.initialWallets = wallets
+}
+
+// SetAuditLogRepository 设定审计日志仓储（登录、锁定与注册写入审计日志）
+func (s *AuthService) SetAuditLogRepository(auditRepo repository.AuditLogRepository) {
+	s.auditRepo = auditRepo
+}
+
+// SetSessionService 设定登入会话服务（修改密码时撤销所有会话）
+func (s *AuthService) SetSessionService(sessions *SessionService) {
+	s.sessions = sessions
+}
+
 // Register 注册新用户
-func (s *AuthService) Register(ctx context.Context, username, email, password string) (*repository.Account, *repository.Player, error) {
+func (s *AuthService) Register(ctx context.Context, username, email, password, ipAddress, userAgent string) (*repository.Account, *repository.Player, error) {
 	// 1. 验证输入
 	if username == "" || email == "" || password == "" {
 		return nil, nil, errors.New("username, email and password are required")
@@ -120,37 +162,61 @@ func (s *AuthService) Register(ctx context.Context, username, email, password st
 		return nil, nil, fmt.Errorf("failed to create player: %w", err)
 	}
 
-	// 7. 创建初始钱包 (For MVP testing: giving initial balance)
-	initialBalance := int64(100000) // 1000.00
-	wallet := &repository.Wallet{
-		ID:            uuid.New(),
-		PlayerID:      player.ID,
-		Balance:       initialBalance,
-		LockedBalance: 0,
-		Currency:      "USD",
-		Version:       1,
+	// 7. 为每个币别创建初始钱包 (For MVP testing: giving initial balance)
+	for _, initial := range s.initialWallets {
+		wallet := &repository.Wallet{
+			ID:            uuid.New(),
+			PlayerID:      player.ID,
+			Balance:       initial.Balance,
+			LockedBalance: 0,
+			Currency:      initial.Currency,
+			Version:       1,
+		}
+
+		if err := s.walletRepo.Create(ctx, wallet); err != nil {
+			s.logger.Error("failed to create wallet for player",
+				zap.String("player_id", player.ID.String()),
+				zap.String("currency", initial.Currency),
+				zap.Error(err),
+			)
+			// Usually transactional, but for MVP we just log
+		}
 	}
 
-	if err := s.walletRepo.Create(ctx, wallet); err != nil {
-		s.logger.Error("failed to create wallet for player",
-			zap.String("player_id", player.ID.String()),
-			zap.Error(err),
-		)
-		// Usually transactional, but for MVP we just log
+	// 8. 寄出邮箱验证链接（失败不影响注册，可稍后重新寄送）
+	if s.tokenRepo != nil {
+		if err := s.SendEmailVerification(ctx, account); err != nil {
+			s.logger.Error("failed to send verification email",
+				zap.String("account_id", account.ID.String()),
+				zap.Error(err),
+			)
+		}
 	}
 
+	currencies := make([]string, 0, len(s.initialWallets))
+	for _, initial := range s.initialWallets {
+		currencies = append(currencies, initial.Currency)
+	}
+	s.audit(ctx, account.ID, AuditActionRegister, ipAddress, userAgent, nil, map[string]interface{}{
+		"username":   username,
+		"email":      email,
+		"player_id":  player.ID,
+		"currencies": currencies,
+	})
+
 	s.logger.Info("user registered successfully with wallet",
 		zap.String("username", username),
 		zap.String("account_id", account.ID.String()),
 		zap.String("player_id", player.ID.String()),
-		zap.Int64("initial_balance", initialBalance),
+		zap.Int("wallets", len(s.initialWallets)),
 	)
 
 	return account, player, nil
 }
 
 // Authenticate 验证用户凭证
-func (s *AuthService) Authenticate(ctx context.Context, username, password, ipAddress string) (*repository.Account, *repository.Player, error) {
+// 账号启用两步验证时，密码正确后回传 *TwoFactorRequiredError（带挑战 token），需再调用 CompleteTwoFactorLogin
+func (s *AuthService) Authenticate(ctx context.Context, username, password, ipAddress, userAgent string) (*repository.Account, *repository.Player, error) {
 	// 1. 查询账号
 	account, err := s.accountRepo.GetByUsername(ctx, username)
 	if err != nil {
@@ -161,94 +227,234 @@ func (s *AuthService) Authenticate(ctx context.Context, username, password, ipAd
 		return nil, nil, ErrInvalidCredentials
 	}
 
-	// 2. 检查账号状态
+	// 2. 检查账号状态与锁定
+	if err := s.checkLoginAllowed(ctx, account, ipAddress, userAgent); err != nil {
+		return nil, nil, err
+	}
+
+	// 3. 验证密码
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)); err != nil {
+		s.logger.Warn("authentication failed: invalid password",
+			zap.String("username", username),
+			zap.String("account_id", account.ID.String()),
+			zap.String("ip", ipAddress),
+		)
+		s.recordFailedLogin(ctx, account, AuditActionLoginFailed, ipAddress, userAgent)
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	// 4. 启用两步验证：失败次数在第二步成功后才重置，避免以密码登录绕过验证码的错误次数限制
+	challenge, err := s.twoFactorChallenge(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge != nil {
+		return nil, nil, challenge
+	}
+
+	// 5. 完成登录
+	player, err := s.completeLogin(ctx, account, false, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, player, nil
+}
+
+// checkLoginAllowed 检查账号状态与锁定（暂停、封禁或锁定中不可登录）
+func (s *AuthService) checkLoginAllowed(ctx context.Context, account *repository.Account, ipAddress, userAgent string) error {
 	if account.Status == "suspended" {
 		s.logger.Warn("authentication failed: account suspended",
-			zap.String("username", username),
+			zap.String("username", account.Username),
 			zap.String("account_id", account.ID.String()),
 		)
-		return nil, nil, ErrAccountSuspended
+		s.audit(ctx, account.ID, AuditActionLoginRejected, ipAddress, userAgent, nil, map[string]interface{}{"status": account.Status})
+		return ErrAccountSuspended
 	}
 
 	if account.Status == "banned" {
 		s.logger.Warn("authentication failed: account banned",
-			zap.String("username", username),
+			zap.String("username", account.Username),
 			zap.String("account_id", account.ID.String()),
 		)
-		return nil, nil, ErrAccountBanned
+		s.audit(ctx, account.ID, AuditActionLoginRejected, ipAddress, userAgent, nil, map[string]interface{}{"status": account.Status})
+		return ErrAccountBanned
 	}
 
-	// 3. 检查是否被锁定
 	if account.LockedUntil != nil && account.LockedUntil.After(time.Now()) {
 		s.logger.Warn("authentication failed: account locked",
-			zap.String("username", username),
+			zap.String("username", account.Username),
 			zap.String("account_id", account.ID.String()),
 			zap.Time("locked_until", *account.LockedUntil),
 		)
-		return nil, nil, ErrAccountLocked
+		s.audit(ctx, account.ID, AuditActionLoginRejected, ipAddress, userAgent, nil, map[string]interface{}{
+			"status":       "locked",
+			"locked_until": account.LockedUntil,
+		})
+		return ErrAccountLocked
 	}
 
-	// 4. 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password)); err != nil {
-		s.logger.Warn("authentication failed: invalid password",
-			zap.String("username", username),
-			zap.String("account_id", account.ID.String()),
-			zap.String("ip", ipAddress),
-		)
-
-		// 增加失败次数
-		if err := s.accountRepo.IncrementFailedAttempts(ctx, account.ID); err != nil {
-			s.logger.Error("failed to increment failed attempts", zap.Error(err))
-		}
-
-		// 检查是否需要锁定账号
-		account.FailedLoginAttempts++
-		if account.FailedLoginAttempts >= MaxFailedAttempts {
-			lockUntil := time.Now().Add(LockDuration)
-			if err := s.accountRepo.LockAccount(ctx, account.ID, lockUntil); err != nil {
-				s.logger.Error("failed to lock account", zap.Error(err))
-			} else {
-				s.logger.Warn("account locked due to too many failed attempts",
-					zap.String("account_id", account.ID.String()),
-					zap.Time("locked_until", lockUntil),
-				)
-			}
-		}
-
-		return nil, nil, ErrInvalidCredentials
-	}
+	return nil
+}
 
-	// 5. 密码正确，重置失败次数
+// completeLogin 凭证验证通过后：重置失败次数、更新最后登录信息并写入审计
+func (s *AuthService) completeLogin(ctx context.Context, account *repository.Account, mfa bool, ipAddress, userAgent string) (*repository.Player, error) {
+	// 重置失败次数
 	if account.FailedLoginAttempts > 0 {
 		if err := s.accountRepo.ResetFailedAttempts(ctx, account.ID); err != nil {
 			s.logger.Error("failed to reset failed attempts", zap.Error(err))
 		}
 	}
 
-	// 6. 更新最后登录时间和 IP
+	// 更新最后登录时间和 IP
 	if err := s.accountRepo.UpdateLastLogin(ctx, account.ID, ipAddress); err != nil {
 		s.logger.Error("failed to update last login", zap.Error(err))
 		// 不影响登录流程，继续
 	}
 
-	// 7. 查询玩家信息
+	// 查询玩家信息
 	player, err := s.playerRepo.GetByAccountID(ctx, account.ID)
 	if err != nil {
 		s.logger.Error("failed to get player by account ID",
 			zap.String("account_id", account.ID.String()),
 			zap.Error(err),
 		)
-		return nil, nil, fmt.Errorf("failed to get player: %w", err)
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	before := map[string]interface{}{"last_login_at": account.LastLoginAt, "last_login_ip": account.LastLoginIP}
+	after := map[string]interface{}{
+		"player_id":     player.ID,
+		"last_login_ip": ipAddress,
+	}
+	if mfa {
+		after["mfa"] = true
 	}
+	s.audit(ctx, account.ID, AuditActionLogin, ipAddress, userAgent, before, after)
 
 	s.logger.Info("user authenticated successfully",
-		zap.String("username", username),
+		zap.String("username", account.Username),
 		zap.String("account_id", account.ID.String()),
 		zap.String("player_id", player.ID.String()),
 		zap.String("ip", ipAddress),
+		zap.Bool("mfa", mfa),
 	)
 
-	return account, player, nil
+	return player, nil
+}
+
+// recordFailedLogin 增加失败次数并写入审计，达到上限时锁定账号（密码与两步验证共用）
+func (s *AuthService) recordFailedLogin(ctx context.Context, account *repository.Account, action, ipAddress, userAgent string) {
+	// 增加失败次数
+	if err := s.accountRepo.IncrementFailedAttempts(ctx, account.ID); err != nil {
+		s.logger.Error("failed to increment failed attempts", zap.Error(err))
+	}
+
+	// 检查是否需要锁定账号
+	account.FailedLoginAttempts++
+	s.audit(ctx, account.ID, action, ipAddress, userAgent,
+		map[string]interface{}{"failed_login_attempts": account.FailedLoginAttempts - 1},
+		map[string]interface{}{"failed_login_attempts": account.FailedLoginAttempts},
+	)
+	if account.FailedLoginAttempts >= MaxFailedAttempts {
+		lockUntil := time.Now().Add(LockDuration)
+		if err := s.accountRepo.LockAccount(ctx, account.ID, lockUntil); err != nil {
+			s.logger.Error("failed to lock account", zap.Error(err))
+		} else {
+			s.logger.Warn("account locked due to too many failed attempts",
+				zap.String("account_id", account.ID.String()),
+				zap.Time("locked_until", lockUntil),
+			)
+			s.audit(ctx, account.ID, AuditActionAccountLocked, ipAddress, userAgent,
+				map[string]interface{}{"locked_until": account.LockedUntil},
+				map[string]interface{}{"locked_until": lockUntil, "failed_login_attempts": account.FailedLoginAttempts},
+			)
+		}
+	}
+}
+
+// ChangePassword 修改密码并撤销玩家所有登入会话（所有设备需重新登录）
+func (s *AuthService) ChangePassword(ctx context.Context, playerID uuid.UUID, currentPassword, newPassword, ipAddress, userAgent string) error {
+	if newPassword == "" {
+		return ErrPasswordRequired
+	}
+
+	account, err := s.accountByPlayerID(ctx, playerID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(currentPassword)); err != nil {
+		s.logger.Warn("password change failed: invalid current password",
+			zap.String("account_id", account.ID.String()),
+			zap.String("ip", ipAddress),
+		)
+		return ErrInvalidCredentials
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), BcryptCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	account.PasswordHash = string(passwordHash)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if s.sessions != nil {
+		if err := s.sessions.RevokeAll(ctx, playerID, RevokeReasonPasswordChanged); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	s.audit(ctx, account.ID, AuditActionPasswordChanged, ipAddress, userAgent, nil, map[string]interface{}{
+		"sessions_revoked": s.sessions != nil,
+	})
+
+	s.logger.Info("password changed",
+		zap.String("account_id", account.ID.String()),
+		zap.String("player_id", playerID.String()),
+	)
+
+	return nil
+}
+
+// audit 写入账号的审计日志，执行者为账号本人
+// 未设定审计仓储时略过；写入失败只记录日志，不影响认证流程
+func (s *AuthService) audit(ctx context.Context, accountID uuid.UUID, action, ipAddress, userAgent string, before, after map[string]interface{}) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	changes := make(map[string]interface{}, 2)
+	if before != nil {
+		changes["before"] = before
+	}
+	if after != nil {
+		changes["after"] = after
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		s.logger.Error("failed to marshal audit changes", zap.String("action", action), zap.Error(err))
+		return
+	}
+
+	err = s.auditRepo.Create(ctx, &repository.AuditLog{
+		EntityType: "account",
+		EntityID:   accountID,
+		Action:     action,
+		Changes:    data,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		ActorID:    &accountID,
+		ActorType:  repository.AuditActorUser,
+	})
+	if err != nil {
+		s.logger.Error("failed to write audit log",
+			zap.String("account_id", accountID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+	}
 }
 
 // GetPlayerByAccountID 根据账号 ID 获取玩家信息
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryAccountRepo 以記憶體保存單一帳號，模擬失敗次數與鎖定
+type memoryAccountRepo struct {
+	repository.AccountRepository
+	account *repository.Account
+}
+
+func (r *memoryAccountRepo) GetByUsername(ctx context.Context, username string) (*repository.Account, error) {
+	if r.account.Username != username {
+		return nil, errors.New("account not found")
+	}
+	copied := *r.account
+	return &copied, nil
+}
+
+func (r *memoryAccountRepo) GetByEmail(ctx context.Context, email string) (*repository.Account, error) {
+	if r.account.Email != email {
+		return nil, errors.New("account not found")
+	}
+	copied := *r.account
+	return &copied, nil
+}
+
+func (r *memoryAccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Account, error) {
+	if r.account.ID != id {
+		return nil, errors.New("account not found")
+	}
+	copied := *r.account
+	return &copied, nil
+}
+
+func (r *memoryAccountRepo) Update(ctx context.Context, account *repository.Account) error {
+	copied := *account
+	r.account = &copied
+	return nil
+}
+
+func (r *memoryAccountRepo) IncrementFailedAttempts(ctx context.Context, id uuid.UUID) error {
+	r.account.FailedLoginAttempts++
+	return nil
+}
+
+func (r *memoryAccountRepo) ResetFailedAttempts(ctx context.Context, id uuid.UUID) error {
+	r.account.FailedLoginAttempts = 0
+	r.account.LockedUntil = nil
+	return nil
+}
+
+func (r *memoryAccountRepo) LockAccount(ctx context.Context, id uuid.UUID, until time.Time) error {
+	r.account.LockedUntil = &until
+	return nil
+}
+
+func (r *memoryAccountRepo) UpdateLastLogin(ctx context.Context, id uuid.UUID, ip string) error {
+	now := time.Now()
+	r.account.LastLoginAt = &now
+	r.account.LastLoginIP = &ip
+	return nil
+}
+
+type memoryPlayerRepo struct {
+	repository.PlayerRepository
+	player *repository.Player
+}
+
+func (r memoryPlayerRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Player, error) {
+	if r.player == nil || r.player.ID != id {
+		return nil, errors.New("player not found")
+	}
+	return r.player, nil
+}
+
+func (r memoryPlayerRepo) GetByAccountID(ctx context.Context, accountID uuid.UUID) (*repository.Player, error) {
+	if r.player != nil {
+		return r.player, nil
+	}
+	return &repository.Player{ID: uuid.New(), AccountID: accountID}, nil
+}
+
+type memoryAuditLogRepo struct {
+	repository.AuditLogRepository
+	logs []*repository.AuditLog
+}
+
+func (r *memoryAuditLogRepo) Create(ctx context.Context, log *repository.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *memoryAuditLogRepo) actions() []string {
+	actions := make([]string, 0, len(r.logs))
+	for _, log := range r.logs {
+		actions = append(actions, log.Action)
+	}
+	return actions
+}
+
+// TestAuthenticate_AuditsFailuresAndLockout 登入失敗、鎖定與鎖定期間的登入嘗試都寫入審計日誌
+func TestAuthenticate_AuditsFailuresAndLockout(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountRepo := &memoryAccountRepo{account: &repository.Account{
+		ID:           uuid.New(),
+		Username:     "alice",
+		PasswordHash: string(hash),
+		Status:       "active",
+	}}
+	auditRepo := &memoryAuditLogRepo{}
+	svc := NewAuthService(accountRepo, memoryPlayerRepo{}, nil, zap.NewNop())
+	svc.SetAuditLogRepository(auditRepo)
+
+	// 成功登入
+	if _, _, err := svc.Authenticate(ctx, "alice", "correct-horse", "198.51.100.1", "browser"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for i := 0; i < MaxFailedAttempts; i++ {
+		if _, _, err := svc.Authenticate(ctx, "alice", "wrong", "198.51.100.2", "bot"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: expected ErrInvalidCredentials, got %v", i+1, err)
+		}
+	}
+	if _, _, err := svc.Authenticate(ctx, "alice", "correct-horse", "198.51.100.2", "bot"); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("expected ErrAccountLocked, got %v", err)
+	}
+
+	want := []string{AuditActionLogin}
+	for i := 0; i < MaxFailedAttempts; i++ {
+		want = append(want, AuditActionLoginFailed)
+	}
+	want = append(want, AuditActionAccountLocked, AuditActionLoginRejected)
+
+	got := auditRepo.actions()
+	if len(got) != len(want) {
+		t.Fatalf("expected actions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected actions %v, got %v", want, got)
+		}
+	}
+
+	for _, log := range auditRepo.logs {
+		if log.EntityType != "account" || log.EntityID != accountRepo.account.ID ||
+			log.ActorID == nil || *log.ActorID != accountRepo.account.ID || log.ActorType != repository.AuditActorUser {
+			t.Errorf("unexpected audit log: %+v", log)
+		}
+	}
+	if first := auditRepo.logs[0]; first.IPAddress != "198.51.100.1" || first.UserAgent != "browser" {
+		t.Errorf("expected request source on login audit, got %+v", first)
+	}
+	if last := auditRepo.logs[len(auditRepo.logs)-1]; last.IPAddress != "198.51.100.2" || last.UserAgent != "bot" {
+		t.Errorf("expected request source on rejected login audit, got %+v", last)
+	}
+}
+
+// TestChangePassword_RevokesAllSessions 修改密碼後所有裝置的會話失效，並寫入審計日誌
+func TestChangePassword_RevokesAllSessions(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountRepo := &memoryAccountRepo{account: &repository.Account{
+		ID:           uuid.New(),
+		Username:     "alice",
+		PasswordHash: string(hash),
+		Status:       "active",
+	}}
+	player := &repository.Player{ID: uuid.New(), AccountID: accountRepo.account.ID}
+	auditRepo := &memoryAuditLogRepo{}
+	sessions := NewSessionService(newMemorySessionRepo(), NewJWTService("test-secret"), zap.NewNop())
+	svc := NewAuthService(accountRepo, memoryPlayerRepo{player: player}, nil, zap.NewNop())
+	svc.SetAuditLogRepository(auditRepo)
+	svc.SetSessionService(sessions)
+
+	phone, _ := sessions.Start(ctx, SessionIdentity{PlayerID: player.ID, Username: "alice"}, "", "phone")
+	laptop, _ := sessions.Start(ctx, SessionIdentity{PlayerID: player.ID, Username: "alice"}, "", "laptop")
+
+	if err := svc.ChangePassword(ctx, player.ID, "wrong", "new-password", "", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if active, _ := sessions.IsSessionActive(ctx, phone.SessionID); !active {
+		t.Fatal("expected sessions to stay active after failed password change")
+	}
+
+	if err := svc.ChangePassword(ctx, player.ID, "old-password", "new-password", "198.51.100.1", "phone"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(accountRepo.account.PasswordHash), []byte("new-password")) != nil {
+		t.Fatal("expected password hash to be updated")
+	}
+	for _, id := range []string{phone.SessionID, laptop.SessionID} {
+		if active, _ := sessions.IsSessionActive(ctx, id); active {
+			t.Fatalf("expected session %s to be revoked", id)
+		}
+	}
+	if got := auditRepo.actions(); len(got) != 1 || got[0] != AuditActionPasswordChanged {
+		t.Fatalf("expected password_changed audit, got %v", got)
+	}
+}
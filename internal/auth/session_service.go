@@ -0,0 +1,352 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidRefreshToken refresh token 无效或已过期
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused 已轮替的 refresh token 被再次使用（会话已撤销）
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrSessionRevoked 会话已撤销
+	ErrSessionRevoked = errors.New("session has been revoked")
+	// ErrInvalidChallenge 两步验证挑战 token 无效或已过期
+	ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+	// ErrSessionNotFound 会话不存在或不属于该玩家
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// 会话撤销原因
+const (
+	RevokeReasonLogout          = "logout"
+	RevokeReasonLogoutAll       = "logout_all"
+	RevokeReasonUserRevoked     = "revoked_by_user"
+	RevokeReasonPasswordChanged = "password_changed"
+	RevokeReasonPasswordReset   = "password_reset"
+	RevokeReasonTokenReused     = "refresh_token_reused"
+)
+
+const (
+	// DefaultAccessTokenTTL 默认 Access Token 有效期
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL 默认 Refresh Token 有效期（每次轮替后重新计算）
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// TwoFactorChallengeTTL 密码验证通过后完成两步验证的时限
+	TwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// challengePurpose 两步验证挑战 token 的用途
+const challengePurpose = "2fa_challenge"
+
+// TokenPair 登入或刷新后发给客户端的凭证
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	SessionID        string
+	AccessExpiresIn  time.Duration
+	RefreshExpiresAt time.Time
+}
+
+// SessionIdentity 建立会话时写入 Access Token 的身份资料
+type SessionIdentity struct {
+	PlayerID uuid.UUID
+	Username string
+	Roles    []string // 管理角色；变更角色时需撤销玩家所有会话
+	MFA      bool     // 登入时通过了两步验证
+}
+
+// sessionData 保存在会话 data 栏位的资料（刷新时签发 Access Token 使用）
+type sessionData struct {
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
+	MFA      bool     `json:"mfa,omitempty"` // 登入时通过两步验证，刷新后保留
+}
+
+// RevocationListener 会话撤销通知（由 ws.SessionManager 实现，断开对应的 WebSocket 连线）
+type RevocationListener interface {
+	// SessionRevoked 单一会话被撤销
+	SessionRevoked(sessionID string)
+	// PlayerSessionsRevoked 玩家所有会话被撤销
+	PlayerSessionsRevoked(playerID uuid.UUID)
+}
+
+// SessionService 登入会话服务：签发短效 Access Token 与可轮替的 Refresh Token
+// 每次登入建立一个会话；刷新时轮替 refresh token，旧 token 再次出现视为遭窃并撤销整个会话
+type SessionService struct {
+	sessionRepo repository.AuthSessionRepository
+	jwtService  *JWTService
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	listener    RevocationListener // 可为 nil
+	logger      *zap.Logger
+}
+
+// NewSessionService 创建登入会话服务
+func NewSessionService(sessionRepo repository.AuthSessionRepository, jwtService *JWTService, logger *zap.Logger) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		jwtService:  jwtService,
+		accessTTL:   DefaultAccessTokenTTL,
+		refreshTTL:  DefaultRefreshTokenTTL,
+		logger:      logger,
+	}
+}
+
+// SetTokenTTL 设定 Access Token 与 Refresh Token 有效期
+func (s *SessionService) SetTokenTTL(accessTTL, refreshTTL time.Duration) {
+	s.accessTTL = accessTTL
+	s.refreshTTL = refreshTTL
+}
+
+// SetRevocationListener 设定会话撤销通知
+func (s *SessionService) SetRevocationListener(listener RevocationListener) {
+	s.listener = listener
+}
+
+// Start 登入成功后建立会话并签发凭证
+func (s *SessionService) Start(ctx context.Context, identity SessionIdentity, ipAddress, userAgent string) (*TokenPair, error) {
+	refreshToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	playerID := identity.PlayerID
+	data := sessionData{Username: identity.Username, Roles: identity.Roles, MFA: identity.MFA}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session data: %w", err)
+	}
+
+	now := time.Now()
+	session := &repository.AuthSession{
+		ID:               uuid.New().String(),
+		PlayerID:         playerID,
+		Data:             encoded,
+		RefreshTokenHash: tokenHash,
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+		ExpiresAt:        now.Add(s.refreshTTL),
+		LastUsedAt:       &now,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("session started",
+		zap.String("session_id", session.ID),
+		zap.String("player_id", playerID.String()),
+		zap.String("ip", ipAddress),
+	)
+
+	return s.issue(session, data, refreshToken)
+}
+
+// Refresh 以 refresh token 换发新凭证（refresh token 同时轮替）
+func (s *SessionService) Refresh(ctx context.Context, refreshToken, ipAddress, userAgent string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	tokenHash := hashToken(refreshToken)
+
+	session, err := s.sessionRepo.GetByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	// 已轮替的旧 token 再次出现：token 可能遭窃，撤销整个会话
+	if session.RefreshTokenHash != tokenHash {
+		if _, err := s.sessionRepo.Revoke(ctx, session.ID, RevokeReasonTokenReused); err != nil {
+			return nil, err
+		}
+		s.notifySessionRevoked(session.ID)
+		s.logger.Warn("refresh token reuse detected, session revoked",
+			zap.String("session_id", session.ID),
+			zap.String("player_id", session.PlayerID.String()),
+			zap.String("ip", ipAddress),
+		)
+		return nil, ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextToken, nextHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = nextHash
+	session.IPAddress = ipAddress
+	session.UserAgent = userAgent
+	session.ExpiresAt = now.Add(s.refreshTTL)
+	session.LastUsedAt = &now
+
+	rotated, err := s.sessionRepo.Rotate(ctx, session, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 并发刷新或会话刚被撤销
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var data sessionData
+	if err := json.Unmarshal(session.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
+	}
+	return s.issue(session, data, nextToken)
+}
+
+// Revoke 撤销单一会话（登出）；会话不存在或已撤销时不视为错误
+func (s *SessionService) Revoke(ctx context.Context, sessionID, reason string) error {
+	revoked, err := s.sessionRepo.Revoke(ctx, sessionID, reason)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		s.logger.Info("session revoked",
+			zap.String("session_id", sessionID),
+			zap.String("reason", reason),
+		)
+		s.notifySessionRevoked(sessionID)
+	}
+	return nil
+}
+
+// RevokeOwned 撤销玩家自己的某个会话（装置管理）；会话不属于该玩家时回传 ErrSessionNotFound
+func (s *SessionService) RevokeOwned(ctx context.Context, playerID uuid.UUID, sessionID, reason string) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.PlayerID != playerID {
+		return ErrSessionNotFound
+	}
+	return s.Revoke(ctx, sessionID, reason)
+}
+
+// RevokeAll 撤销玩家所有会话（例如修改密码后）
+func (s *SessionService) RevokeAll(ctx context.Context, playerID uuid.UUID, reason string) error {
+	count, err := s.sessionRepo.RevokeAllByPlayer(ctx, playerID, reason)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("all player sessions revoked",
+		zap.String("player_id", playerID.String()),
+		zap.String("reason", reason),
+		zap.Int64("count", count),
+	)
+	if s.listener != nil {
+		s.listener.PlayerSessionsRevoked(playerID)
+	}
+	return nil
+}
+
+// ListActive 列出玩家未撤销且未过期的会话（登入装置）
+func (s *SessionService) ListActive(ctx context.Context, playerID uuid.UUID) ([]*repository.AuthSession, error) {
+	sessions, err := s.sessionRepo.ListActiveByPlayer(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// IsSessionActive 会话是否仍有效（实现 SessionValidator，供 JWTMiddleware 使用）
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return session != nil && session.IsActive(time.Now()), nil
+}
+
+// notifySessionRevoked 通知单一会话已撤销
+func (s *SessionService) notifySessionRevoked(sessionID string) {
+	if s.listener != nil {
+		s.listener.SessionRevoked(sessionID)
+	}
+}
+
+// IssueChallenge 密码验证通过但需要两步验证时，签发短效挑战 token（不能当作 Access Token 使用）
+func (s *SessionService) IssueChallenge(playerID uuid.UUID, username string) (string, error) {
+	token, err := s.jwtService.GenerateTokenWithClaims(JWTClaims{
+		PlayerID: playerID.String(),
+		Username: username,
+		Purpose:  challengePurpose,
+	}, TwoFactorChallengeTTL)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	return token, nil
+}
+
+// ParseChallenge 验证挑战 token，回传玩家 ID
+func (s *SessionService) ParseChallenge(token string) (uuid.UUID, error) {
+	claims, err := s.jwtService.ValidateToken(token)
+	if err != nil || claims.Purpose != challengePurpose {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+	playerID, err := uuid.Parse(claims.PlayerID)
+	if err != nil {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+	return playerID, nil
+}
+
+// issue 为会话签发 Access Token
+func (s *SessionService) issue(session *repository.AuthSession, data sessionData, refreshToken string) (*TokenPair, error) {
+	accessToken, err := s.jwtService.GenerateTokenWithClaims(JWTClaims{
+		PlayerID:  session.PlayerID.String(),
+		Username:  data.Username,
+		SessionID: session.ID,
+		Roles:     data.Roles,
+		MFA:       data.MFA,
+	}, s.accessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		SessionID:        session.ID,
+		AccessExpiresIn:  s.accessTTL,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// newOpaqueToken 产生随机不透明令牌与其哈希（数据库只保存哈希）
+// 用于 refresh token 与邮件验证/重设密码令牌
+func newOpaqueToken() (token, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken 计算令牌的 SHA-256（hex）
+// 用于 refresh token、邮件令牌与两步验证恢复码
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
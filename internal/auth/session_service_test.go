@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// memorySessionRepo 以記憶體保存登入會話
+type memorySessionRepo struct {
+	sessions map[string]*repository.AuthSession
+}
+
+func newMemorySessionRepo() *memorySessionRepo {
+	return &memorySessionRepo{sessions: make(map[string]*repository.AuthSession)}
+}
+
+func (r *memorySessionRepo) Create(ctx context.Context, session *repository.AuthSession) error {
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *memorySessionRepo) GetByID(ctx context.Context, id string) (*repository.AuthSession, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memorySessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*repository.AuthSession, error) {
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == tokenHash || session.PreviousTokenHash == tokenHash {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memorySessionRepo) Rotate(ctx context.Context, session *repository.AuthSession, currentHash string) (bool, error) {
+	stored, ok := r.sessions[session.ID]
+	if !ok || stored.RefreshTokenHash != currentHash || stored.RevokedAt != nil {
+		return false, nil
+	}
+	copied := *session
+	copied.PreviousTokenHash = currentHash
+	r.sessions[session.ID] = &copied
+	return true, nil
+}
+
+func (r *memorySessionRepo) Revoke(ctx context.Context, id, reason string) (bool, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+	return true, nil
+}
+
+func (r *memorySessionRepo) RevokeAllByPlayer(ctx context.Context, playerID uuid.UUID, reason string) (int64, error) {
+	var count int64
+	for _, session := range r.sessions {
+		if session.PlayerID == playerID && session.RevokedAt == nil {
+			now := time.Now()
+			session.RevokedAt = &now
+			session.RevokedReason = reason
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memorySessionRepo) ListActiveByPlayer(ctx context.Context, playerID uuid.UUID) ([]*repository.AuthSession, error) {
+	var sessions []*repository.AuthSession
+	for _, session := range r.sessions {
+		if session.PlayerID == playerID && session.IsActive(time.Now()) {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+// TestSessionService_RefreshRotatesAndDetectsReuse 刷新會輪替 refresh token，舊 token 再次使用時撤銷整個會話
+func TestSessionService_RefreshRotatesAndDetectsReuse(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemorySessionRepo()
+	jwtService := NewJWTService("test-secret")
+	svc := NewSessionService(repo, jwtService, zap.NewNop())
+	playerID := uuid.New()
+
+	first, err := svc.Start(ctx, SessionIdentity{PlayerID: playerID, Username: "alice"}, "198.51.100.1", "browser")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	claims, err := jwtService.ValidateToken(first.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.SessionID != first.SessionID || claims.PlayerID != playerID.String() {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if first.AccessExpiresIn != DefaultAccessTokenTTL {
+		t.Errorf("expected access TTL %v, got %v", DefaultAccessTokenTTL, first.AccessExpiresIn)
+	}
+
+	second, err := svc.Refresh(ctx, first.RefreshToken, "198.51.100.2", "browser")
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.SessionID != first.SessionID {
+		t.Fatalf("expected rotated token on same session, got %+v", second)
+	}
+	claims, err = jwtService.ValidateToken(second.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.Username != "alice" {
+		t.Errorf("expected username alice, got %q", claims.Username)
+	}
+
+	// 重複使用已輪替的 token：會話被撤銷
+	if _, err := svc.Refresh(ctx, first.RefreshToken, "203.0.113.9", "attacker"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, first.SessionID); active {
+		t.Fatal("expected session to be revoked after token reuse")
+	}
+	if repo.sessions[first.SessionID].RevokedReason != RevokeReasonTokenReused {
+		t.Errorf("unexpected revoke reason %q", repo.sessions[first.SessionID].RevokedReason)
+	}
+
+	// 合法持有者的新 token 也隨會話失效
+	if _, err := svc.Refresh(ctx, second.RefreshToken, "198.51.100.2", "browser"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("expected ErrSessionRevoked, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, "unknown", "198.51.100.2", "browser"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
+
+// TestSessionService_RevokeAndRevokeAll 登出只撤銷目前會話，RevokeAll 撤銷玩家所有會話
+func TestSessionService_RevokeAndRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemorySessionRepo()
+	svc := NewSessionService(repo, NewJWTService("test-secret"), zap.NewNop())
+	playerID := uuid.New()
+
+	phone, _ := svc.Start(ctx, SessionIdentity{PlayerID: playerID, Username: "alice"}, "", "phone")
+	laptop, _ := svc.Start(ctx, SessionIdentity{PlayerID: playerID, Username: "alice"}, "", "laptop")
+	other, _ := svc.Start(ctx, SessionIdentity{PlayerID: uuid.New(), Username: "bob"}, "", "browser")
+
+	if err := svc.Revoke(ctx, phone.SessionID, RevokeReasonLogout); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, phone.SessionID); active {
+		t.Fatal("expected logged out session to be inactive")
+	}
+	if active, _ := svc.IsSessionActive(ctx, laptop.SessionID); !active {
+		t.Fatal("expected other device session to stay active")
+	}
+	if _, err := svc.Refresh(ctx, phone.RefreshToken, "", "phone"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("expected ErrSessionRevoked, got %v", err)
+	}
+
+	if err := svc.RevokeAll(ctx, playerID, RevokeReasonPasswordChanged); err != nil {
+		t.Fatalf("RevokeAll failed: %v", err)
+	}
+	if active, _ := svc.IsSessionActive(ctx, laptop.SessionID); active {
+		t.Fatal("expected all player sessions to be revoked")
+	}
+	if active, _ := svc.IsSessionActive(ctx, other.SessionID); !active {
+		t.Fatal("expected other player's session to stay active")
+	}
+}
+
+// TestJWTMiddleware_RejectsRevokedSession 會話撤銷後 Access Token 立即失效
+func TestJWTMiddleware_RejectsRevokedSession(t *testing.T) {
+	ctx := context.Background()
+	jwtService := NewJWTService("test-secret")
+	svc := NewSessionService(newMemorySessionRepo(), jwtService, zap.NewNop())
+	tokens, err := svc.Start(ctx, SessionIdentity{PlayerID: uuid.New(), Username: "alice"}, "", "")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	handler := JWTMiddleware(jwtService, svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionID, _ := GetSessionIDFromContext(r.Context()); sessionID != tokens.SessionID {
+			t.Errorf("expected session %s in context, got %s", tokens.SessionID, sessionID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := call(tokens.AccessToken); code != http.StatusNoContent {
+		t.Fatalf("expected 204 before logout, got %d", code)
+	}
+	if err := svc.Revoke(ctx, tokens.SessionID, RevokeReasonLogout); err != nil {
+		t.Fatal(err)
+	}
+	if code := call(tokens.AccessToken); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", code)
+	}
+
+	// 沒有會話 ID 的舊版 token 不被接受
+	legacy, err := jwtService.GenerateToken(uuid.New().String(), "bob", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := call(legacy); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for token without session, got %d", code)
+	}
+}
+
+// recordingRevocationListener 記錄會話撤銷通知
+type recordingRevocationListener struct {
+	sessions []string
+	players  []uuid.UUID
+}
+
+func (l *recordingRevocationListener) SessionRevoked(sessionID string) {
+	l.sessions = append(l.sessions, sessionID)
+}
+
+func (l *recordingRevocationListener) PlayerSessionsRevoked(playerID uuid.UUID) {
+	l.players = append(l.players, playerID)
+}
+
+// TestSessionService_DeviceManagement 列出登入裝置、只能撤銷自己的會話，撤銷時通知 WebSocket 斷線
+func TestSessionService_DeviceManagement(t *testing.T) {
+	ctx := context.Background()
+	jwtService := NewJWTService("test-secret")
+	svc := NewSessionService(newMemorySessionRepo(), jwtService, zap.NewNop())
+	listener := &recordingRevocationListener{}
+	svc.SetRevocationListener(listener)
+	playerID := uuid.New()
+
+	phone, _ := svc.Start(ctx, SessionIdentity{PlayerID: playerID, Username: "alice"}, "198.51.100.1", "phone")
+	laptop, _ := svc.Start(ctx, SessionIdentity{PlayerID: playerID, Username: "alice"}, "198.51.100.2", "laptop")
+	other, _ := svc.Start(ctx, SessionIdentity{PlayerID: uuid.New(), Username: "bob"}, "", "browser")
+
+	// 列表標示目前的會話
+	handler := &Handler{sessionService: svc, logger: zap.NewNop()}
+	list := JWTMiddleware(jwtService, svc)(http.HandlerFunc(handler.HandleListSessions))
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+	rec := httptest.NewRecorder()
+	list.ServeHTTP(rec, req)
+	var resp SessionListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %+v", resp.Sessions)
+	}
+	for _, session := range resp.Sessions {
+		if session.Current != (session.ID == laptop.SessionID) {
+			t.Errorf("unexpected current flag on %+v", session)
+		}
+	}
+
+	if err := svc.RevokeOwned(ctx, playerID, other.SessionID, RevokeReasonUserRevoked); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound for another player's session, got %v", err)
+	}
+	if err := svc.RevokeOwned(ctx, playerID, phone.SessionID, RevokeReasonUserRevoked); err != nil {
+		t.Fatalf("RevokeOwned failed: %v", err)
+	}
+	if len(listener.sessions) != 1 || listener.sessions[0] != phone.SessionID {
+		t.Fatalf("expected revocation notice for phone session, got %v", listener.sessions)
+	}
+	if sessions, _ := svc.ListActive(ctx, playerID); len(sessions) != 1 || sessions[0].ID != laptop.SessionID {
+		t.Fatalf("expected only laptop session to remain, got %+v", sessions)
+	}
+
+	if err := svc.RevokeAll(ctx, playerID, RevokeReasonLogoutAll); err != nil {
+		t.Fatalf("RevokeAll failed: %v", err)
+	}
+	if len(listener.players) != 1 || listener.players[0] != playerID {
+		t.Fatalf("expected player revocation notice, got %v", listener.players)
+	}
+	if active, _ := svc.IsSessionActive(ctx, other.SessionID); !active {
+		t.Fatal("expected other player's session to stay active")
+	}
+}
@@ -9,13 +9,20 @@ import (
 	"time"
 )
 
+// TicketClaims 票券關聯的玩家與登入會話
+// WebSocket 連線記錄 SessionID，登入會話撤銷時據此斷開連線
+type TicketClaims struct {
+	PlayerID  string `json:"player_id"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
 // TicketStore 管理一次性票券的儲存與驗證
 type TicketStore interface {
-	// Generate 生成一個新的票券，關聯到指定的玩家 ID
-	Generate(ctx context.Context, playerID string, ttl time.Duration) (string, error)
+	// Generate 生成一個新的票券，關聯到指定的玩家與登入會話
+	Generate(ctx context.Context, claims TicketClaims, ttl time.Duration) (string, error)
 
-	// Validate 驗證票券並返回關聯的玩家 ID（驗證成功後立即銷毀票券）
-	Validate(ctx context.Context, ticket string) (string, error)
+	// Validate 驗證票券並返回關聯的玩家與登入會話（驗證成功後立即銷毀票券）
+	Validate(ctx context.Context, ticket string) (*TicketClaims, error)
 
 	// Close 關閉儲存資源
 	Close() error
@@ -29,7 +36,7 @@ type MemoryTicketStore struct {
 }
 
 type ticketData struct {
-	PlayerID  string
+	Claims    TicketClaims
 	ExpiresAt time.Time
 }
 
@@ -46,7 +53,7 @@ func NewMemoryTicketStore() *MemoryTicketStore {
 }
 
 // Generate 生成票券
-func (s *MemoryTicketStore) Generate(ctx context.Context, playerID string, ttl time.Duration) (string, error) {
+func (s *MemoryTicketStore) Generate(ctx context.Context, claims TicketClaims, ttl time.Duration) (string, error) {
 	// 生成 32 字元的隨機票券
 	ticket, err := generateRandomTicket(32)
 	if err != nil {
@@ -57,7 +64,7 @@ func (s *MemoryTicketStore) Generate(ctx context.Context, playerID string, ttl t
 	defer s.mu.Unlock()
 
 	s.tickets[ticket] = &ticketData{
-		PlayerID:  playerID,
+		Claims:    claims,
 		ExpiresAt: time.Now().Add(ttl),
 	}
 
@@ -65,27 +72,27 @@ func (s *MemoryTicketStore) Generate(ctx context.Context, playerID string, ttl t
 }
 
 // Validate 驗證並銷毀票券
-func (s *MemoryTicketStore) Validate(ctx context.Context, ticket string) (string, error) {
+func (s *MemoryTicketStore) Validate(ctx context.Context, ticket string) (*TicketClaims, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	data, exists := s.tickets[ticket]
 	if !exists {
-		return "", fmt.Errorf("invalid ticket: not found")
+		return nil, fmt.Errorf("invalid ticket: not found")
 	}
 
 	// 檢查是否過期
 	if time.Now().After(data.ExpiresAt) {
 		delete(s.tickets, ticket)
-		return "", fmt.Errorf("invalid ticket: expired")
+		return nil, fmt.Errorf("invalid ticket: expired")
 	}
 
-	playerID := data.PlayerID
+	claims := data.Claims
 
 	// 立即銷毀票券（防止重放攻擊）
 	delete(s.tickets, ticket)
 
-	return playerID, nil
+	return &claims, nil
 }
 
 // Close 清理資源
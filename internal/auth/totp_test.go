@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTOTPCode_RFC6238Vector RFC 6238 附錄 B 的 SHA1 測試向量（取末 6 位）
+func TestTOTPCode_RFC6238Vector(t *testing.T) {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+	} {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.code {
+			t.Errorf("t=%d: expected %s, got %s", tc.unix, tc.code, code)
+		}
+	}
+
+	// 允許前後一個時間步長的誤差
+	now := time.Unix(1234567890, 0)
+	if step, ok := VerifyTOTP(secret, "005924", now.Add(TOTPPeriod)); !ok || step != TOTPStep(now) {
+		t.Fatalf("expected code from previous step to verify, got step=%d ok=%v", step, ok)
+	}
+	if _, ok := VerifyTOTP(secret, "005924", now.Add(3*TOTPPeriod)); ok {
+		t.Fatal("expected code outside skew window to be rejected")
+	}
+
+	uri := TOTPProvisioningURI("TheNuts", "alice", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/TheNuts:alice?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected provisioning URI %q", uri)
+	}
+}
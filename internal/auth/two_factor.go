@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTwoFactorRequired 密码正确但需要两步验证（实际回传 *TwoFactorRequiredError）
+	ErrTwoFactorRequired = errors.New("two-factor authentication required")
+	// ErrInvalidTwoFactorCode 验证码或恢复码错误
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorAlreadyEnabled 两步验证已启用
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrTwoFactorNotEnabled 两步验证未启用
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+	// ErrTwoFactorNotEnrolled 尚未开始绑定
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment not started")
+)
+
+const (
+	// TOTPIssuer 验证器 App 中显示的发行者
+	TOTPIssuer = "TheNuts"
+	// RecoveryCodeCount 每次产生的恢复码数量
+	RecoveryCodeCount = 10
+)
+
+// 两步验证的审计动作
+const (
+	AuditActionTwoFactorEnabled         = "two_factor_enabled"
+	AuditActionTwoFactorDisabled        = "two_factor_disabled"
+	AuditActionTwoFactorFailed          = "two_factor_failed"
+	AuditActionRecoveryCodeUsed         = "recovery_code_used"
+	AuditActionRecoveryCodesRegenerated = "recovery_codes_regenerated"
+)
+
+// TwoFactorRequiredError 密码验证通过，需以挑战 token 与验证码完成登录
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresIn      time.Duration
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+// Is 使 errors.Is(err, ErrTwoFactorRequired) 成立
+func (e *TwoFactorRequiredError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
+
+// TwoFactorEnrollment 绑定资讯（前端将 ProvisioningURI 转为 QR Code）
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// SetTwoFactorRepository 设定两步验证仓储
+func (s *AuthService) SetTwoFactorRepository(twoFactorRepo repository.TwoFactorRepository) {
+	s.twoFactorRepo = twoFactorRepo
+}
+
+// TwoFactorEnabled 账号是否已启用两步验证
+func (s *AuthService) TwoFactorEnabled(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	if s.twoFactorRepo == nil {
+		return false, nil
+	}
+	totp, err := s.twoFactorRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	return totp.IsEnabled(), nil
+}
+
+// CompleteTwoFactorLogin 登录第二步：以挑战 token 与验证码（或恢复码）完成登录
+// 验证码错误与密码错误共用失败次数与锁定
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, ipAddress, userAgent string) (*repository.Account, *repository.Player, error) {
+	if s.sessions == nil || s.twoFactorRepo == nil {
+		return nil, nil, ErrInvalidChallenge
+	}
+	playerID, err := s.sessions.ParseChallenge(challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := s.accountByPlayerID(ctx, playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkLoginAllowed(ctx, account, ipAddress, userAgent); err != nil {
+		return nil, nil, err
+	}
+
+	totp, err := s.twoFactorRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !totp.IsEnabled() {
+		return nil, nil, ErrInvalidChallenge
+	}
+
+	ok, err := s.verifySecondFactor(ctx, account, totp, code, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		s.logger.Warn("authentication failed: invalid two-factor code",
+			zap.String("account_id", account.ID.String()),
+			zap.String("ip", ipAddress),
+		)
+		s.recordFailedLogin(ctx, account, AuditActionTwoFactorFailed, ipAddress, userAgent)
+		return nil, nil, ErrInvalidTwoFactorCode
+	}
+
+	player, err := s.completeLogin(ctx, account, true, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, player, nil
+}
+
+// BeginTwoFactorEnrollment 开始绑定：产生新金钥（重复调用会替换尚未确认的金钥）
+func (s *AuthService) BeginTwoFactorEnrollment(ctx context.Context, playerID uuid.UUID) (*TwoFactorEnrollment, error) {
+	if s.twoFactorRepo == nil {
+		return nil, errors.New("two-factor repository not configured")
+	}
+	account, err := s.accountByPlayerID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.twoFactorRepo.SavePending(ctx, &repository.AccountTOTP{AccountID: account.ID, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(TOTPIssuer, account.Username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactorEnrollment 以验证器 App 的验证码确认绑定，回传恢复码（只显示这一次）
+func (s *AuthService) ConfirmTwoFactorEnrollment(ctx context.Context, playerID uuid.UUID, code, ipAddress, userAgent string) ([]string, error) {
+	account, totp, err := s.twoFactorState(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if totp.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := VerifyTOTP(totp.Secret, normalizeTOTPCode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(ctx, account.ID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, account.ID, AuditActionTwoFactorEnabled, ipAddress, userAgent, nil, map[string]interface{}{
+		"recovery_codes": len(codes),
+	})
+	s.logger.Info("two-factor authentication enabled", zap.String("account_id", account.ID.String()))
+
+	return codes, nil
+}
+
+// DisableTwoFactor 停用两步验证（需提供目前的验证码或恢复码）
+func (s *AuthService) DisableTwoFactor(ctx context.Context, playerID uuid.UUID, code, ipAddress, userAgent string) error {
+	account, totp, err := s.twoFactorState(ctx, playerID)
+	if err != nil {
+		return err
+	}
+	if !totp.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, account, totp, code, ipAddress, userAgent)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.twoFactorRepo.Delete(ctx, account.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, account.ID, AuditActionTwoFactorDisabled, ipAddress, userAgent, nil, nil)
+	s.logger.Info("two-factor authentication disabled", zap.String("account_id", account.ID.String()))
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新产生恢复码，旧恢复码全部失效（需提供目前的验证码）
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, playerID uuid.UUID, code, ipAddress, userAgent string) ([]string, error) {
+	account, totp, err := s.twoFactorState(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if !totp.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, account, totp, code, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, account.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, account.ID, AuditActionRecoveryCodesRegenerated, ipAddress, userAgent, nil, map[string]interface{}{
+		"recovery_codes": len(codes),
+	})
+	return codes, nil
+}
+
+// twoFactorChallenge 账号启用两步验证时签发挑战 token，未启用时回传 nil
+func (s *AuthService) twoFactorChallenge(ctx context.Context, account *repository.Account) (*TwoFactorRequiredError, error) {
+	enabled, err := s.TwoFactorEnabled(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor status: %w", err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+	if s.sessions == nil {
+		return nil, errors.New("session service not configured")
+	}
+
+	player, err := s.playerRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+	token, err := s.sessions.IssueChallenge(player.ID, account.Username)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorRequiredError{ChallengeToken: token, ExpiresIn: TwoFactorChallengeTTL}, nil
+}
+
+// twoFactorState 查询玩家的账号与两步验证设定
+func (s *AuthService) twoFactorState(ctx context.Context, playerID uuid.UUID) (*repository.Account, *repository.AccountTOTP, error) {
+	if s.twoFactorRepo == nil {
+		return nil, nil, errors.New("two-factor repository not configured")
+	}
+	account, err := s.accountByPlayerID(ctx, playerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	totp, err := s.twoFactorRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, totp, nil
+}
+
+// verifySecondFactor 验证 6 位数验证码（同一验证码不可重复使用）或恢复码（单次有效）
+func (s *AuthService) verifySecondFactor(ctx context.Context, account *repository.Account, totp *repository.AccountTOTP, code, ipAddress, userAgent string) (bool, error) {
+	code = normalizeTOTPCode(code)
+	if code == "" {
+		return false, nil
+	}
+
+	if len(code) == TOTPDigits && strings.Trim(code, "0123456789") == "" {
+		step, ok := VerifyTOTP(totp.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.twoFactorRepo.UseStep(ctx, account.ID, step)
+	}
+
+	used, err := s.twoFactorRepo.ConsumeRecoveryCode(ctx, account.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return false, err
+	}
+	s.audit(ctx, account.ID, AuditActionRecoveryCodeUsed, ipAddress, userAgent, nil, nil)
+	return true, nil
+}
+
+// generateRecoveryCodes 产生恢复码（xxxxx-xxxxx）与其哈希
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeTOTPCode 去除空白
+func normalizeTOTPCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+// normalizeRecoveryCode 恢复码不分大小写、忽略连字号
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
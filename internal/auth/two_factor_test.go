@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryTwoFactorRepo 以記憶體保存 TOTP 設定與恢復碼
+type memoryTwoFactorRepo struct {
+	totp          *repository.AccountTOTP
+	recoveryCodes map[string]bool // hash -> 已使用
+}
+
+func (r *memoryTwoFactorRepo) GetByAccountID(ctx context.Context, accountID uuid.UUID) (*repository.AccountTOTP, error) {
+	if r.totp == nil || r.totp.AccountID != accountID {
+		return nil, nil
+	}
+	copied := *r.totp
+	return &copied, nil
+}
+
+func (r *memoryTwoFactorRepo) SavePending(ctx context.Context, totp *repository.AccountTOTP) (bool, error) {
+	if r.totp.IsEnabled() {
+		return false, nil
+	}
+	copied := *totp
+	r.totp = &copied
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepo) Enable(ctx context.Context, accountID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	now := time.Now()
+	r.totp.ConfirmedAt = &now
+	r.totp.LastUsedStep = step
+	return r.ReplaceRecoveryCodes(ctx, accountID, recoveryCodeHashes)
+}
+
+func (r *memoryTwoFactorRepo) UseStep(ctx context.Context, accountID uuid.UUID, step int64) (bool, error) {
+	if r.totp.LastUsedStep >= step {
+		return false, nil
+	}
+	r.totp.LastUsedStep = step
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, accountID uuid.UUID, codeHashes []string) error {
+	r.recoveryCodes = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.recoveryCodes[hash] = false
+	}
+	return nil
+}
+
+func (r *memoryTwoFactorRepo) ConsumeRecoveryCode(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error) {
+	used, ok := r.recoveryCodes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[codeHash] = true
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepo) Delete(ctx context.Context, accountID uuid.UUID) error {
+	r.totp = nil
+	r.recoveryCodes = nil
+	return nil
+}
+
+func currentTOTPCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := TOTPCode(secret, TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// TestTwoFactorLogin_ChallengeReplayAndRecoveryCodes 啟用後登入需要第二步驗證，驗證碼不可重放，恢復碼單次有效
+func TestTwoFactorLogin_ChallengeReplayAndRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountRepo := &memoryAccountRepo{account: &repository.Account{
+		ID:           uuid.New(),
+		Username:     "alice",
+		PasswordHash: string(hash),
+		Status:       "active",
+	}}
+	player := &repository.Player{ID: uuid.New(), AccountID: accountRepo.account.ID}
+	auditRepo := &memoryAuditLogRepo{}
+	twoFactorRepo := &memoryTwoFactorRepo{}
+	jwtService := NewJWTService("test-secret")
+	svc := NewAuthService(accountRepo, memoryPlayerRepo{player: player}, nil, zap.NewNop())
+	svc.SetAuditLogRepository(auditRepo)
+	svc.SetSessionService(NewSessionService(newMemorySessionRepo(), jwtService, zap.NewNop()))
+	svc.SetTwoFactorRepository(twoFactorRepo)
+
+	// 綁定：確認前登入不需要第二步驗證
+	enrollment, err := svc.BeginTwoFactorEnrollment(ctx, player.ID)
+	if err != nil {
+		t.Fatalf("BeginTwoFactorEnrollment failed: %v", err)
+	}
+	if _, _, err := svc.Authenticate(ctx, "alice", "password", "", ""); err != nil {
+		t.Fatalf("expected login without pending enrollment to succeed, got %v", err)
+	}
+	if _, err := svc.ConfirmTwoFactorEnrollment(ctx, player.ID, "000000", "", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected ErrInvalidTwoFactorCode, got %v", err)
+	}
+	confirmCode := currentTOTPCode(t, enrollment.Secret, 0)
+	recoveryCodes, err := svc.ConfirmTwoFactorEnrollment(ctx, player.ID, confirmCode, "", "")
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactorEnrollment failed: %v", err)
+	}
+	if len(recoveryCodes) != RecoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d", RecoveryCodeCount, len(recoveryCodes))
+	}
+	if _, err := svc.BeginTwoFactorEnrollment(ctx, player.ID); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Fatalf("expected ErrTwoFactorAlreadyEnabled, got %v", err)
+	}
+
+	login := func() string {
+		t.Helper()
+		_, _, err := svc.Authenticate(ctx, "alice", "password", "", "")
+		var challenge *TwoFactorRequiredError
+		if !errors.As(err, &challenge) || !errors.Is(err, ErrTwoFactorRequired) {
+			t.Fatalf("expected two-factor challenge, got %v", err)
+		}
+		return challenge.ChallengeToken
+	}
+
+	// 挑戰 token 不能當作 Access Token 使用
+	challenge := login()
+	handler := JWTMiddleware(jwtService, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected challenge token to be rejected as access token, got %d", rec.Code)
+	}
+
+	// 確認綁定時用過的驗證碼不能再次使用，且計入失敗次數
+	if _, _, err := svc.CompleteTwoFactorLogin(ctx, challenge, confirmCode, "", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected replayed code to be rejected, got %v", err)
+	}
+	if accountRepo.account.FailedLoginAttempts != 1 {
+		t.Fatalf("expected failed 2FA to count as failed login, got %d", accountRepo.account.FailedLoginAttempts)
+	}
+
+	nextCode := currentTOTPCode(t, enrollment.Secret, 1)
+	account, loggedIn, err := svc.CompleteTwoFactorLogin(ctx, challenge, nextCode, "", "")
+	if err != nil {
+		t.Fatalf("CompleteTwoFactorLogin failed: %v", err)
+	}
+	if account.ID != accountRepo.account.ID || loggedIn.ID != player.ID {
+		t.Fatalf("unexpected login result: %+v %+v", account, loggedIn)
+	}
+	if accountRepo.account.FailedLoginAttempts != 0 {
+		t.Fatal("expected failed attempts to reset after second factor")
+	}
+	if _, _, err := svc.CompleteTwoFactorLogin(ctx, login(), nextCode, "", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected reused code to be rejected, got %v", err)
+	}
+
+	// 恢復碼不分大小寫，只能使用一次
+	recovery := strings.ToUpper(recoveryCodes[0])
+	if _, _, err := svc.CompleteTwoFactorLogin(ctx, login(), recovery, "", ""); err != nil {
+		t.Fatalf("expected recovery code to work, got %v", err)
+	}
+	if _, _, err := svc.CompleteTwoFactorLogin(ctx, login(), recovery, "", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected used recovery code to be rejected, got %v", err)
+	}
+
+	// 重新產生後舊恢復碼失效
+	fresh, err := svc.RegenerateRecoveryCodes(ctx, player.ID, recoveryCodes[1], "", "")
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes failed: %v", err)
+	}
+	if _, _, err := svc.CompleteTwoFactorLogin(ctx, login(), recoveryCodes[2], "", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected old recovery code to be rejected, got %v", err)
+	}
+
+	if err := svc.DisableTwoFactor(ctx, player.ID, fresh[0], "", ""); err != nil {
+		t.Fatalf("DisableTwoFactor failed: %v", err)
+	}
+	if _, _, err := svc.Authenticate(ctx, "alice", "password", "", ""); err != nil {
+		t.Fatalf("expected login without 2FA after disable, got %v", err)
+	}
+
+	actions := auditRepo.actions()
+	for _, want := range []string{AuditActionTwoFactorEnabled, AuditActionTwoFactorFailed, AuditActionRecoveryCodeUsed, AuditActionRecoveryCodesRegenerated, AuditActionTwoFactorDisabled} {
+		found := false
+		for _, action := range actions {
+			if action == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected audit action %q in %v", want, actions)
+		}
+	}
+}
+
+// TestRequireRole_RequiresRoleAndMFA 需具備指定角色且登入時通過兩步驗證；刷新後角色保留
+func TestRequireRole_RequiresRoleAndMFA(t *testing.T) {
+	ctx := context.Background()
+	jwtService := NewJWTService("test-secret")
+	sessions := NewSessionService(newMemorySessionRepo(), jwtService, zap.NewNop())
+
+	handler := JWTMiddleware(jwtService, sessions)(RequireRole(repository.RoleAdmin, repository.RoleFinance)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})))
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	start := func(roles []string, mfa bool) *TokenPair {
+		tokens, err := sessions.Start(ctx, SessionIdentity{PlayerID: uuid.New(), Username: "staff", Roles: roles, MFA: mfa}, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokens
+	}
+
+	for name, tc := range map[string]struct {
+		roles []string
+		mfa   bool
+		want  int
+	}{
+		"player":            {nil, true, http.StatusForbidden},
+		"other role":        {[]string{repository.RoleSupport}, true, http.StatusForbidden},
+		"admin without 2fa": {[]string{repository.RoleAdmin}, false, http.StatusForbidden},
+		"admin":             {[]string{repository.RoleAdmin}, true, http.StatusNoContent},
+		"finance":           {[]string{repository.RoleSupport, repository.RoleFinance}, true, http.StatusNoContent},
+	} {
+		if code := call(start(tc.roles, tc.mfa).AccessToken); code != tc.want {
+			t.Errorf("%s: expected %d, got %d", name, tc.want, code)
+		}
+	}
+
+	refreshed, err := sessions.Refresh(ctx, start([]string{repository.RoleAdmin}, true).RefreshToken, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := call(refreshed.AccessToken); code != http.StatusNoContent {
+		t.Fatalf("expected roles and MFA to survive refresh, got %d", code)
+	}
+}
@@ -0,0 +1,263 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// AccountResponse 账号资料（不含密码等敏感栏位）
+type AccountResponse struct {
+	ID            string     `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	Status        string     `json:"status"`
+	Roles         []string   `json:"roles"`
+	EmailVerified bool       `json:"email_verified"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	LastLoginIP   *string    `json:"last_login_ip,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ActiveSessionResponse 进行中的游戏会话
+type ActiveSessionResponse struct {
+	SessionID    string    `json:"session_id"`
+	TableID      string    `json:"table_id"`
+	Currency     string    `json:"currency"`
+	BuyInAmount  int64     `json:"buy_in_amount"`
+	CurrentChips int64     `json:"current_chips"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// PlayerProfileResponse 玩家查询回应
+type PlayerProfileResponse struct {
+	Account          AccountResponse         `json:"account"`
+	PlayerID         string                  `json:"player_id"`
+	DisplayName      string                  `json:"display_name"`
+	TotalHandsPlayed int                     `json:"total_hands_played"`
+	TotalWinnings    int64                   `json:"total_winnings"`
+	Wallets          []WalletBalanceResponse `json:"wallets"`
+	ActiveSession    *ActiveSessionResponse  `json:"active_session,omitempty"`
+}
+
+// AccountStatusRequest 变更账号状态请求
+type AccountStatusRequest struct {
+	Status string `json:"status"` // active, suspended, banned
+	Reason string `json:"reason"`
+}
+
+// AccountRolesRequest 变更账号角色请求（整组替换）
+type AccountRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// AccountAdminHandler 玩家查询与账号管理的 HTTP Handler
+// 路由需经过 auth.JWTMiddleware 与 auth.RequireRole
+type AccountAdminHandler struct {
+	accountService *service.AccountAdminService
+	logger         *zap.Logger
+}
+
+// NewAccountAdminHandler 创建账号管理 Handler
+func NewAccountAdminHandler(accountService *service.AccountAdminService, logger *zap.Logger) *AccountAdminHandler {
+	return &AccountAdminHandler{
+		accountService: accountService,
+		logger:         logger,
+	}
+}
+
+// HandleSearch 以用户名查询玩家
+// GET /api/admin/players?username=alice
+func (h *AccountAdminHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Username is required")
+		return
+	}
+
+	profile, err := h.accountService.GetPlayerByUsername(r.Context(), username)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writePlayerProfile(w, profile)
+}
+
+// HandleGetPlayer 以玩家 ID 查询玩家
+// GET /api/admin/players/{player_id}
+func (h *AccountAdminHandler) HandleGetPlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, err := uuid.Parse(r.PathValue("player_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid player id")
+		return
+	}
+
+	profile, err := h.accountService.GetPlayerByID(r.Context(), playerID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	writePlayerProfile(w, profile)
+}
+
+// HandleSetStatus 停权、封禁或恢复账号（停权与封禁会撤销玩家所有登入会话）
+// POST /api/admin/accounts/{account_id}/status
+func (h *AccountAdminHandler) HandleSetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	accountID, err := uuid.Parse(r.PathValue("account_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid account id")
+		return
+	}
+
+	var req AccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Reason == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Reason is required")
+		return
+	}
+
+	account, err := h.accountService.SetAccountStatus(r.Context(), accountID, req.Status, req.Reason, actor)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+// HandleSetRoles 替换账号角色（变更后玩家需重新登入）
+// PUT /api/admin/accounts/{account_id}/roles
+func (h *AccountAdminHandler) HandleSetRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+	accountID, err := uuid.Parse(r.PathValue("account_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid account id")
+		return
+	}
+
+	var req AccountRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	account, err := h.accountService.SetAccountRoles(r.Context(), accountID, req.Roles, actor)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+// writeError 账号管理的错误响应
+func (h *AccountAdminHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound), errors.Is(err, service.ErrPlayerNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Player not found")
+	case errors.Is(err, service.ErrInvalidAccountStatus):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Status must be active, suspended or banned")
+	case errors.Is(err, service.ErrInvalidRole):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, service.ErrSelfAccountChange):
+		writeErrorResponse(w, http.StatusForbidden, "self_change_forbidden", "You cannot change your own account status or roles")
+	case errors.Is(err, service.ErrInsufficientPrivilege):
+		writeErrorResponse(w, http.StatusForbidden, "insufficient_privilege", "Only admins can change the status of staff accounts")
+	default:
+		h.logger.Error("account admin operation failed", zap.Error(err))
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// writePlayerProfile 输出玩家资料
+func writePlayerProfile(w http.ResponseWriter, profile *service.PlayerProfile) {
+	resp := PlayerProfileResponse{
+		Account:          toAccountResponse(profile.Account),
+		PlayerID:         profile.Player.ID.String(),
+		DisplayName:      profile.Player.DisplayName,
+		TotalHandsPlayed: profile.Player.TotalHandsPlayed,
+		TotalWinnings:    profile.Player.TotalWinnings,
+		Wallets:          make([]WalletBalanceResponse, 0, len(profile.Wallets)),
+	}
+	for _, wallet := range profile.Wallets {
+		resp.Wallets = append(resp.Wallets, WalletBalanceResponse{
+			Currency:      wallet.Currency,
+			Balance:       wallet.Balance,
+			LockedBalance: wallet.LockedBalance,
+			TotalBalance:  wallet.TotalBalance(),
+			UpdatedAt:     wallet.UpdatedAt,
+		})
+	}
+	if session := profile.ActiveSession; session != nil {
+		resp.ActiveSession = &ActiveSessionResponse{
+			SessionID:    session.ID.String(),
+			TableID:      session.TableID,
+			Currency:     session.Currency,
+			BuyInAmount:  session.BuyInAmount,
+			CurrentChips: session.CurrentChips,
+			StartedAt:    session.StartedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// toAccountResponse 转换账号资料
+func toAccountResponse(account *repository.Account) AccountResponse {
+	roles := account.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return AccountResponse{
+		ID:            account.ID.String(),
+		Username:      account.Username,
+		Email:         account.Email,
+		Status:        account.Status,
+		Roles:         roles,
+		EmailVerified: account.EmailVerified,
+		LockedUntil:   account.LockedUntil,
+		LastLoginAt:   account.LastLoginAt,
+		LastLoginIP:   account.LastLoginIP,
+		CreatedAt:     account.CreatedAt,
+	}
+}
@@ -0,0 +1,264 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/auth"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// CreateAdjustmentRequest 管理员钱包调整请求（金额单位为分）
+type CreateAdjustmentRequest struct {
+	PlayerID   string `json:"player_id"`
+	Currency   string `json:"currency"` // 空值使用默认币别
+	Type       string `json:"type"`     // deposit, withdraw, bonus, refund
+	Amount     int64  `json:"amount"`
+	ReasonCode string `json:"reason_code"`
+	Note       string `json:"note"`
+}
+
+// ReviewAdjustmentRequest 核准/驳回请求
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note"`
+}
+
+// AdjustmentResponse 钱包调整回应
+type AdjustmentResponse struct {
+	ID             string     `json:"id"`
+	PlayerID       string     `json:"player_id"`
+	Currency       string     `json:"currency"`
+	Type           string     `json:"type"`
+	Amount         int64      `json:"amount"`
+	ReasonCode     string     `json:"reason_code"`
+	Note           string     `json:"note"`
+	IdempotencyKey string     `json:"idempotency_key"`
+	Status         string     `json:"status"`
+	RequestedBy    string     `json:"requested_by"`
+	ReviewedBy     *string    `json:"reviewed_by,omitempty"`
+	ReviewNote     string     `json:"review_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// AdjustmentHandler 管理员钱包调整的 HTTP Handler
+// 路由需经过 auth.JWTMiddleware 与 auth.RequireRole
+type AdjustmentHandler struct {
+	adjustmentService *service.AdjustmentService
+	logger            *zap.Logger
+}
+
+// NewAdjustmentHandler 创建钱包调整 Handler
+func NewAdjustmentHandler(adjustmentService *service.AdjustmentService, logger *zap.Logger) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		adjustmentService: adjustmentService,
+		logger:            logger,
+	}
+}
+
+// HandleCreate 提交钱包调整（冪等性键由 Idempotency-Key 标头提供）
+// POST /api/admin/wallet-adjustments
+// 不超过核准门槛时立即执行（200），否则等待另一位管理员核准（202）
+func (h *AdjustmentHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	playerID, err := uuid.Parse(req.PlayerID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid player id")
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Request(r.Context(), service.AdjustmentRequest{
+		PlayerID:       playerID,
+		Currency:       req.Currency,
+		Type:           repository.TransactionType(req.Type),
+		Amount:         req.Amount,
+		ReasonCode:     req.ReasonCode,
+		Note:           req.Note,
+		IdempotencyKey: strings.TrimSpace(r.Header.Get("Idempotency-Key")),
+	}, actor)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if adjustment.Status == repository.AdjustmentStatusPending {
+		status = http.StatusAccepted
+	}
+	writeAdjustment(w, status, adjustment)
+}
+
+// HandleList 按状态分页查询调整申请
+// GET /api/admin/wallet-adjustments?status=pending&limit=50&offset=0
+func (h *AdjustmentHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	adjustments, err := h.adjustmentService.List(r.Context(), r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]AdjustmentResponse, 0, len(adjustments))
+	for _, a := range adjustments {
+		resp = append(resp, toAdjustmentResponse(a))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleApprove 核准并执行待审核的调整
+// POST /api/admin/wallet-adjustments/{id}/approve
+func (h *AdjustmentHandler) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	h.handleReview(w, r, h.adjustmentService.Approve)
+}
+
+// HandleReject 驳回待审核的调整
+// POST /api/admin/wallet-adjustments/{id}/reject
+func (h *AdjustmentHandler) HandleReject(w http.ResponseWriter, r *http.Request) {
+	h.handleReview(w, r, h.adjustmentService.Reject)
+}
+
+// handleReview 解析审核请求并呼叫核准或驳回
+func (h *AdjustmentHandler) handleReview(
+	w http.ResponseWriter,
+	r *http.Request,
+	review func(ctx context.Context, id uuid.UUID, note string, actor service.AdminActor) (*repository.WalletAdjustment, error),
+) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid adjustment id")
+		return
+	}
+
+	var req ReviewAdjustmentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+			return
+		}
+	}
+
+	adjustment, err := review(r.Context(), id, req.Note, actor)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeAdjustment(w, http.StatusOK, adjustment)
+}
+
+// writeServiceError 将服务层错误映射为 HTTP 状态码
+func (h *AdjustmentHandler) writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrIdempotencyKeyRequired):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Idempotency-Key header is required")
+	case errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInvalidAdjustmentType),
+		errors.Is(err, service.ErrInvalidReasonCode),
+		errors.Is(err, service.ErrPlayMoneyAdjustment):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, service.ErrInvalidPagination):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid limit or offset")
+	case errors.Is(err, service.ErrWalletNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Wallet not found")
+	case errors.Is(err, service.ErrAdjustmentNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Adjustment not found")
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		writeErrorResponse(w, http.StatusConflict, "idempotency_conflict", err.Error())
+	case errors.Is(err, service.ErrAdjustmentNotPending):
+		writeErrorResponse(w, http.StatusConflict, "not_pending", "Adjustment has already been reviewed")
+	case errors.Is(err, service.ErrInsufficientBalance):
+		writeErrorResponse(w, http.StatusConflict, "insufficient_balance", "Insufficient balance")
+	case errors.Is(err, service.ErrSelfApproval):
+		writeErrorResponse(w, http.StatusForbidden, "self_approval", err.Error())
+	default:
+		h.logger.Error("wallet adjustment failed", zap.Error(err))
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// requestActor 取得执行操作的管理员与请求来源
+func requestActor(w http.ResponseWriter, r *http.Request) (service.AdminActor, bool) {
+	playerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return service.AdminActor{}, false
+	}
+	return service.AdminActor{
+		PlayerID:  playerID,
+		Roles:     auth.GetRolesFromContext(r.Context()),
+		IPAddress: auth.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}, true
+}
+
+// writeAdjustment 输出单笔调整
+func writeAdjustment(w http.ResponseWriter, status int, adjustment *repository.WalletAdjustment) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(toAdjustmentResponse(adjustment))
+}
+
+// toAdjustmentResponse 转换为回应格式
+func toAdjustmentResponse(a *repository.WalletAdjustment) AdjustmentResponse {
+	resp := AdjustmentResponse{
+		ID:             a.ID.String(),
+		PlayerID:       a.PlayerID.String(),
+		Currency:       a.Currency,
+		Type:           string(a.Type),
+		Amount:         a.Amount,
+		ReasonCode:     a.ReasonCode,
+		Note:           a.Note,
+		IdempotencyKey: a.IdempotencyKey,
+		Status:         a.Status,
+		RequestedBy:    a.RequestedBy.String(),
+		ReviewNote:     a.ReviewNote,
+		CreatedAt:      a.CreatedAt,
+		ReviewedAt:     a.ReviewedAt,
+	}
+	if a.ReviewedBy != nil {
+		reviewedBy := a.ReviewedBy.String()
+		resp.ReviewedBy = &reviewedBy
+	}
+	return resp
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// AuditLogResponse 单笔审计日志
+type AuditLogResponse struct {
+	ID         int64           `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Action     string          `json:"action"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	ActorID    *string         `json:"actor_id,omitempty"`
+	ActorType  string          `json:"actor_type"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditLogListResponse 审计日志分页回应
+type AuditLogListResponse struct {
+	Logs   []AuditLogResponse `json:"logs"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// AuditHandler 审计日志查询的 HTTP Handler（供客服与风控使用）
+// 路由需经过 auth.JWTMiddleware 与 auth.RequireRole
+type AuditHandler struct {
+	auditService *service.AuditService
+	logger       *zap.Logger
+}
+
+// NewAuditHandler 创建审计日志 Handler
+func NewAuditHandler(auditService *service.AuditService, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// HandleList 按实体、执行者、动作与期间分页查询审计日志
+// GET /api/admin/audit-logs?entity_type=account&entity_id=...&actor_id=...&action=login&from=2026-01-01&to=2026-02-01&limit=50&offset=0
+func (h *AuditHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := repository.AuditLogFilter{
+		EntityType: query.Get("entity_type"),
+		Action:     query.Get("action"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	for _, p := range []struct {
+		name   string
+		target **uuid.UUID
+	}{{"entity_id", &filter.EntityID}, {"actor_id", &filter.ActorID}} {
+		s := query.Get(p.name)
+		if s == "" {
+			continue
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "'"+p.name+"' must be a UUID")
+			return
+		}
+		*p.target = &id
+	}
+	for _, p := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := query.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(periodDateLayout, s)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "'"+p.name+"' must be YYYY-MM-DD")
+			return
+		}
+		*p.target = &t
+	}
+
+	page, err := h.auditService.List(r.Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWalletPeriod):
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_period", "'from' must be before 'to'")
+		case errors.Is(err, service.ErrInvalidPagination):
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid limit or offset")
+		default:
+			h.logger.Error("audit log query failed", zap.Error(err))
+			writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		}
+		return
+	}
+
+	resp := AuditLogListResponse{
+		Logs:   make([]AuditLogResponse, 0, len(page.Logs)),
+		Total:  page.Total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+	for _, log := range page.Logs {
+		item := AuditLogResponse{
+			ID:         log.ID,
+			EntityType: log.EntityType,
+			EntityID:   log.EntityID.String(),
+			Action:     log.Action,
+			IPAddress:  log.IPAddress,
+			UserAgent:  log.UserAgent,
+			ActorType:  log.ActorType,
+			CreatedAt:  log.CreatedAt,
+		}
+		if len(log.Changes) > 0 {
+			item.Changes = json.RawMessage(log.Changes)
+		}
+		if log.ActorID != nil {
+			actorID := log.ActorID.String()
+			item.ActorID = &actorID
+		}
+		resp.Logs = append(resp.Logs, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,171 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/auth"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSessionHandLimit = 500
+	maxSessionHandLimit     = 5000
+)
+
+// ErrorResponse 错误回应
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// HandHistoryHandler 手牌历史下载的 HTTP Handler
+// 路由需经过 auth.JWTMiddleware，查看者即为 token 中的玩家
+type HandHistoryHandler struct {
+	historyService *service.HandHistoryService
+	logger         *zap.Logger
+}
+
+// NewHandHistoryHandler 创建手牌历史 Handler
+func NewHandHistoryHandler(historyService *service.HandHistoryService, logger *zap.Logger) *HandHistoryHandler {
+	return &HandHistoryHandler{
+		historyService: historyService,
+		logger:         logger,
+	}
+}
+
+// HandleExportHand 下载单手牌历史
+// GET /api/hands/{hand_id}?format=pokerstars|json
+func (h *HandHistoryHandler) HandleExportHand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	viewerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	handID, err := uuid.Parse(r.PathValue("hand_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid hand id")
+		return
+	}
+
+	format, err := service.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_format", "Format must be 'pokerstars' or 'json'")
+		return
+	}
+
+	data, err := h.historyService.ExportHand(r.Context(), handID, viewerID, format)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeExport(w, format, "hand_"+handID.String(), data)
+}
+
+// HandleExportSession 下载游戏会话内的所有手牌历史
+// GET /api/sessions/{session_id}/hands?format=pokerstars|json&limit=500
+func (h *HandHistoryHandler) HandleExportSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	viewerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.PathValue("session_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid session id")
+		return
+	}
+
+	format, err := service.ParseExportFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_format", "Format must be 'pokerstars' or 'json'")
+		return
+	}
+
+	limit := defaultSessionHandLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxSessionHandLimit {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request",
+				fmt.Sprintf("Limit must be between 1 and %d", maxSessionHandLimit))
+			return
+		}
+	}
+
+	data, err := h.historyService.ExportSession(r.Context(), sessionID, viewerID, format, limit)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeExport(w, format, "session_"+sessionID.String(), data)
+}
+
+// authenticatedPlayerID 从 context 取得已验证的玩家 ID
+func authenticatedPlayerID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	playerID, ok := auth.GetPlayerIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized: player_id not found in context", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(playerID)
+	if err != nil {
+		http.Error(w, "unauthorized: invalid player_id", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeExport 以附件形式输出导出内容
+func (h *HandHistoryHandler) writeExport(w http.ResponseWriter, format service.ExportFormat, name string, data []byte) {
+	switch format {
+	case service.ExportFormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.json"`)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.txt"`)
+	}
+	w.Write(data)
+}
+
+// writeServiceError 将服务层错误映射为 HTTP 状态码
+func (h *HandHistoryHandler) writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrHandHistoryNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Hand history not found")
+	case errors.Is(err, service.ErrSessionNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Session not found")
+	case errors.Is(err, service.ErrHandHistoryForbidden):
+		writeErrorResponse(w, http.StatusForbidden, "forbidden", "You did not take part in this hand")
+	default:
+		h.logger.Error("hand history export failed", zap.Error(err))
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// writeErrorResponse 写入错误响应
+func writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   errorCode,
+		Message: message,
+	})
+}
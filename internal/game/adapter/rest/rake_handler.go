@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"go.uber.org/zap"
+)
+
+const periodDateLayout = "2006-01-02"
+
+// RakebackResponse 返水查询回应（金额单位为分）
+type RakebackResponse struct {
+	PlayerID string  `json:"player_id"`
+	Currency string  `json:"currency"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Hands    int     `json:"hands"`
+	RakePaid int64   `json:"rake_paid"`
+	Percent  float64 `json:"percent"`
+	Amount   int64   `json:"amount"`
+}
+
+// RevenueResponse 平台抽水收入回应（金额单位为分）
+type RevenueResponse struct {
+	Currency string `json:"currency"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   int64  `json:"amount"`
+}
+
+// RakeHandler 抽水与返水查询的 HTTP Handler
+type RakeHandler struct {
+	rakeService *service.RakeService
+	logger      *zap.Logger
+}
+
+// NewRakeHandler 创建抽水 Handler
+func NewRakeHandler(rakeService *service.RakeService, logger *zap.Logger) *RakeHandler {
+	return &RakeHandler{
+		rakeService: rakeService,
+		logger:      logger,
+	}
+}
+
+// HandleGetRakeback 查询当前玩家在期间内的返水
+// GET /api/rakeback?currency=USD&from=2026-01-01&to=2026-02-01（默认本月、默认币别）
+func (h *RakeHandler) HandleGetRakeback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	rakeback, err := h.rakeService.CalculateRakeback(r.Context(), playerID, r.URL.Query().Get("currency"), from, to)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RakebackResponse{
+		PlayerID: rakeback.PlayerID.String(),
+		Currency: rakeback.Currency,
+		From:     rakeback.From.Format(periodDateLayout),
+		To:       rakeback.To.Format(periodDateLayout),
+		Hands:    rakeback.Hands,
+		RakePaid: rakeback.RakePaid,
+		Percent:  rakeback.Percent,
+		Amount:   rakeback.Amount,
+	})
+}
+
+// HandleGetRevenue 查询期间内的平台抽水收入（管理员）
+// GET /api/admin/revenue?currency=USD&from=2026-01-01&to=2026-02-01（默认本月、默认币别）
+func (h *RakeHandler) HandleGetRevenue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, ok := parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		currency = h.rakeService.DefaultCurrency()
+	}
+
+	amount, err := h.rakeService.GetRevenue(r.Context(), currency, from, to)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevenueResponse{
+		Currency: currency,
+		From:     from.Format(periodDateLayout),
+		To:       to.Format(periodDateLayout),
+		Amount:   amount,
+	})
+}
+
+// writeServiceError 将服务层错误映射为 HTTP 状态码
+func (h *RakeHandler) writeServiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidRakebackPeriod) {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_period", "'from' must be before 'to'")
+		return
+	}
+	h.logger.Error("rake query failed", zap.Error(err))
+	writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+}
+
+// parsePeriod 解析 from/to 查询参数（UTC 日期，to 不含），缺省为本月
+func parsePeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query := r.URL.Query()
+	if s := query.Get("from"); s != "" {
+		t, err := time.Parse(periodDateLayout, s)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "'from' must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	if s := query.Get("to"); s != "" {
+		t, err := time.Parse(periodDateLayout, s)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "'to' must be YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+
+	return from, to, true
+}
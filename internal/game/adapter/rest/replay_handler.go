@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"go.uber.org/zap"
+)
+
+// ReplayResponse 手牌重播回应
+type ReplayResponse struct {
+	HandID     string               `json:"hand_id"`
+	Verified   bool                 `json:"verified"`
+	Mismatches []string             `json:"mismatches"`
+	ChipDeltas map[string]int64     `json:"chip_deltas"`
+	Steps      []ReplayStepResponse `json:"steps"`
+}
+
+// ReplayStepResponse 重播中的一个步骤，前端可逐步播放
+type ReplayStepResponse struct {
+	Index  int                   `json:"index"`
+	Action *ReplayActionResponse `json:"action,omitempty"` // 第 0 步（开局）为空
+	Events []ReplayEventResponse `json:"events"`
+}
+
+// ReplayActionResponse 该步骤执行的玩家动作
+type ReplayActionResponse struct {
+	PlayerID string `json:"player_id"`
+	Street   string `json:"street"`
+	Action   string `json:"action"`
+	Amount   int64  `json:"amount"`
+	TotalBet int64  `json:"total_bet"`
+	Reason   string `json:"reason,omitempty"` // STAND_UP / LEAVE_TABLE（非輪次內的棄牌）
+}
+
+// ReplayEventResponse 重播产生的桌面事件
+type ReplayEventResponse struct {
+	Type           string                 `json:"type"`
+	TargetPlayerID string                 `json:"target_player_id,omitempty"`
+	Data           map[string]interface{} `json:"data"`
+}
+
+// HandleReplayHand 重播手牌并回传逐事件序列（管理员）
+// GET /api/admin/hands/{hand_id}/replay
+func (h *HandHistoryHandler) HandleReplayHand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	handID, err := uuid.Parse(r.PathValue("hand_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid hand id")
+		return
+	}
+
+	result, err := h.historyService.ReplayHand(r.Context(), handID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrReplayDeckMissing):
+			writeErrorResponse(w, http.StatusUnprocessableEntity, "not_replayable", "Hand was recorded without deck order")
+		case errors.Is(err, service.ErrHandHistoryNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Hand history not found")
+		default:
+			h.logger.Error("hand replay failed", zap.String("hand_id", handID.String()), zap.Error(err))
+			writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toReplayResponse(result))
+}
+
+// toReplayResponse 将 domain.ReplayResult 转换为 JSON 回应
+func toReplayResponse(result *domain.ReplayResult) ReplayResponse {
+	resp := ReplayResponse{
+		HandID:     result.HandID,
+		Verified:   result.Verified(),
+		Mismatches: result.Mismatches,
+		ChipDeltas: result.ChipDeltas,
+		Steps:      make([]ReplayStepResponse, 0, len(result.Steps)),
+	}
+	if resp.Mismatches == nil {
+		resp.Mismatches = []string{}
+	}
+
+	for _, step := range result.Steps {
+		stepResp := ReplayStepResponse{
+			Index:  step.Index,
+			Events: make([]ReplayEventResponse, 0, len(step.Events)),
+		}
+		if step.Action != nil {
+			stepResp.Action = &ReplayActionResponse{
+				PlayerID: step.Action.PlayerID,
+				Street:   step.Action.Street,
+				Action:   step.Action.Action,
+				Amount:   step.Action.Amount,
+				TotalBet: step.Action.TotalBet,
+				Reason:   step.Action.Reason,
+			}
+		}
+		for _, event := range step.Events {
+			stepResp.Events = append(stepResp.Events, ReplayEventResponse{
+				Type:           string(event.Type),
+				TargetPlayerID: event.TargetPlayerID,
+				Data:           event.Data,
+			})
+		}
+		resp.Steps = append(resp.Steps, stepResp)
+	}
+
+	return resp
+}
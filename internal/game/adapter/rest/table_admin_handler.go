@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"go.uber.org/zap"
+)
+
+// tableController 牌桌查询与管理（由 game.TableManager 实现）
+type tableController interface {
+	TableIDs() []string
+	TableSnapshot(id string) (*domain.TableSnapshot, error)
+	TableCurrency(tableID string) string
+	KickPlayer(tableID, playerID string) (*game.KickResult, error)
+}
+
+// TableSeatResponse 入座玩家
+type TableSeatResponse struct {
+	PlayerID string `json:"player_id"`
+	SeatNo   int    `json:"seat_no"`
+	Chips    int64  `json:"chips"`
+}
+
+// TableResponse 牌桌状态
+type TableResponse struct {
+	TableID        string              `json:"table_id"`
+	Currency       string              `json:"currency"`
+	HandNumber     int                 `json:"hand_number"`
+	HandInProgress bool                `json:"hand_in_progress"`
+	HandID         string              `json:"hand_id,omitempty"`
+	Players        []TableSeatResponse `json:"players"`
+	Error          string              `json:"error,omitempty"` // 牌桌未回应时的错误
+}
+
+// TableListResponse 牌桌列表
+type TableListResponse struct {
+	Tables []TableResponse `json:"tables"`
+}
+
+// KickPlayerRequest 踢出玩家请求
+type KickPlayerRequest struct {
+	PlayerID string `json:"player_id"`
+	Reason   string `json:"reason"`
+}
+
+// KickPlayerResponse 踢出玩家回应
+type KickPlayerResponse struct {
+	TableID       string `json:"table_id"`
+	PlayerID      string `json:"player_id"`
+	SeatNo        int    `json:"seat_no"`
+	Chips         int64  `json:"chips"`
+	CashedOut     bool   `json:"cashed_out"`
+	CashOutAmount int64  `json:"cash_out_amount"`
+	Currency      string `json:"currency,omitempty"`
+	CashOutError  string `json:"cash_out_error,omitempty"` // 兑现失败时由会话对帐补兑现
+}
+
+// TableAdminHandler 牌桌管理的 HTTP Handler
+// 路由需经过 auth.JWTMiddleware 与 auth.RequireRole
+type TableAdminHandler struct {
+	tables       tableController
+	auditService *service.AuditService
+	logger       *zap.Logger
+}
+
+// NewTableAdminHandler 创建牌桌管理 Handler
+func NewTableAdminHandler(tables tableController, auditService *service.AuditService, logger *zap.Logger) *TableAdminHandler {
+	return &TableAdminHandler{
+		tables:       tables,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// HandleList 列出所有牌桌与入座玩家
+// GET /api/admin/tables
+func (h *TableAdminHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := TableListResponse{Tables: make([]TableResponse, 0)}
+	for _, tableID := range h.tables.TableIDs() {
+		snapshot, err := h.tables.TableSnapshot(tableID)
+		if err != nil {
+			// 单张牌桌无回应不影响列表
+			resp.Tables = append(resp.Tables, TableResponse{
+				TableID:  tableID,
+				Currency: h.tables.TableCurrency(tableID),
+				Players:  []TableSeatResponse{},
+				Error:    err.Error(),
+			})
+			continue
+		}
+		resp.Tables = append(resp.Tables, h.toTableResponse(snapshot))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleGet 查询单张牌桌
+// GET /api/admin/tables/{table_id}
+func (h *TableAdminHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tableID := r.PathValue("table_id")
+	snapshot, err := h.tables.TableSnapshot(tableID)
+	if err != nil {
+		if errors.Is(err, game.ErrTableNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Table not found")
+			return
+		}
+		h.logger.Warn("table snapshot failed", zap.String("table_id", tableID), zap.Error(err))
+		writeErrorResponse(w, http.StatusServiceUnavailable, "table_unavailable", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.toTableResponse(snapshot))
+}
+
+// toTableResponse 转换牌桌快照
+func (h *TableAdminHandler) toTableResponse(snapshot *domain.TableSnapshot) TableResponse {
+	players := make([]TableSeatResponse, 0, len(snapshot.Players))
+	for _, p := range snapshot.Players {
+		players = append(players, TableSeatResponse{PlayerID: p.PlayerID, SeatNo: p.SeatIdx, Chips: p.Chips})
+	}
+	return TableResponse{
+		TableID:        snapshot.TableID,
+		Currency:       h.tables.TableCurrency(snapshot.TableID),
+		HandNumber:     snapshot.HandNumber,
+		HandInProgress: snapshot.HandInProgress,
+		HandID:         snapshot.HandID,
+		Players:        players,
+	}
+}
+
+// HandleKick 将玩家移出牌桌并写入审计日志（游戏会话随后由会话对帐兑现）
+// POST /api/admin/tables/{table_id}/kick
+func (h *TableAdminHandler) HandleKick(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := requestActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req KickPlayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	playerID, err := uuid.Parse(req.PlayerID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid player id")
+		return
+	}
+	if req.Reason == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Reason is required")
+		return
+	}
+
+	tableID := r.PathValue("table_id")
+	kicked, err := h.tables.KickPlayer(tableID, playerID.String())
+	if err != nil && !errors.Is(err, game.ErrKickCashOutFailed) {
+		switch {
+		case errors.Is(err, game.ErrTableNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Table not found")
+		case errors.Is(err, domain.ErrPlayerNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "not_found", "Player is not seated at this table")
+		default:
+			h.logger.Warn("kick player failed",
+				zap.String("table_id", tableID),
+				zap.String("player_id", playerID.String()),
+				zap.Error(err),
+			)
+			writeErrorResponse(w, http.StatusConflict, "kick_failed", err.Error())
+		}
+		return
+	}
+
+	// 玩家已离座，兑现失败与审计写入失败都只记录日志
+	seat := kicked.Seat
+	resp := KickPlayerResponse{
+		TableID:  tableID,
+		PlayerID: playerID.String(),
+		SeatNo:   seat.SeatIdx,
+		Chips:    seat.Chips,
+	}
+	after := map[string]interface{}{"table_id": nil, "reason": req.Reason}
+	if err != nil {
+		resp.CashOutError = err.Error()
+		after["cash_out_error"] = err.Error()
+	} else if kicked.CashOut != nil {
+		resp.CashedOut = true
+		resp.CashOutAmount = kicked.CashOut.CashOutAmount
+		resp.Currency = kicked.CashOut.Currency
+		after["session_id"] = kicked.CashOut.SessionID.String()
+		after["cash_out_amount"] = kicked.CashOut.CashOutAmount
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = h.auditService.RecordAdminAction(ctx, actor, "player", playerID, service.AuditActionTableKick,
+		map[string]interface{}{"table_id": tableID, "seat_no": seat.SeatIdx, "chips": seat.Chips},
+		after,
+	)
+	if err != nil {
+		h.logger.Error("failed to write kick audit log",
+			zap.String("table_id", tableID),
+			zap.String("player_id", playerID.String()),
+			zap.Error(err),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,332 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// WalletBalanceResponse 单一币别的钱包余额（金额单位为分）
+type WalletBalanceResponse struct {
+	Currency      string    `json:"currency"`
+	Balance       int64     `json:"balance"`
+	LockedBalance int64     `json:"locked_balance"`
+	TotalBalance  int64     `json:"total_balance"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// WalletListResponse 玩家所有币别的钱包余额回应
+type WalletListResponse struct {
+	PlayerID string                  `json:"player_id"`
+	Wallets  []WalletBalanceResponse `json:"wallets"`
+}
+
+// TransactionResponse 单笔交易记录
+type TransactionResponse struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	Amount        int64     `json:"amount"`
+	BalanceBefore int64     `json:"balance_before"`
+	BalanceAfter  int64     `json:"balance_after"`
+	Description   string    `json:"description"`
+	GameSessionID *string   `json:"game_session_id,omitempty"`
+	ReferenceID   *string   `json:"reference_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TransactionListResponse 交易记录分页回应
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Total        int64                 `json:"total"`
+	Limit        int                   `json:"limit"`
+	Offset       int                   `json:"offset"`
+}
+
+// StatementSessionResponse 对账单中的单个会话
+type StatementSessionResponse struct {
+	SessionID string     `json:"session_id"`
+	TableID   string     `json:"table_id"`
+	Currency  string     `json:"currency"`
+	GameType  string     `json:"game_type"`
+	Status    string     `json:"status"`
+	BuyIn     int64      `json:"buy_in"`
+	CashOut   *int64     `json:"cash_out"`
+	Chips     int64      `json:"chips"`
+	Profit    int64      `json:"profit"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// StatementSummaryResponse 对账单中单一币别的汇总
+type StatementSummaryResponse struct {
+	Currency     string `json:"currency"`
+	Sessions     int    `json:"sessions"`
+	TotalBuyIn   int64  `json:"total_buy_in"`
+	TotalCashOut int64  `json:"total_cash_out"`
+	NetProfit    int64  `json:"net_profit"`
+}
+
+// StatementResponse 会话盈亏对账单回应（不同币别分别汇总，不做换算）
+type StatementResponse struct {
+	PlayerID  string                     `json:"player_id"`
+	Currency  string                     `json:"currency,omitempty"`
+	From      string                     `json:"from"`
+	To        string                     `json:"to"`
+	Sessions  int                        `json:"sessions"`
+	Summaries []StatementSummaryResponse `json:"summaries"`
+	Items     []StatementSessionResponse `json:"items"`
+	Limit     int                        `json:"limit"`
+	Offset    int                        `json:"offset"`
+}
+
+// WalletHandler 钱包查询的 HTTP Handler
+// 路由需经过 auth.JWTMiddleware，只能查询 token 中玩家自己的钱包
+type WalletHandler struct {
+	walletService *service.WalletService
+	logger        *zap.Logger
+}
+
+// NewWalletHandler 创建钱包 Handler
+func NewWalletHandler(walletService *service.WalletService, logger *zap.Logger) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+		logger:        logger,
+	}
+}
+
+// HandleGetBalance 查询当前玩家所有币别的钱包余额
+// GET /api/wallet
+func (h *WalletHandler) HandleGetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.walletService.GetBalances(r.Context(), playerID)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := WalletListResponse{
+		PlayerID: playerID.String(),
+		Wallets:  make([]WalletBalanceResponse, 0, len(wallets)),
+	}
+	for _, wallet := range wallets {
+		resp.Wallets = append(resp.Wallets, WalletBalanceResponse{
+			Currency:      wallet.Currency,
+			Balance:       wallet.Balance,
+			LockedBalance: wallet.LockedBalance,
+			TotalBalance:  wallet.TotalBalance(),
+			UpdatedAt:     wallet.UpdatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleListTransactions 分页查询当前玩家的交易记录
+// GET /api/wallet/transactions?type=deposit,withdraw&from=2026-01-01&to=2026-02-01&limit=50&offset=0
+func (h *WalletHandler) HandleListTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	filter := repository.TransactionFilter{
+		PlayerID: playerID,
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	query := r.URL.Query()
+	for _, raw := range query["type"] {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, repository.TransactionType(t))
+			}
+		}
+	}
+	for _, p := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := query.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(periodDateLayout, s)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "'"+p.name+"' must be YYYY-MM-DD")
+			return
+		}
+		*p.target = &t
+	}
+
+	page, err := h.walletService.ListTransactions(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := TransactionListResponse{
+		Transactions: make([]TransactionResponse, 0, len(page.Transactions)),
+		Total:        page.Total,
+		Limit:        page.Limit,
+		Offset:       page.Offset,
+	}
+	for _, tx := range page.Transactions {
+		item := TransactionResponse{
+			ID:            tx.ID.String(),
+			Type:          string(tx.Type),
+			Amount:        tx.Amount,
+			BalanceBefore: tx.BalanceBefore,
+			BalanceAfter:  tx.BalanceAfter,
+			Description:   tx.Description,
+			ReferenceID:   tx.ReferenceID,
+			CreatedAt:     tx.CreatedAt,
+		}
+		if tx.GameSessionID != nil {
+			sessionID := tx.GameSessionID.String()
+			item.GameSessionID = &sessionID
+		}
+		resp.Transactions = append(resp.Transactions, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleGetStatement 查询当前玩家在期间内的会话盈亏对账单
+// GET /api/wallet/statement?currency=USD&from=2026-01-01&to=2026-02-01&limit=50&offset=0（默认本月、所有币别）
+func (h *WalletHandler) HandleGetStatement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, ok := authenticatedPlayerID(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	statement, err := h.walletService.GetStatement(r.Context(), playerID, r.URL.Query().Get("currency"), from, to, limit, offset)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := StatementResponse{
+		PlayerID:  statement.PlayerID.String(),
+		Currency:  statement.Currency,
+		From:      statement.From.Format(periodDateLayout),
+		To:        statement.To.Format(periodDateLayout),
+		Sessions:  statement.TotalSessions(),
+		Summaries: make([]StatementSummaryResponse, 0, len(statement.Summaries)),
+		Items:     make([]StatementSessionResponse, 0, len(statement.Lines)),
+		Limit:     statement.Limit,
+		Offset:    statement.Offset,
+	}
+	for _, summary := range statement.Summaries {
+		resp.Summaries = append(resp.Summaries, StatementSummaryResponse{
+			Currency:     summary.Currency,
+			Sessions:     summary.Sessions,
+			TotalBuyIn:   summary.TotalBuyIn,
+			TotalCashOut: summary.TotalCashOut,
+			NetProfit:    summary.NetProfit,
+		})
+	}
+	for _, line := range statement.Lines {
+		s := line.Session
+		resp.Items = append(resp.Items, StatementSessionResponse{
+			SessionID: s.ID.String(),
+			TableID:   s.TableID,
+			Currency:  s.Currency,
+			GameType:  s.GameType,
+			Status:    s.Status,
+			BuyIn:     s.BuyInAmount,
+			CashOut:   line.CashOut,
+			Chips:     s.CurrentChips,
+			Profit:    line.Profit,
+			StartedAt: s.StartedAt,
+			EndedAt:   s.EndedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// writeServiceError 将服务层错误映射为 HTTP 状态码
+func (h *WalletHandler) writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrWalletNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Wallet not found")
+	case errors.Is(err, service.ErrInvalidTransactionType):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, service.ErrInvalidWalletPeriod):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_period", "'from' must be before 'to'")
+	case errors.Is(err, service.ErrInvalidPagination):
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid limit or offset")
+	default:
+		h.logger.Error("wallet query failed", zap.Error(err))
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+	}
+}
+
+// parsePagination 解析 limit/offset 查询参数（缺省 limit 为 0，由服务层套用默认值）
+func parsePagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	var limit, offset int
+	query := r.URL.Query()
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > service.MaxWalletPageLimit {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request",
+				"Limit must be between 1 and "+strconv.Itoa(service.MaxWalletPageLimit))
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if s := query.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
@@ -1,12 +1,13 @@
 package ws
 
 import (
-	"encoding/json"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/shinjuwu/TheNuts/internal/game"
-	domain "github.com/shinjuwu/TheNuts/internal/game/domain"
 	"go.uber.org/zap"
 )
 
@@ -17,22 +18,35 @@ const (
 	maxMessageSize = 512
 )
 
+// closeFrame 放入 send 佇列後，WritePump 送完前面排隊的訊息再送出關閉訊框並結束連線
+type closeFrame struct {
+	code int
+	text string
+}
+
 type Client struct {
 	Hub          *Hub
 	TableManager *game.TableManager
 	Conn         *websocket.Conn
 	PlayerID     string
 	send         chan interface{}
+	sendMu       sync.Mutex // 保護 send 的寫入與關閉，避免寫入已關閉的 channel
+	sendClosed   bool
+	codec        Codec // 連線協商的訊息編碼
+	messages     *MessageHandler
+	closing      atomic.Bool // 已被取代或會話撤銷，不再處理新訊息
 	logger       *zap.Logger
 }
 
-func NewClient(hub *Hub, tableMgr *game.TableManager, conn *websocket.Conn, playerID string, logger *zap.Logger) *Client {
+func NewClient(hub *Hub, tableMgr *game.TableManager, conn *websocket.Conn, playerID string, codec Codec, messages *MessageHandler, logger *zap.Logger) *Client {
 	return &Client{
 		Hub:          hub,
 		TableManager: tableMgr,
 		Conn:         conn,
 		PlayerID:     playerID,
 		send:         make(chan interface{}, 256),
+		codec:        codec,
+		messages:     messages,
 		logger:       logger,
 	}
 }
@@ -43,6 +57,12 @@ func (c *Client) ReadPump() {
 		c.Conn.Close()
 	}()
 
+	playerID, err := uuid.Parse(c.PlayerID)
+	if err != nil {
+		c.logger.Error("invalid player ID format", zap.String("player_id", c.PlayerID), zap.Error(err))
+		return
+	}
+
 	c.Conn.SetReadLimit(maxMessageSize)
 	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
@@ -59,26 +79,12 @@ func (c *Client) ReadPump() {
 			break
 		}
 
-		var req Request
-		if err := json.Unmarshal(message, &req); err != nil {
-			c.logger.Warn("invalid message format", zap.Error(err))
+		if c.closing.Load() {
 			continue
 		}
 
-		// 路由到對應的桌子
-		if req.TableID != "" {
-			table := c.TableManager.GetTable(req.TableID)
-			if table != nil {
-				// DTO -> Domain Entity 轉換 (Translator)
-				actionType := MapActionType(req.Action)
-				playerAction := domain.PlayerAction{
-					PlayerID: c.PlayerID,
-					Type:     actionType,
-					Amount:   req.Amount,
-				}
-				table.ActionCh <- playerAction
-			}
-		}
+		// 交由 MessageHandler 檢查頻率並依動作類型路由
+		c.messages.HandleMessage(playerID, c.codec, message)
 	}
 }
 
@@ -98,7 +104,17 @@ func (c *Client) WritePump() {
 				return
 			}
 
-			if err := c.Conn.WriteJSON(message); err != nil {
+			if frame, ok := message.(closeFrame); ok {
+				c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(frame.code, frame.text))
+				return
+			}
+
+			data, err := c.codec.Marshal(message)
+			if err != nil {
+				c.logger.Error("failed to encode message", zap.String("player_id", c.PlayerID), zap.Error(err))
+				continue
+			}
+			if err := c.Conn.WriteMessage(c.codec.MessageType(), data); err != nil {
 				return
 			}
 
@@ -110,3 +126,48 @@ func (c *Client) WritePump() {
 		}
 	}
 }
+
+// trySend 非阻塞地放入 send 佇列；佇列已關閉或已滿時回傳 false
+func (c *Client) trySend(message interface{}) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.sendClosed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend 關閉 send 佇列，WritePump 隨之結束（由 Hub 在連線註銷時呼叫，可重複呼叫）
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.sendClosed {
+		c.sendClosed = true
+		close(c.send)
+	}
+}
+
+// closeWith 送出最後一則通知後關閉連線（send 佇列已滿時直接關閉）
+// 與 closeSend 持有同一把鎖，Hub 已關閉 send 時不再寫入
+func (c *Client) closeWith(message interface{}, code int, text string) {
+	c.closing.Store(true)
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.sendClosed {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+	}
+	select {
+	case c.send <- closeFrame{code: code, text: text}:
+	default:
+		c.Conn.Close()
+	}
+}
@@ -0,0 +1,75 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// WebSocket 子協定：客戶端在 Sec-WebSocket-Protocol 提出，伺服器選定後整條連線使用該編碼
+// 未提出子協定的客戶端使用 JSON（預設）
+const (
+	SubprotocolJSON    = "thenuts.v1.json"
+	SubprotocolMsgpack = "thenuts.v1.msgpack"
+)
+
+// Codec 訊息編碼（JSON 文字訊框或 MessagePack 二進位訊框）
+// 兩種編碼共用 dto.go 的 json 標籤作為欄位名稱
+type Codec interface {
+	Subprotocol() string
+	MessageType() int // websocket.TextMessage 或 websocket.BinaryMessage
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+)
+
+// supportedSubprotocols 伺服器偏好順序（同時提出時優先使用二進位編碼）
+var supportedSubprotocols = []string{SubprotocolMsgpack, SubprotocolJSON}
+
+// codecFor 依協商結果選擇編碼
+func codecFor(subprotocol string) Codec {
+	if subprotocol == SubprotocolMsgpack {
+		return MsgpackCodec
+	}
+	return JSONCodec
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Subprotocol() string { return SubprotocolJSON }
+
+func (jsonCodec) MessageType() int { return websocket.TextMessage }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Subprotocol() string { return SubprotocolMsgpack }
+
+func (msgpackCodec) MessageType() int { return websocket.BinaryMessage }
+
+// Marshal 以 json 標籤命名欄位並壓縮整數，減少行動網路的傳輸量
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
@@ -0,0 +1,312 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/shinjuwu/TheNuts/internal/auth"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"go.uber.org/zap"
+)
+
+var testCodecs = []Codec{JSONCodec, MsgpackCodec}
+
+// roundTripResponse 編碼後以與原 Payload 同型別的指標解碼
+func roundTripResponse(t *testing.T, codec Codec, resp Response) Response {
+	t.Helper()
+	data, err := codec.Marshal(resp)
+	if err != nil {
+		t.Fatalf("%s: marshal %s failed: %v", codec.Subprotocol(), resp.Type, err)
+	}
+	decoded := Response{Payload: reflect.New(reflect.TypeOf(resp.Payload)).Interface()}
+	if err := codec.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s: unmarshal %s failed: %v", codec.Subprotocol(), resp.Type, err)
+	}
+	decoded.Payload = utcTimes(reflect.ValueOf(decoded.Payload).Elem())
+	return decoded
+}
+
+// utcTimes 將 Payload 的時間欄位轉為 UTC（MessagePack 解碼為本地時區）
+func utcTimes(v reflect.Value) interface{} {
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	if copied.Kind() == reflect.Struct {
+		for i := 0; i < copied.NumField(); i++ {
+			if ts, ok := copied.Field(i).Interface().(time.Time); ok {
+				copied.Field(i).Set(reflect.ValueOf(ts.UTC()))
+			}
+		}
+	}
+	return copied.Interface()
+}
+
+// TestCodec_RequestRoundTrip 所有客戶端動作在兩種編碼下都能完整還原
+func TestCodec_RequestRoundTrip(t *testing.T) {
+	actions := []string{
+		ActionBuyIn, ActionCashOut, ActionJoinTable, ActionLeaveTable, ActionSitDown, ActionStandUp,
+		ActionGameAction, ActionGetBalance, ActionGetTableSnapshot, ActionResume, ActionAck,
+	}
+	for _, codec := range testCodecs {
+		for _, action := range actions {
+			req := Request{
+				Action:     action,
+				TableID:    "table-1",
+				PlayerID:   uuid.NewString(),
+				Amount:     1500,
+				SeatNo:     3,
+				GameAction: "RAISE",
+				LastSeq:    42,
+				Timestamp:  time.Unix(1700000000, 0).UTC(),
+				TraceID:    "trace-" + action,
+			}
+			data, err := codec.Marshal(req)
+			if err != nil {
+				t.Fatalf("%s: marshal %s failed: %v", codec.Subprotocol(), action, err)
+			}
+			var decoded Request
+			if err := codec.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("%s: unmarshal %s failed: %v", codec.Subprotocol(), action, err)
+			}
+			if !decoded.Timestamp.Equal(req.Timestamp) {
+				t.Fatalf("%s: %s timestamp mismatch: %v", codec.Subprotocol(), action, decoded.Timestamp)
+			}
+			decoded.Timestamp = req.Timestamp
+			if decoded != req {
+				t.Fatalf("%s: %s round trip mismatch:\n got %+v\nwant %+v", codec.Subprotocol(), action, decoded, req)
+			}
+		}
+	}
+}
+
+// TestCodec_ResponseRoundTrip 所有伺服器訊息與牌桌事件的 Payload 在兩種編碼下都能完整還原
+func TestCodec_ResponseRoundTrip(t *testing.T) {
+	payloads := map[string]interface{}{
+		TypeBuyInSuccess: BuyInSuccessPayload{
+			SessionID: uuid.NewString(), TableID: "table-1", Chips: 1000, Currency: "USD", WalletBalance: 9000,
+			CreatedAt: time.Unix(1700000000, 0).UTC(),
+		},
+		TypeCashOutSuccess: CashOutSuccessPayload{
+			SessionID: uuid.NewString(), BuyInAmount: 1000, CashOut: 1200, Profit: 200, Currency: "USD", WalletBalance: 10200,
+			EndedAt: time.Unix(1700003600, 0).UTC(),
+		},
+		TypeJoinTableSuccess:  JoinTableSuccessPayload{TableID: "table-1", SeatNo: 3, Chips: 1000},
+		TypeLeaveTableSuccess: LeaveTableSuccessPayload{TableID: "table-1"},
+		TypeSitDownSuccess:    SitDownSuccessPayload{TableID: "table-1", SeatNo: 3},
+		TypeStandUpSuccess:    StandUpSuccessPayload{TableID: "table-1", WasInHand: true},
+		TypeBalanceInfo: BalanceInfoPayload{
+			Wallets:      []WalletBalance{{Currency: "USD", WalletBalance: 9000, LockedBalance: 1000, TotalBalance: 10000}},
+			CurrentChips: 1000,
+		},
+		TypeTableState: TableStatePayload{
+			TableID: "table-1", State: 1, CommunityCards: []string{"As", "Kd", "7h"}, DealerPos: 0, CurrentPos: 2, MinBet: 20, PotTotal: 30,
+			Players: []TableStatePlayer{{ID: "p1", SeatIdx: 0, Chips: 980, CurrentBet: 20, Status: 1, HasActed: true}},
+		},
+		TypeTableSnapshot: TableSnapshotPayload{
+			TableID: "table-1", HandNumber: 7, State: "FLOP", DealerPos: 0, CurrentPos: 1, MinBet: 20,
+			Seats:          []SnapshotSeat{{PlayerID: "p1", SeatIdx: 0, Chips: 980, CurrentBet: 20, Status: "PLAYING", HasActed: true, Disconnected: true}},
+			Pots:           []SnapshotPot{{Amount: 40, Eligible: []string{"p1", "p2"}}},
+			CommunityCards: []string{"As", "Kd", "7h"}, HoleCards: []string{"Qc", "Qs"},
+			CurrentPlayerID: "p2", Deadline: 1700000030000, RemainingMs: 29500,
+		},
+		TypeResumed:         ResumedPayload{LastSeq: 12, Replayed: 3, FullResync: true},
+		TypeError:           ErrorPayload{Code: "unknown_action", Message: "Unknown action: NOPE"},
+		TypeSessionRevoked:  ErrorPayload{Code: "session_revoked", Message: "Session has been revoked"},
+		TypeSessionReplaced: ErrorPayload{Code: "session_replaced", Message: "Session taken over by a new connection"},
+
+		string(domain.EventHandStart): HandStartPayload{DealerPos: 2, Players: []HandStartPlayer{{PlayerID: "p1", SeatIdx: 0, Chips: 990}}},
+		string(domain.EventHoleCards): HoleCardsPayload{Cards: []string{"Ah", "Ad"}},
+		string(domain.EventBlindsPosted): BlindsPostedPayload{
+			MinBet: 20, Blinds: []PostedBlind{{PlayerID: "p1", SeatIdx: 0, Amount: 10}, {PlayerID: "p2", SeatIdx: 1, Amount: 20}},
+		},
+		string(domain.EventYourTurn):     YourTurnPayload{SeatIdx: 1, MinBet: 20, PotTotal: 30, Deadline: 1700000030000},
+		string(domain.EventPlayerAction): PlayerActionPayload{PlayerID: "p1", Action: "RAISE", Amount: 60, Chips: 930},
+		string(domain.EventCommunityCards): CommunityCardsPayload{
+			Street: "TURN", NewCards: []string{"2c"}, CommunityCards: []string{"As", "Kd", "7h", "2c"},
+		},
+		string(domain.EventShowdownResult): ShowdownResultPayload{
+			Winners:        []ShowdownWinner{{PlayerID: "p1", Amount: 200, FinalChips: 1180, HoleCards: []string{"Ah", "Ad"}}},
+			CommunityCards: []string{"As", "Kd", "7h", "2c", "9s"},
+			Rake:           5,
+		},
+		string(domain.EventWinByFold):     WinByFoldPayload{PlayerID: "p2", Amount: 30, Rake: 1, FinalChips: 1010},
+		string(domain.EventHandEnd):       HandEndPayload{Players: []PlayerChips{{PlayerID: "p1", Chips: 1180}}},
+		string(domain.EventActionTimeout): ActionTimeoutPayload{PlayerID: "p1", SeatIdx: 0},
+	}
+
+	for _, codec := range testCodecs {
+		for msgType, payload := range payloads {
+			resp := Response{
+				Type:      msgType,
+				Seq:       99,
+				Payload:   payload,
+				Timestamp: time.Unix(1700000000, 0).UTC(),
+				TraceID:   "trace-1",
+			}
+			decoded := roundTripResponse(t, codec, resp)
+			if decoded.Type != resp.Type || decoded.Seq != resp.Seq || decoded.TraceID != resp.TraceID ||
+				!decoded.Timestamp.Equal(resp.Timestamp) {
+				t.Fatalf("%s: %s envelope mismatch: %+v", codec.Subprotocol(), msgType, decoded)
+			}
+			if !reflect.DeepEqual(decoded.Payload, payload) {
+				t.Fatalf("%s: %s payload mismatch:\n got %+v\nwant %+v", codec.Subprotocol(), msgType, decoded.Payload, payload)
+			}
+		}
+	}
+}
+
+// TestNewEventResponse_TypedPayloads 實際牌局發出的事件都轉換為型別化 Payload，且兩種編碼都能還原
+func TestNewEventResponse_TypedPayloads(t *testing.T) {
+	table := domain.NewTable("codec-test")
+	for i, id := range []string{"p1", "p2"} {
+		p := &domain.Player{ID: id, SeatIdx: i, Chips: 1000, Status: domain.StatusPlaying}
+		table.Seats[i] = p
+		table.Players[id] = p
+	}
+	var events []domain.TableEvent
+	table.AddOnEvent(func(event domain.TableEvent) { events = append(events, event) })
+
+	// 雙方跟注、過牌直到攤牌；行動者由玩家視角的牌桌狀態取得
+	table.StartHand()
+	go table.Run()
+	defer close(table.CloseCh)
+	send := func(cmd domain.PlayerAction) domain.ActionResult {
+		resultCh := make(chan domain.ActionResult, 1)
+		cmd.ResultCh = resultCh
+		table.ActionCh <- cmd
+		return <-resultCh
+	}
+	for i := 0; ; i++ {
+		view := send(domain.PlayerAction{PlayerID: "p1", Type: domain.ActionPlayerView}).View
+		if view.CurrentPlayerID == "" {
+			break
+		}
+		if i > 20 {
+			t.Fatal("expected hand to reach showdown")
+		}
+		action := domain.ActionCheck
+		for _, seat := range view.Seats {
+			if seat.PlayerID == view.CurrentPlayerID && seat.CurrentBet < view.MinBet {
+				action = domain.ActionCall
+			}
+		}
+		if result := send(domain.PlayerAction{PlayerID: view.CurrentPlayerID, Type: action}); result.Err != nil {
+			t.Fatalf("%s failed: %v", action, result.Err)
+		}
+	}
+
+	seen := make(map[domain.TableEventType]bool)
+	for _, event := range events {
+		seen[event.Type] = true
+		resp := NewEventResponse(event)
+		if resp.Type != string(event.Type) {
+			t.Fatalf("expected type %s, got %s", event.Type, resp.Type)
+		}
+		if _, isMap := resp.Payload.(map[string]interface{}); isMap {
+			t.Fatalf("expected typed payload for %s, got %T", event.Type, resp.Payload)
+		}
+		for _, codec := range testCodecs {
+			if decoded := roundTripResponse(t, codec, resp); !reflect.DeepEqual(decoded.Payload, resp.Payload) {
+				t.Fatalf("%s: %s payload mismatch:\n got %+v\nwant %+v", codec.Subprotocol(), event.Type, decoded.Payload, resp.Payload)
+			}
+		}
+	}
+	for _, eventType := range []domain.TableEventType{
+		domain.EventHandStart, domain.EventHoleCards, domain.EventBlindsPosted, domain.EventYourTurn,
+		domain.EventPlayerAction, domain.EventCommunityCards, domain.EventShowdownResult, domain.EventHandEnd,
+	} {
+		if !seen[eventType] {
+			t.Fatalf("expected %s event, got %v", eventType, seen)
+		}
+	}
+
+	// 資料與 domain 事件一致
+	for _, event := range events {
+		if event.Type == domain.EventShowdownResult {
+			payload := NewEventResponse(event).Payload.(ShowdownResultPayload)
+			if len(payload.Winners) == 0 || len(payload.CommunityCards) != 5 {
+				t.Fatalf("unexpected SHOWDOWN_RESULT payload %+v", payload)
+			}
+			for _, winner := range payload.Winners {
+				if winner.FinalChips != table.Players[winner.PlayerID].Chips {
+					t.Fatalf("expected final chips %d, got %+v", table.Players[winner.PlayerID].Chips, winner)
+				}
+			}
+		}
+	}
+}
+
+// TestHandler_SubprotocolNegotiation 提出 MessagePack 子協定時改用二進位訊框，未提出時使用 JSON 文字訊框
+func TestHandler_SubprotocolNegotiation(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+	hub := NewHub(sm, zap.NewNop())
+	go hub.Run()
+	tickets := auth.NewMemoryTicketStore()
+	defer tickets.Close()
+	server := httptest.NewServer(NewHandler(hub, nil, sm, nil, tickets, zap.NewNop()))
+	defer server.Close()
+
+	dial := func(subprotocols []string) (*websocket.Conn, *http.Response) {
+		ticket, err := tickets.Generate(context.Background(), auth.TicketClaims{PlayerID: uuid.NewString()}, time.Minute)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		dialer := websocket.Dialer{Subprotocols: subprotocols, EnableCompression: true}
+		conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?ticket="+ticket, nil)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		return conn, resp
+	}
+	exchange := func(conn *websocket.Conn, codec Codec) Response {
+		data, err := codec.Marshal(Request{Action: "NOPE", TraceID: "trace-1", Timestamp: time.Now()})
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		if err := conn.WriteMessage(codec.MessageType(), data); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if messageType != codec.MessageType() {
+			t.Fatalf("%s: expected message type %d, got %d", codec.Subprotocol(), codec.MessageType(), messageType)
+		}
+		resp := Response{Payload: &ErrorPayload{}}
+		if err := codec.Unmarshal(data, &resp); err != nil {
+			t.Fatalf("%s: unmarshal failed: %v", codec.Subprotocol(), err)
+		}
+		return resp
+	}
+
+	conn, httpResp := dial([]string{SubprotocolMsgpack, SubprotocolJSON})
+	defer conn.Close()
+	if conn.Subprotocol() != SubprotocolMsgpack {
+		t.Fatalf("expected %s, got %q", SubprotocolMsgpack, conn.Subprotocol())
+	}
+	if !strings.Contains(httpResp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Fatalf("expected permessage-deflate, got %q", httpResp.Header.Get("Sec-WebSocket-Extensions"))
+	}
+	resp := exchange(conn, MsgpackCodec)
+	if payload := resp.Payload.(*ErrorPayload); resp.Type != TypeError || resp.TraceID != "trace-1" || payload.Code != "unknown_action" {
+		t.Fatalf("unexpected msgpack reply %+v (%+v)", resp, payload)
+	}
+
+	legacy, _ := dial(nil)
+	defer legacy.Close()
+	if legacy.Subprotocol() != "" {
+		t.Fatalf("expected no subprotocol, got %q", legacy.Subprotocol())
+	}
+	if resp := exchange(legacy, JSONCodec); resp.Type != TypeError || resp.TraceID != "trace-1" {
+		t.Fatalf("unexpected json reply %+v", resp)
+	}
+}
@@ -2,6 +2,38 @@ package ws
 
 import "time"
 
+// 客戶端請求的動作（Request.Action）
+const (
+	ActionBuyIn            = "BUY_IN"
+	ActionCashOut          = "CASH_OUT"
+	ActionJoinTable        = "JOIN_TABLE"
+	ActionLeaveTable       = "LEAVE_TABLE"
+	ActionSitDown          = "SIT_DOWN"
+	ActionStandUp          = "STAND_UP"
+	ActionGameAction       = "GAME_ACTION"
+	ActionGetBalance       = "GET_BALANCE"
+	ActionGetTableSnapshot = "GET_TABLE_SNAPSHOT"
+	ActionResume           = "RESUME"
+	ActionAck              = "ACK"
+)
+
+// 伺服器訊息類型（Response.Type）；牌桌事件沿用 domain.TableEventType
+const (
+	TypeBuyInSuccess      = "BUY_IN_SUCCESS"
+	TypeCashOutSuccess    = "CASH_OUT_SUCCESS"
+	TypeJoinTableSuccess  = "JOIN_TABLE_SUCCESS"
+	TypeLeaveTableSuccess = "LEAVE_TABLE_SUCCESS"
+	TypeSitDownSuccess    = "SIT_DOWN_SUCCESS"
+	TypeStandUpSuccess    = "STAND_UP_SUCCESS"
+	TypeBalanceInfo       = "BALANCE_INFO"
+	TypeTableState        = "TABLE_STATE"
+	TypeTableSnapshot     = "TABLE_SNAPSHOT"
+	TypeResumed           = "RESUMED"
+	TypeError             = "ERROR"
+	TypeSessionRevoked    = "SESSION_REVOKED"
+	TypeSessionReplaced   = "SESSION_REPLACED"
+)
+
 // Request 代表前端發出的指令
 type Request struct {
 	Action     string    `json:"action"`
@@ -10,20 +42,240 @@ type Request struct {
 	Amount     int64     `json:"amount,omitempty"`
 	SeatNo     int       `json:"seat_no,omitempty"`
 	GameAction string    `json:"game_action,omitempty"` // FOLD, CHECK, CALL, BET, RAISE, ALL_IN
+	LastSeq    uint64    `json:"last_seq,omitempty"`    // RESUME/ACK：最後收到的訊息序號
 	Timestamp  time.Time `json:"timestamp"`
 	TraceID    string    `json:"trace_id"`
 }
 
 // Response 代表伺服器回傳的訊息
+// Payload 為下方對應訊息類型的結構；解碼時可預先放入目標結構的指標
 type Response struct {
-	Type      string      `json:"type"` // snapshot, update, error, notification
+	Type      string      `json:"type"`          // snapshot, update, error, notification
+	Seq       uint64      `json:"seq,omitempty"` // 會話內遞增的訊息序號（RESUME 補發用）
 	Payload   interface{} `json:"payload"`
 	Timestamp time.Time   `json:"timestamp"`
 	TraceID   string      `json:"trace_id"`
 }
 
-// ErrorPayload 錯誤訊息具體內容
+// ErrorPayload 錯誤訊息具體內容（ERROR、SESSION_REVOKED、SESSION_REPLACED）
 type ErrorPayload struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
+
+// BuyInSuccessPayload BUY_IN_SUCCESS
+type BuyInSuccessPayload struct {
+	SessionID     string    `json:"session_id"`
+	TableID       string    `json:"table_id"`
+	Chips         int64     `json:"chips"`
+	Currency      string    `json:"currency"`
+	WalletBalance int64     `json:"wallet_balance"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CashOutSuccessPayload CASH_OUT_SUCCESS
+type CashOutSuccessPayload struct {
+	SessionID     string    `json:"session_id"`
+	BuyInAmount   int64     `json:"buy_in_amount"`
+	CashOut       int64     `json:"cash_out"`
+	Profit        int64     `json:"profit"`
+	Currency      string    `json:"currency"`
+	WalletBalance int64     `json:"wallet_balance"`
+	EndedAt       time.Time `json:"ended_at"`
+}
+
+// JoinTableSuccessPayload JOIN_TABLE_SUCCESS
+type JoinTableSuccessPayload struct {
+	TableID string `json:"table_id"`
+	SeatNo  int    `json:"seat_no"`
+	Chips   int64  `json:"chips"`
+}
+
+// LeaveTableSuccessPayload LEAVE_TABLE_SUCCESS
+type LeaveTableSuccessPayload struct {
+	TableID string `json:"table_id"`
+}
+
+// SitDownSuccessPayload SIT_DOWN_SUCCESS
+type SitDownSuccessPayload struct {
+	TableID string `json:"table_id"`
+	SeatNo  int    `json:"seat_no"`
+}
+
+// StandUpSuccessPayload STAND_UP_SUCCESS
+type StandUpSuccessPayload struct {
+	TableID   string `json:"table_id"`
+	WasInHand bool   `json:"was_in_hand"`
+}
+
+// BalanceInfoPayload BALANCE_INFO
+type BalanceInfoPayload struct {
+	Wallets      []WalletBalance `json:"wallets"`
+	CurrentChips int64           `json:"current_chips"`
+}
+
+// WalletBalance 單一幣別的錢包餘額
+type WalletBalance struct {
+	Currency      string `json:"currency"`
+	WalletBalance int64  `json:"wallet_balance"`
+	LockedBalance int64  `json:"locked_balance"`
+	TotalBalance  int64  `json:"total_balance"`
+}
+
+// TableStatePayload TABLE_STATE（桌面變動時廣播的公開狀態）
+type TableStatePayload struct {
+	TableID        string             `json:"table_id"`
+	State          int                `json:"state"`
+	Players        []TableStatePlayer `json:"players"`
+	CommunityCards []string           `json:"community_cards"`
+	DealerPos      int                `json:"dealer_pos"`
+	CurrentPos     int                `json:"current_pos"`
+	MinBet         int64              `json:"min_bet"`
+	PotTotal       int64              `json:"pot_total"`
+}
+
+// TableStatePlayer TABLE_STATE 中的玩家
+type TableStatePlayer struct {
+	ID         string `json:"id"`
+	SeatIdx    int    `json:"seat_idx"`
+	Chips      int64  `json:"chips"`
+	CurrentBet int64  `json:"current_bet"`
+	Status     int    `json:"status"`
+	HasActed   bool   `json:"has_acted"`
+}
+
+// TableSnapshotPayload TABLE_SNAPSHOT（玩家視角的完整牌桌狀態）
+// 無人需行動時不含 current_player_id、deadline 與 remaining_ms
+type TableSnapshotPayload struct {
+	TableID         string         `json:"table_id"`
+	HandNumber      int            `json:"hand_number"`
+	State           string         `json:"state"`
+	DealerPos       int            `json:"dealer_pos"`
+	CurrentPos      int            `json:"current_pos"`
+	MinBet          int64          `json:"min_bet"`
+	Seats           []SnapshotSeat `json:"seats"`
+	Pots            []SnapshotPot  `json:"pots"`
+	CommunityCards  []string       `json:"community_cards"`
+	HoleCards       []string       `json:"hole_cards"`
+	CurrentPlayerID string         `json:"current_player_id,omitempty"`
+	Deadline        int64          `json:"deadline,omitempty"`
+	RemainingMs     int64          `json:"remaining_ms,omitempty"`
+}
+
+// SnapshotSeat TABLE_SNAPSHOT 中的座位
+type SnapshotSeat struct {
+	PlayerID     string `json:"player_id"`
+	SeatIdx      int    `json:"seat_idx"`
+	Chips        int64  `json:"chips"`
+	CurrentBet   int64  `json:"current_bet"`
+	Status       string `json:"status"`
+	HasActed     bool   `json:"has_acted"`
+	Disconnected bool   `json:"disconnected"`
+}
+
+// SnapshotPot TABLE_SNAPSHOT 中的底池
+type SnapshotPot struct {
+	Amount   int64    `json:"amount"`
+	Eligible []string `json:"eligible"`
+}
+
+// ResumedPayload RESUMED
+type ResumedPayload struct {
+	LastSeq    uint64 `json:"last_seq"`
+	Replayed   int    `json:"replayed"`
+	FullResync bool   `json:"full_resync"`
+}
+
+// HandStartPayload HAND_START
+type HandStartPayload struct {
+	DealerPos int               `json:"dealer_pos"`
+	Players   []HandStartPlayer `json:"players"`
+}
+
+// HandStartPlayer 參與本手的玩家（籌碼為下盲注後）
+type HandStartPlayer struct {
+	PlayerID string `json:"player_id"`
+	SeatIdx  int    `json:"seat_idx"`
+	Chips    int64  `json:"chips"`
+}
+
+// HoleCardsPayload HOLE_CARDS（僅發給該玩家）
+type HoleCardsPayload struct {
+	Cards []string `json:"cards"`
+}
+
+// BlindsPostedPayload BLINDS_POSTED
+type BlindsPostedPayload struct {
+	Blinds []PostedBlind `json:"blinds"`
+	MinBet int64         `json:"min_bet"`
+}
+
+// PostedBlind 已下的盲注
+type PostedBlind struct {
+	PlayerID string `json:"player_id"`
+	SeatIdx  int    `json:"seat_idx"`
+	Amount   int64  `json:"amount"`
+}
+
+// YourTurnPayload YOUR_TURN（僅發給行動者）
+type YourTurnPayload struct {
+	SeatIdx  int   `json:"seat_idx"`
+	MinBet   int64 `json:"min_bet"`
+	PotTotal int64 `json:"pot_total"`
+	Deadline int64 `json:"deadline"`
+}
+
+// PlayerActionPayload PLAYER_ACTION
+type PlayerActionPayload struct {
+	PlayerID string `json:"player_id"`
+	Action   string `json:"action"`
+	Amount   int64  `json:"amount"`
+	Chips    int64  `json:"chips"`
+}
+
+// CommunityCardsPayload COMMUNITY_CARDS
+type CommunityCardsPayload struct {
+	Street         string   `json:"street"`
+	NewCards       []string `json:"new_cards"`
+	CommunityCards []string `json:"community_cards"`
+}
+
+// ShowdownResultPayload SHOWDOWN_RESULT
+type ShowdownResultPayload struct {
+	Winners        []ShowdownWinner `json:"winners"`
+	CommunityCards []string         `json:"community_cards"`
+	Rake           int64            `json:"rake"`
+}
+
+// ShowdownWinner 攤牌贏家
+type ShowdownWinner struct {
+	PlayerID   string   `json:"player_id"`
+	Amount     int64    `json:"amount"`
+	FinalChips int64    `json:"final_chips"`
+	HoleCards  []string `json:"hole_cards,omitempty"`
+}
+
+// WinByFoldPayload WIN_BY_FOLD
+type WinByFoldPayload struct {
+	PlayerID   string `json:"player_id"`
+	Amount     int64  `json:"amount"`
+	Rake       int64  `json:"rake"`
+	FinalChips int64  `json:"final_chips"`
+}
+
+// HandEndPayload HAND_END
+type HandEndPayload struct {
+	Players []PlayerChips `json:"players"`
+}
+
+// PlayerChips 手牌結束後的玩家籌碼
+type PlayerChips struct {
+	PlayerID string `json:"player_id"`
+	Chips    int64  `json:"chips"`
+}
+
+// ActionTimeoutPayload ACTION_TIMEOUT
+type ActionTimeoutPayload struct {
+	PlayerID string `json:"player_id"`
+	SeatIdx  int    `json:"seat_idx"`
+}
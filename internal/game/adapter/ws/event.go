@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+)
+
+// NewEventResponse 將牌桌事件轉換為帶有型別化 Payload 的訊息
+// 未定義結構的事件直接轉送原始資料
+func NewEventResponse(event domain.TableEvent) Response {
+	return Response{
+		Type:      string(event.Type),
+		Payload:   eventPayload(event),
+		Timestamp: time.Now(),
+	}
+}
+
+// eventPayload 依事件類型組裝 Payload（欄位與 domain 發射的資料一一對應）
+func eventPayload(event domain.TableEvent) interface{} {
+	data := eventData(event.Data)
+
+	switch event.Type {
+	case domain.EventHandStart:
+		payload := HandStartPayload{DealerPos: data.int("dealer_pos"), Players: []HandStartPlayer{}}
+		for _, p := range data.list("players") {
+			payload.Players = append(payload.Players, HandStartPlayer{
+				PlayerID: p.string("player_id"),
+				SeatIdx:  p.int("seat_idx"),
+				Chips:    p.int64("chips"),
+			})
+		}
+		return payload
+	case domain.EventHoleCards:
+		return HoleCardsPayload{Cards: data.strings("cards")}
+	case domain.EventBlindsPosted:
+		payload := BlindsPostedPayload{MinBet: data.int64("min_bet"), Blinds: []PostedBlind{}}
+		for _, b := range data.list("blinds") {
+			payload.Blinds = append(payload.Blinds, PostedBlind{
+				PlayerID: b.string("player_id"),
+				SeatIdx:  b.int("seat_idx"),
+				Amount:   b.int64("amount"),
+			})
+		}
+		return payload
+	case domain.EventYourTurn:
+		return YourTurnPayload{
+			SeatIdx:  data.int("seat_idx"),
+			MinBet:   data.int64("min_bet"),
+			PotTotal: data.int64("pot_total"),
+			Deadline: data.int64("deadline"),
+		}
+	case domain.EventPlayerAction:
+		return PlayerActionPayload{
+			PlayerID: data.string("player_id"),
+			Action:   data.string("action"),
+			Amount:   data.int64("amount"),
+			Chips:    data.int64("chips"),
+		}
+	case domain.EventCommunityCards:
+		return CommunityCardsPayload{
+			Street:         data.string("street"),
+			NewCards:       data.strings("new_cards"),
+			CommunityCards: data.strings("community_cards"),
+		}
+	case domain.EventShowdownResult:
+		payload := ShowdownResultPayload{
+			Winners:        []ShowdownWinner{},
+			CommunityCards: data.strings("community_cards"),
+			Rake:           data.int64("rake"),
+		}
+		for _, w := range data.list("winners") {
+			payload.Winners = append(payload.Winners, ShowdownWinner{
+				PlayerID:   w.string("player_id"),
+				Amount:     w.int64("amount"),
+				FinalChips: w.int64("final_chips"),
+				HoleCards:  w.strings("hole_cards"),
+			})
+		}
+		return payload
+	case domain.EventWinByFold:
+		return WinByFoldPayload{
+			PlayerID:   data.string("player_id"),
+			Amount:     data.int64("amount"),
+			Rake:       data.int64("rake"),
+			FinalChips: data.int64("final_chips"),
+		}
+	case domain.EventHandEnd:
+		payload := HandEndPayload{Players: []PlayerChips{}}
+		for _, p := range data.list("players") {
+			payload.Players = append(payload.Players, PlayerChips{
+				PlayerID: p.string("player_id"),
+				Chips:    p.int64("chips"),
+			})
+		}
+		return payload
+	case domain.EventActionTimeout:
+		return ActionTimeoutPayload{
+			PlayerID: data.string("player_id"),
+			SeatIdx:  data.int("seat_idx"),
+		}
+	default:
+		return event.Data
+	}
+}
+
+// eventData 讀取事件資料的輔助型別（欄位缺少或型別不符時回傳零值）
+type eventData map[string]interface{}
+
+func (d eventData) string(key string) string {
+	v, _ := d[key].(string)
+	return v
+}
+
+func (d eventData) int(key string) int {
+	v, _ := d[key].(int)
+	return v
+}
+
+func (d eventData) int64(key string) int64 {
+	v, _ := d[key].(int64)
+	return v
+}
+
+func (d eventData) strings(key string) []string {
+	v, _ := d[key].([]string)
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
+
+func (d eventData) list(key string) []eventData {
+	items, _ := d[key].([]map[string]interface{})
+	list := make([]eventData, 0, len(items))
+	for _, item := range items {
+		list = append(list, eventData(item))
+	}
+	return list
+}
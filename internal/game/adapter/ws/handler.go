@@ -19,6 +19,7 @@ type Handler struct {
 	GameService    *service.GameService
 	TicketStore    auth.TicketStore
 	Logger         *zap.Logger
+	messages       *MessageHandler
 	upgrader       websocket.Upgrader
 }
 
@@ -37,10 +38,14 @@ func NewHandler(
 		GameService:    gameService,
 		TicketStore:    ticketStore,
 		Logger:         logger,
+		messages:       NewMessageHandler(sessionMgr, tableMgr, gameService, logger),
 	}
 	h.upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
+		// 客戶端以 Sec-WebSocket-Protocol 選擇編碼（未提出時使用 JSON），支援時啟用 permessage-deflate
+		Subprotocols:      supportedSubprotocols,
+		EnableCompression: true,
 		CheckOrigin: func(r *http.Request) bool {
 			return true // 預設允許所有（透過 SetAllowedOrigins 覆蓋）
 		},
@@ -82,6 +87,11 @@ func (h *Handler) SetAllowedOrigins(origins []string) {
 	}
 }
 
+// SetRateLimiter 設定每位玩家的 WebSocket 訊息限流器
+func (h *Handler) SetRateLimiter(limiter auth.RateLimiter) {
+	h.messages.SetRateLimiter(limiter)
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 從 URL Query 取得票券
 	ticket := r.URL.Query().Get("ticket")
@@ -91,8 +101,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 驗證票券並取得玩家 ID
-	playerID, err := h.TicketStore.Validate(context.Background(), ticket)
+	// 驗證票券並取得玩家 ID 與登入會話
+	claims, err := h.TicketStore.Validate(context.Background(), ticket)
 	if err != nil {
 		http.Error(w, "invalid ticket: "+err.Error(), http.StatusUnauthorized)
 		h.Logger.Warn("websocket connection rejected: invalid ticket",
@@ -101,6 +111,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
+	playerID := claims.PlayerID
 
 	h.Logger.Info("ticket validated successfully",
 		zap.String("player_id", playerID),
@@ -122,11 +133,15 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 創建客戶端
-	client := NewClient(h.Hub, h.TableManager, conn, playerID, h.Logger)
+	// 創建客戶端（依協商的子協定選擇編碼）
+	codec := codecFor(conn.Subprotocol())
+	client := NewClient(h.Hub, h.TableManager, conn, playerID, codec, h.messages, h.Logger)
 
-	// 創建 PlayerSession
+	// 創建 PlayerSession（已有連線時由新連線接管，舊連線收到 SESSION_REPLACED 後關閉）
 	session := NewPlayerSession(playerUUID, playerID, client, h.GameService, h.Logger)
+	session.IPAddress = auth.ClientIP(r)
+	session.UserAgent = r.UserAgent()
+	session.AuthSessionID = claims.SessionID
 	h.SessionManager.AddSession(session)
 
 	// 註冊客戶端到 Hub
@@ -135,10 +150,16 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("websocket client connected",
 		zap.String("player_id", playerID),
 		zap.String("remote_addr", r.RemoteAddr),
+		zap.String("subprotocol", codec.Subprotocol()),
 	)
 
 	go client.WritePump()
 	go client.ReadPump()
+
+	// 重連時等待客戶端 RESUME 補發遺漏的訊息；未在寬限期內 RESUME 才推送完整牌桌狀態
+	if session.GetTableID() != "" {
+		h.messages.ScheduleResyncFallback(session)
+	}
 }
 
 func min(a, b int) int {
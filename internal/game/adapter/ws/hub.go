@@ -33,32 +33,31 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
+			// 旧连接已由 SessionManager 通知被取代并关闭，这里只替换路由
 			h.clients[client.PlayerID] = client
 			h.mu.Unlock()
 			h.logger.Info("player connected", zap.String("player_id", client.PlayerID))
 
 		case client := <-h.unregister:
+			// 先将会话标记为断线再关闭 send，之后的消息只写入会话缓冲，不会送进已关闭的 channel
+			playerID, err := uuid.Parse(client.PlayerID)
+			if err == nil {
+				h.sessionManager.HandleDisconnect(playerID, client)
+			}
+
 			h.mu.Lock()
-			if _, ok := h.clients[client.PlayerID]; ok {
+			if current, ok := h.clients[client.PlayerID]; ok && current == client {
 				delete(h.clients, client.PlayerID)
-				close(client.send)
-				
-				playerID, err := uuid.Parse(client.PlayerID)
-				if err == nil {
-					go h.sessionManager.HandleDisconnect(playerID)
-				}
-				
-				h.logger.Info("player disconnected", zap.String("player_id", client.PlayerID))
 			}
+			client.closeSend()
 			h.mu.Unlock()
 
+			h.logger.Info("player disconnected", zap.String("player_id", client.PlayerID))
+
 		case message := <-h.broadcast:
 			h.mu.RLock()
 			for _, client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-				}
+				client.trySend(message)
 			}
 			h.mu.RUnlock()
 		}
@@ -72,12 +71,8 @@ func (h *Hub) Broadcast(message interface{}) {
 func (h *Hub) SendToPlayer(playerID string, message interface{}) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	if client, ok := h.clients[playerID]; ok {
-		select {
-		case client.send <- message:
-		default:
-			h.logger.Warn("failed to send to player", zap.String("player_id", playerID))
-		}
+	if client, ok := h.clients[playerID]; ok && !client.trySend(message) {
+		h.logger.Warn("failed to send to player", zap.String("player_id", playerID))
 	}
 }
 
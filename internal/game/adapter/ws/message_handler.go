@@ -2,28 +2,41 @@ package ws
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/auth"
 	"github.com/shinjuwu/TheNuts/internal/game/domain"
 	"github.com/shinjuwu/TheNuts/internal/game/service"
 	"go.uber.org/zap"
 )
 
+// tableProvider 提供牌桌、牌桌币别与玩家视角的牌桌状态（由 game.TableManager 实现）
+type tableProvider interface {
+	GetOrCreateTable(id string) *domain.Table
+	TableCurrency(tableID string) string
+	PlayerView(tableID, playerID string) (*domain.TableView, error)
+}
+
 // MessageHandler 处理各种 WebSocket 消息
 type MessageHandler struct {
 	sessionManager *SessionManager
-	tableManager   interface{ GetOrCreateTable(id string) *domain.Table }
+	tableManager   tableProvider
 	gameService    *service.GameService
+	limiter        auth.RateLimiter // 可为 nil（不限流）
+	requests       *requestCache    // 以 trace_id 去重重试的请求
+	resumeGrace    time.Duration    // 重连后等待 RESUME 的时间，逾时改推完整牌桌状态
 	logger         *zap.Logger
 }
 
+// resumeGracePeriod 重连后等待客户端 RESUME 的默认时间
+const resumeGracePeriod = 3 * time.Second
+
 // NewMessageHandler 创建消息处理器
 func NewMessageHandler(
 	sessionMgr *SessionManager,
-	tableMgr interface{ GetOrCreateTable(id string) *domain.Table },
+	tableMgr tableProvider,
 	gameService *service.GameService,
 	logger *zap.Logger,
 ) *MessageHandler {
@@ -31,22 +44,82 @@ func NewMessageHandler(
 		sessionManager: sessionMgr,
 		tableManager:   tableMgr,
 		gameService:    gameService,
+		requests:       newRequestCache(requestCacheTTL),
+		resumeGrace:    resumeGracePeriod,
 		logger:         logger,
 	}
 }
 
-// HandleMessage 处理客户端消息
-func (h *MessageHandler) HandleMessage(playerID uuid.UUID, message []byte) {
+// SetRateLimiter 设定每位玩家的消息限流器
+func (h *MessageHandler) SetRateLimiter(limiter auth.RateLimiter) {
+	h.limiter = limiter
+}
+
+// AllowMessage 检查玩家消息频率，超过限制时回传 rate_limited 错误（带上被拒请求的 trace_id）
+// 限流器故障时放行，不影响游戏进行
+func (h *MessageHandler) AllowMessage(playerID uuid.UUID, traceID string) bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	allowed, retryAfter, err := h.limiter.Allow(ctx, "player:"+playerID.String())
+	if err != nil {
+		h.logger.Warn("rate limiter unavailable, allowing message",
+			zap.String("player_id", playerID.String()),
+			zap.Error(err),
+		)
+		return true
+	}
+	if !allowed {
+		h.logger.Debug("message rate limited",
+			zap.String("player_id", playerID.String()),
+			zap.String("trace_id", traceID),
+			zap.Duration("retry_after", retryAfter),
+		)
+		h.sendError(playerID, traceID, "rate_limited", "Too many messages, retry after "+retryAfter.String())
+		return false
+	}
+	return true
+}
+
+// HandleMessage 处理客户端消息（以连接协商的编码解码）
+func (h *MessageHandler) HandleMessage(playerID uuid.UUID, codec Codec, message []byte) {
 	var req Request
-	if err := json.Unmarshal(message, &req); err != nil {
+	err := codec.Unmarshal(message, &req)
+
+	// 先检查消息频率（格式错误的消息同样计入），超过限制的消息直接丢弃
+	if !h.AllowMessage(playerID, req.TraceID) {
+		return
+	}
+
+	if err != nil {
 		h.logger.Warn("invalid message format",
 			zap.String("player_id", playerID.String()),
 			zap.Error(err),
 		)
-		h.sendError(playerID, "invalid_format", "Invalid message format")
+		h.sendError(playerID, "", "invalid_format", "Invalid message format")
 		return
 	}
 
+	// 带 trace_id 重试的请求：已处理过时重送原回应，处理中时忽略（回应会带上同一个 trace_id）
+	if req.TraceID != "" && dedupedActions[req.Action] {
+		if replies, duplicate := h.requests.begin(playerID, req.TraceID); duplicate {
+			h.logger.Info("duplicate request ignored",
+				zap.String("player_id", playerID.String()),
+				zap.String("trace_id", req.TraceID),
+				zap.String("action", req.Action),
+				zap.Int("replayed_replies", len(replies)),
+			)
+			for _, reply := range replies {
+				h.sessionManager.SendToPlayer(playerID, reply)
+			}
+			return
+		}
+	}
+
 	// 更新会话活动时间
 	if session, exists := h.sessionManager.GetSession(playerID); exists {
 		session.UpdateActivity()
@@ -54,49 +127,59 @@ func (h *MessageHandler) HandleMessage(playerID uuid.UUID, message []byte) {
 
 	// 根据动作类型路由
 	switch req.Action {
-	case "BUY_IN":
+	case ActionBuyIn:
 		h.handleBuyIn(playerID, req)
-	case "CASH_OUT":
+	case ActionCashOut:
 		h.handleCashOut(playerID, req)
-	case "JOIN_TABLE":
+	case ActionJoinTable:
 		h.handleJoinTable(playerID, req)
-	case "LEAVE_TABLE":
+	case ActionLeaveTable:
 		h.handleLeaveTable(playerID, req)
-	case "SIT_DOWN":
+	case ActionSitDown:
 		h.handleSitDown(playerID, req)
-	case "STAND_UP":
+	case ActionStandUp:
 		h.handleStandUp(playerID, req)
-	case "GAME_ACTION":
+	case ActionGameAction:
 		h.handleGameAction(playerID, req)
-	case "GET_BALANCE":
+	case ActionGetBalance:
 		h.handleGetBalance(playerID, req)
+	case ActionGetTableSnapshot:
+		h.handleGetTableSnapshot(playerID, req)
+	case ActionResume:
+		h.handleResume(playerID, req)
+	case ActionAck:
+		h.handleAck(playerID, req)
 	default:
 		h.logger.Warn("unknown action",
 			zap.String("player_id", playerID.String()),
+			zap.String("trace_id", req.TraceID),
 			zap.String("action", req.Action),
 		)
-		h.sendError(playerID, "unknown_action", "Unknown action: "+req.Action)
+		h.sendError(playerID, req.TraceID, "unknown_action", "Unknown action: "+req.Action)
 	}
 }
 
 // handleBuyIn 处理买入请求
 func (h *MessageHandler) handleBuyIn(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// 验证金额
 	if req.Amount <= 0 {
-		h.sendError(playerID, "invalid_amount", "Amount must be positive")
+		h.sendError(playerID, req.TraceID, "invalid_amount", "Amount must be positive")
 		return
 	}
 
-	// 确保玩家有钱包
-	if err := h.gameService.EnsureWalletExists(ctx, playerID, "USD"); err != nil {
-		h.logger.Error("failed to ensure wallet exists",
-			zap.String("player_id", playerID.String()),
+	// 买入只动用牌桌币别的钱包（游戏币桌不会扣真钱）
+	currency := h.tableManager.TableCurrency(req.TableID)
+
+	// 确保玩家有该币别的钱包
+	if err := h.gameService.EnsureWalletExists(ctx, playerID, currency); err != nil {
+		log.Error("failed to ensure wallet exists",
 			zap.Error(err),
 		)
-		h.sendError(playerID, "wallet_error", "Failed to access wallet")
+		h.sendError(playerID, req.TraceID, "wallet_error", "Failed to access wallet")
 		return
 	}
 
@@ -106,12 +189,14 @@ func (h *MessageHandler) handleBuyIn(playerID uuid.UUID, req Request) {
 		TableID:  req.TableID,
 		GameType: "poker",
 		Amount:   req.Amount,
+		Currency: currency,
+		Source:   h.requestSource(playerID),
 	})
 
 	if err != nil {
-		h.logger.Warn("buy-in failed",
-			zap.String("player_id", playerID.String()),
+		log.Warn("buy-in failed",
 			zap.String("table_id", req.TableID),
+			zap.String("currency", currency),
 			zap.Int64("amount", req.Amount),
 			zap.Error(err),
 		)
@@ -119,11 +204,13 @@ func (h *MessageHandler) handleBuyIn(playerID uuid.UUID, req Request) {
 		// 根据错误类型返回不同消息
 		switch err {
 		case service.ErrInsufficientBalance:
-			h.sendError(playerID, "insufficient_balance", "Insufficient balance")
+			h.sendError(playerID, req.TraceID, "insufficient_balance", "Insufficient balance")
 		case service.ErrSessionAlreadyActive:
-			h.sendError(playerID, "already_in_game", "Already have an active game session")
+			h.sendError(playerID, req.TraceID, "already_in_game", "Already have an active game session")
+		case service.ErrEmailNotVerified:
+			h.sendError(playerID, req.TraceID, "email_not_verified", "Verify your email address before buying in with real money")
 		default:
-			h.sendError(playerID, "buy_in_failed", "Buy-in failed")
+			h.sendError(playerID, req.TraceID, "buy_in_failed", "Buy-in failed")
 		}
 		return
 	}
@@ -134,16 +221,16 @@ func (h *MessageHandler) handleBuyIn(playerID uuid.UUID, req Request) {
 	}
 
 	// 发送成功响应
-	h.sendResponse(playerID, "BUY_IN_SUCCESS", map[string]interface{}{
-		"session_id":     response.SessionID.String(),
-		"table_id":       response.TableID,
-		"chips":          response.Chips,
-		"wallet_balance": response.WalletBalance,
-		"created_at":     response.CreatedAt,
+	h.sendResponse(playerID, req.TraceID, TypeBuyInSuccess, BuyInSuccessPayload{
+		SessionID:     response.SessionID.String(),
+		TableID:       response.TableID,
+		Chips:         response.Chips,
+		Currency:      response.Currency,
+		WalletBalance: response.WalletBalance,
+		CreatedAt:     response.CreatedAt,
 	})
 
-	h.logger.Info("buy-in successful",
-		zap.String("player_id", playerID.String()),
+	log.Info("buy-in successful",
 		zap.String("table_id", req.TableID),
 		zap.Int64("amount", req.Amount),
 		zap.Int64("chips", response.Chips),
@@ -152,18 +239,19 @@ func (h *MessageHandler) handleBuyIn(playerID uuid.UUID, req Request) {
 
 // handleCashOut 处理兑现请求
 func (h *MessageHandler) handleCashOut(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// 获取玩家会话
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	if session.GameSessionID == uuid.Nil {
-		h.sendError(playerID, "no_game_session", "No active game session")
+		h.sendError(playerID, req.TraceID, "no_game_session", "No active game session")
 		return
 	}
 
@@ -172,14 +260,14 @@ func (h *MessageHandler) handleCashOut(playerID uuid.UUID, req Request) {
 		PlayerID:  playerID,
 		SessionID: session.GameSessionID,
 		Chips:     session.GetChips(),
+		Source:    session.RequestSource(),
 	})
 
 	if err != nil {
-		h.logger.Error("cash-out failed",
-			zap.String("player_id", playerID.String()),
+		log.Error("cash-out failed",
 			zap.Error(err),
 		)
-		h.sendError(playerID, "cash_out_failed", "Cash-out failed")
+		h.sendError(playerID, req.TraceID, "cash_out_failed", "Cash-out failed")
 		return
 	}
 
@@ -189,21 +277,37 @@ func (h *MessageHandler) handleCashOut(playerID uuid.UUID, req Request) {
 	session.Chips = 0
 
 	// 发送成功响应
-	h.sendResponse(playerID, "CASH_OUT_SUCCESS", map[string]interface{}{
-		"session_id":     response.SessionID.String(),
-		"buy_in_amount":  response.BuyInAmount,
-		"cash_out":       response.CashOutAmount,
-		"profit":         response.Profit,
-		"wallet_balance": response.WalletBalance,
-		"ended_at":       response.EndedAt,
+	h.sendResponse(playerID, req.TraceID, TypeCashOutSuccess, CashOutSuccessPayload{
+		SessionID:     response.SessionID.String(),
+		BuyInAmount:   response.BuyInAmount,
+		CashOut:       response.CashOutAmount,
+		Profit:        response.Profit,
+		Currency:      response.Currency,
+		WalletBalance: response.WalletBalance,
+		EndedAt:       response.EndedAt,
 	})
 
-	h.logger.Info("cash-out successful",
-		zap.String("player_id", playerID.String()),
+	log.Info("cash-out successful",
 		zap.Int64("profit", response.Profit),
 	)
 }
 
+// requestLogger 带有玩家 ID 与 trace_id 的日志（追踪单一请求的处理过程）
+func (h *MessageHandler) requestLogger(playerID uuid.UUID, req Request) *zap.Logger {
+	return h.logger.With(
+		zap.String("player_id", playerID.String()),
+		zap.String("trace_id", req.TraceID),
+	)
+}
+
+// requestSource 取得玩家连接的请求来源（写入审计日志）
+func (h *MessageHandler) requestSource(playerID uuid.UUID) *service.RequestSource {
+	if session, exists := h.sessionManager.GetSession(playerID); exists {
+		return session.RequestSource()
+	}
+	return &service.RequestSource{}
+}
+
 // sendTableCommand 透過 ActionCh 發送命令到 Table.Run() 並等待結果
 func (h *MessageHandler) sendTableCommand(table *domain.Table, cmd domain.PlayerAction) domain.ActionResult {
 	resultCh := make(chan domain.ActionResult, 1)
@@ -225,15 +329,34 @@ func (h *MessageHandler) sendTableCommand(table *domain.Table, cmd domain.Player
 
 // handleJoinTable 处理加入桌子请求
 func (h *MessageHandler) handleJoinTable(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	// 检查是否已有游戏会话
 	if session.GameSessionID == uuid.Nil {
-		h.sendError(playerID, "no_game_session", "Please buy-in first")
+		h.sendError(playerID, req.TraceID, "no_game_session", "Please buy-in first")
+		return
+	}
+
+	// 游戏会话须是在这张桌子、以这张桌子的币别买入的
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	currency := h.tableManager.TableCurrency(req.TableID)
+	if err := h.gameService.VerifySessionForTable(ctx, playerID, session.GameSessionID, req.TableID, currency); err != nil {
+		log.Warn("join table rejected",
+			zap.String("table_id", req.TableID),
+			zap.String("session_id", session.GameSessionID.String()),
+			zap.Error(err),
+		)
+		if errors.Is(err, service.ErrSessionTableMismatch) {
+			h.sendError(playerID, req.TraceID, "session_table_mismatch", "Game session was bought in for a different table")
+			return
+		}
+		h.sendError(playerID, req.TraceID, "no_game_session", "Please buy-in first")
 		return
 	}
 
@@ -243,6 +366,7 @@ func (h *MessageHandler) handleJoinTable(playerID uuid.UUID, req Request) {
 	// 创建 domain.Player
 	domainPlayer := &domain.Player{
 		ID:         playerID.String(),
+		SessionID:  session.GameSessionID.String(),
 		SeatIdx:    req.SeatNo,
 		Chips:      session.GetChips(),
 		CurrentBet: 0,
@@ -253,17 +377,18 @@ func (h *MessageHandler) handleJoinTable(playerID uuid.UUID, req Request) {
 
 	// 透過 ActionCh 發送，由 Table.Run() 統一處理
 	result := h.sendTableCommand(table, domain.PlayerAction{
-		Type:    domain.ActionJoinTable,
-		Player:  domainPlayer,
-		SeatIdx: req.SeatNo,
+		Type:     domain.ActionJoinTable,
+		PlayerID: playerID.String(),
+		Player:   domainPlayer,
+		SeatIdx:  req.SeatNo,
+		TraceID:  req.TraceID,
 	})
 	if result.Err != nil {
-		h.logger.Warn("join table failed",
-			zap.String("player_id", playerID.String()),
+		log.Warn("join table failed",
 			zap.String("table_id", req.TableID),
 			zap.Error(result.Err),
 		)
-		h.sendError(playerID, "join_table_failed", result.Err.Error())
+		h.sendError(playerID, req.TraceID, "join_table_failed", result.Err.Error())
 		return
 	}
 
@@ -274,14 +399,13 @@ func (h *MessageHandler) handleJoinTable(playerID uuid.UUID, req Request) {
 	h.broadcastTableState(req.TableID, table)
 
 	// 发送成功响应
-	h.sendResponse(playerID, "JOIN_TABLE_SUCCESS", map[string]interface{}{
-		"table_id": req.TableID,
-		"seat_no":  req.SeatNo,
-		"chips":    session.GetChips(),
+	h.sendResponse(playerID, req.TraceID, TypeJoinTableSuccess, JoinTableSuccessPayload{
+		TableID: req.TableID,
+		SeatNo:  req.SeatNo,
+		Chips:   session.GetChips(),
 	})
 
-	h.logger.Info("player joined table",
-		zap.String("player_id", playerID.String()),
+	log.Info("player joined table",
 		zap.String("table_id", req.TableID),
 		zap.Int("seat_no", req.SeatNo),
 	)
@@ -289,15 +413,16 @@ func (h *MessageHandler) handleJoinTable(playerID uuid.UUID, req Request) {
 
 // handleLeaveTable 处理离开桌子请求
 func (h *MessageHandler) handleLeaveTable(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	tableID := session.GetTableID()
 	if tableID == "" {
-		h.sendError(playerID, "not_at_table", "Not at any table")
+		h.sendError(playerID, req.TraceID, "not_at_table", "Not at any table")
 		return
 	}
 
@@ -308,14 +433,14 @@ func (h *MessageHandler) handleLeaveTable(playerID uuid.UUID, req Request) {
 	result := h.sendTableCommand(table, domain.PlayerAction{
 		Type:     domain.ActionLeaveTable,
 		PlayerID: playerID.String(),
+		TraceID:  req.TraceID,
 	})
 	if result.Err != nil {
-		h.logger.Warn("leave table failed",
-			zap.String("player_id", playerID.String()),
+		log.Warn("leave table failed",
 			zap.String("table_id", tableID),
 			zap.Error(result.Err),
 		)
-		h.sendError(playerID, "leave_table_failed", result.Err.Error())
+		h.sendError(playerID, req.TraceID, "leave_table_failed", result.Err.Error())
 		return
 	}
 
@@ -326,27 +451,27 @@ func (h *MessageHandler) handleLeaveTable(playerID uuid.UUID, req Request) {
 	h.broadcastTableState(tableID, table)
 
 	// 发送成功响应
-	h.sendResponse(playerID, "LEAVE_TABLE_SUCCESS", map[string]interface{}{
-		"table_id": tableID,
+	h.sendResponse(playerID, req.TraceID, TypeLeaveTableSuccess, LeaveTableSuccessPayload{
+		TableID: tableID,
 	})
 
-	h.logger.Info("player left table",
-		zap.String("player_id", playerID.String()),
+	log.Info("player left table",
 		zap.String("table_id", tableID),
 	)
 }
 
 // handleSitDown 处理坐下请求
 func (h *MessageHandler) handleSitDown(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	tableID := session.GetTableID()
 	if tableID == "" {
-		h.sendError(playerID, "not_at_table", "Not at any table")
+		h.sendError(playerID, req.TraceID, "not_at_table", "Not at any table")
 		return
 	}
 
@@ -355,26 +480,25 @@ func (h *MessageHandler) handleSitDown(playerID uuid.UUID, req Request) {
 	result := h.sendTableCommand(table, domain.PlayerAction{
 		Type:     domain.ActionSitDown,
 		PlayerID: playerID.String(),
+		TraceID:  req.TraceID,
 	})
 	if result.Err != nil {
-		h.logger.Warn("sit down failed",
-			zap.String("player_id", playerID.String()),
+		log.Warn("sit down failed",
 			zap.String("table_id", tableID),
 			zap.Error(result.Err),
 		)
-		h.sendError(playerID, "sit_down_failed", result.Err.Error())
+		h.sendError(playerID, req.TraceID, "sit_down_failed", result.Err.Error())
 		return
 	}
 
 	h.broadcastTableState(tableID, table)
 
-	h.sendResponse(playerID, "SIT_DOWN_SUCCESS", map[string]interface{}{
-		"table_id": tableID,
-		"seat_no":  session.SeatNo,
+	h.sendResponse(playerID, req.TraceID, TypeSitDownSuccess, SitDownSuccessPayload{
+		TableID: tableID,
+		SeatNo:  session.SeatNo,
 	})
 
-	h.logger.Info("player sat down",
-		zap.String("player_id", playerID.String()),
+	log.Info("player sat down",
 		zap.String("table_id", tableID),
 		zap.Int("seat_no", session.SeatNo),
 	)
@@ -382,15 +506,16 @@ func (h *MessageHandler) handleSitDown(playerID uuid.UUID, req Request) {
 
 // handleStandUp 处理站起请求
 func (h *MessageHandler) handleStandUp(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	tableID := session.GetTableID()
 	if tableID == "" {
-		h.sendError(playerID, "not_at_table", "Not at any table")
+		h.sendError(playerID, req.TraceID, "not_at_table", "Not at any table")
 		return
 	}
 
@@ -399,26 +524,25 @@ func (h *MessageHandler) handleStandUp(playerID uuid.UUID, req Request) {
 	result := h.sendTableCommand(table, domain.PlayerAction{
 		Type:     domain.ActionStandUp,
 		PlayerID: playerID.String(),
+		TraceID:  req.TraceID,
 	})
 	if result.Err != nil {
-		h.logger.Warn("stand up failed",
-			zap.String("player_id", playerID.String()),
+		log.Warn("stand up failed",
 			zap.String("table_id", tableID),
 			zap.Error(result.Err),
 		)
-		h.sendError(playerID, "stand_up_failed", result.Err.Error())
+		h.sendError(playerID, req.TraceID, "stand_up_failed", result.Err.Error())
 		return
 	}
 
 	h.broadcastTableState(tableID, table)
 
-	h.sendResponse(playerID, "STAND_UP_SUCCESS", map[string]interface{}{
-		"table_id":    tableID,
-		"was_in_hand": result.WasInHand,
+	h.sendResponse(playerID, req.TraceID, TypeStandUpSuccess, StandUpSuccessPayload{
+		TableID:   tableID,
+		WasInHand: result.WasInHand,
 	})
 
-	h.logger.Info("player stood up",
-		zap.String("player_id", playerID.String()),
+	log.Info("player stood up",
 		zap.String("table_id", tableID),
 		zap.Bool("was_in_hand", result.WasInHand),
 	)
@@ -426,15 +550,16 @@ func (h *MessageHandler) handleStandUp(playerID uuid.UUID, req Request) {
 
 // handleGameAction 处理游戏动作
 func (h *MessageHandler) handleGameAction(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
 	session, exists := h.sessionManager.GetSession(playerID)
 	if !exists {
-		h.sendError(playerID, "no_session", "No active session")
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
 		return
 	}
 
 	tableID := session.GetTableID()
 	if tableID == "" {
-		h.sendError(playerID, "not_at_table", "Not at any table")
+		h.sendError(playerID, req.TraceID, "not_at_table", "Not at any table")
 		return
 	}
 
@@ -446,29 +571,29 @@ func (h *MessageHandler) handleGameAction(playerID uuid.UUID, req Request) {
 		PlayerID: playerID.String(),
 		Type:     actionType,
 		Amount:   req.Amount,
+		TraceID:  req.TraceID,
 	}
 
 	// 透過 sendTableCommand 同步等待結果
 	result := h.sendTableCommand(table, playerAction)
 	if result.Err != nil {
-		h.sendError(playerID, "action_rejected", result.Err.Error())
+		h.sendError(playerID, req.TraceID, "action_rejected", result.Err.Error())
 		return
 	}
 
-	h.logger.Debug("game action accepted",
-		zap.String("player_id", playerID.String()),
+	log.Debug("game action accepted",
 		zap.String("action", req.GameAction),
 	)
 }
 
-// handleGetBalance 处理查询余额请求
+// handleGetBalance 处理查询余额请求（回传所有币别的钱包）
 func (h *MessageHandler) handleGetBalance(playerID uuid.UUID, req Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	wallet, err := h.gameService.GetPlayerBalance(ctx, playerID)
+	wallets, err := h.gameService.GetPlayerWallets(ctx, playerID)
 	if err != nil {
-		h.sendError(playerID, "balance_error", "Failed to get balance")
+		h.sendError(playerID, req.TraceID, "balance_error", "Failed to get balance")
 		return
 	}
 
@@ -478,15 +603,175 @@ func (h *MessageHandler) handleGetBalance(playerID uuid.UUID, req Request) {
 		chips = session.GetChips()
 	}
 
-	h.sendResponse(playerID, "BALANCE_INFO", map[string]interface{}{
-		"wallet_balance": wallet.Balance,
-		"locked_balance": wallet.LockedBalance,
-		"current_chips":  chips,
-		"total_balance":  wallet.TotalBalance(),
-		"currency":       wallet.Currency,
+	balances := make([]WalletBalance, 0, len(wallets))
+	for _, wallet := range wallets {
+		balances = append(balances, WalletBalance{
+			Currency:      wallet.Currency,
+			WalletBalance: wallet.Balance,
+			LockedBalance: wallet.LockedBalance,
+			TotalBalance:  wallet.TotalBalance(),
+		})
+	}
+
+	h.sendResponse(playerID, req.TraceID, TypeBalanceInfo, BalanceInfoPayload{
+		Wallets:      balances,
+		CurrentChips: chips,
 	})
 }
 
+// handleGetTableSnapshot 处理重新同步请求，回传玩家视角的完整牌桌状态
+// 未指定 table_id 时使用玩家目前所在的桌子
+func (h *MessageHandler) handleGetTableSnapshot(playerID uuid.UUID, req Request) {
+	tableID := req.TableID
+	if tableID == "" {
+		if session, exists := h.sessionManager.GetSession(playerID); exists {
+			tableID = session.GetTableID()
+		}
+	}
+	if tableID == "" {
+		h.sendError(playerID, req.TraceID, "not_at_table", "Not at any table")
+		return
+	}
+
+	if err := h.SendTableSnapshot(playerID, req.TraceID, tableID); err != nil {
+		h.sendError(playerID, req.TraceID, "snapshot_failed", "Failed to get table snapshot")
+	}
+}
+
+// SendTableSnapshot 发送玩家视角的完整牌桌状态（TABLE_SNAPSHOT）
+// 状态在牌桌 goroutine 中产生，包含座位、筹码、下注、底池、公共牌、自己的手牌与当前行动者
+// traceID 为触发的请求（伺服器主动推送时为空）
+func (h *MessageHandler) SendTableSnapshot(playerID uuid.UUID, traceID, tableID string) error {
+	view, err := h.tableManager.PlayerView(tableID, playerID.String())
+	if err != nil {
+		h.logger.Warn("failed to get table snapshot",
+			zap.String("player_id", playerID.String()),
+			zap.String("trace_id", traceID),
+			zap.String("table_id", tableID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	h.sendResponse(playerID, traceID, TypeTableSnapshot, buildPlayerView(view, time.Now()))
+	return nil
+}
+
+// buildPlayerView 构建 TABLE_SNAPSHOT 的内容
+func buildPlayerView(view *domain.TableView, now time.Time) TableSnapshotPayload {
+	seats := make([]SnapshotSeat, 0, len(view.Seats))
+	for _, seat := range view.Seats {
+		seats = append(seats, SnapshotSeat{
+			PlayerID:     seat.PlayerID,
+			SeatIdx:      seat.SeatIdx,
+			Chips:        seat.Chips,
+			CurrentBet:   seat.CurrentBet,
+			Status:       seat.Status.String(),
+			HasActed:     seat.HasActed,
+			Disconnected: seat.Disconnected,
+		})
+	}
+
+	pots := make([]SnapshotPot, 0, len(view.Pots))
+	for _, pot := range view.Pots {
+		pots = append(pots, SnapshotPot{
+			Amount:   pot.Amount,
+			Eligible: pot.Eligible,
+		})
+	}
+
+	payload := TableSnapshotPayload{
+		TableID:        view.TableID,
+		HandNumber:     view.HandNumber,
+		State:          view.State.String(),
+		DealerPos:      view.DealerPos,
+		CurrentPos:     view.CurrentPos,
+		MinBet:         view.MinBet,
+		Seats:          seats,
+		Pots:           pots,
+		CommunityCards: cardStrings(view.CommunityCards),
+		HoleCards:      cardStrings(view.HoleCards),
+	}
+
+	// 当前行动者与剩余时间（无人需行动时不包含）
+	if view.CurrentPlayerID != "" {
+		remaining := view.ActionDeadline.Sub(now)
+		if remaining < 0 {
+			remaining = 0
+		}
+		payload.CurrentPlayerID = view.CurrentPlayerID
+		payload.Deadline = view.ActionDeadline.Unix()
+		payload.RemainingMs = remaining.Milliseconds()
+	}
+
+	return payload
+}
+
+// cardStrings 将牌转换为字串（空切片输出 []）
+func cardStrings(cards []domain.Card) []string {
+	result := make([]string, 0, len(cards))
+	for _, card := range cards {
+		result = append(result, card.String())
+	}
+	return result
+}
+
+// handleResume 处理重连后的补发请求：补发 last_seq 之后遗漏的消息，最后回传 RESUMED
+// 遗漏的消息已不在缓冲中时改送完整牌桌状态（full_resync）
+func (h *MessageHandler) handleResume(playerID uuid.UUID, req Request) {
+	log := h.requestLogger(playerID, req)
+	session, exists := h.sessionManager.GetSession(playerID)
+	if !exists {
+		h.sendError(playerID, req.TraceID, "no_session", "No active session")
+		return
+	}
+
+	session.markResumed()
+	replayed, seq, ok := session.Resume(req.LastSeq)
+	h.sendResponse(playerID, req.TraceID, TypeResumed, ResumedPayload{
+		LastSeq:    seq,
+		Replayed:   replayed,
+		FullResync: !ok,
+	})
+
+	if !ok {
+		log.Info("resume window exceeded, sending full snapshot",
+			zap.Uint64("client_seq", req.LastSeq),
+			zap.Uint64("server_seq", seq),
+		)
+		if tableID := session.GetTableID(); tableID != "" {
+			h.SendTableSnapshot(playerID, req.TraceID, tableID)
+		}
+		return
+	}
+
+	log.Debug("session resumed",
+		zap.Uint64("client_seq", req.LastSeq),
+		zap.Int("replayed", replayed),
+	)
+}
+
+// ScheduleResyncFallback 重连后等待客户端 RESUME；宽限期内未收到时推送完整牌桌状态
+// 已被更新的连接取代时不推送
+func (h *MessageHandler) ScheduleResyncFallback(session *PlayerSession) {
+	time.AfterFunc(h.resumeGrace, func() {
+		current, exists := h.sessionManager.GetSession(session.PlayerID)
+		if !exists || current != session || session.hasResumed() {
+			return
+		}
+		if tableID := session.GetTableID(); tableID != "" {
+			h.SendTableSnapshot(session.PlayerID, "", tableID)
+		}
+	})
+}
+
+// handleAck 处理客户端确认（可选），释放已确认的缓冲消息，不回传响应
+func (h *MessageHandler) handleAck(playerID uuid.UUID, req Request) {
+	if session, exists := h.sessionManager.GetSession(playerID); exists {
+		session.Ack(req.LastSeq)
+	}
+}
+
 // broadcastTableState 广播桌子状态
 func (h *MessageHandler) broadcastTableState(tableID string, table *domain.Table) {
 	// 构建桌子状态快照
@@ -494,68 +779,69 @@ func (h *MessageHandler) broadcastTableState(tableID string, table *domain.Table
 
 	// 广播给桌子上的所有玩家
 	h.sessionManager.BroadcastToTable(tableID, Response{
-		Type:      "TABLE_STATE",
+		Type:      TypeTableState,
 		Payload:   snapshot,
 		Timestamp: time.Now(),
 	})
 }
 
 // buildTableSnapshot 构建桌子状态快照
-func (h *MessageHandler) buildTableSnapshot(table *domain.Table) map[string]interface{} {
-	players := make([]map[string]interface{}, 0)
+func (h *MessageHandler) buildTableSnapshot(table *domain.Table) TableStatePayload {
+	players := make([]TableStatePlayer, 0)
 
 	for _, player := range table.Players {
 		if player != nil && player.SeatIdx >= 0 {
-			players = append(players, map[string]interface{}{
-				"id":          player.ID,
-				"seat_idx":    player.SeatIdx,
-				"chips":       player.Chips,
-				"current_bet": player.CurrentBet,
-				"status":      player.Status,
-				"has_acted":   player.HasActed,
+			players = append(players, TableStatePlayer{
+				ID:         player.ID,
+				SeatIdx:    player.SeatIdx,
+				Chips:      player.Chips,
+				CurrentBet: player.CurrentBet,
+				Status:     int(player.Status),
+				HasActed:   player.HasActed,
 			})
 		}
 	}
 
-	communityCards := make([]string, 0)
-	for _, card := range table.CommunityCards {
-		communityCards = append(communityCards, card.String())
-	}
-
-	return map[string]interface{}{
-		"table_id":        table.ID,
-		"state":           table.State,
-		"players":         players,
-		"community_cards": communityCards,
-		"dealer_pos":      table.DealerPos,
-		"current_pos":     table.CurrentPos,
-		"min_bet":         table.MinBet,
-		"pot_total":       table.Pots.Total(),
+	return TableStatePayload{
+		TableID:        table.ID,
+		State:          int(table.State),
+		Players:        players,
+		CommunityCards: cardStrings(table.CommunityCards),
+		DealerPos:      table.DealerPos,
+		CurrentPos:     table.CurrentPos,
+		MinBet:         table.MinBet,
+		PotTotal:       table.Pots.Total(),
 	}
 }
 
-// sendResponse 发送响应消息
-func (h *MessageHandler) sendResponse(playerID uuid.UUID, msgType string, payload interface{}) {
-	response := Response{
+// sendResponse 发送响应消息，带上触发请求的 trace_id
+func (h *MessageHandler) sendResponse(playerID uuid.UUID, traceID, msgType string, payload interface{}) {
+	h.reply(playerID, Response{
 		Type:      msgType,
 		Payload:   payload,
 		Timestamp: time.Now(),
-	}
-
-	h.sessionManager.SendToPlayer(playerID, response)
+		TraceID:   traceID,
+	})
 }
 
-// sendError 发送错误消息
-func (h *MessageHandler) sendError(playerID uuid.UUID, code, message string) {
-	response := Response{
-		Type: "ERROR",
+// sendError 发送错误消息，带上触发请求的 trace_id（无法解析的请求为空）
+func (h *MessageHandler) sendError(playerID uuid.UUID, traceID, code, message string) {
+	h.reply(playerID, Response{
+		Type: TypeError,
 		Payload: ErrorPayload{
 			Code:    code,
 			Message: message,
 		},
 		Timestamp: time.Now(),
-	}
+		TraceID:   traceID,
+	})
+}
 
+// reply 发送回应并记录到请求去重缓存（重试时重送）
+func (h *MessageHandler) reply(playerID uuid.UUID, response Response) {
+	if response.TraceID != "" {
+		h.requests.record(playerID, response.TraceID, response)
+	}
 	h.sessionManager.SendToPlayer(playerID, response)
 }
 
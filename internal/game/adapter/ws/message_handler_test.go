@@ -0,0 +1,227 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"go.uber.org/zap"
+)
+
+// stubTableProvider 回传固定的牌桌与玩家视角
+type stubTableProvider struct {
+	table  *domain.Table
+	view   *domain.TableView
+	viewer string
+}
+
+func (p *stubTableProvider) GetOrCreateTable(id string) *domain.Table {
+	if p.table != nil {
+		return p.table
+	}
+	return domain.NewTable(id)
+}
+
+func (p *stubTableProvider) TableCurrency(tableID string) string { return "USD" }
+
+func (p *stubTableProvider) PlayerView(tableID, playerID string) (*domain.TableView, error) {
+	p.viewer = playerID
+	return p.view, nil
+}
+
+// TestMessageHandler_GetTableSnapshot 未指定 table_id 时使用所在桌子，回传自己的手牌与当前行动者剩余时间
+func TestMessageHandler_GetTableSnapshot(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+
+	playerID := uuid.New()
+	client := newTestClient(playerID)
+	session := NewPlayerSession(playerID, "alice", client, nil, zap.NewNop())
+	sm.AddSession(session)
+
+	tables := &stubTableProvider{view: &domain.TableView{
+		TableID:         "table-1",
+		State:           domain.StateFlop,
+		CurrentPos:      3,
+		CurrentPlayerID: "bob",
+		ActionDeadline:  time.Now().Add(20 * time.Second),
+		Pots:            []domain.PotView{{Amount: 300, Eligible: []string{playerID.String(), "bob"}}},
+		Seats: []domain.SeatView{
+			{PlayerID: playerID.String(), SeatIdx: 0, Chips: 900, Status: domain.StatusPlaying},
+			{PlayerID: "bob", SeatIdx: 3, Chips: 700, CurrentBet: 50, Status: domain.StatusPlaying, Disconnected: true},
+		},
+		HoleCards: []domain.Card{domain.NewCard(domain.RankA, domain.SuitSpade), domain.NewCard(domain.RankK, domain.SuitSpade)},
+	}}
+	h := NewMessageHandler(sm, tables, nil, zap.NewNop())
+
+	// 不在桌上
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GET_TABLE_SNAPSHOT"}`))
+	if resp := (<-client.send).(Response); resp.Type != "ERROR" || resp.Payload.(ErrorPayload).Code != "not_at_table" {
+		t.Fatalf("expected not_at_table error, got %+v", resp)
+	}
+
+	session.SetTable("table-1", 0)
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GET_TABLE_SNAPSHOT"}`))
+	resp := (<-client.send).(Response)
+	if resp.Type != "TABLE_SNAPSHOT" || tables.viewer != playerID.String() {
+		t.Fatalf("expected TABLE_SNAPSHOT for the player, got %+v", resp)
+	}
+	payload := resp.Payload.(TableSnapshotPayload)
+	if payload.State != "FLOP" || payload.CurrentPlayerID != "bob" {
+		t.Fatalf("unexpected snapshot %+v", payload)
+	}
+	if cards := payload.HoleCards; len(cards) != 2 || cards[0] != "As" {
+		t.Fatalf("expected own hole cards, got %v", cards)
+	}
+	if remaining := payload.RemainingMs; remaining <= 0 || remaining > 20000 {
+		t.Fatalf("unexpected remaining time %d", remaining)
+	}
+	seats := payload.Seats
+	if len(seats) != 2 || seats[1].Status != "PLAYING" || !seats[1].Disconnected || seats[1].CurrentBet != 50 {
+		t.Fatalf("unexpected seats %+v", seats)
+	}
+}
+
+// TestMessageHandler_ResumeAfterReconnect 断线期间的消息写入缓冲，新连接 RESUME 后依序补发
+func TestMessageHandler_ResumeAfterReconnect(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+	h := NewMessageHandler(sm, &stubTableProvider{view: &domain.TableView{TableID: "table-1"}}, nil, zap.NewNop())
+
+	playerID := uuid.New()
+	oldClient := newTestClient(playerID)
+	sm.AddSession(NewPlayerSession(playerID, "alice", oldClient, nil, zap.NewNop()))
+	sm.SendToPlayer(playerID, Response{Type: "HAND_START"})
+	if resp := (<-oldClient.send).(Response); resp.Seq != 1 {
+		t.Fatalf("expected seq 1, got %d", resp.Seq)
+	}
+
+	// 断线期间的消息只写入缓冲
+	sm.HandleDisconnect(playerID, oldClient)
+	if sm.SendToPlayer(playerID, Response{Type: "YOUR_TURN"}) {
+		t.Fatal("expected player to be offline")
+	}
+	sm.SendToPlayer(playerID, Response{Type: "PLAYER_ACTION"})
+	if len(oldClient.send) != 0 {
+		t.Fatal("expected nothing to be delivered while disconnected")
+	}
+
+	newClient := newTestClient(playerID)
+	sm.AddSession(NewPlayerSession(playerID, "alice", newClient, nil, zap.NewNop()))
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"RESUME","last_seq":1}`))
+
+	for i, expected := range []string{"YOUR_TURN", "PLAYER_ACTION", "RESUMED"} {
+		resp := (<-newClient.send).(Response)
+		if resp.Type != expected || resp.Seq != uint64(i+2) {
+			t.Fatalf("expected %s with seq %d, got %s seq %d", expected, i+2, resp.Type, resp.Seq)
+		}
+		if expected == "RESUMED" {
+			if payload := resp.Payload.(ResumedPayload); payload.Replayed != 2 || payload.FullResync {
+				t.Fatalf("unexpected RESUMED payload %+v", payload)
+			}
+		}
+	}
+
+	// 确认后过旧的序号改送完整牌桌状态
+	session, _ := sm.GetSession(playerID)
+	session.SetTable("table-1", 0)
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"ACK","last_seq":4}`))
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"RESUME","last_seq":1}`))
+	if resp := (<-newClient.send).(Response); resp.Type != "RESUMED" || !resp.Payload.(ResumedPayload).FullResync {
+		t.Fatalf("expected full resync, got %+v", resp)
+	}
+	if resp := (<-newClient.send).(Response); resp.Type != "TABLE_SNAPSHOT" {
+		t.Fatalf("expected TABLE_SNAPSHOT, got %s", resp.Type)
+	}
+}
+
+// TestMessageHandler_ResyncFallbackOnlyWithoutResume 重连后有 RESUME 时不推送牌桌状态，未 RESUME 时宽限期后推送
+func TestMessageHandler_ResyncFallbackOnlyWithoutResume(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+	h := NewMessageHandler(sm, &stubTableProvider{view: &domain.TableView{TableID: "table-1"}}, nil, zap.NewNop())
+	h.resumeGrace = 20 * time.Millisecond
+
+	playerID := uuid.New()
+	reconnect := func() (*PlayerSession, *Client) {
+		client := newTestClient(playerID)
+		session := NewPlayerSession(playerID, "alice", client, nil, zap.NewNop())
+		sm.AddSession(session)
+		session.SetTable("table-1", 0)
+		h.ScheduleResyncFallback(session)
+		return session, client
+	}
+
+	// 客户端 RESUME：只收到 RESUMED，不再推送完整牌桌状态
+	_, client := reconnect()
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"RESUME","last_seq":0}`))
+	if resp := (<-client.send).(Response); resp.Type != "RESUMED" {
+		t.Fatalf("expected RESUMED, got %s", resp.Type)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(client.send) != 0 {
+		t.Fatalf("expected no snapshot after RESUME, got %+v", <-client.send)
+	}
+
+	// 客户端未 RESUME：宽限期后推送完整牌桌状态
+	_, client = reconnect()
+	select {
+	case msg := <-client.send:
+		if resp := msg.(Response); resp.Type != "TABLE_SNAPSHOT" {
+			t.Fatalf("expected TABLE_SNAPSHOT, got %s", resp.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected fallback TABLE_SNAPSHOT without RESUME")
+	}
+}
+
+// TestMessageHandler_TraceIDEchoAndDedup 回应与错误带上请求的 trace_id；相同 trace_id 重试不会重复执行
+func TestMessageHandler_TraceIDEchoAndDedup(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+
+	table := domain.NewTable("table-1")
+	h := NewMessageHandler(sm, &stubTableProvider{table: table, view: &domain.TableView{TableID: "table-1"}}, nil, zap.NewNop())
+
+	playerID := uuid.New()
+	client := newTestClient(playerID)
+	session := NewPlayerSession(playerID, "alice", client, nil, zap.NewNop())
+	sm.AddSession(session)
+	session.SetTable("table-1", 0)
+
+	// 错误回应带上 trace_id，重试时重送同一个错误
+	for i := 0; i < 2; i++ {
+		h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"BUY_IN","amount":0,"trace_id":"req-1"}`))
+		resp := (<-client.send).(Response)
+		if resp.Type != "ERROR" || resp.TraceID != "req-1" || resp.Payload.(ErrorPayload).Code != "invalid_amount" {
+			t.Fatalf("expected invalid_amount error for req-1, got %+v", resp)
+		}
+	}
+
+	// 游戏动作只送进牌桌一次，命令带上 trace_id
+	commands := make(chan domain.PlayerAction, 4)
+	go func() {
+		for cmd := range table.ActionCh {
+			commands <- cmd
+			cmd.ResultCh <- domain.ActionResult{}
+		}
+	}()
+	defer close(table.ActionCh)
+
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GAME_ACTION","game_action":"CALL","trace_id":"req-2"}`))
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GAME_ACTION","game_action":"CALL","trace_id":"req-2"}`))
+	if cmd := <-commands; cmd.TraceID != "req-2" || cmd.Type != domain.ActionCall {
+		t.Fatalf("expected CALL with trace id, got %+v", cmd)
+	}
+	if len(commands) != 0 {
+		t.Fatal("expected retried action not to reach the table")
+	}
+
+	// 查询类请求不去重
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GET_TABLE_SNAPSHOT","trace_id":"req-3"}`))
+	h.HandleMessage(playerID, JSONCodec, []byte(`{"action":"GET_TABLE_SNAPSHOT","trace_id":"req-3"}`))
+	if len(client.send) != 2 {
+		t.Fatalf("expected both snapshot requests to be answered, got %d", len(client.send))
+	}
+}
@@ -0,0 +1,77 @@
+package ws
+
+import "sync"
+
+// outboxSize 每个会话保留的最近消息数（断线期间超过此数量时改为重送完整牌桌状态）
+const outboxSize = 256
+
+// outbox 会话的出站消息缓冲：为每则 Response 编上递增序号并保留最近的消息，
+// 客户端重连后以 RESUME 带上最后收到的序号，补发之后遗漏的消息
+type outbox struct {
+	mu       sync.Mutex
+	seq      uint64     // 最后一则消息的序号
+	messages []Response // 尚未确认的最近消息（序号递增）
+	size     int
+}
+
+func newOutbox(size int) *outbox {
+	return &outbox{size: size}
+}
+
+// push 编上序号并缓冲，deliver 在锁内执行以保证送出顺序与序号一致
+func (o *outbox) push(resp Response, deliver func(Response)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	resp.Seq = o.seq
+	o.messages = append(o.messages, resp)
+	if len(o.messages) > o.size {
+		o.messages = o.messages[len(o.messages)-o.size:]
+	}
+	deliver(resp)
+}
+
+// replay 补发 lastSeq 之后的消息，回传补发数量与目前的序号
+// lastSeq 之后的消息已不在缓冲中（或序号超前）时回传 ok=false，由调用方改送完整状态
+func (o *outbox) replay(lastSeq uint64, deliver func(Response)) (replayed int, seq uint64, ok bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if lastSeq > o.seq {
+		return 0, o.seq, false
+	}
+	if lastSeq == o.seq {
+		return 0, o.seq, true
+	}
+	if len(o.messages) == 0 || o.messages[0].Seq > lastSeq+1 {
+		return 0, o.seq, false
+	}
+
+	for _, resp := range o.messages {
+		if resp.Seq > lastSeq {
+			deliver(resp)
+			replayed++
+		}
+	}
+	return replayed, o.seq, true
+}
+
+// ack 客户端确认已收到 seq 以前的消息，释放缓冲
+func (o *outbox) ack(seq uint64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := 0
+	for i < len(o.messages) && o.messages[i].Seq <= seq {
+		i++
+	}
+	o.messages = o.messages[i:]
+}
+
+// lastSeq 目前的序号
+func (o *outbox) lastSeq() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.seq
+}
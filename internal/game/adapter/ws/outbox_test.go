@@ -0,0 +1,43 @@
+package ws
+
+import "testing"
+
+// TestOutbox_ReplayAndAck 依序号补发遗漏的消息；超出缓冲或已确认的范围需改送完整状态
+func TestOutbox_ReplayAndAck(t *testing.T) {
+	o := newOutbox(3)
+	var delivered []uint64
+	collect := func(resp Response) { delivered = append(delivered, resp.Seq) }
+
+	for i := 0; i < 5; i++ {
+		o.push(Response{Type: "EVENT"}, collect)
+	}
+	if len(delivered) != 5 || delivered[4] != 5 || o.lastSeq() != 5 {
+		t.Fatalf("expected sequential seqs 1..5, got %v", delivered)
+	}
+
+	// 缓冲保留 3..5
+	delivered = nil
+	replayed, seq, ok := o.replay(2, collect)
+	if !ok || replayed != 3 || seq != 5 || delivered[0] != 3 || delivered[2] != 5 {
+		t.Fatalf("expected replay of 3..5, got ok=%v replayed=%d delivered=%v", ok, replayed, delivered)
+	}
+	if _, _, ok := o.replay(1, collect); ok {
+		t.Fatal("expected seq 2 to be out of the buffer")
+	}
+	if replayed, _, ok := o.replay(5, collect); !ok || replayed != 0 {
+		t.Fatalf("expected nothing to replay when up to date, got ok=%v replayed=%d", ok, replayed)
+	}
+	if _, _, ok := o.replay(9, collect); ok {
+		t.Fatal("expected seq ahead of server to require full resync")
+	}
+
+	// 确认后释放缓冲，已确认范围之前无法补发
+	o.ack(4)
+	delivered = nil
+	if replayed, _, ok := o.replay(4, collect); !ok || replayed != 1 || delivered[0] != 5 {
+		t.Fatalf("expected replay of 5 after ack, got ok=%v delivered=%v", ok, delivered)
+	}
+	if _, _, ok := o.replay(3, collect); ok {
+		t.Fatal("expected acked messages to be released")
+	}
+}
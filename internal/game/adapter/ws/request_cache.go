@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// requestCacheTTL 重复请求的判定窗口（客户端在此时间内以相同 trace_id 重试视为同一请求）
+const requestCacheTTL = 5 * time.Minute
+
+// dedupedActions 会改变状态、需要去重的动作（查询类请求重试时重新执行即可）
+var dedupedActions = map[string]bool{
+	ActionBuyIn:      true,
+	ActionCashOut:    true,
+	ActionJoinTable:  true,
+	ActionLeaveTable: true,
+	ActionSitDown:    true,
+	ActionStandUp:    true,
+	ActionGameAction: true,
+}
+
+// requestCache 以 (玩家, trace_id) 记录近期请求与其回应
+// 客户端因网络中断重试时，已处理的请求直接重送原回应，不会重复买入或重复下注
+type requestCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]*cachedRequest
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type cachedRequest struct {
+	replies   []Response
+	createdAt time.Time
+}
+
+func newRequestCache(ttl time.Duration) *requestCache {
+	return &requestCache{
+		ttl:     ttl,
+		entries: make(map[string]*cachedRequest),
+		now:     time.Now,
+	}
+}
+
+func requestKey(playerID uuid.UUID, traceID string) string {
+	return playerID.String() + ":" + traceID
+}
+
+// begin 登记请求；已登记过时回传 duplicate=true 与目前已送出的回应（处理中的请求为空）
+func (c *requestCache) begin(playerID uuid.UUID, traceID string) (replies []Response, duplicate bool) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep(now)
+
+	key := requestKey(playerID, traceID)
+	if entry, exists := c.entries[key]; exists && now.Sub(entry.createdAt) < c.ttl {
+		return append([]Response(nil), entry.replies...), true
+	}
+	c.entries[key] = &cachedRequest{createdAt: now}
+	return nil, false
+}
+
+// record 记录请求的回应（未登记的请求略过）
+func (c *requestCache) record(playerID uuid.UUID, traceID string, resp Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, exists := c.entries[requestKey(playerID, traceID)]; exists {
+		entry.replies = append(entry.replies, resp)
+	}
+}
+
+// sweep 每分钟清理一次过期的请求
+func (c *requestCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if now.Sub(entry.createdAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/auth"
 	"github.com/shinjuwu/TheNuts/internal/game/service"
 	"go.uber.org/zap"
 )
@@ -32,6 +33,17 @@ type PlayerSession struct {
 	LastActivityAt time.Time
 	IsConnected    bool
 
+	// 请求来源（建立连接时的 IP 与 User-Agent，写入审计日志）
+	IPAddress string
+	UserAgent string
+
+	// 取得票券时的登入会话，会话撤销时断开连接
+	AuthSessionID string
+
+	// 出站消息缓冲（带序号，断线重连后以 RESUME 补发）
+	outbox  *outbox
+	resumed bool // 本连接已发出 RESUME，不需再推送完整牌桌状态
+
 	// 同步锁
 	mu sync.RWMutex
 }
@@ -53,6 +65,15 @@ func NewPlayerSession(
 		ConnectedAt:    time.Now(),
 		LastActivityAt: time.Now(),
 		IsConnected:    true,
+		outbox:         newOutbox(outboxSize),
+	}
+}
+
+// RequestSource 回传玩家操作的请求来源
+func (s *PlayerSession) RequestSource() *service.RequestSource {
+	return &service.RequestSource{
+		IPAddress: s.IPAddress,
+		UserAgent: s.UserAgent,
 	}
 }
 
@@ -117,16 +138,81 @@ func (s *PlayerSession) Disconnect() {
 	s.IsConnected = false
 }
 
+// connected 连接是否在线（线程安全）
+func (s *PlayerSession) connected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.IsConnected
+}
+
+// Kick 发送通知后强制断开连接
+func (s *PlayerSession) Kick(msg interface{}, code int, reason string) {
+	s.mu.Lock()
+	if !s.IsConnected {
+		s.mu.Unlock()
+		return
+	}
+	s.IsConnected = false
+	s.mu.Unlock()
+
+	if s.Client != nil {
+		s.Client.closeWith(msg, code, reason)
+	}
+}
+
 // SendMessage 发送消息给玩家
+// Response 会编上序号并写入缓冲，断线期间只写入缓冲，重连后由 RESUME 补发
 func (s *PlayerSession) SendMessage(msg interface{}) {
-	if s.Client != nil {
-		select {
-		case s.Client.send <- msg:
-		default:
-			s.Logger.Warn("failed to send message: channel full",
-				zap.String("player_id", s.PlayerID.String()),
-			)
-		}
+	resp, ok := msg.(Response)
+	if !ok {
+		s.deliver(msg)
+		return
+	}
+	s.outbox.push(resp, func(resp Response) { s.deliver(resp) })
+}
+
+// Resume 补发 lastSeq 之后遗漏的消息，回传补发数量与目前的序号
+// 遗漏的消息已不在缓冲中时回传 ok=false，需改送完整牌桌状态
+func (s *PlayerSession) Resume(lastSeq uint64) (replayed int, seq uint64, ok bool) {
+	return s.outbox.replay(lastSeq, func(resp Response) { s.deliver(resp) })
+}
+
+// markResumed 记录本连接已发出 RESUME
+func (s *PlayerSession) markResumed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resumed = true
+}
+
+// hasResumed 本连接是否已发出 RESUME
+func (s *PlayerSession) hasResumed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resumed
+}
+
+// Ack 客户端确认已收到 seq 以前的消息
+func (s *PlayerSession) Ack(seq uint64) {
+	s.outbox.ack(seq)
+}
+
+// LastSeq 最后一则已发送消息的序号
+func (s *PlayerSession) LastSeq() uint64 {
+	return s.outbox.lastSeq()
+}
+
+// deliver 放入连接的发送队列（未连接时略过）
+func (s *PlayerSession) deliver(msg interface{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.IsConnected || s.Client == nil {
+		return
+	}
+	if !s.Client.trySend(msg) {
+		s.Logger.Warn("failed to send message: channel full or closed",
+			zap.String("player_id", s.PlayerID.String()),
+		)
 	}
 }
 
@@ -163,6 +249,12 @@ func (s *PlayerSession) GetSnapshot() SessionSnapshot {
 	}
 }
 
+// 强制断线的 WebSocket 关闭码（4000-4999 为应用自定义）
+const (
+	CloseSessionRevoked  = 4001 // 登入会话已撤销（登出、登出所有装置、修改密码等）
+	CloseSessionReplaced = 4002 // 同一玩家的新连接接管了会话
+)
+
 // TableNotifier 通知牌桌玩家斷線/重連
 type TableNotifier interface {
 	NotifyDisconnect(playerID, tableID string)
@@ -179,6 +271,9 @@ type SessionManager struct {
 	// 牌桌通知
 	tableNotifier TableNotifier
 
+	// 登入会话验证（定期检查，处理在其他实例上撤销的会话）
+	authSessions auth.SessionValidator
+
 	// 清理配置
 	cleanupInterval time.Duration
 	sessionTimeout  time.Duration
@@ -210,34 +305,111 @@ func (sm *SessionManager) SetTableNotifier(notifier TableNotifier) {
 	sm.tableNotifier = notifier
 }
 
+// SetAuthSessionValidator 设定登入会话验证器
+func (sm *SessionManager) SetAuthSessionValidator(validator auth.SessionValidator) {
+	sm.authSessions = validator
+}
+
+// SessionRevoked 登入会话被撤销时断开对应的连接（实现 auth.RevocationListener）
+func (sm *SessionManager) SessionRevoked(sessionID string) {
+	for _, session := range sm.GetActiveSessions() {
+		if session.AuthSessionID == sessionID {
+			sm.kickRevoked(session)
+		}
+	}
+}
+
+// PlayerSessionsRevoked 玩家所有登入会话被撤销时断开玩家的连接（实现 auth.RevocationListener）
+func (sm *SessionManager) PlayerSessionsRevoked(playerID uuid.UUID) {
+	if session, exists := sm.GetSession(playerID); exists && session.connected() {
+		sm.kickRevoked(session)
+	}
+}
+
+// kickRevoked 通知客户端登入会话已撤销并断开连接（座位保留，视同断线）
+func (sm *SessionManager) kickRevoked(session *PlayerSession) {
+	sm.logger.Info("disconnecting client with revoked auth session",
+		zap.String("player_id", session.PlayerID.String()),
+		zap.String("auth_session_id", session.AuthSessionID),
+	)
+	session.Kick(Response{
+		Type: TypeSessionRevoked,
+		Payload: ErrorPayload{
+			Code:    "session_revoked",
+			Message: "Your login session has been revoked, please login again",
+		},
+		Timestamp: time.Now(),
+	}, CloseSessionRevoked, "session revoked")
+}
+
+// checkAuthSessions 断开登入会话已失效的连接
+func (sm *SessionManager) checkAuthSessions() {
+	if sm.authSessions == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, session := range sm.GetActiveSessions() {
+		if session.AuthSessionID == "" {
+			continue
+		}
+		active, err := sm.authSessions.IsSessionActive(ctx, session.AuthSessionID)
+		if err != nil {
+			sm.logger.Warn("failed to check auth session",
+				zap.String("player_id", session.PlayerID.String()),
+				zap.Error(err),
+			)
+			return
+		}
+		if !active {
+			sm.kickRevoked(session)
+		}
+	}
+}
+
 // AddSession 添加新会话
+// 每位玩家只保留一条连接：已有连接时由新连接接管会话（保留座位、游戏会话与筹码），
+// 旧连接收到 SESSION_REPLACED 后关闭，牌桌收到重连而非断线
 func (sm *SessionManager) AddSession(session *PlayerSession) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	// 如果已有会话，先清理旧会话
 	if oldSession, exists := sm.sessions[session.PlayerID]; exists {
-		sm.logger.Info("replacing existing session (possible reconnect)",
+		oldSession.mu.RLock()
+		wasConnected := oldSession.IsConnected
+		tableID := oldSession.CurrentTableID
+		session.CurrentTableID = oldSession.CurrentTableID
+		session.SeatNo = oldSession.SeatNo
+		session.IsSeated = oldSession.IsSeated
+		session.GameSessionID = oldSession.GameSessionID
+		session.Chips = oldSession.Chips
+		session.outbox = oldSession.outbox // 延续消息序号，新连接可 RESUME 补发断线期间的消息
+		oldSession.mu.RUnlock()
+
+		sm.logger.Info("new connection takes over existing session",
 			zap.String("player_id", session.PlayerID.String()),
-			zap.Bool("old_connected", oldSession.IsConnected),
-			zap.String("old_table", oldSession.CurrentTableID),
+			zap.Bool("old_connected", wasConnected),
+			zap.String("table_id", tableID),
 		)
 
-		// 如果舊 session 在牌桌上，繼承狀態到新 session
-		if oldSession.CurrentTableID != "" {
-			session.CurrentTableID = oldSession.CurrentTableID
-			session.SeatNo = oldSession.SeatNo
-			session.IsSeated = oldSession.IsSeated
-			session.GameSessionID = oldSession.GameSessionID
-			session.Chips = oldSession.Chips
-
-			// 通知牌桌玩家已重連
-			if sm.tableNotifier != nil {
-				sm.tableNotifier.NotifyReconnect(session.PlayerID.String(), oldSession.CurrentTableID)
-			}
+		// 旧连接仍在线：通知被取代后关闭
+		if wasConnected {
+			oldSession.Kick(Response{
+				Type: TypeSessionReplaced,
+				Payload: ErrorPayload{
+					Code:    "session_replaced",
+					Message: "Your session was taken over by a new connection",
+				},
+				Timestamp: time.Now(),
+			}, CloseSessionReplaced, "session replaced")
 		}
 
-		oldSession.Disconnect()
+		// 通知牌桌玩家已重連
+		if tableID != "" && sm.tableNotifier != nil {
+			sm.tableNotifier.NotifyReconnect(session.PlayerID.String(), tableID)
+		}
 	}
 
 	sm.sessions[session.PlayerID] = session
@@ -274,9 +446,10 @@ func (sm *SessionManager) RemoveSession(playerID uuid.UUID) {
 }
 
 // HandleDisconnect 处理玩家断开连接
-func (sm *SessionManager) HandleDisconnect(playerID uuid.UUID) error {
+// 已被新连接取代的旧连接断开时不影响会话
+func (sm *SessionManager) HandleDisconnect(playerID uuid.UUID, client *Client) error {
 	session, exists := sm.GetSession(playerID)
-	if !exists {
+	if !exists || session.Client != client {
 		return nil
 	}
 
@@ -297,14 +470,14 @@ func (sm *SessionManager) HandleDisconnect(playerID uuid.UUID) error {
 	return nil
 }
 
-// BroadcastToTable 向桌子内所有玩家广播消息
+// BroadcastToTable 向桌子内所有玩家广播消息（断线中的玩家写入缓冲，重连后补发）
 func (sm *SessionManager) BroadcastToTable(tableID string, message interface{}) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
 	count := 0
 	for _, session := range sm.sessions {
-		if session.GetTableID() == tableID && session.IsConnected {
+		if session.GetTableID() == tableID {
 			session.SendMessage(message)
 			count++
 		}
@@ -316,15 +489,16 @@ func (sm *SessionManager) BroadcastToTable(tableID string, message interface{})
 	)
 }
 
-// SendToPlayer 向特定玩家发送消息
+// SendToPlayer 向特定玩家发送消息，回传玩家目前是否在线
+// 断线中的玩家仍写入缓冲，重连后可由 RESUME 补发
 func (sm *SessionManager) SendToPlayer(playerID uuid.UUID, message interface{}) bool {
 	session, exists := sm.GetSession(playerID)
-	if !exists || !session.IsConnected {
+	if !exists {
 		return false
 	}
 
 	session.SendMessage(message)
-	return true
+	return session.connected()
 }
 
 // GetActiveSessions 获取所有活跃会话
@@ -334,7 +508,7 @@ func (sm *SessionManager) GetActiveSessions() []*PlayerSession {
 
 	sessions := make([]*PlayerSession, 0, len(sm.sessions))
 	for _, session := range sm.sessions {
-		if session.IsConnected {
+		if session.connected() {
 			sessions = append(sessions, session)
 		}
 	}
@@ -356,7 +530,7 @@ func (sm *SessionManager) GetActiveSessionCount() int {
 
 	count := 0
 	for _, session := range sm.sessions {
-		if session.IsConnected {
+		if session.connected() {
 			count++
 		}
 	}
@@ -372,6 +546,7 @@ func (sm *SessionManager) cleanupLoop() {
 		select {
 		case <-ticker.C:
 			sm.cleanupExpiredSessions()
+			sm.checkAuthSessions()
 		case <-sm.stopCh:
 			return
 		}
@@ -0,0 +1,111 @@
+package ws
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordingNotifier 記錄牌桌斷線/重連通知
+type recordingNotifier struct {
+	disconnects []string
+	reconnects  []string
+}
+
+func (n *recordingNotifier) NotifyDisconnect(playerID, tableID string) {
+	n.disconnects = append(n.disconnects, tableID)
+}
+
+func (n *recordingNotifier) NotifyReconnect(playerID, tableID string) {
+	n.reconnects = append(n.reconnects, tableID)
+}
+
+func newTestClient(playerID uuid.UUID) *Client {
+	return &Client{PlayerID: playerID.String(), send: make(chan interface{}, 8), logger: zap.NewNop()}
+}
+
+// TestSessionManager_NewConnectionTakesOver 新連線接管會話：保留座位與籌碼，舊連線收到 SESSION_REPLACED 後關閉，牌桌收到重連
+func TestSessionManager_NewConnectionTakesOver(t *testing.T) {
+	sm := NewSessionManager(nil, zap.NewNop())
+	defer sm.Stop()
+	notifier := &recordingNotifier{}
+	sm.SetTableNotifier(notifier)
+
+	playerID := uuid.New()
+	gameSessionID := uuid.New()
+	oldClient := newTestClient(playerID)
+	oldSession := NewPlayerSession(playerID, "alice", oldClient, nil, zap.NewNop())
+	sm.AddSession(oldSession)
+	oldSession.SetGameSession(gameSessionID, 1500)
+	oldSession.SetTable("table-1", 3)
+
+	newClient := newTestClient(playerID)
+	newSession := NewPlayerSession(playerID, "alice", newClient, nil, zap.NewNop())
+	sm.AddSession(newSession)
+
+	snapshot := newSession.GetSnapshot()
+	if snapshot.CurrentTableID != "table-1" || snapshot.SeatNo != 3 || !snapshot.IsSeated ||
+		snapshot.Chips != 1500 || snapshot.GameSessionID != gameSessionID.String() {
+		t.Fatalf("expected new session to inherit seat and chips, got %+v", snapshot)
+	}
+	if current, _ := sm.GetSession(playerID); current != newSession {
+		t.Fatal("expected new session to be current")
+	}
+
+	// 舊連線：先收到 SESSION_REPLACED，再收到關閉訊框，之後不再處理訊息
+	if resp, ok := (<-oldClient.send).(Response); !ok || resp.Type != "SESSION_REPLACED" {
+		t.Fatalf("expected SESSION_REPLACED, got %+v", resp)
+	}
+	if frame, ok := (<-oldClient.send).(closeFrame); !ok || frame.code != CloseSessionReplaced {
+		t.Fatalf("expected close frame %d, got %+v", CloseSessionReplaced, frame)
+	}
+	if !oldClient.closing.Load() || oldSession.IsConnected {
+		t.Fatal("expected old connection to be closing")
+	}
+	if len(notifier.reconnects) != 1 || notifier.reconnects[0] != "table-1" {
+		t.Fatalf("expected table reconnect, got %v", notifier.reconnects)
+	}
+
+	// 舊連線斷開不影響新會話，牌桌不會收到斷線
+	sm.HandleDisconnect(playerID, oldClient)
+	if !newSession.IsConnected || len(notifier.disconnects) != 0 {
+		t.Fatalf("expected replaced connection close to be ignored, disconnects=%v", notifier.disconnects)
+	}
+
+	sm.HandleDisconnect(playerID, newClient)
+	if newSession.IsConnected || len(notifier.disconnects) != 1 {
+		t.Fatalf("expected current connection close to notify table, disconnects=%v", notifier.disconnects)
+	}
+}
+
+// TestPlayerSession_KickRacesHubClose 踢出與 Hub 關閉 send 同時發生時不寫入已關閉的 channel
+func TestPlayerSession_KickRacesHubClose(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		playerID := uuid.New()
+		client := newTestClient(playerID)
+		session := NewPlayerSession(playerID, "alice", client, nil, zap.NewNop())
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			session.Kick(Response{Type: TypeSessionRevoked}, CloseSessionRevoked, "session revoked")
+		}()
+		go func() {
+			defer wg.Done()
+			session.SendMessage(Response{Type: "TABLE_STATE"})
+		}()
+		go func() {
+			defer wg.Done()
+			client.closeSend()
+		}()
+		wg.Wait()
+
+		// 關閉後的寫入被略過
+		if client.trySend(Response{Type: "TABLE_STATE"}) {
+			t.Fatal("expected send after close to be dropped")
+		}
+	}
+}
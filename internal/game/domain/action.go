@@ -19,6 +19,8 @@ const (
 	ActionStandUp    // 站起
 	ActionDisconnect // 玩家斷線
 	ActionReconnect  // 玩家重連
+	ActionSnapshot   // 取得牌桌快照（崩潰復原用）
+	ActionPlayerView // 取得玩家視角的牌桌狀態（重連同步用）
 )
 
 // String 回傳動作類型的字串表示
@@ -36,11 +38,37 @@ func (a ActionType) String() string {
 		return "RAISE"
 	case ActionAllIn:
 		return "ALL_IN"
+	case ActionJoinTable:
+		return "JOIN_TABLE"
+	case ActionLeaveTable:
+		return "LEAVE_TABLE"
+	case ActionSitDown:
+		return "SIT_DOWN"
+	case ActionStandUp:
+		return "STAND_UP"
+	case ActionDisconnect:
+		return "DISCONNECT"
+	case ActionReconnect:
+		return "RECONNECT"
+	case ActionSnapshot:
+		return "SNAPSHOT"
+	case ActionPlayerView:
+		return "PLAYER_VIEW"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// ParseActionType 解析遊戲動作字串（String 的反向操作，僅限遊戲動作）
+func ParseActionType(s string) (ActionType, bool) {
+	for _, a := range []ActionType{ActionFold, ActionCheck, ActionCall, ActionBet, ActionRaise, ActionAllIn} {
+		if a.String() == s {
+			return a, true
+		}
+	}
+	return 0, false
+}
+
 // PlayerAction 是核心邏輯使用的標準動作結構 (Royal Language)
 // 它不依賴任何外部標籤 (如 json tag)
 type PlayerAction struct {
@@ -52,6 +80,9 @@ type PlayerAction struct {
 	Player  *Player // 要加入的玩家（僅 ActionJoinTable 使用）
 	SeatIdx int     // 目標座位（僅 ActionJoinTable 使用）
 
+	// 追蹤 ID（來自客戶端請求，寫入日誌以串連整個處理過程）
+	TraceID string
+
 	// 同步回應通道（nil 表示 fire-and-forget）
 	ResultCh chan<- ActionResult
 }
@@ -59,5 +90,8 @@ type PlayerAction struct {
 // ActionResult 命令執行結果
 type ActionResult struct {
 	Err       error
-	WasInHand bool // StandUp 回傳用
+	WasInHand bool           // StandUp 回傳用
+	Chips     int64          // LeaveTable 回傳用：離座時的剩餘籌碼（不含本手已下注）
+	Snapshot  *TableSnapshot // Snapshot 回傳用
+	View      *TableView     // PlayerView 回傳用
 }
@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 /****************************************************************************************
  * Card Encoding Scheme (inspired by Cactus Kev / 2+2)
@@ -76,3 +79,16 @@ func (c Card) String() string {
 	}
 	return fmt.Sprintf("%c%c", rankChars[r], suitChars[s])
 }
+
+// ParseCard 解析如 "Ah", "Ks" 的字串（String 的反向操作）
+func ParseCard(s string) (Card, error) {
+	if len(s) != 2 {
+		return 0, fmt.Errorf("invalid card %q", s)
+	}
+	rank := strings.IndexByte(rankChars, s[0])
+	suit := strings.IndexByte(suitChars, s[1])
+	if rank < 0 || suit < 0 {
+		return 0, fmt.Errorf("invalid card %q", s)
+	}
+	return NewCard(rank, suit), nil
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HandRecord 一手牌的完整紀錄（由 Table 在 Run() goroutine 中累積）
+// 手牌結束後存放於 Table.LastHand，供 onHandComplete 回調讀取並持久化。
+// 紀錄完成後不再被 Table 修改，可安全交給其他 goroutine 使用。
+type HandRecord struct {
+	HandID     string
+	HandNumber int
+	TableID    string
+	DealerPos  int
+	SmallBlind int64
+	BigBlind   int64
+
+	Players []*HandPlayerRecord
+	Actions []HandActionRecord
+	Deck    []Card // 發牌前的完整牌序（用於重播）
+	Board   []Card
+	Pots    []HandPotRecord
+	Winners []HandWinnerRecord
+	Rake    int64 // 本手抽水（已從底池扣除）
+
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// HandPlayerRecord 參與手牌的玩家紀錄
+type HandPlayerRecord struct {
+	PlayerID      string
+	SessionID     string // 開局時玩家的遊戲會話 ID（結算時只記入此會話）
+	SeatIdx       int
+	StartingChips int64 // 下盲注前的籌碼
+	EndingChips   int64 // 派彩後的籌碼
+	HoleCards     []Card
+	ShowedDown    bool // 是否在攤牌時亮牌
+}
+
+// NetResult 回傳玩家本手牌的淨輸贏
+func (p *HandPlayerRecord) NetResult() int64 {
+	return p.EndingChips - p.StartingChips
+}
+
+// HandActionRecord 單一動作紀錄
+type HandActionRecord struct {
+	PlayerID  string
+	Street    string // PREFLOP, FLOP, TURN, RIVER
+	Action    string // SMALL_BLIND, BIG_BLIND, FOLD, CHECK, CALL, BET, RAISE, ALL_IN
+	Amount    int64  // 本次動作投入的籌碼
+	TotalBet  int64  // 動作後該玩家本街的累計下注
+	Reason    string // 非輪次內的棄牌來源：STAND_UP（站起）或 LEAVE_TABLE（離桌），一般動作為空
+	Timestamp time.Time
+}
+
+// HandPotRecord 底池紀錄
+type HandPotRecord struct {
+	Amount       int64
+	Contributors []string
+}
+
+// HandWinnerRecord 贏家紀錄
+type HandWinnerRecord struct {
+	PlayerID string
+	Amount   int64
+}
+
+// Player 依 ID 查找玩家紀錄
+func (r *HandRecord) Player(playerID string) *HandPlayerRecord {
+	for _, p := range r.Players {
+		if p.PlayerID == playerID {
+			return p
+		}
+	}
+	return nil
+}
+
+// Duration 回傳手牌持續時間
+func (r *HandRecord) Duration() time.Duration {
+	if r.EndedAt.IsZero() {
+		return 0
+	}
+	return r.EndedAt.Sub(r.StartedAt)
+}
+
+// String 回傳街道名稱（用於紀錄與事件）
+func (s GameState) String() string {
+	switch s {
+	case StateIdle:
+		return "IDLE"
+	case StatePreFlop:
+		return "PREFLOP"
+	case StateFlop:
+		return "FLOP"
+	case StateTurn:
+		return "TURN"
+	case StateRiver:
+		return "RIVER"
+	case StateShowdown:
+		return "SHOWDOWN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// beginHandRecord 在發完手牌後建立新的手牌紀錄
+func (t *Table) beginHandRecord(deckOrder []Card) {
+	t.HandNumber++
+	record := &HandRecord{
+		HandID:     newHandID(),
+		HandNumber: t.HandNumber,
+		TableID:    t.ID,
+		DealerPos:  t.DealerPos,
+		Players:    make([]*HandPlayerRecord, 0),
+		Actions:    make([]HandActionRecord, 0),
+		Deck:       deckOrder,
+		StartedAt:  time.Now(),
+	}
+	for _, p := range t.Seats {
+		if p != nil && p.IsActive() {
+			holeCards := make([]Card, len(p.HoleCards))
+			copy(holeCards, p.HoleCards)
+			record.Players = append(record.Players, &HandPlayerRecord{
+				PlayerID:      p.ID,
+				SessionID:     p.SessionID,
+				SeatIdx:       p.SeatIdx,
+				StartingChips: p.Chips,
+				HoleCards:     holeCards,
+			})
+		}
+	}
+	t.currentHand = record
+}
+
+// recordAction 將動作附加到當前手牌紀錄（無進行中手牌時忽略）
+func (t *Table) recordAction(playerID, action string, amount, totalBet int64) {
+	t.appendAction(HandActionRecord{
+		PlayerID: playerID,
+		Action:   action,
+		Amount:   amount,
+		TotalBet: totalBet,
+	})
+}
+
+// recordForcedFold 記錄站起或離桌造成的棄牌，TotalBet 為棄牌當下的本街下注
+func (t *Table) recordForcedFold(playerID string, reason ActionType, totalBet int64) {
+	t.appendAction(HandActionRecord{
+		PlayerID: playerID,
+		Action:   ActionFold.String(),
+		TotalBet: totalBet,
+		Reason:   reason.String(),
+	})
+}
+
+// appendAction 補上街道與時間後加入紀錄並通知回調
+func (t *Table) appendAction(record HandActionRecord) {
+	if t.currentHand == nil {
+		return
+	}
+	record.Street = t.State.String()
+	record.Timestamp = time.Now()
+	t.currentHand.Actions = append(t.currentHand.Actions, record)
+	for _, fn := range t.onActionCallbacks {
+		fn(t.currentHand.HandID, record)
+	}
+}
+
+// recordShowdown 記錄底池與贏家（在派彩後、endHand 前呼叫）
+func (t *Table) recordShowdown(payouts map[string]int64, showdown bool) {
+	if t.currentHand == nil {
+		return
+	}
+	record := t.currentHand
+
+	record.Pots = make([]HandPotRecord, 0, len(t.Pots.Pots))
+	for _, pot := range t.Pots.Pots {
+		if pot.Amount == 0 {
+			continue
+		}
+		contributors := make([]string, 0, len(pot.Contributors))
+		// 依座位順序輸出，讓紀錄可重現
+		for _, p := range record.Players {
+			if pot.Contributors[p.PlayerID] {
+				contributors = append(contributors, p.PlayerID)
+			}
+		}
+		record.Pots = append(record.Pots, HandPotRecord{
+			Amount:       pot.Amount,
+			Contributors: contributors,
+		})
+	}
+
+	record.Winners = make([]HandWinnerRecord, 0, len(payouts))
+	for _, p := range record.Players {
+		if amount, ok := payouts[p.PlayerID]; ok && amount > 0 {
+			record.Winners = append(record.Winners, HandWinnerRecord{
+				PlayerID: p.PlayerID,
+				Amount:   amount,
+			})
+		}
+	}
+
+	if showdown {
+		for _, p := range record.Players {
+			if player, exists := t.Players[p.PlayerID]; exists && player.IsActive() {
+				p.ShowedDown = true
+			}
+		}
+	}
+}
+
+// finishHandRecord 收尾手牌紀錄並移到 LastHand（在清理離桌玩家前呼叫）
+func (t *Table) finishHandRecord() {
+	if t.currentHand == nil {
+		return
+	}
+	record := t.currentHand
+	record.Board = make([]Card, len(t.CommunityCards))
+	copy(record.Board, t.CommunityCards)
+	for _, p := range record.Players {
+		if player, exists := t.Players[p.PlayerID]; exists {
+			p.EndingChips = player.Chips
+		}
+	}
+	record.EndedAt = time.Now()
+
+	t.LastHand = record
+	t.currentHand = nil
+}
+
+// newHandID 產生 UUID v4 格式的手牌 ID
+func newHandID() string {
+	return uuid.NewString()
+}
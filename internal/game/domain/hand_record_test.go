@@ -0,0 +1,164 @@
+package domain
+
+import "testing"
+
+// TestHandRecord_Showdown 完整打到攤牌，檢查手牌紀錄內容
+func TestHandRecord_Showdown(t *testing.T) {
+	table := NewTable("record-test")
+	p1 := &Player{ID: "p1", SeatIdx: 0, Chips: 1000, Status: StatusPlaying}
+	p2 := &Player{ID: "p2", SeatIdx: 1, Chips: 1000, Status: StatusPlaying}
+	table.Seats[0] = p1
+	table.Seats[1] = p2
+	table.Players["p1"] = p1
+	table.Players["p2"] = p2
+	table.DealerPos = 0
+
+	var completed *HandRecord
+	table.AddOnHandComplete(func(tb *Table) {
+		completed = tb.LastHand
+	})
+
+	table.StartHand()
+
+	if table.HandNumber != 1 {
+		t.Fatalf("Expected HandNumber 1, got %d", table.HandNumber)
+	}
+
+	p1.HoleCards = []Card{NewCard(RankA, SuitSpade), NewCard(RankA, SuitHeart)}
+	p2.HoleCards = []Card{NewCard(RankK, SuitSpade), NewCard(RankK, SuitHeart)}
+	table.Deck.Cards = []Card{
+		NewCard(RankT, SuitClub),
+		NewCard(Rank2, SuitClub), NewCard(Rank3, SuitClub), NewCard(Rank4, SuitDiamond),
+		NewCard(RankJ, SuitClub),
+		NewCard(Rank7, SuitHeart),
+		NewCard(RankQ, SuitClub),
+		NewCard(Rank9, SuitDiamond),
+	}
+
+	// Preflop: P1 (SB) call, P2 check
+	table.CurrentPos = 0
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall})
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+
+	// Flop / Turn: 雙方過牌
+	for i := 0; i < 2; i++ {
+		table.CurrentPos = 1
+		table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+		table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCheck})
+	}
+
+	// River: P2 check, P1 bet 100, P2 call
+	table.CurrentPos = 1
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionBet, Amount: 100})
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCall})
+
+	if completed == nil {
+		t.Fatal("Expected LastHand to be set when OnHandComplete fires")
+	}
+	if completed.HandID == "" || len(completed.HandID) != 36 {
+		t.Errorf("Expected UUID hand id, got %q", completed.HandID)
+	}
+	if completed.TableID != "record-test" {
+		t.Errorf("Expected TableID 'record-test', got %q", completed.TableID)
+	}
+	if completed.SmallBlind != 10 || completed.BigBlind != 20 {
+		t.Errorf("Expected blinds 10/20, got %d/%d", completed.SmallBlind, completed.BigBlind)
+	}
+	if len(completed.Players) != 2 {
+		t.Fatalf("Expected 2 players, got %d", len(completed.Players))
+	}
+	if len(completed.Board) != 5 {
+		t.Errorf("Expected 5 board cards, got %d", len(completed.Board))
+	}
+	if completed.EndedAt.IsZero() {
+		t.Error("Expected EndedAt to be set")
+	}
+
+	// 動作: SB, BB, call, check, 4x check, check, bet, call
+	expected := []struct {
+		player, street, action string
+		amount                 int64
+	}{
+		{"p1", "PREFLOP", "SMALL_BLIND", 10},
+		{"p2", "PREFLOP", "BIG_BLIND", 20},
+		{"p1", "PREFLOP", "CALL", 10},
+		{"p2", "PREFLOP", "CHECK", 0},
+		{"p2", "FLOP", "CHECK", 0},
+		{"p1", "FLOP", "CHECK", 0},
+		{"p2", "TURN", "CHECK", 0},
+		{"p1", "TURN", "CHECK", 0},
+		{"p2", "RIVER", "CHECK", 0},
+		{"p1", "RIVER", "BET", 100},
+		{"p2", "RIVER", "CALL", 100},
+	}
+	if len(completed.Actions) != len(expected) {
+		t.Fatalf("Expected %d actions, got %d: %+v", len(expected), len(completed.Actions), completed.Actions)
+	}
+	for i, want := range expected {
+		got := completed.Actions[i]
+		if got.PlayerID != want.player || got.Street != want.street || got.Action != want.action || got.Amount != want.amount {
+			t.Errorf("Action %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	rec1 := completed.Player("p1")
+	rec2 := completed.Player("p2")
+	if rec1.StartingChips != 1000 || rec1.EndingChips != 1120 || rec1.NetResult() != 120 {
+		t.Errorf("Unexpected p1 record: %+v", rec1)
+	}
+	if rec2.NetResult() != -120 {
+		t.Errorf("Expected p2 net -120, got %d", rec2.NetResult())
+	}
+	if !rec1.ShowedDown || !rec2.ShowedDown {
+		t.Error("Expected both players to be marked as showed down")
+	}
+
+	if len(completed.Winners) != 1 || completed.Winners[0].PlayerID != "p1" || completed.Winners[0].Amount != 240 {
+		t.Errorf("Expected p1 to win 240, got %+v", completed.Winners)
+	}
+	var potTotal int64
+	for _, pot := range completed.Pots {
+		potTotal += pot.Amount
+	}
+	if potTotal != 240 {
+		t.Errorf("Expected pot total 240, got %d", potTotal)
+	}
+}
+
+// TestHandRecord_WinByFold 棄牌獲勝時不標記亮牌
+func TestHandRecord_WinByFold(t *testing.T) {
+	table, _, _, _ := setupThreePlayerTable()
+	table.DealerPos = 0
+	table.StartHand()
+
+	// p2、p3 棄牌後 p1 跟注，回合結束時只剩 p1
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionFold})
+	table.handleAction(PlayerAction{PlayerID: "p3", Type: ActionFold})
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall})
+
+	record := table.LastHand
+	if record == nil {
+		t.Fatal("Expected LastHand after win by fold")
+	}
+	if len(record.Winners) != 1 || record.Winners[0].PlayerID != "p1" || record.Winners[0].Amount != 50 {
+		t.Errorf("Expected p1 to win 50, got %+v", record.Winners)
+	}
+	for _, p := range record.Players {
+		if p.ShowedDown {
+			t.Errorf("Player %s should not be marked as showed down", p.PlayerID)
+		}
+	}
+	if net := record.Player("p1").NetResult(); net != 30 {
+		t.Errorf("Expected p1 net 30, got %d", net)
+	}
+	if len(record.Board) != 0 {
+		t.Errorf("Expected empty board, got %v", record.Board)
+	}
+
+	// 第二手牌編號遞增
+	table.StartHand()
+	if table.HandNumber != 2 {
+		t.Errorf("Expected HandNumber 2, got %d", table.HandNumber)
+	}
+}
@@ -18,6 +18,7 @@ const (
 
 type Player struct {
 	ID         string
+	SessionID  string // 託管買入資金的遊戲會話 ID（機器人、測試玩家為空）
 	SeatIdx    int
 	Chips      int64
 	CurrentBet int64
@@ -69,3 +70,19 @@ func (p *Player) StandUp() (wasInHand bool, err error) {
 		return false, ErrInvalidStatusTransition
 	}
 }
+
+// String 回傳玩家狀態的字串表示（用於牌桌視圖）
+func (s PlayerStatus) String() string {
+	switch s {
+	case StatusSittingOut:
+		return "SITTING_OUT"
+	case StatusPlaying:
+		return "PLAYING"
+	case StatusFolded:
+		return "FOLDED"
+	case StatusAllIn:
+		return "ALL_IN"
+	default:
+		return "UNKNOWN"
+	}
+}
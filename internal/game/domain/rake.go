@@ -0,0 +1,159 @@
+package domain
+
+import "math"
+
+// RakeConfig 抽水規則（零值表示不抽水）
+type RakeConfig struct {
+	Percent      float64   // 抽水比例（0.05 = 5%）
+	Cap          int64     // 每手抽水上限（0 表示無上限）
+	PlayerCaps   []RakeCap // 依發牌人數調整的上限（優先於 Cap）
+	NoFlopNoDrop bool      // 未發翻牌即結束的手牌不抽水
+}
+
+// RakeCap 發牌人數達到 MinPlayers 時適用的抽水上限
+type RakeCap struct {
+	MinPlayers int
+	Cap        int64
+}
+
+// Enabled 是否啟用抽水
+func (c RakeConfig) Enabled() bool {
+	return c.Percent > 0
+}
+
+// CapFor 回傳指定發牌人數適用的抽水上限（0 表示無上限）
+// 取 MinPlayers 不超過人數的規則中門檻最高者；都不符合時使用 Cap。
+func (c RakeConfig) CapFor(players int) int64 {
+	limit := c.Cap
+	best := -1
+	for _, pc := range c.PlayerCaps {
+		if pc.MinPlayers <= players && pc.MinPlayers > best {
+			best = pc.MinPlayers
+			limit = pc.Cap
+		}
+	}
+	return limit
+}
+
+// Calculate 計算本手牌應抽取的金額
+// rakeable 為可抽水的底池總額（不含無人跟注的部分），sawFlop 表示是否已發翻牌。
+func (c RakeConfig) Calculate(rakeable int64, players int, sawFlop bool) int64 {
+	if !c.Enabled() || rakeable <= 0 {
+		return 0
+	}
+	if c.NoFlopNoDrop && !sawFlop {
+		return 0
+	}
+
+	// 加上微小偏移，避免 0.05 等比例的浮點誤差少抽 1
+	rake := int64(math.Floor(float64(rakeable)*c.Percent + 1e-9))
+	if limit := c.CapFor(players); limit > 0 && rake > limit {
+		rake = limit
+	}
+	if rake > rakeable {
+		rake = rakeable
+	}
+	return rake
+}
+
+// takeRake 在派彩前從底池扣除抽水，回傳抽水金額
+// 只有兩人以上貢獻的底池可抽水（單人底池實為無人跟注的退款），
+// 從主池開始依序扣除。
+func (t *Table) takeRake() int64 {
+	var rakeable int64
+	for _, pot := range t.Pots.Pots {
+		if len(pot.Contributors) >= 2 {
+			rakeable += pot.Amount
+		}
+	}
+
+	var rake int64
+	if t.replayRake != nil {
+		rake = min(*t.replayRake, rakeable)
+	} else {
+		rake = t.Rake.Calculate(rakeable, t.dealtPlayers(), len(t.CommunityCards) >= 3)
+	}
+	if rake <= 0 {
+		return 0
+	}
+
+	remaining := rake
+	for _, pot := range t.Pots.Pots {
+		if remaining == 0 {
+			break
+		}
+		if len(pot.Contributors) < 2 {
+			continue
+		}
+		take := min(pot.Amount, remaining)
+		pot.Amount -= take
+		remaining -= take
+	}
+
+	if t.currentHand != nil {
+		t.currentHand.Rake = rake
+	}
+	t.Logger.Info("rake collected", "amount", rake)
+	return rake
+}
+
+// dealtPlayers 回傳本手牌發牌人數
+func (t *Table) dealtPlayers() int {
+	if t.currentHand != nil {
+		return len(t.currentHand.Players)
+	}
+	count := 0
+	for _, p := range t.Seats {
+		if p != nil && p.IsActive() {
+			count++
+		}
+	}
+	return count
+}
+
+// RakeContributions 依各玩家投入底池的比例分攤本手抽水（用於計算返水）
+// 分攤後的餘數依座位順序逐一分配，保證總和等於 Rake。
+func (r *HandRecord) RakeContributions() map[string]int64 {
+	contributions := make(map[string]int64)
+	if r.Rake <= 0 {
+		return contributions
+	}
+
+	won := make(map[string]int64, len(r.Winners))
+	for _, w := range r.Winners {
+		won[w.PlayerID] += w.Amount
+	}
+
+	invested := make([]int64, len(r.Players))
+	var total int64
+	for i, p := range r.Players {
+		// 投入 = 起始籌碼 - 派彩前籌碼 = 起始 - (結束 - 贏得)
+		amount := p.StartingChips - p.EndingChips + won[p.PlayerID]
+		if amount > 0 {
+			invested[i] = amount
+			total += amount
+		}
+	}
+	if total == 0 {
+		return contributions
+	}
+
+	var assigned int64
+	for i, p := range r.Players {
+		if invested[i] == 0 {
+			continue
+		}
+		share := r.Rake * invested[i] / total
+		contributions[p.PlayerID] = share
+		assigned += share
+	}
+	for i := 0; assigned < r.Rake; i = (i + 1) % len(r.Players) {
+		if invested[i] == 0 {
+			continue
+		}
+		contributions[r.Players[i].PlayerID]++
+		assigned++
+	}
+
+	return contributions
+}
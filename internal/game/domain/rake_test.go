@@ -0,0 +1,178 @@
+package domain
+
+import "testing"
+
+func TestRakeConfig_Calculate(t *testing.T) {
+	cfg := RakeConfig{
+		Percent: 0.05,
+		Cap:     300,
+		PlayerCaps: []RakeCap{
+			{MinPlayers: 2, Cap: 50},
+			{MinPlayers: 4, Cap: 150},
+		},
+		NoFlopNoDrop: true,
+	}
+
+	tests := []struct {
+		name     string
+		rakeable int64
+		players  int
+		sawFlop  bool
+		want     int64
+	}{
+		{"percentage", 400, 2, true, 20},
+		{"rounds down", 399, 6, true, 19},
+		{"heads-up cap", 2000, 2, true, 50},
+		{"three-handed uses 2+ cap", 2000, 3, true, 50},
+		{"four-handed cap", 10000, 4, true, 150},
+		{"no flop no drop", 2000, 6, false, 0},
+		{"empty pot", 0, 6, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Calculate(tt.rakeable, tt.players, tt.sawFlop); got != tt.want {
+				t.Errorf("Calculate(%d, %d, %v) = %d, want %d", tt.rakeable, tt.players, tt.sawFlop, got, tt.want)
+			}
+		})
+	}
+
+	if got := (RakeConfig{}).Calculate(1000, 2, true); got != 0 {
+		t.Errorf("zero config should not take rake, got %d", got)
+	}
+	if got := (RakeConfig{Percent: 0.1}).Calculate(1000, 9, false); got != 100 {
+		t.Errorf("expected uncapped rake 100 without no-flop-no-drop, got %d", got)
+	}
+}
+
+// TestRake_Showdown 攤牌前從底池扣除抽水並寫入紀錄與事件
+func TestRake_Showdown(t *testing.T) {
+	table := NewTable("rake-test")
+	table.Rake = RakeConfig{Percent: 0.05, Cap: 10, NoFlopNoDrop: true}
+	p1 := &Player{ID: "p1", SeatIdx: 0, Chips: 1000, Status: StatusPlaying}
+	p2 := &Player{ID: "p2", SeatIdx: 1, Chips: 1000, Status: StatusPlaying}
+	table.Seats[0] = p1
+	table.Seats[1] = p2
+	table.Players["p1"] = p1
+	table.Players["p2"] = p2
+	table.DealerPos = 0
+
+	var showdown TableEvent
+	table.AddOnEvent(func(e TableEvent) {
+		if e.Type == EventShowdownResult {
+			showdown = e
+		}
+	})
+
+	table.StartHand()
+	p1.HoleCards = []Card{NewCard(RankA, SuitSpade), NewCard(RankA, SuitHeart)}
+	p2.HoleCards = []Card{NewCard(RankK, SuitSpade), NewCard(RankK, SuitHeart)}
+	table.Deck.Cards = []Card{
+		NewCard(RankT, SuitClub),
+		NewCard(Rank2, SuitClub), NewCard(Rank3, SuitClub), NewCard(Rank4, SuitDiamond),
+		NewCard(RankJ, SuitClub),
+		NewCard(Rank7, SuitHeart),
+		NewCard(RankQ, SuitClub),
+		NewCard(Rank9, SuitDiamond),
+	}
+
+	// Preflop: P1 (SB) call, P2 check
+	table.CurrentPos = 0
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall})
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+
+	// Flop / Turn / River: P2 bet 100, P1 call，之後過牌
+	table.CurrentPos = 1
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionBet, Amount: 100})
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall})
+	for i := 0; i < 2; i++ {
+		table.CurrentPos = 1
+		table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+		table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCheck})
+	}
+
+	record := table.LastHand
+	if record == nil {
+		t.Fatal("Expected LastHand after showdown")
+	}
+
+	// 底池 240，5% = 12，上限 10
+	if record.Rake != 10 {
+		t.Errorf("Expected rake 10, got %d", record.Rake)
+	}
+	if showdown.Data["rake"] != int64(10) {
+		t.Errorf("Expected SHOWDOWN_RESULT rake 10, got %v", showdown.Data["rake"])
+	}
+	if p1.Chips != 1110 {
+		t.Errorf("Expected p1 chips 1110 after rake, got %d", p1.Chips)
+	}
+
+	var net int64
+	for _, p := range record.Players {
+		net += p.NetResult()
+	}
+	if net != -10 {
+		t.Errorf("Expected players' net results to sum to -rake, got %d", net)
+	}
+
+	contributions := record.RakeContributions()
+	if contributions["p1"] != 5 || contributions["p2"] != 5 {
+		t.Errorf("Expected rake split 5/5, got %v", contributions)
+	}
+}
+
+// TestRake_NoFlopNoDrop 翻牌前棄牌獲勝不抽水
+func TestRake_NoFlopNoDrop(t *testing.T) {
+	table, p1, _, _ := setupThreePlayerTable()
+	table.Rake = RakeConfig{Percent: 0.5, NoFlopNoDrop: true}
+	table.DealerPos = 0
+
+	var winByFold TableEvent
+	table.AddOnEvent(func(e TableEvent) {
+		if e.Type == EventWinByFold {
+			winByFold = e
+		}
+	})
+
+	table.StartHand()
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionFold})
+	table.handleAction(PlayerAction{PlayerID: "p3", Type: ActionFold})
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall})
+
+	if table.LastHand == nil || table.LastHand.Rake != 0 {
+		t.Fatalf("Expected no rake preflop, got %+v", table.LastHand)
+	}
+	if winByFold.Data["rake"] != int64(0) || winByFold.Data["amount"] != int64(50) {
+		t.Errorf("Unexpected WIN_BY_FOLD data: %v", winByFold.Data)
+	}
+	if p1.Chips != 1030 {
+		t.Errorf("Expected p1 chips 1030, got %d", p1.Chips)
+	}
+}
+
+func TestHandRecord_RakeContributions(t *testing.T) {
+	record := &HandRecord{
+		Rake: 10,
+		Players: []*HandPlayerRecord{
+			{PlayerID: "a", StartingChips: 1000, EndingChips: 1190}, // 投入 100，贏回 290
+			{PlayerID: "b", StartingChips: 1000, EndingChips: 900},  // 投入 100
+			{PlayerID: "c", StartingChips: 1000, EndingChips: 900},  // 投入 100
+			{PlayerID: "d", StartingChips: 1000, EndingChips: 1000}, // 未投入
+		},
+		Winners: []HandWinnerRecord{{PlayerID: "a", Amount: 290}},
+	}
+
+	got := record.RakeContributions()
+	var total int64
+	for _, amount := range got {
+		total += amount
+	}
+	if total != 10 {
+		t.Errorf("Expected contributions to sum to 10, got %v", got)
+	}
+	if got["a"] != 4 || got["b"] != 3 || got["c"] != 3 {
+		t.Errorf("Expected 4/3/3 split with remainder to first seat, got %v", got)
+	}
+	if _, ok := got["d"]; ok {
+		t.Errorf("Player who invested nothing should not contribute rake, got %v", got)
+	}
+}
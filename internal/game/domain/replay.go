@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrReplayDeckMissing 手牌紀錄缺少牌序，無法重播
+var ErrReplayDeckMissing = errors.New("hand record has no deck order")
+
+// ReplayStep 重播中的一個步驟：一個玩家動作及其觸發的事件
+type ReplayStep struct {
+	Index  int
+	Action *HandActionRecord // nil 表示開局（發牌、盲注）
+	Events []TableEvent
+}
+
+// ReplayResult 重播結果
+type ReplayResult struct {
+	HandID     string
+	Steps      []ReplayStep
+	ChipDeltas map[string]int64 // 重播得出的各玩家淨輸贏
+	Mismatches []string         // 與紀錄不符之處（空表示一致）
+}
+
+// Verified 重播結果是否與紀錄一致
+func (r *ReplayResult) Verified() bool {
+	return len(r.Mismatches) == 0
+}
+
+// ReplayHand 以紀錄中的牌序與動作重新執行一手牌
+// 在獨立的 Table 上同步執行（不啟動 Run()），逐步收集事件，
+// 最後比對各玩家籌碼變化、公共牌與動作序列是否與紀錄相符。
+func ReplayHand(record *HandRecord) (*ReplayResult, error) {
+	if len(record.Deck) == 0 {
+		return nil, ErrReplayDeckMissing
+	}
+
+	table := NewTable(record.TableID)
+	for _, p := range record.Players {
+		if p.SeatIdx < 0 || p.SeatIdx >= len(table.Seats) {
+			return nil, fmt.Errorf("invalid seat %d for player %s", p.SeatIdx, p.PlayerID)
+		}
+		player := &Player{
+			ID:      p.PlayerID,
+			SeatIdx: p.SeatIdx,
+			Chips:   p.StartingChips,
+			Status:  StatusPlaying,
+		}
+		table.Seats[p.SeatIdx] = player
+		table.Players[p.PlayerID] = player
+	}
+	table.DealerPos = record.DealerPos
+	table.HandNumber = record.HandNumber - 1
+	table.StackDeck(record.Deck)
+	rake := record.Rake
+	table.replayRake = &rake // 重現紀錄中的抽水，而非套用目前的抽水規則
+
+	var pending []TableEvent
+	table.AddOnEvent(func(event TableEvent) {
+		pending = append(pending, event)
+	})
+
+	result := &ReplayResult{
+		HandID:     record.HandID,
+		ChipDeltas: make(map[string]int64),
+	}
+
+	table.StartHand()
+	result.Steps = append(result.Steps, ReplayStep{Index: 0, Events: pending})
+	pending = nil
+
+	for i := range record.Actions {
+		action := &record.Actions[i]
+		if action.Action == "SMALL_BLIND" || action.Action == "BIG_BLIND" {
+			continue // 盲注由 StartHand 重現
+		}
+		if table.State == StateIdle {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("action %d (%s %s) recorded after hand ended", i, action.PlayerID, action.Action))
+			break
+		}
+
+		if err := table.replayAction(action); err != nil {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("action %d (%s %s) rejected: %v", i, action.PlayerID, action.Action, err))
+			break
+		}
+
+		result.Steps = append(result.Steps, ReplayStep{
+			Index:  len(result.Steps),
+			Action: action,
+			Events: pending,
+		})
+		pending = nil
+	}
+
+	replayed := table.LastHand
+	if replayed == nil {
+		result.Mismatches = append(result.Mismatches, "replayed hand did not complete")
+		return result, nil
+	}
+
+	for _, p := range replayed.Players {
+		result.ChipDeltas[p.PlayerID] = p.NetResult()
+		original := record.Player(p.PlayerID)
+		if original == nil {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("player %s not in original record", p.PlayerID))
+			continue
+		}
+		if p.NetResult() != original.NetResult() {
+			result.Mismatches = append(result.Mismatches,
+				fmt.Sprintf("player %s chip delta: recorded %d, replayed %d", p.PlayerID, original.NetResult(), p.NetResult()))
+		}
+	}
+	if !slices.Equal(replayed.Board, record.Board) {
+		result.Mismatches = append(result.Mismatches,
+			fmt.Sprintf("board: recorded %v, replayed %v", record.Board, replayed.Board))
+	}
+	if len(replayed.Actions) != len(record.Actions) {
+		result.Mismatches = append(result.Mismatches,
+			fmt.Sprintf("action count: recorded %d, replayed %d", len(record.Actions), len(replayed.Actions)))
+	}
+
+	return result, nil
+}
+
+// StackDeck 指定下一手牌使用的牌序（僅供重播使用，應在 StartHand 前呼叫）
+func (t *Table) StackDeck(cards []Card) {
+	t.stackedDeck = make([]Card, len(cards))
+	copy(t.stackedDeck, cards)
+}
+
+// replayAction 重現一個紀錄中的動作
+func (t *Table) replayAction(action *HandActionRecord) error {
+	actType, ok := ParseActionType(action.Action)
+	if !ok {
+		return fmt.Errorf("unknown action %q", action.Action)
+	}
+
+	if actType == ActionFold {
+		return t.replayFold(action)
+	}
+
+	act := PlayerAction{PlayerID: action.PlayerID, Type: actType}
+	if actType == ActionBet || actType == ActionRaise {
+		act.Amount = action.TotalBet
+	}
+	return t.handleAction(act)
+}
+
+// replayFold 重現棄牌（依紀錄的來源走站起、離桌或一般棄牌的路徑）
+func (t *Table) replayFold(action *HandActionRecord) error {
+	if _, exists := t.Players[action.PlayerID]; !exists {
+		return ErrPlayerNotFound
+	}
+
+	switch action.Reason {
+	case ActionStandUp.String():
+		t.processCommand(PlayerAction{PlayerID: action.PlayerID, Type: ActionStandUp})
+		return nil
+	case ActionLeaveTable.String():
+		return t.removePlayer(action.PlayerID)
+	default:
+		return t.handleAction(PlayerAction{PlayerID: action.PlayerID, Type: ActionFold})
+	}
+}
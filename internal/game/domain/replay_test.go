@@ -0,0 +1,144 @@
+package domain
+
+import "testing"
+
+// playRecordedHand 以隨機牌序打一手三人牌局並回傳紀錄
+func playRecordedHand(t *testing.T) *HandRecord {
+	t.Helper()
+	table, _, _, _ := setupThreePlayerTable()
+	table.DealerPos = 0
+	table.StartHand()
+
+	// Preflop: p2 (SB) 加注到 60，p3 跟注，p1 棄牌，p2 補齊
+	steps := []PlayerAction{
+		{PlayerID: "p2", Type: ActionRaise, Amount: 60},
+		{PlayerID: "p3", Type: ActionCall},
+		{PlayerID: "p1", Type: ActionFold},
+	}
+	for _, act := range steps {
+		if err := table.handleAction(act); err != nil {
+			t.Fatalf("action %+v failed: %v", act, err)
+		}
+	}
+
+	// 之後各街由當前行動者過牌直到攤牌
+	for table.State != StateIdle {
+		current := table.Seats[table.CurrentPos]
+		if err := table.handleAction(PlayerAction{PlayerID: current.ID, Type: ActionCheck}); err != nil {
+			t.Fatalf("check by %s failed: %v", current.ID, err)
+		}
+	}
+
+	if table.LastHand == nil {
+		t.Fatal("Expected completed hand record")
+	}
+	return table.LastHand
+}
+
+// TestReplayHand_Verified 以相同牌序重播應得到一致的結果
+func TestReplayHand_Verified(t *testing.T) {
+	record := playRecordedHand(t)
+	if len(record.Deck) != 52 {
+		t.Fatalf("Expected 52-card deck order, got %d", len(record.Deck))
+	}
+
+	result, err := ReplayHand(record)
+	if err != nil {
+		t.Fatalf("ReplayHand failed: %v", err)
+	}
+	if !result.Verified() {
+		t.Fatalf("Expected replay to match, mismatches: %v", result.Mismatches)
+	}
+
+	for _, p := range record.Players {
+		if result.ChipDeltas[p.PlayerID] != p.NetResult() {
+			t.Errorf("Player %s: expected delta %d, got %d", p.PlayerID, p.NetResult(), result.ChipDeltas[p.PlayerID])
+		}
+	}
+
+	// 開局步驟 + 每個非盲注動作各一步
+	if len(result.Steps) != len(record.Actions)-2+1 {
+		t.Errorf("Expected %d steps, got %d", len(record.Actions)-1, len(result.Steps))
+	}
+	if result.Steps[0].Action != nil || len(result.Steps[0].Events) == 0 {
+		t.Error("Expected first step to be hand start with events")
+	}
+	last := result.Steps[len(result.Steps)-1]
+	if last.Events[len(last.Events)-1].Type != EventHandEnd {
+		t.Errorf("Expected final step to end with HAND_END, got %s", last.Events[len(last.Events)-1].Type)
+	}
+}
+
+// TestReplayHand_DetectsTampering 紀錄被竄改時應回報不一致
+func TestReplayHand_DetectsTampering(t *testing.T) {
+	record := playRecordedHand(t)
+	record.Player("p3").EndingChips += 100
+
+	result, err := ReplayHand(record)
+	if err != nil {
+		t.Fatalf("ReplayHand failed: %v", err)
+	}
+	if result.Verified() {
+		t.Fatal("Expected mismatch after tampering with ending chips")
+	}
+}
+
+// TestReplayHand_StandUpAndLeaveFolds 站起與離桌的棄牌記錄當下下注與來源，重播時走相同路徑
+func TestReplayHand_StandUpAndLeaveFolds(t *testing.T) {
+	table, _, _, _ := setupThreePlayerTable()
+	table.DealerPos = 0
+	table.StartHand()
+
+	// 大盲 p3 非輪次內站起；輪到小盲 p2 時離桌；p1 跟注後贏得底池
+	table.processCommand(PlayerAction{PlayerID: "p3", Type: ActionStandUp})
+	if err := table.removePlayer("p2"); err != nil {
+		t.Fatalf("removePlayer failed: %v", err)
+	}
+	if err := table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionCall}); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if table.State != StateIdle || table.LastHand == nil {
+		t.Fatal("Expected hand to end after the other players folded")
+	}
+
+	record := table.LastHand
+	folds := make(map[string]HandActionRecord)
+	for _, a := range record.Actions {
+		if a.Action == ActionFold.String() {
+			folds[a.PlayerID] = a
+		}
+	}
+	if f := folds["p3"]; f.Reason != "STAND_UP" || f.TotalBet != 20 {
+		t.Errorf("Expected stand-up fold with total bet 20, got %+v", f)
+	}
+	if f := folds["p2"]; f.Reason != "LEAVE_TABLE" || f.TotalBet != 10 {
+		t.Errorf("Expected leave fold with total bet 10, got %+v", f)
+	}
+
+	result, err := ReplayHand(record)
+	if err != nil {
+		t.Fatalf("ReplayHand failed: %v", err)
+	}
+	if !result.Verified() {
+		t.Fatalf("Expected replay to match, mismatches: %v", result.Mismatches)
+	}
+}
+
+// TestReplayHand_MissingDeck 缺少牌序時無法重播
+func TestReplayHand_MissingDeck(t *testing.T) {
+	if _, err := ReplayHand(&HandRecord{}); err != ErrReplayDeckMissing {
+		t.Errorf("Expected ErrReplayDeckMissing, got %v", err)
+	}
+}
+
+func TestParseCard_RoundTrip(t *testing.T) {
+	for _, c := range NewDeck().Cards {
+		parsed, err := ParseCard(c.String())
+		if err != nil || parsed != c {
+			t.Errorf("ParseCard(%q) = %v, %v", c.String(), parsed, err)
+		}
+	}
+	if _, err := ParseCard("Xz"); err == nil {
+		t.Error("Expected error for invalid card")
+	}
+}
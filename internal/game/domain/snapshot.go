@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTableNotEmpty 牌桌已有玩家或手牌進行中，無法從快照回復
+var ErrTableNotEmpty = errors.New("table is not empty")
+
+// TableSnapshot 牌桌在手牌邊界的狀態（用於崩潰復原）
+// 手牌進行中取快照時，玩家籌碼取該手開始前的數值，
+// 復原時等同作廢進行中的手牌並退還各玩家已投入的籌碼。
+type TableSnapshot struct {
+	TableID        string
+	HandNumber     int
+	DealerPos      int
+	HandInProgress bool   // 取快照時是否有進行中的手牌
+	HandID         string // 進行中手牌的 ID（HandInProgress 為 false 時為空）
+	Players        []SnapshotPlayer
+	Leavers        []SnapshotLeaver // 進行中手牌已離桌、仍有籌碼在底池的玩家
+	TakenAt        time.Time
+}
+
+// SnapshotPlayer 快照中的入座玩家
+type SnapshotPlayer struct {
+	PlayerID  string
+	SessionID string // 遊戲會話 ID（復原後的手牌仍記入同一會話）
+	SeatIdx   int
+	Chips     int64 // 手牌邊界的籌碼
+}
+
+// SnapshotLeaver 手牌進行中離桌的玩家
+// 離桌時已兌現的會話不含留在底池的籌碼，作廢該手時須另行退還。
+type SnapshotLeaver struct {
+	PlayerID     string
+	SessionID    string
+	Chips        int64 // 該手開始前的籌碼
+	Contribution int64 // 離桌前已投入該手的籌碼
+}
+
+// Snapshot 取得牌桌目前的快照（必須在 Run() goroutine 中呼叫，或在 Run() 啟動前）
+// 手牌期間離桌的玩家（SeatIdx == -1）不列入座位，已投入底池者列入 Leavers。
+func (t *Table) Snapshot() *TableSnapshot {
+	snapshot := &TableSnapshot{
+		TableID:    t.ID,
+		HandNumber: t.HandNumber,
+		DealerPos:  t.DealerPos,
+		Players:    make([]SnapshotPlayer, 0),
+		TakenAt:    time.Now(),
+	}
+	if t.currentHand != nil {
+		snapshot.HandInProgress = true
+		snapshot.HandID = t.currentHand.HandID
+		snapshot.DealerPos = t.currentHand.DealerPos
+	}
+
+	for _, p := range t.Seats {
+		if p == nil {
+			continue
+		}
+		chips := p.Chips
+		if t.currentHand != nil {
+			if rec := t.currentHand.Player(p.ID); rec != nil {
+				chips = rec.StartingChips
+			}
+		}
+		snapshot.Players = append(snapshot.Players, SnapshotPlayer{
+			PlayerID:  p.ID,
+			SessionID: p.SessionID,
+			SeatIdx:   p.SeatIdx,
+			Chips:     chips,
+		})
+	}
+
+	if t.currentHand != nil {
+		for _, rec := range t.currentHand.Players {
+			p, exists := t.Players[rec.PlayerID]
+			if !exists || p.SeatIdx != -1 || rec.StartingChips <= p.Chips {
+				continue
+			}
+			snapshot.Leavers = append(snapshot.Leavers, SnapshotLeaver{
+				PlayerID:     p.ID,
+				SessionID:    p.SessionID,
+				Chips:        rec.StartingChips,
+				Contribution: rec.StartingChips - p.Chips,
+			})
+		}
+	}
+
+	return snapshot
+}
+
+// Restore 以快照重建牌桌（必須在 Run() 啟動前呼叫）
+// 玩家以 SittingOut 狀態入座，重新連線後須主動坐下，避免在玩家缺席時自動開局。
+func (t *Table) Restore(snapshot *TableSnapshot) error {
+	if len(t.Players) > 0 || t.State != StateIdle {
+		return ErrTableNotEmpty
+	}
+
+	for _, p := range snapshot.Players {
+		if p.SeatIdx < 0 || p.SeatIdx >= len(t.Seats) {
+			return fmt.Errorf("invalid seat %d for player %s", p.SeatIdx, p.PlayerID)
+		}
+		if t.Seats[p.SeatIdx] != nil {
+			return fmt.Errorf("seat %d is occupied", p.SeatIdx)
+		}
+		player := &Player{
+			ID:        p.PlayerID,
+			SessionID: p.SessionID,
+			SeatIdx:   p.SeatIdx,
+			Chips:     p.Chips,
+			Status:    StatusSittingOut,
+		}
+		t.Seats[p.SeatIdx] = player
+		t.Players[p.PlayerID] = player
+	}
+	t.HandNumber = snapshot.HandNumber
+	t.DealerPos = snapshot.DealerPos
+
+	t.Logger.Info("table restored from snapshot",
+		"table_id", t.ID,
+		"players", len(snapshot.Players),
+		"hand_in_progress", snapshot.HandInProgress,
+	)
+	return nil
+}
@@ -0,0 +1,135 @@
+package domain
+
+import "testing"
+
+// TestSnapshot_MidHandUsesStartingChips 手牌進行中的快照取開局前籌碼，復原後等同退還本手投入
+func TestSnapshot_MidHandUsesStartingChips(t *testing.T) {
+	table := NewTable("snapshot-test")
+	p1 := &Player{ID: "p1", SeatIdx: 0, Chips: 1000, Status: StatusPlaying}
+	p2 := &Player{ID: "p2", SeatIdx: 3, Chips: 800, Status: StatusPlaying}
+	table.Seats[0] = p1
+	table.Seats[3] = p2
+	table.Players["p1"] = p1
+	table.Players["p2"] = p2
+	table.DealerPos = 0
+
+	var logged []HandActionRecord
+	table.AddOnAction(func(handID string, action HandActionRecord) {
+		if handID != table.currentHand.HandID {
+			t.Errorf("unexpected hand id %s", handID)
+		}
+		logged = append(logged, action)
+	})
+
+	table.StartHand()
+	table.CurrentPos = 0
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionRaise, Amount: 100})
+
+	// 盲注 2 筆 + 加注 1 筆
+	if len(logged) != 3 || logged[2].Action != "RAISE" {
+		t.Fatalf("expected blinds and raise in action log, got %+v", logged)
+	}
+
+	snapshot := table.Snapshot()
+	if !snapshot.HandInProgress || snapshot.HandID == "" || snapshot.HandNumber != 1 {
+		t.Fatalf("unexpected snapshot header: %+v", snapshot)
+	}
+	chips := make(map[string]int64)
+	for _, p := range snapshot.Players {
+		chips[p.PlayerID] = p.Chips
+	}
+	if chips["p1"] != 1000 || chips["p2"] != 800 {
+		t.Errorf("expected pre-hand chips, got %v", chips)
+	}
+
+	restored := NewTable("snapshot-test")
+	if err := restored.Restore(snapshot); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.HandNumber != 1 || restored.DealerPos != 0 || restored.State != StateIdle {
+		t.Errorf("unexpected restored table: hand=%d dealer=%d state=%v", restored.HandNumber, restored.DealerPos, restored.State)
+	}
+	if p := restored.Seats[3]; p == nil || p.ID != "p2" || p.Chips != 800 || p.Status != StatusSittingOut {
+		t.Errorf("unexpected restored player: %+v", p)
+	}
+
+	// 玩家皆為 SittingOut，不應自動開局
+	restored.tryStartNewHand()
+	if restored.State != StateIdle {
+		t.Error("restored table should not start a hand before players sit down")
+	}
+
+	if err := restored.Restore(snapshot); err != ErrTableNotEmpty {
+		t.Errorf("expected ErrTableNotEmpty, got %v", err)
+	}
+}
+
+// TestSnapshot_AtHandBoundary 手牌結束後的快照取最終籌碼
+func TestSnapshot_AtHandBoundary(t *testing.T) {
+	table := NewTable("snapshot-test")
+	p1 := &Player{ID: "p1", SeatIdx: 0, Chips: 1000, Status: StatusPlaying}
+	p2 := &Player{ID: "p2", SeatIdx: 1, Chips: 1000, Status: StatusPlaying}
+	table.Seats[0] = p1
+	table.Seats[1] = p2
+	table.Players["p1"] = p1
+	table.Players["p2"] = p2
+	table.DealerPos = 0
+
+	table.StartHand()
+	// P1 (SB) 棄牌，P2 過牌後贏得盲注
+	table.CurrentPos = 0
+	table.handleAction(PlayerAction{PlayerID: "p1", Type: ActionFold})
+	table.handleAction(PlayerAction{PlayerID: "p2", Type: ActionCheck})
+
+	snapshot := table.Snapshot()
+	if snapshot.HandInProgress || snapshot.HandID != "" {
+		t.Fatalf("expected hand boundary snapshot, got %+v", snapshot)
+	}
+	for _, p := range snapshot.Players {
+		want := map[string]int64{"p1": 990, "p2": 1010}[p.PlayerID]
+		if p.Chips != want {
+			t.Errorf("player %s: expected %d chips, got %d", p.PlayerID, want, p.Chips)
+		}
+	}
+}
+
+// TestSnapshot_MidHandLeaverContribution 手牌進行中離桌的玩家不佔座位，但記下留在底池的籌碼
+func TestSnapshot_MidHandLeaverContribution(t *testing.T) {
+	table := NewTable("snapshot-test")
+	for i, id := range []string{"p1", "p2", "p3"} {
+		p := &Player{ID: id, SessionID: "session-" + id, SeatIdx: i, Chips: 1000, Status: StatusPlaying}
+		table.Seats[i] = p
+		table.Players[id] = p
+	}
+	table.DealerPos = 0
+	table.StartHand()
+
+	var leaver *Player
+	for _, p := range table.Seats {
+		if p != nil && p.CurrentBet > 0 {
+			leaver = p
+			break
+		}
+	}
+	if leaver == nil {
+		t.Fatal("expected a player to have posted a blind")
+	}
+	bet := leaver.CurrentBet
+	if err := table.removePlayer(leaver.ID); err != nil {
+		t.Fatalf("removePlayer failed: %v", err)
+	}
+
+	snapshot := table.Snapshot()
+	for _, p := range snapshot.Players {
+		if p.PlayerID == leaver.ID {
+			t.Errorf("leaver should not keep a seat in the snapshot: %+v", p)
+		}
+	}
+	if len(snapshot.Leavers) != 1 {
+		t.Fatalf("expected 1 leaver, got %+v", snapshot.Leavers)
+	}
+	l := snapshot.Leavers[0]
+	if l.PlayerID != leaver.ID || l.SessionID != "session-"+leaver.ID || l.Chips != 1000 || l.Contribution != bet {
+		t.Errorf("unexpected leaver: %+v (bet %d)", l, bet)
+	}
+}
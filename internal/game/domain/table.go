@@ -36,6 +36,9 @@ type Table struct {
 	// onEventCallbacks 遊戲事件回調切片（用於廣播到 WebSocket）
 	onEventCallbacks []func(event TableEvent)
 
+	// onActionCallbacks 手牌動作回調切片（用於寫入動作日誌）
+	onActionCallbacks []func(handID string, action HandActionRecord)
+
 	// 行動計時器
 	ActionTimeout  time.Duration // 行動超時時間（預設 30 秒）
 	ActionDeadline time.Time     // 當前行動者的截止時間（zero 表示無計時）
@@ -44,6 +47,16 @@ type Table struct {
 	DisconnectedAt    map[string]time.Time // playerID -> 斷線時間
 	DisconnectTimeout time.Duration        // 斷線超時（預設 30 秒）
 
+	// 手牌紀錄
+	HandNumber  int         // 本桌已開始的手牌數
+	currentHand *HandRecord // 進行中的手牌紀錄
+	LastHand    *HandRecord // 最近一手完成的紀錄（onHandComplete 回調讀取）
+	stackedDeck []Card      // 下一手使用的指定牌序（僅重播使用）
+
+	// 抽水
+	Rake       RakeConfig // 抽水規則（零值表示不抽水）
+	replayRake *int64     // 固定抽水金額（僅重播使用，重現紀錄中的抽水）
+
 	// 日誌
 	Logger Logger
 }
@@ -94,6 +107,11 @@ func (t *Table) fireEvent(event TableEvent) {
 	}
 }
 
+// AddOnAction 註冊手牌動作回調，每個寫入手牌紀錄的動作都會觸發（應在 Run() 啟動前呼叫）
+func (t *Table) AddOnAction(fn func(handID string, action HandActionRecord)) {
+	t.onActionCallbacks = append(t.onActionCallbacks, fn)
+}
+
 var ErrPlayerNotFound = errors.New("player not found at table")
 
 // PlayerSitDown 讓指定玩家坐下（SittingOut → Playing）
@@ -116,9 +134,16 @@ func (t *Table) PlayerStandUp(playerID string) (wasInHand bool, err error) {
 
 // StartHand 開始新的一手牌
 func (t *Table) StartHand() {
-	// 1. 洗牌
-	t.Deck = NewDeck()
-	t.Deck.Shuffle()
+	// 1. 洗牌（重播時使用預先排好的牌序）
+	if t.stackedDeck != nil {
+		t.Deck = &Deck{Cards: t.stackedDeck}
+		t.stackedDeck = nil
+	} else {
+		t.Deck = NewDeck()
+		t.Deck.Shuffle()
+	}
+	deckOrder := make([]Card, len(t.Deck.Cards))
+	copy(deckOrder, t.Deck.Cards)
 
 	// 2. 重置狀態
 	t.CommunityCards = make([]Card, 0)
@@ -138,7 +163,8 @@ func (t *Table) StartHand() {
 		}
 	}
 
-	// 4. 設定盲注 (Blind)
+	// 4. 建立手牌紀錄並設定盲注 (Blind)
+	t.beginHandRecord(deckOrder)
 	t.postBlinds()
 
 	// 5. 發射事件：HAND_START
@@ -212,6 +238,11 @@ func (t *Table) postBlinds() {
 		t.MinBet = bigBlindAmount
 	}
 
+	if t.currentHand != nil {
+		t.currentHand.SmallBlind = smallBlindAmount
+		t.currentHand.BigBlind = bigBlindAmount
+	}
+
 	// Heads-up (兩人對決) 時的特殊規則:
 	// - Button (莊家) 是小盲
 	// - 另一位是大盲
@@ -248,6 +279,7 @@ func (t *Table) postBlinds() {
 			sb.Status = StatusAllIn
 		}
 
+		t.recordAction(sb.ID, "SMALL_BLIND", amount, sb.CurrentBet)
 		t.Logger.Info("post small blind",
 			"player_id", sb.ID, "amount", amount, "remaining", sb.Chips)
 	}
@@ -263,6 +295,7 @@ func (t *Table) postBlinds() {
 			bb.Status = StatusAllIn
 		}
 
+		t.recordAction(bb.ID, "BIG_BLIND", amount, bb.CurrentBet)
 		t.Logger.Info("post big blind",
 			"player_id", bb.ID, "amount", amount, "remaining", bb.Chips)
 	}
@@ -297,6 +330,7 @@ func (t *Table) postBlindHeadsUp(smallBlindAmount, bigBlindAmount int64) {
 			buttonPlayer.Status = StatusAllIn
 		}
 
+		t.recordAction(buttonPlayer.ID, "SMALL_BLIND", amount, buttonPlayer.CurrentBet)
 		t.Logger.Info("post small blind (button)",
 			"player_id", buttonPlayer.ID, "amount", amount, "remaining", buttonPlayer.Chips)
 	}
@@ -311,6 +345,7 @@ func (t *Table) postBlindHeadsUp(smallBlindAmount, bigBlindAmount int64) {
 			otherPlayer.Status = StatusAllIn
 		}
 
+		t.recordAction(otherPlayer.ID, "BIG_BLIND", amount, otherPlayer.CurrentBet)
 		t.Logger.Info("post big blind",
 			"player_id", otherPlayer.ID, "amount", amount, "remaining", otherPlayer.Chips)
 	}
@@ -364,22 +399,47 @@ func (t *Table) processCommand(cmd PlayerAction) {
 	case ActionJoinTable:
 		result.Err = t.addPlayer(cmd.Player, cmd.SeatIdx)
 	case ActionLeaveTable:
+		if player, exists := t.Players[cmd.PlayerID]; exists {
+			result.Chips = player.Chips
+		}
 		result.Err = t.removePlayer(cmd.PlayerID)
 	case ActionSitDown:
 		result.Err = t.PlayerSitDown(cmd.PlayerID)
 	case ActionStandUp:
+		var currentBet int64
+		if player, exists := t.Players[cmd.PlayerID]; exists {
+			currentBet = player.CurrentBet
+		}
 		result.WasInHand, result.Err = t.PlayerStandUp(cmd.PlayerID)
+		if result.WasInHand {
+			t.recordForcedFold(cmd.PlayerID, ActionStandUp, currentBet)
+		}
 	case ActionDisconnect:
 		t.handleDisconnect(cmd.PlayerID)
 		return
 	case ActionReconnect:
 		t.handleReconnect(cmd.PlayerID)
 		return
+	case ActionSnapshot:
+		result.Snapshot = t.Snapshot()
+	case ActionPlayerView:
+		result.View = t.PlayerView(cmd.PlayerID)
 	default:
 		// 遊戲動作 (Fold/Check/Call/Bet/Raise/AllIn) 走原有邏輯
 		result.Err = t.handleAction(cmd)
 	}
 
+	// 客戶端請求帶有追蹤 ID 時記錄處理結果，與上層日誌串連
+	if cmd.TraceID != "" {
+		if result.Err != nil {
+			t.Logger.Info("command rejected", "table_id", t.ID, "player_id", cmd.PlayerID,
+				"command", cmd.Type.String(), "trace_id", cmd.TraceID, "error", result.Err.Error())
+		} else {
+			t.Logger.Info("command processed", "table_id", t.ID, "player_id", cmd.PlayerID,
+				"command", cmd.Type.String(), "amount", cmd.Amount, "trace_id", cmd.TraceID)
+		}
+	}
+
 	// 回傳結果（如果有 ResultCh）
 	if cmd.ResultCh != nil {
 		cmd.ResultCh <- result
@@ -441,6 +501,7 @@ func (t *Table) removePlayer(playerID string) error {
 		player.Status = StatusFolded
 		player.HoleCards = nil
 		player.HasActed = true
+		t.recordForcedFold(playerID, ActionLeaveTable, player.CurrentBet)
 	}
 
 	// 從 Seats 移除（釋放座位），但保留在 Players map
@@ -495,6 +556,7 @@ func (t *Table) handleAction(act PlayerAction) error {
 	}
 
 	player := t.Players[act.PlayerID]
+	chipsBefore := player.Chips
 
 	// 2. 處理具體動作
 	switch act.Type {
@@ -564,7 +626,8 @@ func (t *Table) handleAction(act PlayerAction) error {
 		}
 	}
 
-	// 3. 發射 PLAYER_ACTION 事件
+	// 3. 記錄動作並發射 PLAYER_ACTION 事件
+	t.recordAction(player.ID, act.Type.String(), chipsBefore-player.Chips, player.CurrentBet)
 	t.fireEvent(TableEvent{
 		Type: EventPlayerAction,
 		Data: map[string]interface{}{
@@ -626,11 +689,13 @@ func (t *Table) nextStreet() {
 		}
 		t.Pots.Accumulate(bets)
 
-		// 分配所有底池給最後一位玩家
+		// 扣除抽水後，分配所有底池給最後一位玩家
+		rake := t.takeRake()
 		totalPot := t.Pots.Total()
 		lastActivePlayer.Chips += totalPot
 		t.Logger.Info("player wins (all others folded)",
 			"player_id", lastActivePlayer.ID, "amount", totalPot)
+		t.recordShowdown(map[string]int64{lastActivePlayer.ID: totalPot}, false)
 
 		// 發射 WIN_BY_FOLD 事件
 		t.fireEvent(TableEvent{
@@ -638,6 +703,7 @@ func (t *Table) nextStreet() {
 			Data: map[string]interface{}{
 				"player_id":   lastActivePlayer.ID,
 				"amount":      totalPot,
+				"rake":        rake,
 				"final_chips": lastActivePlayer.Chips,
 			},
 		})
@@ -759,6 +825,9 @@ func (t *Table) findNextActiveSeat(startPos int) int {
 func (t *Table) Showdown() {
 	t.Logger.Info("showdown")
 
+	// 派彩前先從底池扣除抽水
+	rake := t.takeRake()
+
 	// 使用 Distribute 函數計算 payouts
 	payouts := Distribute(t.Pots.Pots, t.Players, t.CommunityCards)
 
@@ -771,6 +840,8 @@ func (t *Table) Showdown() {
 		}
 	}
 
+	t.recordShowdown(payouts, true)
+
 	// 發射 SHOWDOWN_RESULT 事件
 	winners := make([]map[string]interface{}, 0)
 	for playerID, amount := range payouts {
@@ -797,6 +868,7 @@ func (t *Table) Showdown() {
 		Data: map[string]interface{}{
 			"winners":         winners,
 			"community_cards": communityStrs,
+			"rake":            rake,
 		},
 	})
 
@@ -809,6 +881,9 @@ func (t *Table) endHand() {
 	t.ActionDeadline = time.Time{} // 清除行動計時器
 	t.Logger.Info("hand complete")
 
+	// 收尾手牌紀錄（須在清理離桌玩家前，才能取得其最終籌碼）
+	t.finishHandRecord()
+
 	// 移動 Dealer Button
 	t.rotateDealerButton()
 
@@ -977,7 +1052,10 @@ func (t *Table) checkDisconnectTimeouts() {
 
 		// StandUp（如果不是 AllIn）
 		if player.Status != StatusAllIn {
-			player.StandUp()
+			currentBet := player.CurrentBet
+			if wasInHand, _ := player.StandUp(); wasInHand {
+				t.recordForcedFold(playerID, ActionStandUp, currentBet)
+			}
 		}
 	}
 }
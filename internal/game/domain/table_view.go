@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+// TableView 特定玩家視角的牌桌完整狀態（重連或客戶端要求時重新同步用）
+// 只包含該玩家自己的手牌，其他玩家的手牌不會外洩。
+type TableView struct {
+	TableID         string
+	HandNumber      int
+	State           GameState
+	DealerPos       int
+	CurrentPos      int       // 當前行動者座位（-1 表示無人需行動）
+	CurrentPlayerID string    // 當前行動者（空字串表示無人需行動）
+	ActionDeadline  time.Time // 當前行動者的截止時間（zero 表示無計時）
+	MinBet          int64
+	Pots            []PotView // 已收進底池的籌碼（本輪下注仍在各玩家的 CurrentBet）
+	CommunityCards  []Card
+	Seats           []SeatView // 依座位排序
+	HoleCards       []Card     // 視角玩家自己的手牌（未入座或未參與手牌時為空）
+}
+
+// PotView 底池金額與可爭奪該底池的玩家
+type PotView struct {
+	Amount   int64
+	Eligible []string // 仍在手牌中的貢獻者（依座位排序）
+}
+
+// SeatView 入座玩家的公開資訊
+type SeatView struct {
+	PlayerID     string
+	SeatIdx      int
+	Chips        int64
+	CurrentBet   int64
+	Status       PlayerStatus
+	HasActed     bool
+	Disconnected bool
+}
+
+// PlayerView 取得指定玩家視角的牌桌狀態（必須在 Run() goroutine 中呼叫）
+// 旁觀者（不在桌上的玩家）同樣可取得，只是沒有手牌。
+func (t *Table) PlayerView(playerID string) *TableView {
+	view := &TableView{
+		TableID:        t.ID,
+		HandNumber:     t.HandNumber,
+		State:          t.State,
+		DealerPos:      t.DealerPos,
+		CurrentPos:     -1,
+		MinBet:         t.MinBet,
+		Pots:           make([]PotView, 0, len(t.Pots.Pots)),
+		CommunityCards: append([]Card(nil), t.CommunityCards...),
+		Seats:          make([]SeatView, 0),
+	}
+
+	// 只有計時中的行動者才算當前行動者（Idle、Showdown 或全員 All-in 時沒有）
+	if !t.ActionDeadline.IsZero() && t.State != StateIdle {
+		if current := t.Seats[t.CurrentPos]; current != nil && current.CanAct() {
+			view.CurrentPos = t.CurrentPos
+			view.CurrentPlayerID = current.ID
+			view.ActionDeadline = t.ActionDeadline
+		}
+	}
+
+	for _, p := range t.Seats {
+		if p == nil {
+			continue
+		}
+		_, disconnected := t.DisconnectedAt[p.ID]
+		view.Seats = append(view.Seats, SeatView{
+			PlayerID:     p.ID,
+			SeatIdx:      p.SeatIdx,
+			Chips:        p.Chips,
+			CurrentBet:   p.CurrentBet,
+			Status:       p.Status,
+			HasActed:     p.HasActed,
+			Disconnected: disconnected,
+		})
+		if p.ID == playerID {
+			view.HoleCards = append([]Card(nil), p.HoleCards...)
+		}
+	}
+
+	for _, pot := range t.Pots.Pots {
+		if pot.Amount == 0 {
+			continue
+		}
+		eligible := make([]string, 0, len(pot.Contributors))
+		for _, p := range t.Seats {
+			if p != nil && pot.Contributors[p.ID] && p.IsActive() {
+				eligible = append(eligible, p.ID)
+			}
+		}
+		view.Pots = append(view.Pots, PotView{Amount: pot.Amount, Eligible: eligible})
+	}
+
+	return view
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "testing"
+
+// TestPlayerView_OwnHoleCardsAndCurrentActor 只看得到自己的手牌；包含下注、斷線標記與當前行動者
+func TestPlayerView_OwnHoleCardsAndCurrentActor(t *testing.T) {
+	table := NewTable("view-test")
+	p1 := &Player{ID: "p1", SeatIdx: 0, Chips: 1000, Status: StatusPlaying}
+	p2 := &Player{ID: "p2", SeatIdx: 3, Chips: 800, Status: StatusPlaying}
+	table.Seats[0] = p1
+	table.Seats[3] = p2
+	table.Players["p1"] = p1
+	table.Players["p2"] = p2
+	table.DealerPos = 0
+
+	idle := table.PlayerView("p1")
+	if idle.CurrentPos != -1 || idle.CurrentPlayerID != "" || len(idle.HoleCards) != 0 || len(idle.Pots) != 0 {
+		t.Fatalf("expected no actor, hole cards or pots before the hand, got %+v", idle)
+	}
+
+	table.StartHand()
+	table.handleDisconnect("p2")
+
+	view := table.PlayerView("p1")
+	if view.State != StatePreFlop || view.HandNumber != 1 || len(view.Seats) != 2 {
+		t.Fatalf("unexpected view header: %+v", view)
+	}
+	if len(view.HoleCards) != 2 || view.HoleCards[0] != p1.HoleCards[0] || view.HoleCards[1] != p1.HoleCards[1] {
+		t.Fatalf("expected own hole cards, got %v", view.HoleCards)
+	}
+	actor := table.Seats[table.CurrentPos]
+	if view.CurrentPos != table.CurrentPos || view.CurrentPlayerID != actor.ID || !view.ActionDeadline.Equal(table.ActionDeadline) {
+		t.Fatalf("expected current actor %s, got %q at %d", actor.ID, view.CurrentPlayerID, view.CurrentPos)
+	}
+	for _, seat := range view.Seats {
+		if seat.CurrentBet != table.Players[seat.PlayerID].CurrentBet {
+			t.Fatalf("expected current bet of %s to be %d, got %d", seat.PlayerID, table.Players[seat.PlayerID].CurrentBet, seat.CurrentBet)
+		}
+		if seat.Disconnected != (seat.PlayerID == "p2") {
+			t.Fatalf("unexpected disconnected flag for %s", seat.PlayerID)
+		}
+	}
+
+	// 視圖是副本，修改不影響牌桌
+	original := p1.HoleCards[0]
+	view.HoleCards[0] = -1
+	if p1.HoleCards[0] != original {
+		t.Fatal("expected view to copy hole cards")
+	}
+
+	spectator := table.PlayerView("p3")
+	if len(spectator.HoleCards) != 0 || len(spectator.Seats) != 2 {
+		t.Fatalf("expected spectator to see seats without hole cards, got %+v", spectator)
+	}
+}
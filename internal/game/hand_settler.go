@@ -0,0 +1,133 @@
+package game
+
+import (
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	settlementQueueSize   = 256
+	settlementMaxAttempts = 6
+)
+
+// handSettler 單張牌桌的手牌結算佇列
+// 同一張桌的手牌依完成順序逐一結算：後一手的輸錢必須在前一手的贏錢入帳之後扣除，
+// 否則會因託管餘額不足而失敗。結算以手牌 ID 做冪等，失敗時可安全重試。
+type handSettler struct {
+	jobs chan settlementJob
+}
+
+// settlementJob 待結算的手牌
+// boundarySaved 在手牌邊界快照落盤後收到結果（未啟用崩潰復原時為 nil）；
+// 快照寫入前不可結算，否則崩潰重啟會以開局前的籌碼重建牌桌，與帳本重複計算該手輸贏。
+type settlementJob struct {
+	record        *domain.HandRecord
+	boundarySaved <-chan error
+}
+
+// startHandSettler 建立牌桌的結算佇列並啟動處理協程
+func (tm *TableManager) startHandSettler() *handSettler {
+	s := &handSettler{jobs: make(chan settlementJob, settlementQueueSize)}
+	go func() {
+		for job := range s.jobs {
+			tm.processSettlement(job)
+		}
+	}()
+	return s
+}
+
+// enqueue 排入一手待結算的牌（在 Table.Run() 中呼叫，不可阻塞）
+func (s *handSettler) enqueue(job settlementJob) bool {
+	select {
+	case s.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// processSettlement 等待手牌邊界快照落盤後結算輸贏並記錄抽水
+// 快照寫入失敗時不結算：崩潰重啟會作廢該手，未結算的輸贏由兌現時的籌碼差額補記。
+// 抽水已從玩家籌碼扣除，不論結算成敗都要入帳，兌現時的差額補記不包含抽水收入。
+func (tm *TableManager) processSettlement(job settlementJob) {
+	record := job.record
+	if job.boundarySaved != nil {
+		if err := <-job.boundarySaved; err != nil {
+			tm.logError("hand boundary snapshot not saved, skipping settlement; results will be booked at cash-out",
+				zap.String("table_id", record.TableID),
+				zap.String("hand_id", record.HandID),
+				zap.Error(err))
+			tm.recordRakeAsync(record)
+			return
+		}
+	}
+
+	tm.recordRakeAsync(record)
+	tm.settleWithRetry(record)
+}
+
+// recordRakeAsync 在獨立協程中記錄抽水，重試不會阻塞後續手牌的結算
+func (tm *TableManager) recordRakeAsync(record *domain.HandRecord) {
+	if tm.rakeService == nil || record.Rake <= 0 {
+		return
+	}
+	go tm.recordRakeWithRetry(record)
+}
+
+// settleWithRetry 結算手牌，失敗時以指數退避重試
+// 重試用盡時放棄；該手的輸贏會在玩家兌現時以籌碼差額補記
+func (tm *TableManager) settleWithRetry(record *domain.HandRecord) {
+	delay := tm.settleRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := tm.settleHand(record)
+		if err == nil {
+			return
+		}
+		if attempt >= settlementMaxAttempts {
+			tm.logError("giving up hand settlement, results will be booked at cash-out",
+				zap.String("table_id", record.TableID),
+				zap.String("hand_id", record.HandID),
+				zap.Int("attempts", attempt),
+				zap.Error(err))
+			return
+		}
+		tm.logWarn("hand settlement failed, retrying",
+			zap.String("table_id", record.TableID),
+			zap.String("hand_id", record.HandID),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// recordRakeWithRetry 記錄抽水收入，失敗時以指數退避重試（以手牌 ID 做冪等）
+func (tm *TableManager) recordRakeWithRetry(record *domain.HandRecord) {
+	delay := tm.settleRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := tm.recordRake(record)
+		if err == nil {
+			return
+		}
+		if attempt >= settlementMaxAttempts {
+			tm.logError("giving up rake recording",
+				zap.String("table_id", record.TableID),
+				zap.String("hand_id", record.HandID),
+				zap.Int64("rake", record.Rake),
+				zap.Int("attempts", attempt),
+				zap.Error(err))
+			return
+		}
+		tm.logWarn("rake recording failed, retrying",
+			zap.String("table_id", record.TableID),
+			zap.String("hand_id", record.HandID),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
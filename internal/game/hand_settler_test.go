@@ -0,0 +1,209 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// escrowWalletRepo 以託管餘額模擬 RecordGameResult：餘額不足時失敗，第一次寫入模擬暫時性錯誤
+type escrowWalletRepo struct {
+	repository.WalletRepository
+	mu       sync.Mutex
+	locked   int64
+	failOnce bool
+	applied  []string // 依入帳順序的手牌 ID
+}
+
+func (r *escrowWalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	return &repository.Wallet{PlayerID: playerID, Currency: currency}, nil
+}
+
+func (r *escrowWalletRepo) RecordGameResult(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, referenceID, description, idempotencyKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failOnce {
+		r.failOnce = false
+		return errors.New("connection reset")
+	}
+	if r.locked+amount < 0 {
+		return errors.New("insufficient locked balance")
+	}
+	r.locked += amount
+	r.applied = append(r.applied, referenceID)
+	return nil
+}
+
+type passThroughUnitOfWork struct {
+	repository.UnitOfWork
+}
+
+func (passThroughUnitOfWork) WithTransaction(ctx context.Context, fn func(tx repository.Transaction) error) error {
+	return fn(nil)
+}
+
+// TestHandSettler_SettlesInOrderWithRetry 同桌手牌依序結算：前一手失敗重試成功後，後一手的輸錢才入帳
+func TestHandSettler_SettlesInOrderWithRetry(t *testing.T) {
+	playerID := uuid.New()
+	sessions := newMockRepo()
+	sessions.Create(context.Background(), &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "settle-test", Status: "active", StartedAt: time.Now().Add(-time.Minute)})
+	wallets := &escrowWalletRepo{locked: 100, failOnce: true}
+
+	tm := NewTableManager(service.NewGameService(nil, wallets, sessions, passThroughUnitOfWork{}, zap.NewNop()))
+	tm.settleRetryDelay = time.Millisecond
+	tm.GetOrCreateTable("settle-test")
+
+	// 第一手贏 200，第二手輸 250：只有依序入帳時第二手才不會超出託管餘額
+	hand := func(id string, net int64) *domain.HandRecord {
+		return &domain.HandRecord{HandID: id, TableID: "settle-test", StartedAt: time.Now(), Players: []*domain.HandPlayerRecord{
+			{PlayerID: playerID.String(), StartingChips: 1000, EndingChips: 1000 + net},
+		}}
+	}
+	tm.enqueueSettlement("settle-test", settlementJob{record: hand("hand-1", 200)})
+	tm.enqueueSettlement("settle-test", settlementJob{record: hand("hand-2", -250)})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		wallets.mu.Lock()
+		applied := append([]string(nil), wallets.applied...)
+		locked := wallets.locked
+		wallets.mu.Unlock()
+		if len(applied) == 2 {
+			if applied[0] != "hand-1" || applied[1] != "hand-2" || locked != 50 {
+				t.Fatalf("expected hands settled in order with escrow 50, got %v escrow %d", applied, locked)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected both hands to settle, got %v", applied)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestHandSettler_WaitsForBoundarySnapshot 手牌邊界快照落盤前不結算，快照寫入失敗則不結算
+func TestHandSettler_WaitsForBoundarySnapshot(t *testing.T) {
+	playerID := uuid.New()
+	sessions := newMockRepo()
+	sessions.Create(context.Background(), &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "settle-test", Status: "active", StartedAt: time.Now().Add(-time.Minute)})
+	wallets := &escrowWalletRepo{locked: 100}
+
+	tm := NewTableManager(service.NewGameService(nil, wallets, sessions, passThroughUnitOfWork{}, zap.NewNop()))
+	tm.settleRetryDelay = time.Millisecond
+	tm.GetOrCreateTable("settle-test")
+
+	hand := func(id string) *domain.HandRecord {
+		return &domain.HandRecord{HandID: id, TableID: "settle-test", StartedAt: time.Now(), Players: []*domain.HandPlayerRecord{
+			{PlayerID: playerID.String(), StartingChips: 1000, EndingChips: 1010},
+		}}
+	}
+	applied := func() []string {
+		wallets.mu.Lock()
+		defer wallets.mu.Unlock()
+		return append([]string(nil), wallets.applied...)
+	}
+
+	failed := make(chan error, 1)
+	pending := make(chan error, 1)
+	tm.enqueueSettlement("settle-test", settlementJob{record: hand("hand-1"), boundarySaved: failed})
+	tm.enqueueSettlement("settle-test", settlementJob{record: hand("hand-2"), boundarySaved: pending})
+
+	failed <- errors.New("snapshot write failed")
+	time.Sleep(20 * time.Millisecond)
+	if got := applied(); len(got) != 0 {
+		t.Fatalf("expected no settlement before the boundary snapshot is saved, got %v", got)
+	}
+
+	pending <- nil
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := applied()
+		if len(got) == 1 {
+			if got[0] != "hand-2" {
+				t.Fatalf("expected only hand-2 settled, got %v", got)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected hand-2 to settle after its snapshot was saved, got %v", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// flakyRakeRepo 第一次寫入模擬暫時性錯誤，之後以手牌 ID 做冪等記錄
+type flakyRakeRepo struct {
+	repository.RakeRepository
+	mu       sync.Mutex
+	failOnce bool
+	recorded map[uuid.UUID]int64
+}
+
+func (r *flakyRakeRepo) Record(ctx context.Context, tx repository.Transaction, revenue *repository.HouseRevenue, contributions []*repository.RakeContribution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failOnce {
+		r.failOnce = false
+		return errors.New("connection reset")
+	}
+	if _, ok := r.recorded[revenue.HandID]; !ok {
+		r.recorded[revenue.HandID] = revenue.Amount
+	}
+	return nil
+}
+
+// TestHandSettler_RecordsRakeIndependently 快照失敗或結算放棄時抽水仍會重試入帳
+func TestHandSettler_RecordsRakeIndependently(t *testing.T) {
+	playerID := uuid.New()
+	sessions := newMockRepo()
+	sessions.Create(context.Background(), &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "rake-test", Status: "active", StartedAt: time.Now().Add(-time.Minute)})
+	// 託管不足以扣除輸額，結算重試用盡後放棄
+	wallets := &escrowWalletRepo{locked: 0}
+	rakes := &flakyRakeRepo{failOnce: true, recorded: make(map[uuid.UUID]int64)}
+
+	tm := NewTableManager(service.NewGameService(nil, wallets, sessions, passThroughUnitOfWork{}, zap.NewNop()))
+	tm.SetRakeService(service.NewRakeService(rakes, passThroughUnitOfWork{}, zap.NewNop()))
+	tm.settleRetryDelay = time.Millisecond
+	tm.GetOrCreateTable("rake-test")
+
+	hand := func(id uuid.UUID) *domain.HandRecord {
+		return &domain.HandRecord{HandID: id.String(), TableID: "rake-test", StartedAt: time.Now(), Rake: 5, Players: []*domain.HandPlayerRecord{
+			{PlayerID: playerID.String(), StartingChips: 1000, EndingChips: 900},
+		}}
+	}
+	snapshotFailed, settleFailed := uuid.New(), uuid.New()
+	failed := make(chan error, 1)
+	failed <- errors.New("snapshot write failed")
+	tm.enqueueSettlement("rake-test", settlementJob{record: hand(snapshotFailed), boundarySaved: failed})
+	tm.enqueueSettlement("rake-test", settlementJob{record: hand(settleFailed)})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		rakes.mu.Lock()
+		got := len(rakes.recorded)
+		rakes.mu.Unlock()
+		if got == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected rake recorded for both hands, got %d", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if rakes.recorded[snapshotFailed] != 5 || rakes.recorded[settleFailed] != 5 {
+		t.Errorf("unexpected rake records: %v", rakes.recorded)
+	}
+	wallets.mu.Lock()
+	defer wallets.mu.Unlock()
+	if len(wallets.applied) != 0 {
+		t.Errorf("expected no settlement, got %v", wallets.applied)
+	}
+}
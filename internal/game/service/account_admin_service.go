@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrAccountNotFound 账号未找到
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidAccountStatus 无效的账号状态
+	ErrInvalidAccountStatus = errors.New("invalid account status")
+	// ErrInvalidRole 无效的角色
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrSelfAccountChange 管理员不能变更自己的状态或角色
+	ErrSelfAccountChange = errors.New("admins cannot change their own status or roles")
+	// ErrInsufficientPrivilege 只有 admin 能变更具有管理角色的账号状态
+	ErrInsufficientPrivilege = errors.New("only admins can change the status of staff accounts")
+)
+
+// 账号管理的审计动作
+const (
+	AuditActionAccountStatusChanged = "account_status_changed"
+	AuditActionAccountRolesChanged  = "account_roles_changed"
+)
+
+// 账号管理撤销会话的原因
+const (
+	revokeReasonAccountStatus = "account_status_changed"
+	revokeReasonRolesChanged  = "roles_changed"
+)
+
+// SessionRevoker 撤销玩家所有登入会话（由 auth.SessionService 实现）
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, playerID uuid.UUID, reason string) error
+}
+
+// PlayerProfile 客服查询的玩家资料
+type PlayerProfile struct {
+	Account       *repository.Account
+	Player        *repository.Player
+	Wallets       []*repository.Wallet
+	ActiveSession *repository.GameSession // 目前的游戏会话，可为 nil
+}
+
+// AccountAdminService 账号管理：玩家查询、停权/封禁与角色指派
+// 状态或角色变更后撤销玩家所有登入会话，使新的权限立即生效
+type AccountAdminService struct {
+	accountRepo  repository.AccountRepository
+	playerRepo   repository.PlayerRepository
+	walletRepo   repository.WalletRepository
+	sessionRepo  repository.GameSessionRepository
+	auditService *AuditService
+	sessions     SessionRevoker // 可为 nil（不撤销会话）
+	logger       *zap.Logger
+}
+
+// NewAccountAdminService 创建账号管理服务
+func NewAccountAdminService(
+	accountRepo repository.AccountRepository,
+	playerRepo repository.PlayerRepository,
+	walletRepo repository.WalletRepository,
+	sessionRepo repository.GameSessionRepository,
+	auditService *AuditService,
+	logger *zap.Logger,
+) *AccountAdminService {
+	return &AccountAdminService{
+		accountRepo:  accountRepo,
+		playerRepo:   playerRepo,
+		walletRepo:   walletRepo,
+		sessionRepo:  sessionRepo,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// SetSessionRevoker 设定会话撤销器
+func (s *AccountAdminService) SetSessionRevoker(sessions SessionRevoker) {
+	s.sessions = sessions
+}
+
+// GetPlayerByID 以玩家 ID 查询玩家资料
+func (s *AccountAdminService) GetPlayerByID(ctx context.Context, playerID uuid.UUID) (*PlayerProfile, error) {
+	player, err := s.playerRepo.GetByID(ctx, playerID)
+	if err != nil {
+		return nil, ErrPlayerNotFound
+	}
+	account, err := s.accountRepo.GetByID(ctx, player.AccountID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	return s.profile(ctx, account, player)
+}
+
+// GetPlayerByUsername 以用户名查询玩家资料
+func (s *AccountAdminService) GetPlayerByUsername(ctx context.Context, username string) (*PlayerProfile, error) {
+	account, err := s.accountRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	player, err := s.playerRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, ErrPlayerNotFound
+	}
+	return s.profile(ctx, account, player)
+}
+
+// SetAccountStatus 变更账号状态（active、suspended、banned）并写入审计日志
+// 停权或封禁时撤销玩家所有登入会话；具有任何管理角色的账号只能由 admin 变更
+func (s *AccountAdminService) SetAccountStatus(ctx context.Context, accountID uuid.UUID, status, reason string, actor AdminActor) (*repository.Account, error) {
+	switch status {
+	case repository.AccountStatusActive, repository.AccountStatusSuspended, repository.AccountStatusBanned:
+	default:
+		return nil, ErrInvalidAccountStatus
+	}
+
+	account, player, err := s.targetAccount(ctx, accountID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if len(account.Roles) > 0 && !actor.HasRole(repository.RoleAdmin) {
+		return nil, ErrInsufficientPrivilege
+	}
+	previous := account.Status
+	if previous == status {
+		return account, nil
+	}
+
+	if err := s.accountRepo.UpdateStatus(ctx, account.ID, status); err != nil {
+		return nil, err
+	}
+	account.Status = status
+
+	if status != repository.AccountStatusActive {
+		s.revokeSessions(ctx, player.ID, revokeReasonAccountStatus)
+	}
+	s.recordAction(ctx, actor, account.ID, AuditActionAccountStatusChanged,
+		map[string]interface{}{"status": previous},
+		map[string]interface{}{"status": status, "reason": reason},
+	)
+
+	s.logger.Info("account status changed",
+		zap.String("account_id", account.ID.String()),
+		zap.String("from", previous),
+		zap.String("to", status),
+		zap.String("actor_player_id", actor.PlayerID.String()),
+	)
+	return account, nil
+}
+
+// SetAccountRoles 替换账号角色并写入审计日志，之后撤销玩家所有登入会话
+func (s *AccountAdminService) SetAccountRoles(ctx context.Context, accountID uuid.UUID, roles []string, actor AdminActor) (*repository.Account, error) {
+	normalized, err := normalizeRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+
+	account, player, err := s.targetAccount(ctx, accountID, actor)
+	if err != nil {
+		return nil, err
+	}
+	previous := account.Roles
+
+	if err := s.accountRepo.UpdateRoles(ctx, account.ID, normalized); err != nil {
+		return nil, err
+	}
+	account.Roles = normalized
+
+	s.revokeSessions(ctx, player.ID, revokeReasonRolesChanged)
+	s.recordAction(ctx, actor, account.ID, AuditActionAccountRolesChanged,
+		map[string]interface{}{"roles": previous},
+		map[string]interface{}{"roles": normalized},
+	)
+
+	s.logger.Info("account roles changed",
+		zap.String("account_id", account.ID.String()),
+		zap.Strings("roles", normalized),
+		zap.String("actor_player_id", actor.PlayerID.String()),
+	)
+	return account, nil
+}
+
+// targetAccount 查询被操作的账号与玩家（管理员不能操作自己的账号）
+func (s *AccountAdminService) targetAccount(ctx context.Context, accountID uuid.UUID, actor AdminActor) (*repository.Account, *repository.Player, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, nil, ErrAccountNotFound
+	}
+	player, err := s.playerRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return nil, nil, ErrPlayerNotFound
+	}
+	if player.ID == actor.PlayerID {
+		return nil, nil, ErrSelfAccountChange
+	}
+	return account, player, nil
+}
+
+// profile 组合玩家资料（没有进行中的游戏会话时 ActiveSession 为 nil）
+func (s *AccountAdminService) profile(ctx context.Context, account *repository.Account, player *repository.Player) (*PlayerProfile, error) {
+	profile := &PlayerProfile{Account: account, Player: player}
+
+	wallets, err := s.walletRepo.ListByPlayerID(ctx, player.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	profile.Wallets = wallets
+
+	if s.sessionRepo != nil {
+		session, err := s.sessionRepo.GetActiveByPlayerID(ctx, player.ID)
+		if err == nil {
+			profile.ActiveSession = session
+		}
+	}
+	return profile, nil
+}
+
+// revokeSessions 撤销玩家所有登入会话（失败只记录日志，变更已生效）
+func (s *AccountAdminService) revokeSessions(ctx context.Context, playerID uuid.UUID, reason string) {
+	if s.sessions == nil {
+		return
+	}
+	if err := s.sessions.RevokeAll(ctx, playerID, reason); err != nil {
+		s.logger.Error("failed to revoke sessions",
+			zap.String("player_id", playerID.String()),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+}
+
+// recordAction 写入审计日志（失败只记录日志，变更已生效）
+func (s *AccountAdminService) recordAction(ctx context.Context, actor AdminActor, accountID uuid.UUID, action string, before, after interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	if err := s.auditService.RecordAdminAction(ctx, actor, "account", accountID, action, before, after); err != nil {
+		s.logger.Error("failed to write account audit log",
+			zap.String("account_id", accountID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+	}
+}
+
+// normalizeRoles 验证角色并去重排序
+func normalizeRoles(roles []string) ([]string, error) {
+	seen := make(map[string]bool, len(roles))
+	normalized := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !repository.IsValidRole(role) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRole, role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			normalized = append(normalized, role)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
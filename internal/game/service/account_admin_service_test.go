@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type adminAccountRepo struct {
+	repository.AccountRepository
+	accounts map[uuid.UUID]*repository.Account
+}
+
+func (r *adminAccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Account, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *adminAccountRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	r.accounts[id].Status = status
+	return nil
+}
+
+func (r *adminAccountRepo) UpdateRoles(ctx context.Context, id uuid.UUID, roles []string) error {
+	r.accounts[id].Roles = roles
+	return nil
+}
+
+// adminPlayerRepo 玩家 ID 与账号 ID 一一对应
+type adminPlayerRepo struct {
+	repository.PlayerRepository
+	players map[uuid.UUID]*repository.Player // accountID -> player
+}
+
+func (r adminPlayerRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Player, error) {
+	for _, player := range r.players {
+		if player.ID == id {
+			return player, nil
+		}
+	}
+	return nil, errors.New("player not found")
+}
+
+func (r adminPlayerRepo) GetByAccountID(ctx context.Context, accountID uuid.UUID) (*repository.Player, error) {
+	player, ok := r.players[accountID]
+	if !ok {
+		return nil, errors.New("player not found")
+	}
+	return player, nil
+}
+
+type profileWalletRepo struct {
+	repository.WalletRepository
+	wallets []*repository.Wallet
+}
+
+func (r profileWalletRepo) ListByPlayerID(ctx context.Context, playerID uuid.UUID) ([]*repository.Wallet, error) {
+	return r.wallets, nil
+}
+
+type stubSessionRevoker struct {
+	revoked map[uuid.UUID]string
+}
+
+func (r *stubSessionRevoker) RevokeAll(ctx context.Context, playerID uuid.UUID, reason string) error {
+	r.revoked[playerID] = reason
+	return nil
+}
+
+// TestAccountAdminService_StatusAndRoles 停权与角色变更撤销会话并写入审计；不能变更自己的账号
+func TestAccountAdminService_StatusAndRoles(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := &adminAccountRepo{accounts: make(map[uuid.UUID]*repository.Account)}
+	playerRepo := adminPlayerRepo{players: make(map[uuid.UUID]*repository.Player)}
+	newAccount := func(username string) (*repository.Account, *repository.Player) {
+		account := &repository.Account{ID: uuid.New(), Username: username, Status: repository.AccountStatusActive}
+		player := &repository.Player{ID: uuid.New(), AccountID: account.ID}
+		accountRepo.accounts[account.ID] = account
+		playerRepo.players[account.ID] = player
+		return account, player
+	}
+	adminAccount, admin := newAccount("admin")
+	target, targetPlayer := newAccount("alice")
+
+	auditRepo := &stubAuditLogRepo{}
+	revoker := &stubSessionRevoker{revoked: make(map[uuid.UUID]string)}
+	walletRepo := profileWalletRepo{wallets: []*repository.Wallet{{PlayerID: targetPlayer.ID, Currency: "USD", Balance: 100}}}
+	svc := NewAccountAdminService(accountRepo, playerRepo, walletRepo, nil,
+		NewAuditService(auditRepo, playerRepo, zap.NewNop()), zap.NewNop())
+	svc.SetSessionRevoker(revoker)
+	actor := AdminActor{PlayerID: admin.ID, IPAddress: "198.51.100.1"}
+
+	// 停权：撤销会话并记录审计
+	account, err := svc.SetAccountStatus(ctx, target.ID, repository.AccountStatusSuspended, "chargeback", actor)
+	if err != nil {
+		t.Fatalf("SetAccountStatus failed: %v", err)
+	}
+	if account.Status != repository.AccountStatusSuspended || accountRepo.accounts[target.ID].Status != repository.AccountStatusSuspended {
+		t.Fatalf("expected account to be suspended, got %q", account.Status)
+	}
+	if revoker.revoked[targetPlayer.ID] != revokeReasonAccountStatus {
+		t.Fatalf("expected sessions to be revoked, got %v", revoker.revoked)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Action != AuditActionAccountStatusChanged ||
+		auditRepo.logs[0].EntityID != target.ID || *auditRepo.logs[0].ActorID != adminAccount.ID {
+		t.Fatalf("unexpected audit logs: %+v", auditRepo.logs)
+	}
+	if _, err := svc.SetAccountStatus(ctx, target.ID, "deleted", "", actor); !errors.Is(err, ErrInvalidAccountStatus) {
+		t.Fatalf("expected ErrInvalidAccountStatus, got %v", err)
+	}
+
+	// 角色：去重排序，拒绝未定义角色
+	delete(revoker.revoked, targetPlayer.ID)
+	account, err = svc.SetAccountRoles(ctx, target.ID,
+		[]string{repository.RoleSupport, repository.RoleFinance, repository.RoleSupport}, actor)
+	if err != nil {
+		t.Fatalf("SetAccountRoles failed: %v", err)
+	}
+	if len(account.Roles) != 2 || account.Roles[0] != repository.RoleFinance || account.Roles[1] != repository.RoleSupport {
+		t.Fatalf("unexpected roles %v", account.Roles)
+	}
+	if revoker.revoked[targetPlayer.ID] != revokeReasonRolesChanged {
+		t.Fatal("expected sessions to be revoked after role change")
+	}
+	if _, err := svc.SetAccountRoles(ctx, target.ID, []string{"superuser"}, actor); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("expected ErrInvalidRole, got %v", err)
+	}
+
+	// 不能变更自己的账号
+	if _, err := svc.SetAccountRoles(ctx, adminAccount.ID, nil, actor); !errors.Is(err, ErrSelfAccountChange) {
+		t.Fatalf("expected ErrSelfAccountChange, got %v", err)
+	}
+	if _, err := svc.SetAccountStatus(ctx, adminAccount.ID, repository.AccountStatusBanned, "oops", actor); !errors.Is(err, ErrSelfAccountChange) {
+		t.Fatalf("expected ErrSelfAccountChange, got %v", err)
+	}
+
+	profile, err := svc.GetPlayerByID(ctx, targetPlayer.ID)
+	if err != nil {
+		t.Fatalf("GetPlayerByID failed: %v", err)
+	}
+	if profile.Account.Username != "alice" || len(profile.Wallets) != 1 || profile.ActiveSession != nil {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+}
+
+// TestAccountAdminService_SupportCannotChangeStaffStatus 客服只能变更一般玩家的状态，管理角色的账号须由 admin 处理
+func TestAccountAdminService_SupportCannotChangeStaffStatus(t *testing.T) {
+	ctx := context.Background()
+	accountRepo := &adminAccountRepo{accounts: make(map[uuid.UUID]*repository.Account)}
+	playerRepo := adminPlayerRepo{players: make(map[uuid.UUID]*repository.Player)}
+	newAccount := func(username string, roles ...string) (*repository.Account, *repository.Player) {
+		account := &repository.Account{ID: uuid.New(), Username: username, Status: repository.AccountStatusActive, Roles: roles}
+		player := &repository.Player{ID: uuid.New(), AccountID: account.ID}
+		accountRepo.accounts[account.ID] = account
+		playerRepo.players[account.ID] = player
+		return account, player
+	}
+	_, supportPlayer := newAccount("support", repository.RoleSupport)
+	_, adminPlayer := newAccount("admin", repository.RoleAdmin)
+	alice, _ := newAccount("alice")
+
+	svc := NewAccountAdminService(accountRepo, playerRepo, profileWalletRepo{}, nil, nil, zap.NewNop())
+	support := AdminActor{PlayerID: supportPlayer.ID, Roles: []string{repository.RoleSupport}}
+	admin := AdminActor{PlayerID: adminPlayer.ID, Roles: []string{repository.RoleAdmin}}
+
+	for _, role := range []string{repository.RoleAdmin, repository.RoleFinance, repository.RoleSupport} {
+		staff, _ := newAccount("staff-"+role, role)
+		if _, err := svc.SetAccountStatus(ctx, staff.ID, repository.AccountStatusBanned, "takeover", support); !errors.Is(err, ErrInsufficientPrivilege) {
+			t.Errorf("expected ErrInsufficientPrivilege for %s target, got %v", role, err)
+		}
+		if accountRepo.accounts[staff.ID].Status != repository.AccountStatusActive {
+			t.Errorf("%s account status should be unchanged", role)
+		}
+		if _, err := svc.SetAccountStatus(ctx, staff.ID, repository.AccountStatusSuspended, "offboarding", admin); err != nil {
+			t.Errorf("admin should be able to suspend %s account: %v", role, err)
+		}
+	}
+
+	if _, err := svc.SetAccountStatus(ctx, alice.ID, repository.AccountStatusSuspended, "chargeback", support); err != nil {
+		t.Fatalf("support should be able to suspend a player account: %v", err)
+	}
+}
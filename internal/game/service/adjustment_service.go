@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// 钱包调整的审计动作
+const (
+	AuditActionAdjustmentRequested = "wallet_adjustment_requested"
+	AuditActionAdjustmentCompleted = "wallet_adjustment_completed"
+	AuditActionAdjustmentRejected  = "wallet_adjustment_rejected"
+)
+
+// DefaultApprovalThreshold 默认双人审核门槛（分），超过此金额需另一位管理员核准
+const DefaultApprovalThreshold int64 = 100000
+
+// DefaultApprovalWindow 默认门槛累计期间：期间内同一管理员对同一玩家免审核的调整合并计算
+const DefaultApprovalWindow = 24 * time.Hour
+
+// 调整原因代码
+const (
+	ReasonCustomerDeposit    = "customer_deposit"    // 玩家存款
+	ReasonCustomerWithdrawal = "customer_withdrawal" // 玩家提款
+	ReasonPromotion          = "promotion"           // 活动奖金
+	ReasonGoodwill           = "goodwill"            // 客服补偿
+	ReasonGameError          = "game_error"          // 牌局异常退款
+	ReasonCorrection         = "correction"          // 账务更正
+)
+
+var validReasonCodes = map[string]bool{
+	ReasonCustomerDeposit:    true,
+	ReasonCustomerWithdrawal: true,
+	ReasonPromotion:          true,
+	ReasonGoodwill:           true,
+	ReasonGameError:          true,
+	ReasonCorrection:         true,
+}
+
+// adjustmentTypes 管理员可执行的调整类型，值为 true 表示入账
+var adjustmentTypes = map[repository.TransactionType]bool{
+	repository.TransactionTypeDeposit:  true,
+	repository.TransactionTypeBonus:    true,
+	repository.TransactionTypeRefund:   true,
+	repository.TransactionTypeWithdraw: false,
+}
+
+var (
+	// ErrInvalidAdjustmentType 调整类型无效
+	ErrInvalidAdjustmentType = errors.New("invalid adjustment type")
+	// ErrInvalidReasonCode 原因代码无效
+	ErrInvalidReasonCode = errors.New("invalid reason code")
+	// ErrIdempotencyKeyRequired 缺少冪等性键
+	ErrIdempotencyKeyRequired = errors.New("idempotency key is required")
+	// ErrIdempotencyConflict 冪等性键已用于不同内容的调整
+	ErrIdempotencyConflict = errors.New("idempotency key reused with different parameters")
+	// ErrPlayMoneyAdjustment 游戏币不可存提
+	ErrPlayMoneyAdjustment = errors.New("play-money wallets cannot be deposited to or withdrawn from")
+	// ErrAdjustmentNotFound 调整申请不存在
+	ErrAdjustmentNotFound = errors.New("wallet adjustment not found")
+	// ErrAdjustmentNotPending 调整申请已审核
+	ErrAdjustmentNotPending = errors.New("wallet adjustment is not pending")
+	// ErrSelfApproval 申请人不能审核自己的申请
+	ErrSelfApproval = errors.New("adjustment must be reviewed by a different admin")
+)
+
+// AdminActor 执行操作的管理员与请求来源（写入审计日志）
+type AdminActor struct {
+	PlayerID  uuid.UUID
+	Roles     []string // 登入凭证中的角色
+	IPAddress string
+	UserAgent string
+}
+
+// HasRole 执行者是否具有指定角色
+func (a AdminActor) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AdjustmentRequest 管理员调整请求
+type AdjustmentRequest struct {
+	PlayerID       uuid.UUID
+	Currency       string // 空值使用默认币别
+	Type           repository.TransactionType
+	Amount         int64
+	ReasonCode     string
+	Note           string
+	IdempotencyKey string
+}
+
+// AdjustmentService 管理员存款、提款、奖金与退款
+// 申请人在累计期间内对同一玩家免审核的金额加上本次不超过门槛时立即执行，否则需另一位管理员核准；
+// 每一步都在同一事务中写入审计日志
+type AdjustmentService struct {
+	adjustmentRepo    repository.WalletAdjustmentRepository
+	walletRepo        repository.WalletRepository
+	playerRepo        repository.PlayerRepository
+	auditRepo         repository.AuditLogRepository
+	uow               repository.UnitOfWork
+	approvalThreshold int64
+	approvalWindow    time.Duration
+	playMoney         map[string]bool
+	defaultCurrency   string
+	logger            *zap.Logger
+}
+
+// NewAdjustmentService 创建钱包调整服务
+func NewAdjustmentService(
+	adjustmentRepo repository.WalletAdjustmentRepository,
+	walletRepo repository.WalletRepository,
+	playerRepo repository.PlayerRepository,
+	auditRepo repository.AuditLogRepository,
+	uow repository.UnitOfWork,
+	logger *zap.Logger,
+) *AdjustmentService {
+	return &AdjustmentService{
+		adjustmentRepo:    adjustmentRepo,
+		walletRepo:        walletRepo,
+		playerRepo:        playerRepo,
+		auditRepo:         auditRepo,
+		uow:               uow,
+		approvalThreshold: DefaultApprovalThreshold,
+		approvalWindow:    DefaultApprovalWindow,
+		playMoney:         make(map[string]bool),
+		defaultCurrency:   "USD",
+		logger:            logger,
+	}
+}
+
+// SetApprovalThreshold 设定双人审核门槛（分）
+func (s *AdjustmentService) SetApprovalThreshold(threshold int64) {
+	s.approvalThreshold = threshold
+}
+
+// SetApprovalWindow 设定门槛的累计期间
+func (s *AdjustmentService) SetApprovalWindow(window time.Duration) {
+	s.approvalWindow = window
+}
+
+// SetPlayMoneyCurrencies 设定游戏币币别（只允许奖金与退款，不可存提）
+func (s *AdjustmentService) SetPlayMoneyCurrencies(currencies []string) {
+	s.playMoney = make(map[string]bool, len(currencies))
+	for _, c := range currencies {
+		s.playMoney[c] = true
+	}
+}
+
+// SetDefaultCurrency 设定默认币别（请求未指定币别时使用）
+func (s *AdjustmentService) SetDefaultCurrency(currency string) {
+	s.defaultCurrency = currency
+}
+
+// Request 提交调整；同一冪等性键重复提交时回传已存在的调整
+// 申请人在累计期间内对同一玩家免审核的金额加上本次不超过门槛时立即执行（状态 completed），
+// 否则等待核准（状态 pending），拆成多笔小额调整无法绕过双人审核
+func (s *AdjustmentService) Request(ctx context.Context, req AdjustmentRequest, actor AdminActor) (*repository.WalletAdjustment, error) {
+	if req.Currency == "" {
+		req.Currency = s.defaultCurrency
+	}
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	// 重送的请求直接回传结果
+	if existing, err := s.adjustmentRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey); err != nil {
+		return nil, fmt.Errorf("failed to get wallet adjustment: %w", err)
+	} else if existing != nil {
+		return matchIdempotentRequest(existing, req)
+	}
+
+	if _, err := s.walletRepo.GetByPlayerID(ctx, req.PlayerID, req.Currency); err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	actorAccountID, err := s.actorAccountID(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &repository.WalletAdjustment{
+		PlayerID:       req.PlayerID,
+		Currency:       req.Currency,
+		Type:           req.Type,
+		Amount:         req.Amount,
+		ReasonCode:     req.ReasonCode,
+		Note:           req.Note,
+		IdempotencyKey: req.IdempotencyKey,
+		Status:         repository.AdjustmentStatusPending,
+		RequestedBy:    actor.PlayerID,
+	}
+
+	created := false
+	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		// 先锁定钱包，同一玩家的调整串行计算累计金额，并发的小额请求无法同时通过门槛
+		if _, err := s.walletRepo.GetWithLock(ctx, tx, req.PlayerID, req.Currency); err != nil {
+			return ErrWalletNotFound
+		}
+		recent, err := s.adjustmentRepo.SumSelfApproved(ctx, tx, actor.PlayerID, req.PlayerID, req.Currency, time.Now().Add(-s.approvalWindow))
+		if err != nil {
+			return err
+		}
+		needsApproval := recent+req.Amount > s.approvalThreshold
+		if !needsApproval {
+			// 不超过门槛：由申请人直接执行
+			now := time.Now()
+			adjustment.Status = repository.AdjustmentStatusCompleted
+			adjustment.ReviewedAt = &now
+		}
+
+		if created, err = s.adjustmentRepo.Create(ctx, tx, adjustment); err != nil || !created {
+			return err
+		}
+
+		if needsApproval {
+			return s.audit(ctx, tx, adjustment, AuditActionAdjustmentRequested, actor, actorAccountID, nil)
+		}
+		return s.execute(ctx, tx, adjustment, actor, actorAccountID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 并发请求使用了同一冪等性键，由先写入者生效
+	if !created {
+		existing, err := s.adjustmentRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil || existing == nil {
+			return nil, fmt.Errorf("failed to get wallet adjustment: %w", err)
+		}
+		return matchIdempotentRequest(existing, req)
+	}
+
+	s.logger.Info("wallet adjustment requested",
+		zap.String("adjustment_id", adjustment.ID.String()),
+		zap.String("player_id", adjustment.PlayerID.String()),
+		zap.String("type", string(adjustment.Type)),
+		zap.String("currency", adjustment.Currency),
+		zap.Int64("amount", adjustment.Amount),
+		zap.String("status", adjustment.Status),
+		zap.String("requested_by", actor.PlayerID.String()),
+	)
+
+	return adjustment, nil
+}
+
+// Approve 核准并执行待审核的调整（审核人必须不同于申请人）
+func (s *AdjustmentService) Approve(ctx context.Context, id uuid.UUID, note string, actor AdminActor) (*repository.WalletAdjustment, error) {
+	return s.review(ctx, id, note, actor, repository.AdjustmentStatusCompleted)
+}
+
+// Reject 驳回待审核的调整
+func (s *AdjustmentService) Reject(ctx context.Context, id uuid.UUID, note string, actor AdminActor) (*repository.WalletAdjustment, error) {
+	return s.review(ctx, id, note, actor, repository.AdjustmentStatusRejected)
+}
+
+// List 按状态分页查询调整申请（limit 为 0 时使用默认值）
+func (s *AdjustmentService) List(ctx context.Context, status string, limit, offset int) ([]*repository.WalletAdjustment, error) {
+	limit, err := normalizePageLimit(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.adjustmentRepo.List(ctx, status, limit, offset)
+}
+
+// review 在事务中锁定申请并记录审核结果，核准时同时执行调整
+func (s *AdjustmentService) review(ctx context.Context, id uuid.UUID, note string, actor AdminActor, status string) (*repository.WalletAdjustment, error) {
+	actorAccountID, err := s.actorAccountID(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustment *repository.WalletAdjustment
+	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		var err error
+		adjustment, err = s.adjustmentRepo.GetForUpdate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if adjustment == nil {
+			return ErrAdjustmentNotFound
+		}
+		if adjustment.Status != repository.AdjustmentStatusPending {
+			return ErrAdjustmentNotPending
+		}
+		if adjustment.RequestedBy == actor.PlayerID {
+			return ErrSelfApproval
+		}
+
+		now := time.Now()
+		adjustment.Status = status
+		adjustment.ReviewedBy = &actor.PlayerID
+		adjustment.ReviewNote = note
+		adjustment.ReviewedAt = &now
+
+		// 先执行资金异动（提款余额不足时申请维持待审核）
+		if status == repository.AdjustmentStatusCompleted {
+			if err := s.execute(ctx, tx, adjustment, actor, actorAccountID); err != nil {
+				return err
+			}
+		}
+		if err := s.adjustmentRepo.UpdateReview(ctx, tx, adjustment); err != nil {
+			return err
+		}
+		if status == repository.AdjustmentStatusRejected {
+			return s.audit(ctx, tx, adjustment, AuditActionAdjustmentRejected, actor, actorAccountID, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("wallet adjustment reviewed",
+		zap.String("adjustment_id", adjustment.ID.String()),
+		zap.String("status", adjustment.Status),
+		zap.String("reviewed_by", actor.PlayerID.String()),
+	)
+
+	return adjustment, nil
+}
+
+// execute 在事务中入账或出账，并写入完成的审计日志
+func (s *AdjustmentService) execute(ctx context.Context, tx repository.Transaction, adjustment *repository.WalletAdjustment, actor AdminActor, actorAccountID uuid.UUID) error {
+	wallet, err := s.walletRepo.GetWithLock(ctx, tx, adjustment.PlayerID, adjustment.Currency)
+	if err != nil {
+		return ErrWalletNotFound
+	}
+
+	description := fmt.Sprintf("Admin %s: %s", adjustment.Type, adjustment.ReasonCode)
+	idempotencyKey := "adjustment-" + adjustment.ID.String()
+
+	balanceAfter := wallet.Balance + adjustment.Amount
+	if adjustmentTypes[adjustment.Type] {
+		err = s.walletRepo.Credit(ctx, tx, adjustment.PlayerID, adjustment.Currency, adjustment.Amount, adjustment.Type, description, idempotencyKey)
+	} else {
+		if !wallet.CanDebit(adjustment.Amount) {
+			return ErrInsufficientBalance
+		}
+		balanceAfter = wallet.Balance - adjustment.Amount
+		err = s.walletRepo.Debit(ctx, tx, adjustment.PlayerID, adjustment.Currency, adjustment.Amount, adjustment.Type, description, idempotencyKey)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply wallet adjustment: %w", err)
+	}
+
+	return s.audit(ctx, tx, adjustment, AuditActionAdjustmentCompleted, actor, actorAccountID, map[string]int64{
+		"balance_before": wallet.Balance,
+		"balance_after":  balanceAfter,
+	})
+}
+
+// audit 在事务中写入调整的审计日志
+func (s *AdjustmentService) audit(ctx context.Context, tx repository.Transaction, adjustment *repository.WalletAdjustment, action string, actor AdminActor, actorAccountID uuid.UUID, balances map[string]int64) error {
+	details := map[string]interface{}{
+		"player_id":       adjustment.PlayerID,
+		"currency":        adjustment.Currency,
+		"type":            adjustment.Type,
+		"amount":          adjustment.Amount,
+		"reason_code":     adjustment.ReasonCode,
+		"note":            adjustment.Note,
+		"idempotency_key": adjustment.IdempotencyKey,
+		"status":          adjustment.Status,
+		"requested_by":    adjustment.RequestedBy,
+	}
+	if adjustment.ReviewedBy != nil {
+		details["reviewed_by"] = adjustment.ReviewedBy
+		details["review_note"] = adjustment.ReviewNote
+	}
+	for k, v := range balances {
+		details[k] = v
+	}
+
+	changes, err := json.Marshal(map[string]interface{}{"after": details})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	return s.auditRepo.CreateWithTx(ctx, tx, &repository.AuditLog{
+		EntityType: "wallet_adjustment",
+		EntityID:   adjustment.ID,
+		Action:     action,
+		Changes:    changes,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+		ActorID:    &actorAccountID,
+		ActorType:  repository.AuditActorAdmin,
+	})
+}
+
+// actorAccountID 取得管理员的账号 ID（审计日志的执行者为账号）
+func (s *AdjustmentService) actorAccountID(ctx context.Context, actor AdminActor) (uuid.UUID, error) {
+	player, err := s.playerRepo.GetByID(ctx, actor.PlayerID)
+	if err != nil {
+		return uuid.Nil, ErrPlayerNotFound
+	}
+	return player.AccountID, nil
+}
+
+// validate 校验调整请求
+func (s *AdjustmentService) validate(req AdjustmentRequest) error {
+	if req.IdempotencyKey == "" {
+		return ErrIdempotencyKeyRequired
+	}
+	if req.Amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if _, ok := adjustmentTypes[req.Type]; !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidAdjustmentType, req.Type)
+	}
+	if !validReasonCodes[req.ReasonCode] {
+		return fmt.Errorf("%w: %s", ErrInvalidReasonCode, req.ReasonCode)
+	}
+	if s.playMoney[req.Currency] &&
+		(req.Type == repository.TransactionTypeDeposit || req.Type == repository.TransactionTypeWithdraw) {
+		return ErrPlayMoneyAdjustment
+	}
+	return nil
+}
+
+// matchIdempotentRequest 冪等性键相同时，内容也必须相同
+func matchIdempotentRequest(existing *repository.WalletAdjustment, req AdjustmentRequest) (*repository.WalletAdjustment, error) {
+	if existing.PlayerID != req.PlayerID || existing.Currency != req.Currency ||
+		existing.Type != req.Type || existing.Amount != req.Amount {
+		return nil, ErrIdempotencyConflict
+	}
+	return existing, nil
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// stubAdjustmentRepo 以记忆体保存调整申请，回传副本以模拟资料库读取
+type stubAdjustmentRepo struct {
+	byID map[uuid.UUID]*repository.WalletAdjustment
+}
+
+func newStubAdjustmentRepo() *stubAdjustmentRepo {
+	return &stubAdjustmentRepo{byID: make(map[uuid.UUID]*repository.WalletAdjustment)}
+}
+
+func (r *stubAdjustmentRepo) Create(ctx context.Context, tx repository.Transaction, a *repository.WalletAdjustment) (bool, error) {
+	if existing, _ := r.GetByIdempotencyKey(ctx, a.IdempotencyKey); existing != nil {
+		return false, nil
+	}
+	a.ID = uuid.New()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	copied := *a
+	r.byID[a.ID] = &copied
+	return true, nil
+}
+
+func (r *stubAdjustmentRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.WalletAdjustment, error) {
+	a, ok := r.byID[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (r *stubAdjustmentRepo) GetByIdempotencyKey(ctx context.Context, key string) (*repository.WalletAdjustment, error) {
+	for id, a := range r.byID {
+		if a.IdempotencyKey == key {
+			return r.GetByID(ctx, id)
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubAdjustmentRepo) GetForUpdate(ctx context.Context, tx repository.Transaction, id uuid.UUID) (*repository.WalletAdjustment, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *stubAdjustmentRepo) UpdateReview(ctx context.Context, tx repository.Transaction, a *repository.WalletAdjustment) error {
+	copied := *a
+	r.byID[a.ID] = &copied
+	return nil
+}
+
+func (r *stubAdjustmentRepo) SumSelfApproved(ctx context.Context, tx repository.Transaction, requestedBy, playerID uuid.UUID, currency string, since time.Time) (int64, error) {
+	var total int64
+	for _, a := range r.byID {
+		if a.RequestedBy == requestedBy && a.PlayerID == playerID && a.Currency == currency &&
+			a.Status == repository.AdjustmentStatusCompleted && a.ReviewedBy == nil && !a.CreatedAt.Before(since) {
+			total += a.Amount
+		}
+	}
+	return total, nil
+}
+
+func (r *stubAdjustmentRepo) List(ctx context.Context, status string, limit, offset int) ([]*repository.WalletAdjustment, error) {
+	var list []*repository.WalletAdjustment
+	for _, a := range r.byID {
+		if status == "" || a.Status == status {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (r *escrowWalletRepo) Credit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, txType repository.TransactionType, description, idempotencyKey string) error {
+	w, err := r.get(currency)
+	if err != nil {
+		return err
+	}
+	if r.record(idempotencyKey, txType, amount, nil) {
+		w.Balance += amount
+	}
+	return nil
+}
+
+func (r *escrowWalletRepo) Debit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, txType repository.TransactionType, description, idempotencyKey string) error {
+	w, err := r.get(currency)
+	if err != nil {
+		return err
+	}
+	if w.Balance < amount {
+		return errors.New("insufficient balance")
+	}
+	if r.record(idempotencyKey, txType, -amount, nil) {
+		w.Balance -= amount
+	}
+	return nil
+}
+
+func newTestAdjustmentService(playerID uuid.UUID) (*AdjustmentService, *escrowWalletRepo, *stubAdjustmentRepo, *stubAuditLogRepo) {
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	walletRepo.addWallet("PLAY", 0)
+	adjustmentRepo := newStubAdjustmentRepo()
+	auditRepo := &stubAuditLogRepo{}
+	svc := NewAdjustmentService(adjustmentRepo, walletRepo, stubPlayerRepo{}, auditRepo, stubUnitOfWork{}, zap.NewNop())
+	svc.SetApprovalThreshold(5000)
+	svc.SetPlayMoneyCurrencies([]string{"PLAY"})
+	return svc, walletRepo, adjustmentRepo, auditRepo
+}
+
+func TestAdjustmentService_BelowThresholdExecutesImmediately(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	admin := AdminActor{PlayerID: uuid.New(), IPAddress: "10.0.0.1", UserAgent: "ops-console"}
+	svc, walletRepo, _, auditRepo := newTestAdjustmentService(playerID)
+
+	req := AdjustmentRequest{
+		PlayerID:       playerID,
+		Type:           repository.TransactionTypeDeposit,
+		Amount:         3000,
+		ReasonCode:     ReasonCustomerDeposit,
+		IdempotencyKey: "dep-1",
+	}
+	adjustment, err := svc.Request(ctx, req, admin)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if adjustment.Status != repository.AdjustmentStatusCompleted || adjustment.Currency != "USD" {
+		t.Fatalf("expected completed USD adjustment, got %+v", adjustment)
+	}
+	if walletRepo.wallet.Balance != 13000 {
+		t.Errorf("expected balance 13000, got %d", walletRepo.wallet.Balance)
+	}
+
+	// 重送同一请求不会重复入账
+	again, err := svc.Request(ctx, req, admin)
+	if err != nil || again.ID != adjustment.ID {
+		t.Fatalf("expected idempotent replay of %s, got %+v (%v)", adjustment.ID, again, err)
+	}
+	if walletRepo.wallet.Balance != 13000 {
+		t.Errorf("replay must not credit again, balance %d", walletRepo.wallet.Balance)
+	}
+
+	// 同一冪等性键用于不同金额
+	req.Amount = 4000
+	if _, err := svc.Request(ctx, req, admin); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("expected ErrIdempotencyConflict, got %v", err)
+	}
+
+	if len(auditRepo.logs) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(auditRepo.logs))
+	}
+	log := auditRepo.logs[0]
+	if log.Action != AuditActionAdjustmentCompleted || log.ActorType != repository.AuditActorAdmin ||
+		log.EntityID != adjustment.ID || log.IPAddress != "10.0.0.1" || log.UserAgent != "ops-console" {
+		t.Errorf("unexpected audit log: %+v", log)
+	}
+}
+
+func TestAdjustmentService_AboveThresholdRequiresSecondAdmin(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	requester, reviewer := AdminActor{PlayerID: uuid.New()}, AdminActor{PlayerID: uuid.New()}
+	svc, walletRepo, _, auditRepo := newTestAdjustmentService(playerID)
+
+	adjustment, err := svc.Request(ctx, AdjustmentRequest{
+		PlayerID:       playerID,
+		Type:           repository.TransactionTypeWithdraw,
+		Amount:         8000,
+		ReasonCode:     ReasonCustomerWithdrawal,
+		IdempotencyKey: "wd-1",
+	}, requester)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if adjustment.Status != repository.AdjustmentStatusPending || walletRepo.wallet.Balance != 10000 {
+		t.Fatalf("expected pending adjustment with untouched wallet, got %+v balance=%d", adjustment, walletRepo.wallet.Balance)
+	}
+
+	if _, err := svc.Approve(ctx, adjustment.ID, "", requester); !errors.Is(err, ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+
+	approved, err := svc.Approve(ctx, adjustment.ID, "verified with player", reviewer)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if approved.Status != repository.AdjustmentStatusCompleted || approved.ReviewedBy == nil || *approved.ReviewedBy != reviewer.PlayerID {
+		t.Errorf("unexpected approved adjustment: %+v", approved)
+	}
+	if walletRepo.wallet.Balance != 2000 {
+		t.Errorf("expected balance 2000, got %d", walletRepo.wallet.Balance)
+	}
+	if _, ok := walletRepo.txs["adjustment-"+adjustment.ID.String()]; !ok {
+		t.Error("expected wallet transaction keyed by adjustment id")
+	}
+
+	if _, err := svc.Reject(ctx, adjustment.ID, "", reviewer); !errors.Is(err, ErrAdjustmentNotPending) {
+		t.Errorf("expected ErrAdjustmentNotPending, got %v", err)
+	}
+
+	actions := make([]string, 0, len(auditRepo.logs))
+	for _, l := range auditRepo.logs {
+		actions = append(actions, l.Action)
+	}
+	if len(actions) != 2 || actions[0] != AuditActionAdjustmentRequested || actions[1] != AuditActionAdjustmentCompleted {
+		t.Errorf("unexpected audit actions: %v", actions)
+	}
+}
+
+// TestAdjustmentService_SplitAmountsRequireApproval 拆成多笔小额调整时按累计金额套用门槛
+func TestAdjustmentService_SplitAmountsRequireApproval(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	requester, other := AdminActor{PlayerID: uuid.New()}, AdminActor{PlayerID: uuid.New()}
+	svc, walletRepo, adjustmentRepo, _ := newTestAdjustmentService(playerID)
+
+	withdraw := func(key string, amount int64, actor AdminActor) *repository.WalletAdjustment {
+		t.Helper()
+		adjustment, err := svc.Request(ctx, AdjustmentRequest{
+			PlayerID:       playerID,
+			Type:           repository.TransactionTypeWithdraw,
+			Amount:         amount,
+			ReasonCode:     ReasonCustomerWithdrawal,
+			IdempotencyKey: key,
+		}, actor)
+		if err != nil {
+			t.Fatalf("Request %s failed: %v", key, err)
+		}
+		return adjustment
+	}
+
+	// 门槛 5000：前两笔累计 4000 直接执行，第三笔累计 6000 需核准
+	if a := withdraw("wd-1", 2000, requester); a.Status != repository.AdjustmentStatusCompleted {
+		t.Fatalf("expected first withdrawal to complete, got %s", a.Status)
+	}
+	if a := withdraw("wd-2", 2000, requester); a.Status != repository.AdjustmentStatusCompleted {
+		t.Fatalf("expected second withdrawal to complete, got %s", a.Status)
+	}
+	if a := withdraw("wd-3", 2000, requester); a.Status != repository.AdjustmentStatusPending {
+		t.Fatalf("expected cumulative amount over threshold to require approval, got %s", a.Status)
+	}
+	if walletRepo.wallet.Balance != 6000 {
+		t.Errorf("expected balance 6000, got %d", walletRepo.wallet.Balance)
+	}
+
+	// 其他管理员的额度分开计算
+	if a := withdraw("wd-4", 2000, other); a.Status != repository.AdjustmentStatusCompleted {
+		t.Errorf("expected another admin's withdrawal to complete, got %s", a.Status)
+	}
+
+	// 超出累计期间的调整不再计入
+	for _, a := range adjustmentRepo.byID {
+		a.CreatedAt = a.CreatedAt.Add(-2 * DefaultApprovalWindow)
+	}
+	if a := withdraw("wd-5", 2000, requester); a.Status != repository.AdjustmentStatusCompleted {
+		t.Errorf("expected withdrawal after the window to complete, got %s", a.Status)
+	}
+}
+
+func TestAdjustmentService_RejectAndInsufficientBalance(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	requester, reviewer := AdminActor{PlayerID: uuid.New()}, AdminActor{PlayerID: uuid.New()}
+	svc, walletRepo, adjustmentRepo, _ := newTestAdjustmentService(playerID)
+
+	withdraw, err := svc.Request(ctx, AdjustmentRequest{
+		PlayerID:       playerID,
+		Type:           repository.TransactionTypeWithdraw,
+		Amount:         20000,
+		ReasonCode:     ReasonCustomerWithdrawal,
+		IdempotencyKey: "wd-2",
+	}, requester)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	// 余额不足时核准失败，申请维持待审核
+	if _, err := svc.Approve(ctx, withdraw.ID, "", reviewer); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+	if a, _ := adjustmentRepo.GetByID(ctx, withdraw.ID); a.Status != repository.AdjustmentStatusPending {
+		t.Errorf("expected adjustment to stay pending, got %s", a.Status)
+	}
+
+	rejected, err := svc.Reject(ctx, withdraw.ID, "insufficient funds", reviewer)
+	if err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+	if rejected.Status != repository.AdjustmentStatusRejected || walletRepo.wallet.Balance != 10000 {
+		t.Errorf("unexpected rejected adjustment %+v balance=%d", rejected, walletRepo.wallet.Balance)
+	}
+}
+
+func TestAdjustmentService_Validation(t *testing.T) {
+	playerID := uuid.New()
+	svc, _, _, _ := newTestAdjustmentService(playerID)
+	base := AdjustmentRequest{PlayerID: playerID, Type: repository.TransactionTypeBonus, Amount: 100, ReasonCode: ReasonPromotion, IdempotencyKey: "k"}
+
+	cases := []struct {
+		name   string
+		modify func(r *AdjustmentRequest)
+		want   error
+	}{
+		{"missing idempotency key", func(r *AdjustmentRequest) { r.IdempotencyKey = "" }, ErrIdempotencyKeyRequired},
+		{"non-positive amount", func(r *AdjustmentRequest) { r.Amount = 0 }, ErrInvalidAmount},
+		{"game result type", func(r *AdjustmentRequest) { r.Type = repository.TransactionTypeWin }, ErrInvalidAdjustmentType},
+		{"unknown reason", func(r *AdjustmentRequest) { r.ReasonCode = "because" }, ErrInvalidReasonCode},
+		{"play-money deposit", func(r *AdjustmentRequest) { r.Currency, r.Type = "PLAY", repository.TransactionTypeDeposit }, ErrPlayMoneyAdjustment},
+		{"missing wallet", func(r *AdjustmentRequest) { r.Currency = "EUR" }, ErrWalletNotFound},
+	}
+	for _, tc := range cases {
+		req := base
+		tc.modify(&req)
+		if _, err := svc.Request(context.Background(), req, AdminActor{PlayerID: uuid.New()}); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	// 游戏币可以发放奖金
+	req := base
+	req.Currency = "PLAY"
+	if _, err := svc.Request(context.Background(), req, AdminActor{PlayerID: uuid.New()}); err != nil {
+		t.Errorf("play-money bonus should be allowed: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// 游戏与牌桌操作的审计动作
+const (
+	AuditActionBuyIn     = "buy_in"
+	AuditActionCashOut   = "cash_out"
+	AuditActionTableKick = "table_kick"
+)
+
+// RequestSource 玩家发起操作时的请求来源（写入审计日志）
+// 为 nil 表示由系统发起（对账、崩溃恢复、会话超时等）
+type RequestSource struct {
+	IPAddress string
+	UserAgent string
+}
+
+// AuditLogPage 审计日志分页结果
+type AuditLogPage struct {
+	Logs   []*repository.AuditLog
+	Total  int64
+	Limit  int
+	Offset int
+}
+
+// AuditService 审计日志服务：记录管理员操作并供客服查询
+type AuditService struct {
+	auditRepo  repository.AuditLogRepository
+	playerRepo repository.PlayerRepository
+	logger     *zap.Logger
+}
+
+// NewAuditService 创建审计日志服务
+func NewAuditService(
+	auditRepo repository.AuditLogRepository,
+	playerRepo repository.PlayerRepository,
+	logger *zap.Logger,
+) *AuditService {
+	return &AuditService{
+		auditRepo:  auditRepo,
+		playerRepo: playerRepo,
+		logger:     logger,
+	}
+}
+
+// RecordAdminAction 记录管理员操作（before/after 为变更前后的值，可为 nil）
+func (s *AuditService) RecordAdminAction(
+	ctx context.Context,
+	actor AdminActor,
+	entityType string,
+	entityID uuid.UUID,
+	action string,
+	before, after interface{},
+) error {
+	player, err := s.playerRepo.GetByID(ctx, actor.PlayerID)
+	if err != nil {
+		return ErrPlayerNotFound
+	}
+
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return err
+	}
+
+	err = s.auditRepo.Create(ctx, &repository.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+		ActorID:    &player.AccountID,
+		ActorType:  repository.AuditActorAdmin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return nil
+}
+
+// List 按条件分页查询审计日志（Limit 为 0 时使用默认值）
+func (s *AuditService) List(ctx context.Context, filter repository.AuditLogFilter) (*AuditLogPage, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, ErrInvalidWalletPeriod
+	}
+	limit, err := normalizePageLimit(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	logs, total, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return &AuditLogPage{
+		Logs:   logs,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// auditChanges 序列化审计日志的变更内容 {before: {...}, after: {...}}
+func auditChanges(before, after interface{}) ([]byte, error) {
+	changes := make(map[string]interface{}, 2)
+	if before != nil {
+		changes["before"] = before
+	}
+	if after != nil {
+		changes["after"] = after
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+	return data, nil
+}
@@ -22,17 +22,25 @@ var (
 	ErrSessionNotFound = errors.New("session not found")
 	// ErrSessionAlreadyActive 玩家已有活跃会话
 	ErrSessionAlreadyActive = errors.New("player already has active session")
+	// ErrSessionTableMismatch 游戏会话的牌桌或币别与要加入的牌桌不符
+	ErrSessionTableMismatch = errors.New("game session does not match table")
 	// ErrInvalidAmount 无效金额
 	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrEmailNotVerified 邮箱未验证，不可使用真钱买入
+	ErrEmailNotVerified = errors.New("email must be verified for real-money buy-ins")
 )
 
 // GameService 游戏服务，处理游戏业务逻辑和资金操作
 type GameService struct {
-	playerRepo  repository.PlayerRepository
-	walletRepo  repository.WalletRepository
-	sessionRepo repository.GameSessionRepository
-	uow         repository.UnitOfWork
-	logger      *zap.Logger
+	playerRepo      repository.PlayerRepository
+	walletRepo      repository.WalletRepository
+	sessionRepo     repository.GameSessionRepository
+	uow             repository.UnitOfWork
+	auditRepo       repository.AuditLogRepository
+	accountRepo     repository.AccountRepository
+	playMoney       map[string]bool
+	defaultCurrency string
+	logger          *zap.Logger
 }
 
 // NewGameService 创建游戏服务
@@ -44,20 +52,55 @@ func NewGameService(
 	logger *zap.Logger,
 ) *GameService {
 	return &GameService{
-		playerRepo:  playerRepo,
-		walletRepo:  walletRepo,
-		sessionRepo: sessionRepo,
-		uow:         uow,
-		logger:      logger,
+		playerRepo:      playerRepo,
+		walletRepo:      walletRepo,
+		sessionRepo:     sessionRepo,
+		uow:             uow,
+		playMoney:       make(map[string]bool),
+		defaultCurrency: "USD",
+		logger:          logger,
 	}
 }
 
+// SetDefaultCurrency 设定默认币别（买入请求未指定币别、旧会话没有币别时使用）
+func (s *GameService) SetDefaultCurrency(currency string) {
+	s.defaultCurrency = currency
+}
+
+// SetAuditLogRepository 设定审计日志仓储（买入与兑现在同一事务中写入审计日志）
+func (s *GameService) SetAuditLogRepository(auditRepo repository.AuditLogRepository) {
+	s.auditRepo = auditRepo
+}
+
+// SetAccountRepository 设定账号仓储（真钱买入前检查邮箱是否已验证）
+func (s *GameService) SetAccountRepository(accountRepo repository.AccountRepository) {
+	s.accountRepo = accountRepo
+}
+
+// SetPlayMoneyCurrencies 设定游戏币币别（游戏币买入不要求邮箱验证）
+func (s *GameService) SetPlayMoneyCurrencies(currencies []string) {
+	s.playMoney = make(map[string]bool, len(currencies))
+	for _, c := range currencies {
+		s.playMoney[c] = true
+	}
+}
+
+// currencyOrDefault 回传币别，为空时使用默认币别
+func (s *GameService) currencyOrDefault(currency string) string {
+	if currency == "" {
+		return s.defaultCurrency
+	}
+	return currency
+}
+
 // BuyInRequest 买入请求
 type BuyInRequest struct {
 	PlayerID uuid.UUID
 	TableID  string
 	GameType string
-	Amount   int64 // 单位：分（cents）
+	Currency string // 牌桌币别，决定动用哪个钱包（空值使用默认币别）
+	Amount   int64  // 单位：分（cents）
+	Source   *RequestSource
 }
 
 // BuyInResponse 买入响应
@@ -65,6 +108,7 @@ type BuyInResponse struct {
 	SessionID     uuid.UUID
 	PlayerID      uuid.UUID
 	TableID       string
+	Currency      string
 	Chips         int64
 	WalletBalance int64
 	CreatedAt     time.Time
@@ -76,7 +120,7 @@ func (s *GameService) BuyIn(ctx context.Context, req BuyInRequest) (*BuyInRespon
 		return nil, ErrInvalidAmount
 	}
 
-	_, err := s.playerRepo.GetByID(ctx, req.PlayerID)
+	player, err := s.playerRepo.GetByID(ctx, req.PlayerID)
 	if err != nil {
 		s.logger.Error("failed to get player",
 			zap.String("player_id", req.PlayerID.String()),
@@ -94,10 +138,16 @@ func (s *GameService) BuyIn(ctx context.Context, req BuyInRequest) (*BuyInRespon
 		return nil, ErrSessionAlreadyActive
 	}
 
+	currency := s.currencyOrDefault(req.Currency)
+	if err := s.checkRealMoneyAllowed(ctx, player, currency); err != nil {
+		return nil, err
+	}
+
 	var response *BuyInResponse
 
 	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		wallet, err := s.walletRepo.GetWithLock(ctx, tx, req.PlayerID)
+		// 只动用与牌桌同币别的钱包：游戏币牌桌不会碰到真钱钱包
+		wallet, err := s.walletRepo.GetWithLock(ctx, tx, req.PlayerID, currency)
 		if err != nil {
 			return fmt.Errorf("failed to get wallet: %w", err)
 		}
@@ -106,51 +156,59 @@ func (s *GameService) BuyIn(ctx context.Context, req BuyInRequest) (*BuyInRespon
 			return ErrInsufficientBalance
 		}
 
-		idempotencyKey := fmt.Sprintf("buyin-%s-%s-%d",
-			req.PlayerID.String(),
-			req.TableID,
-			time.Now().UnixNano(),
-		)
-
-		err = s.walletRepo.Debit(
-			ctx,
-			tx,
-			req.PlayerID,
-			req.Amount,
-			repository.TransactionTypeBuyIn,
-			fmt.Sprintf("Buy-in to table %s", req.TableID),
-			idempotencyKey,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to debit wallet: %w", err)
-		}
-
+		// 先建立会话，买入分录才能关联到会话
 		session := &repository.GameSession{
 			ID:           uuid.New(),
 			PlayerID:     req.PlayerID,
 			GameType:     req.GameType,
 			TableID:      req.TableID,
+			Currency:     currency,
 			BuyInAmount:  req.Amount,
 			CurrentChips: req.Amount,
 			Status:       "active",
 			StartedAt:    time.Now(),
 		}
 
-		if err := s.sessionRepo.Create(ctx, session); err != nil {
+		if err := s.sessionRepo.CreateWithTx(ctx, tx, session); err != nil {
 			return fmt.Errorf("failed to create session: %w", err)
 		}
 
-		wallet, err = s.walletRepo.GetByPlayerID(ctx, req.PlayerID)
+		// 买入资金移入托管（锁定余额），总余额不变
+		err = s.walletRepo.LockBalance(
+			ctx,
+			tx,
+			req.PlayerID,
+			currency,
+			req.Amount,
+			&session.ID,
+			repository.TransactionTypeBuyIn,
+			fmt.Sprintf("Buy-in to table %s", req.TableID),
+			fmt.Sprintf("buyin-%s", session.ID),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to lock balance: %w", err)
+		}
+
+		// 事务尚未提交，变更后的钱包状态由金额推算（连接池读取的仍是旧值）
+		before := wallet
+		after := *before
+		after.Balance -= req.Amount
+		after.LockedBalance += req.Amount
+
+		err = s.auditWalletChange(ctx, tx, AuditActionBuyIn, session, player.AccountID, req.Source, before, &after, map[string]interface{}{
+			"amount": req.Amount,
+		})
 		if err != nil {
-			return fmt.Errorf("failed to get updated wallet: %w", err)
+			return err
 		}
 
 		response = &BuyInResponse{
 			SessionID:     session.ID,
 			PlayerID:      req.PlayerID,
 			TableID:       req.TableID,
+			Currency:      currency,
 			Chips:         session.CurrentChips,
-			WalletBalance: wallet.Balance,
+			WalletBalance: after.Balance,
 			CreatedAt:     session.StartedAt,
 		}
 
@@ -161,6 +219,7 @@ func (s *GameService) BuyIn(ctx context.Context, req BuyInRequest) (*BuyInRespon
 		s.logger.Error("buy-in failed",
 			zap.String("player_id", req.PlayerID.String()),
 			zap.String("table_id", req.TableID),
+			zap.String("currency", currency),
 			zap.Int64("amount", req.Amount),
 			zap.Error(err),
 		)
@@ -171,6 +230,7 @@ func (s *GameService) BuyIn(ctx context.Context, req BuyInRequest) (*BuyInRespon
 		zap.String("player_id", req.PlayerID.String()),
 		zap.String("session_id", response.SessionID.String()),
 		zap.String("table_id", req.TableID),
+		zap.String("currency", currency),
 		zap.Int64("amount", req.Amount),
 		zap.Int64("wallet_balance", response.WalletBalance),
 	)
@@ -183,12 +243,14 @@ type CashOutRequest struct {
 	PlayerID  uuid.UUID
 	SessionID uuid.UUID
 	Chips     int64
+	Source    *RequestSource
 }
 
 // CashOutResponse 兑现响应
 type CashOutResponse struct {
 	SessionID     uuid.UUID
 	PlayerID      uuid.UUID
+	Currency      string
 	BuyInAmount   int64
 	CashOutAmount int64
 	Profit        int64
@@ -219,48 +281,94 @@ func (s *GameService) CashOut(ctx context.Context, req CashOutRequest) (*CashOut
 		return nil, errors.New("session is not active")
 	}
 
+	// 兑现回买入时的钱包
+	currency := s.currencyOrDefault(session.Currency)
 	var response *CashOutResponse
 
+	// 玩家发起的兑现以玩家账号为审计执行者
+	var accountID uuid.UUID
+	if req.Source != nil && s.auditRepo != nil {
+		player, err := s.playerRepo.GetByID(ctx, req.PlayerID)
+		if err != nil {
+			return nil, ErrPlayerNotFound
+		}
+		accountID = player.AccountID
+	}
+
 	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		if req.Chips > 0 {
-			idempotencyKey := fmt.Sprintf("cashout-%s-%s-%d",
-				req.PlayerID.String(),
-				req.SessionID.String(),
-				time.Now().UnixNano(),
+		// 先锁定钱包，与 SettleHand 串行化
+		before, err := s.walletRepo.GetWithLock(ctx, tx, req.PlayerID, currency)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet: %w", err)
+		}
+
+		// 托管金额 = 买入 + 已结算的手牌输赢；与兑现筹码的差额为尚未结算的手牌
+		settled, err := s.walletRepo.SumGameResultsBySession(ctx, tx, session.ID)
+		if err != nil {
+			return err
+		}
+		// 事务尚未提交，变更后的钱包状态由金额推算（连接池读取的仍是旧值）
+		after := *before
+		if diff := req.Chips - (session.BuyInAmount + settled); diff != 0 {
+			err = s.walletRepo.RecordGameResult(
+				ctx,
+				tx,
+				req.PlayerID,
+				currency,
+				diff,
+				&session.ID,
+				session.ID.String(),
+				fmt.Sprintf("Unsettled results at cash-out from table %s", session.TableID),
+				fmt.Sprintf("cashout-adjust-%s", session.ID),
 			)
+			if err != nil {
+				return fmt.Errorf("failed to settle remaining results: %w", err)
+			}
+			after.LockedBalance += diff
+		}
 
-			err = s.walletRepo.Credit(
+		if req.Chips > 0 {
+			err = s.walletRepo.UnlockBalance(
 				ctx,
 				tx,
 				req.PlayerID,
+				currency,
 				req.Chips,
+				&session.ID,
 				repository.TransactionTypeCashOut,
 				fmt.Sprintf("Cash-out from table %s", session.TableID),
-				idempotencyKey,
+				fmt.Sprintf("cashout-%s", session.ID),
 			)
 			if err != nil {
-				return fmt.Errorf("failed to credit wallet: %w", err)
+				return fmt.Errorf("failed to unlock balance: %w", err)
 			}
+			after.LockedBalance -= req.Chips
+			after.Balance += req.Chips
 		}
 
-		if err := s.sessionRepo.End(ctx, session.ID, req.Chips); err != nil {
+		if err := s.sessionRepo.EndWithTx(ctx, tx, session.ID, req.Chips); err != nil {
 			return fmt.Errorf("failed to end session: %w", err)
 		}
 
 		profit := req.Chips - session.BuyInAmount
 
-		wallet, err := s.walletRepo.GetByPlayerID(ctx, req.PlayerID)
+		err = s.auditWalletChange(ctx, tx, AuditActionCashOut, session, accountID, req.Source, before, &after, map[string]interface{}{
+			"buy_in_amount": session.BuyInAmount,
+			"chips":         req.Chips,
+			"profit":        profit,
+		})
 		if err != nil {
-			return fmt.Errorf("failed to get wallet: %w", err)
+			return err
 		}
 
 		response = &CashOutResponse{
 			SessionID:     session.ID,
 			PlayerID:      req.PlayerID,
+			Currency:      currency,
 			BuyInAmount:   session.BuyInAmount,
 			CashOutAmount: req.Chips,
 			Profit:        profit,
-			WalletBalance: wallet.Balance,
+			WalletBalance: after.Balance,
 			EndedAt:       time.Now(),
 		}
 
@@ -289,12 +397,69 @@ func (s *GameService) CashOut(ctx context.Context, req CashOutRequest) (*CashOut
 	return response, nil
 }
 
-// GetPlayerBalance 获取玩家余额
-func (s *GameService) GetPlayerBalance(ctx context.Context, playerID uuid.UUID) (*repository.Wallet, error) {
-	wallet, err := s.walletRepo.GetByPlayerID(ctx, playerID)
+// auditWalletChange 在事务中记录买入/兑现对钱包的变更（未设定审计仓储时略过）
+// source 为 nil 表示系统发起，执行者记为 system
+func (s *GameService) auditWalletChange(
+	ctx context.Context,
+	tx repository.Transaction,
+	action string,
+	session *repository.GameSession,
+	accountID uuid.UUID,
+	source *RequestSource,
+	before, after *repository.Wallet,
+	details map[string]interface{},
+) error {
+	if s.auditRepo == nil {
+		return nil
+	}
+
+	walletState := func(w *repository.Wallet) map[string]interface{} {
+		return map[string]interface{}{
+			"balance":        w.Balance,
+			"locked_balance": w.LockedBalance,
+		}
+	}
+	afterState := walletState(after)
+	afterState["player_id"] = session.PlayerID
+	afterState["table_id"] = session.TableID
+	afterState["currency"] = after.Currency
+	for k, v := range details {
+		afterState[k] = v
+	}
+
+	changes, err := auditChanges(walletState(before), afterState)
+	if err != nil {
+		return err
+	}
+
+	log := &repository.AuditLog{
+		EntityType: "game_session",
+		EntityID:   session.ID,
+		Action:     action,
+		Changes:    changes,
+		ActorType:  repository.AuditActorSystem,
+	}
+	if source != nil {
+		log.IPAddress = source.IPAddress
+		log.UserAgent = source.UserAgent
+		log.ActorID = &accountID
+		log.ActorType = repository.AuditActorUser
+	}
+
+	if err := s.auditRepo.CreateWithTx(ctx, tx, log); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// GetPlayerBalance 获取玩家指定币别的余额（空值使用默认币别）
+func (s *GameService) GetPlayerBalance(ctx context.Context, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	currency = s.currencyOrDefault(currency)
+	wallet, err := s.walletRepo.GetByPlayerID(ctx, playerID, currency)
 	if err != nil {
 		s.logger.Error("failed to get player balance",
 			zap.String("player_id", playerID.String()),
+			zap.String("currency", currency),
 			zap.Error(err),
 		)
 		return nil, ErrWalletNotFound
@@ -303,6 +468,18 @@ func (s *GameService) GetPlayerBalance(ctx context.Context, playerID uuid.UUID)
 	return wallet, nil
 }
 
+// GetPlayerWallets 获取玩家所有币别的钱包
+func (s *GameService) GetPlayerWallets(ctx context.Context, playerID uuid.UUID) ([]*repository.Wallet, error) {
+	wallets, err := s.walletRepo.ListByPlayerID(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	if len(wallets) == 0 {
+		return nil, ErrWalletNotFound
+	}
+	return wallets, nil
+}
+
 // GetActiveSession 获取玩家活跃会话
 func (s *GameService) GetActiveSession(ctx context.Context, playerID uuid.UUID) (*repository.GameSession, error) {
 	session, err := s.sessionRepo.GetActiveByPlayerID(ctx, playerID)
@@ -313,6 +490,21 @@ func (s *GameService) GetActiveSession(ctx context.Context, playerID uuid.UUID)
 	return session, nil
 }
 
+// VerifySessionForTable 确认活跃游戏会话是在该牌桌、以该币别买入的（入座前检查）
+// 避免在 A 桌买入的托管资金被带到其他牌桌或其他币别的牌桌上使用
+func (s *GameService) VerifySessionForTable(ctx context.Context, playerID, sessionID uuid.UUID, tableID, currency string) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.PlayerID != playerID || session.Status != "active" {
+		return ErrSessionNotFound
+	}
+
+	if session.TableID != tableID || s.currencyOrDefault(session.Currency) != s.currencyOrDefault(currency) {
+		return ErrSessionTableMismatch
+	}
+
+	return nil
+}
+
 // UpdateSessionChips 更新会话筹码（游戏过程中）
 func (s *GameService) UpdateSessionChips(ctx context.Context, sessionID uuid.UUID, chips int64) error {
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
@@ -335,9 +527,10 @@ func (s *GameService) UpdateSessionChips(ctx context.Context, sessionID uuid.UUI
 	return nil
 }
 
-// EnsureWalletExists 确保玩家钱包存在，如果不存在则创建
+// EnsureWalletExists 确保玩家指定币别的钱包存在，如果不存在则创建
 func (s *GameService) EnsureWalletExists(ctx context.Context, playerID uuid.UUID, currency string) error {
-	_, err := s.walletRepo.GetByPlayerID(ctx, playerID)
+	currency = s.currencyOrDefault(currency)
+	_, err := s.walletRepo.GetByPlayerID(ctx, playerID, currency)
 	if err == nil {
 		return nil
 	}
@@ -369,3 +562,23 @@ func (s *GameService) EnsureWalletExists(ctx context.Context, playerID uuid.UUID
 
 	return nil
 }
+
+// checkRealMoneyAllowed 真钱买入要求账号邮箱已验证（游戏币不限制）
+func (s *GameService) checkRealMoneyAllowed(ctx context.Context, player *repository.Player, currency string) error {
+	if s.accountRepo == nil || s.playMoney[currency] {
+		return nil
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, player.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if !account.EmailVerified {
+		s.logger.Warn("real-money buy-in rejected: email not verified",
+			zap.String("player_id", player.ID.String()),
+			zap.String("currency", currency),
+		)
+		return ErrEmailNotVerified
+	}
+	return nil
+}
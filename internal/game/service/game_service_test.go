@@ -0,0 +1,482 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// escrowWalletRepo 以记忆体模拟托管：按币别的可用余额、锁定余额与依幂等性键去重的交易记录
+type escrowWalletRepo struct {
+	repository.WalletRepository
+	wallet  *repository.Wallet // USD 钱包
+	wallets map[string]*repository.Wallet
+	txs     map[string]*repository.WalletTransaction
+}
+
+func newEscrowWalletRepo(playerID uuid.UUID, balance int64) *escrowWalletRepo {
+	usd := &repository.Wallet{ID: uuid.New(), PlayerID: playerID, Balance: balance, Currency: "USD"}
+	return &escrowWalletRepo{
+		wallet:  usd,
+		wallets: map[string]*repository.Wallet{"USD": usd},
+		txs:     make(map[string]*repository.WalletTransaction),
+	}
+}
+
+// addWallet 为玩家新增另一币别的钱包
+func (r *escrowWalletRepo) addWallet(currency string, balance int64) *repository.Wallet {
+	w := &repository.Wallet{ID: uuid.New(), PlayerID: r.wallet.PlayerID, Balance: balance, Currency: currency}
+	r.wallets[currency] = w
+	return w
+}
+
+func (r *escrowWalletRepo) get(currency string) (*repository.Wallet, error) {
+	w, ok := r.wallets[currency]
+	if !ok {
+		return nil, fmt.Errorf("wallet not found")
+	}
+	return w, nil
+}
+
+func (r *escrowWalletRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	w, err := r.get(currency)
+	if err != nil {
+		return nil, err
+	}
+	copied := *w
+	return &copied, nil
+}
+
+func (r *escrowWalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	return r.GetByPlayerID(ctx, playerID, currency)
+}
+
+func (r *escrowWalletRepo) record(key string, txType repository.TransactionType, amount int64, sessionID *uuid.UUID) bool {
+	if _, ok := r.txs[key]; ok {
+		return false
+	}
+	r.txs[key] = &repository.WalletTransaction{Type: txType, Amount: amount, GameSessionID: sessionID}
+	return true
+}
+
+func (r *escrowWalletRepo) LockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType repository.TransactionType, description, idempotencyKey string) error {
+	w, err := r.get(currency)
+	if err != nil {
+		return err
+	}
+	if w.Balance < amount {
+		return fmt.Errorf("insufficient balance")
+	}
+	if r.record(idempotencyKey, txType, -amount, gameSessionID) {
+		w.Balance -= amount
+		w.LockedBalance += amount
+	}
+	return nil
+}
+
+func (r *escrowWalletRepo) UnlockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType repository.TransactionType, description, idempotencyKey string) error {
+	w, err := r.get(currency)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.txs[idempotencyKey]; ok {
+		return nil // 与 postgres 实现一致：先检查幂等性
+	}
+	if w.LockedBalance < amount {
+		return fmt.Errorf("insufficient locked balance")
+	}
+	if r.record(idempotencyKey, txType, amount, gameSessionID) {
+		w.Balance += amount
+		w.LockedBalance -= amount
+	}
+	return nil
+}
+
+func (r *escrowWalletRepo) RecordGameResult(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, referenceID, description, idempotencyKey string) error {
+	w, err := r.get(currency)
+	if err != nil {
+		return err
+	}
+	if w.LockedBalance+amount < 0 {
+		return fmt.Errorf("insufficient locked balance")
+	}
+	txType := repository.TransactionTypeWin
+	if amount < 0 {
+		txType = repository.TransactionTypeLoss
+	}
+	if r.record(idempotencyKey, txType, amount, gameSessionID) {
+		w.LockedBalance += amount
+	}
+	return nil
+}
+
+func (r *escrowWalletRepo) SumGameResultsBySession(ctx context.Context, tx repository.Transaction, sessionID uuid.UUID) (int64, error) {
+	var total int64
+	for _, t := range r.txs {
+		if t.GameSessionID != nil && *t.GameSessionID == sessionID &&
+			(t.Type == repository.TransactionTypeWin || t.Type == repository.TransactionTypeLoss) {
+			total += t.Amount
+		}
+	}
+	return total, nil
+}
+
+type escrowSessionRepo struct {
+	repository.GameSessionRepository
+	sessions map[uuid.UUID]*repository.GameSession
+}
+
+func (r *escrowSessionRepo) Create(ctx context.Context, session *repository.GameSession) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *escrowSessionRepo) CreateWithTx(ctx context.Context, tx repository.Transaction, session *repository.GameSession) error {
+	return r.Create(ctx, session)
+}
+
+func (r *escrowSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.GameSession, error) {
+	if s, ok := r.sessions[id]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+func (r *escrowSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid.UUID) (*repository.GameSession, error) {
+	for _, s := range r.sessions {
+		if s.PlayerID == playerID && s.Status == "active" {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+func (r *escrowSessionRepo) ListActive(ctx context.Context) ([]*repository.GameSession, error) {
+	var active []*repository.GameSession
+	for _, s := range r.sessions {
+		if s.Status == "active" {
+			active = append(active, s)
+		}
+	}
+	return active, nil
+}
+
+func (r *escrowSessionRepo) End(ctx context.Context, id uuid.UUID, finalChips int64) error {
+	r.sessions[id].Status = "completed"
+	r.sessions[id].CurrentChips = finalChips
+	return nil
+}
+
+func (r *escrowSessionRepo) EndWithTx(ctx context.Context, tx repository.Transaction, id uuid.UUID, finalChips int64) error {
+	return r.End(ctx, id, finalChips)
+}
+
+type stubPlayerRepo struct {
+	repository.PlayerRepository
+}
+
+func (stubPlayerRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Player, error) {
+	return &repository.Player{ID: id}, nil
+}
+
+// TestEscrow_BuyInSettleCashOut 买入锁定、手牌结算调整锁定余额、兑现释放，总余额始终一致
+func TestEscrow_BuyInSettleCashOut(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	buyIn, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 2000})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	if w := walletRepo.wallet; w.Balance != 8000 || w.LockedBalance != 2000 || w.TotalBalance() != 10000 {
+		t.Fatalf("unexpected wallet after buy-in: %+v", w)
+	}
+	if tx := walletRepo.txs["buyin-"+buyIn.SessionID.String()]; tx == nil || tx.GameSessionID == nil || *tx.GameSessionID != buyIn.SessionID {
+		t.Fatalf("expected buy-in transaction linked to session, got %+v", tx)
+	}
+
+	// 已结算一手 +300
+	err = walletRepo.RecordGameResult(ctx, nil, playerID, "USD", 300, &buyIn.SessionID, "hand-1", "", "hand-1-"+playerID.String())
+	if err != nil {
+		t.Fatalf("RecordGameResult failed: %v", err)
+	}
+	if w := walletRepo.wallet; w.LockedBalance != 2300 || w.TotalBalance() != 10300 {
+		t.Fatalf("unexpected wallet after settlement: %+v", w)
+	}
+
+	// 兑现 2100：最后一手 -200 尚未结算，兑现时补记差额
+	cashOut, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: buyIn.SessionID, Chips: 2100})
+	if err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	if cashOut.Profit != 100 {
+		t.Errorf("expected profit 100, got %d", cashOut.Profit)
+	}
+	if w := walletRepo.wallet; w.Balance != 10100 || w.LockedBalance != 0 {
+		t.Errorf("expected balance=10100 locked=0, got %+v", w)
+	}
+	adjust, ok := walletRepo.txs["cashout-adjust-"+buyIn.SessionID.String()]
+	if !ok || adjust.Amount != -200 {
+		t.Errorf("expected -200 cash-out adjustment, got %+v", adjust)
+	}
+}
+
+// TestEscrow_QueuedHandAfterRebuyNotBookedTwice 排队中的手牌在玩家兑现并重新买入后才结算，不可记入新会话
+func TestEscrow_QueuedHandAfterRebuyNotBookedTwice(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	first, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 2000})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	hand := func(sessionID string) *domain.HandRecord {
+		return &domain.HandRecord{HandID: uuid.New().String(), TableID: "t1", StartedAt: time.Now(), Players: []*domain.HandPlayerRecord{
+			{PlayerID: playerID.String(), SessionID: sessionID, StartingChips: 2000, EndingChips: 1700},
+		}}
+	}
+	queued := hand(first.SessionID.String())
+	legacy := hand("") // 旧快照复原的手牌未记录会话 ID
+	time.Sleep(time.Millisecond)
+
+	// 兑现 1700：尚未结算的 -300 以差额补记
+	if _, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: first.SessionID, Chips: 1700}); err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	second, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 1000})
+	if err != nil {
+		t.Fatalf("second BuyIn failed: %v", err)
+	}
+
+	for _, record := range []*domain.HandRecord{queued, legacy} {
+		if err := svc.SettleHand(ctx, record); err != nil {
+			t.Fatalf("SettleHand failed: %v", err)
+		}
+	}
+
+	if w := walletRepo.wallet; w.Balance != 8700 || w.LockedBalance != 1000 {
+		t.Errorf("expected balance=8700 locked=1000, got %+v", w)
+	}
+	for key, tx := range walletRepo.txs {
+		if tx.GameSessionID != nil && *tx.GameSessionID == second.SessionID && key != "buyin-"+second.SessionID.String() {
+			t.Errorf("queued hand booked against the new session: %s %+v", key, tx)
+		}
+	}
+}
+
+// TestEscrow_PlayMoneyTableIsolated 游戏币桌的买入与兑现只动用游戏币钱包，不影响真钱钱包
+func TestEscrow_PlayMoneyTableIsolated(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	play := walletRepo.addWallet("PLAY", 50000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	buyIn, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "play-1", GameType: "poker", Amount: 20000, Currency: "PLAY"})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	if buyIn.Currency != "PLAY" || sessionRepo.sessions[buyIn.SessionID].Currency != "PLAY" {
+		t.Fatalf("expected PLAY session, got %+v", buyIn)
+	}
+	if play.Balance != 30000 || play.LockedBalance != 20000 {
+		t.Fatalf("unexpected PLAY wallet after buy-in: %+v", play)
+	}
+
+	// 游戏币不足时不得改用真钱钱包
+	if _, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "play-1", GameType: "poker", Amount: 40000, Currency: "PLAY"}); err == nil {
+		t.Error("expected play-money buy-in beyond PLAY balance to fail")
+	}
+
+	if _, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: buyIn.SessionID, Chips: 25000}); err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	if play.Balance != 55000 || play.LockedBalance != 0 {
+		t.Errorf("unexpected PLAY wallet after cash-out: %+v", play)
+	}
+	if w := walletRepo.wallet; w.Balance != 10000 || w.LockedBalance != 0 {
+		t.Errorf("USD wallet must be untouched, got %+v", w)
+	}
+
+	// 未指定币别时使用默认币别（USD）
+	buyIn, err = svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 1000})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	if buyIn.Currency != "USD" || walletRepo.wallet.LockedBalance != 1000 || play.LockedBalance != 0 {
+		t.Errorf("expected default USD buy-in, got %+v", buyIn)
+	}
+}
+
+// TestGameService_VerifySessionForTable 游戏会话只能用于买入时的牌桌与币别
+func TestGameService_VerifySessionForTable(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	walletRepo.addWallet("PLAY", 50000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	buyIn, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "play-1", GameType: "poker", Amount: 20000, Currency: "PLAY"})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+
+	if err := svc.VerifySessionForTable(ctx, playerID, buyIn.SessionID, "play-1", "PLAY"); err != nil {
+		t.Errorf("expected session to match its own table, got %v", err)
+	}
+	if err := svc.VerifySessionForTable(ctx, playerID, buyIn.SessionID, "play-2", "PLAY"); !errors.Is(err, ErrSessionTableMismatch) {
+		t.Errorf("expected table mismatch, got %v", err)
+	}
+	if err := svc.VerifySessionForTable(ctx, playerID, buyIn.SessionID, "play-1", "USD"); !errors.Is(err, ErrSessionTableMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+	if err := svc.VerifySessionForTable(ctx, uuid.New(), buyIn.SessionID, "play-1", "PLAY"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected other player's session to be rejected, got %v", err)
+	}
+
+	if _, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: buyIn.SessionID, Chips: 20000}); err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	if err := svc.VerifySessionForTable(ctx, playerID, buyIn.SessionID, "play-1", "PLAY"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ended session to be rejected, got %v", err)
+	}
+}
+
+// poolReadWalletRepo 事务中不得经连接池读取钱包：未提交的变更在池上看不到
+type poolReadWalletRepo struct {
+	*escrowWalletRepo
+}
+
+func (r poolReadWalletRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	return nil, fmt.Errorf("wallet read outside the transaction")
+}
+
+// TestGameService_AuditsBuyInAndCashOut 买入与兑现写入审计日志：玩家发起记录来源，系统发起不记录执行者
+func TestGameService_AuditsBuyInAndCashOut(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := poolReadWalletRepo{newEscrowWalletRepo(playerID, 10000)}
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	auditRepo := &stubAuditLogRepo{}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+	svc.SetAuditLogRepository(auditRepo)
+
+	source := &RequestSource{IPAddress: "203.0.113.7", UserAgent: "poker-client/1.0"}
+	buyIn, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 2000, Source: source})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	// 系统兑现（例如对账）没有请求来源
+	cashOut, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: buyIn.SessionID, Chips: 2500})
+	if err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	if buyIn.WalletBalance != 8000 || cashOut.WalletBalance != 10500 {
+		t.Errorf("expected wallet balances 8000 and 10500, got %d and %d", buyIn.WalletBalance, cashOut.WalletBalance)
+	}
+
+	if len(auditRepo.logs) != 2 {
+		t.Fatalf("expected 2 audit logs, got %d", len(auditRepo.logs))
+	}
+	buyInLog, cashOutLog := auditRepo.logs[0], auditRepo.logs[1]
+
+	if buyInLog.Action != AuditActionBuyIn || buyInLog.EntityID != buyIn.SessionID ||
+		buyInLog.ActorType != repository.AuditActorUser || buyInLog.ActorID == nil ||
+		buyInLog.IPAddress != source.IPAddress || buyInLog.UserAgent != source.UserAgent {
+		t.Errorf("unexpected buy-in audit log: %+v", buyInLog)
+	}
+	var changes struct {
+		Before map[string]int64 `json:"before"`
+		After  struct {
+			Balance       int64 `json:"balance"`
+			LockedBalance int64 `json:"locked_balance"`
+			Amount        int64 `json:"amount"`
+		} `json:"after"`
+	}
+	if err := json.Unmarshal(buyInLog.Changes, &changes); err != nil {
+		t.Fatalf("invalid audit changes: %v", err)
+	}
+	if changes.Before["balance"] != 10000 || changes.After.Balance != 8000 ||
+		changes.After.LockedBalance != 2000 || changes.After.Amount != 2000 {
+		t.Errorf("unexpected buy-in changes: %s", buyInLog.Changes)
+	}
+
+	if cashOutLog.Action != AuditActionCashOut || cashOutLog.ActorType != repository.AuditActorSystem ||
+		cashOutLog.ActorID != nil || cashOutLog.IPAddress != "" {
+		t.Errorf("unexpected cash-out audit log: %+v", cashOutLog)
+	}
+	var cashOutChanges struct {
+		Before map[string]int64 `json:"before"`
+		After  struct {
+			Balance       int64 `json:"balance"`
+			LockedBalance int64 `json:"locked_balance"`
+		} `json:"after"`
+	}
+	if err := json.Unmarshal(cashOutLog.Changes, &cashOutChanges); err != nil {
+		t.Fatalf("invalid audit changes: %v", err)
+	}
+	// 未结算的 +500 补记后连同买入一并释放
+	if cashOutChanges.Before["locked_balance"] != 2000 || cashOutChanges.After.Balance != 10500 ||
+		cashOutChanges.After.LockedBalance != 0 {
+		t.Errorf("unexpected cash-out changes: %s", cashOutLog.Changes)
+	}
+}
+
+type verificationAccountRepo struct {
+	repository.AccountRepository
+	verified bool
+}
+
+func (r *verificationAccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Account, error) {
+	return &repository.Account{ID: id, EmailVerified: r.verified}, nil
+}
+
+// TestGameService_RealMoneyBuyInRequiresVerifiedEmail 邮箱未验证不可真钱买入，游戏币桌不受限制
+func TestGameService_RealMoneyBuyInRequiresVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 10000)
+	walletRepo.addWallet("PLAY", 50000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	accountRepo := &verificationAccountRepo{}
+	svc := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+	svc.SetAccountRepository(accountRepo)
+	svc.SetPlayMoneyCurrencies([]string{"PLAY"})
+
+	if _, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 2000}); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("expected ErrEmailNotVerified, got %v", err)
+	}
+	if w := walletRepo.wallet; w.Balance != 10000 || w.LockedBalance != 0 {
+		t.Fatalf("wallet must be untouched after rejected buy-in, got %+v", w)
+	}
+
+	playBuyIn, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "play-1", GameType: "poker", Amount: 2000, Currency: "PLAY"})
+	if err != nil {
+		t.Fatalf("expected play-money buy-in to be allowed, got %v", err)
+	}
+	if _, err := svc.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: playBuyIn.SessionID, Chips: 2000}); err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+
+	accountRepo.verified = true
+	if _, err := svc.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "t1", GameType: "poker", Amount: 2000}); err != nil {
+		t.Fatalf("expected verified account to buy in, got %v", err)
+	}
+}
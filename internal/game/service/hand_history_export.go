@@ -0,0 +1,503 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrHandHistoryNotFound 手牌历史未找到
+	ErrHandHistoryNotFound = errors.New("hand history not found")
+	// ErrHandHistoryForbidden 无权查看该手牌历史
+	ErrHandHistoryForbidden = errors.New("hand history access denied")
+	// ErrUnsupportedExportFormat 不支持的导出格式
+	ErrUnsupportedExportFormat = errors.New("unsupported export format")
+)
+
+// ExportFormat 手牌历史导出格式
+type ExportFormat string
+
+const (
+	ExportFormatPokerStars ExportFormat = "pokerstars" // PokerStars 文本格式（追踪软件通用）
+	ExportFormatJSON       ExportFormat = "json"       // JSON 格式
+)
+
+// ParseExportFormat 解析导出格式（空字串默认为 PokerStars）
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(s)) {
+	case "", ExportFormatPokerStars:
+		return ExportFormatPokerStars, nil
+	case ExportFormatJSON:
+		return ExportFormatJSON, nil
+	default:
+		return "", ErrUnsupportedExportFormat
+	}
+}
+
+// ExportedHand 对外导出的手牌（已按查看者过滤底牌）
+type ExportedHand struct {
+	HandID     string                         `json:"hand_id"`
+	HandNumber int                            `json:"hand_number"`
+	TableID    string                         `json:"table_id"`
+	ButtonSeat int                            `json:"button_seat"` // 从 1 开始
+	SmallBlind int64                          `json:"small_blind"`
+	BigBlind   int64                          `json:"big_blind"`
+	Players    []ExportedPlayer               `json:"players"`
+	Actions    []repository.HandHistoryAction `json:"actions"`
+	Board      []string                       `json:"board"`
+	Pots       []repository.HandHistoryPot    `json:"pots"`
+	Winners    []repository.HandHistoryWinner `json:"winners"`
+	Rake       int64                          `json:"rake"`
+	StartedAt  time.Time                      `json:"started_at"`
+	EndedAt    *time.Time                     `json:"ended_at,omitempty"`
+}
+
+// ExportedPlayer 导出手牌中的玩家
+// HoleCards 只对查看者本人及摊牌亮牌的玩家公开
+type ExportedPlayer struct {
+	PlayerID      string   `json:"player_id"`
+	Name          string   `json:"name"`
+	Seat          int      `json:"seat"` // 从 1 开始
+	StartingChips int64    `json:"starting_chips"`
+	EndingChips   int64    `json:"ending_chips"`
+	HoleCards     []string `json:"hole_cards,omitempty"`
+	ShowedDown    bool     `json:"showed_down"`
+	IsDealer      bool     `json:"is_dealer"`
+	IsViewer      bool     `json:"is_viewer"`
+}
+
+// ExportHand 以指定格式导出单手牌，查看者必须参与了该手牌
+func (s *HandHistoryService) ExportHand(ctx context.Context, handID, viewerID uuid.UUID, format ExportFormat) ([]byte, error) {
+	history, err := s.historyRepo.GetByID(ctx, handID)
+	if err != nil {
+		s.logger.Debug("hand history lookup failed",
+			zap.String("hand_id", handID.String()),
+			zap.Error(err),
+		)
+		return nil, ErrHandHistoryNotFound
+	}
+
+	names := make(map[string]string)
+	hand, err := s.buildExportedHand(ctx, history, viewerID, names)
+	if err != nil {
+		return nil, err
+	}
+	if !hand.hasViewer() {
+		return nil, ErrHandHistoryForbidden
+	}
+
+	return encodeHands([]*ExportedHand{hand}, format)
+}
+
+// ExportSession 以指定格式导出游戏会话内的所有手牌，会话必须属于查看者
+func (s *HandHistoryService) ExportSession(ctx context.Context, sessionID, viewerID uuid.UUID, format ExportFormat, limit int) ([]byte, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.PlayerID != viewerID {
+		return nil, ErrHandHistoryForbidden
+	}
+
+	histories, err := s.historyRepo.GetByGameSessionID(ctx, sessionID, limit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session hands: %w", err)
+	}
+
+	// 仓库按时间倒序返回，导出时按发生顺序排列
+	hands := make([]*ExportedHand, 0, len(histories))
+	names := make(map[string]string)
+	for i := len(histories) - 1; i >= 0; i-- {
+		hand, err := s.buildExportedHand(ctx, histories[i], viewerID, names)
+		if err != nil {
+			return nil, err
+		}
+		hands = append(hands, hand)
+	}
+
+	return encodeHands(hands, format)
+}
+
+// buildExportedHand 解码 JSONB 栏位并依查看者过滤底牌
+// names 为玩家显示名称缓存，跨多手牌共用
+func (s *HandHistoryService) buildExportedHand(ctx context.Context, history *repository.HandHistory, viewerID uuid.UUID, names map[string]string) (*ExportedHand, error) {
+	var players []repository.HandHistoryPlayer
+	if err := json.Unmarshal(history.PlayersData, &players); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal players: %w", err)
+	}
+
+	hand := &ExportedHand{
+		HandID:     history.ID.String(),
+		HandNumber: history.HandNumber,
+		TableID:    history.TableID,
+		ButtonSeat: history.DealerPos + 1,
+		SmallBlind: history.SmallBlind,
+		BigBlind:   history.BigBlind,
+		Rake:       history.RakeAmount,
+		StartedAt:  history.StartedAt,
+		EndedAt:    history.EndedAt,
+	}
+	if err := unmarshalOptional(history.Actions, &hand.Actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+	if err := unmarshalOptional(history.CommunityCards, &hand.Board); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal community cards: %w", err)
+	}
+	if err := unmarshalOptional(history.Pots, &hand.Pots); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pots: %w", err)
+	}
+	if err := unmarshalOptional(history.Winners, &hand.Winners); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal winners: %w", err)
+	}
+
+	viewer := viewerID.String()
+	hand.Players = make([]ExportedPlayer, 0, len(players))
+	for _, p := range players {
+		exported := ExportedPlayer{
+			PlayerID:      p.PlayerID,
+			Name:          s.playerName(ctx, p.PlayerID, names),
+			Seat:          p.Seat + 1,
+			StartingChips: p.StartingChips,
+			EndingChips:   p.EndingChips,
+			ShowedDown:    p.ShowedDown,
+			IsDealer:      p.IsDealer,
+			IsViewer:      p.PlayerID == viewer,
+		}
+		// 其他玩家的底牌只在摊牌亮牌时公开
+		if exported.IsViewer || p.ShowedDown {
+			exported.HoleCards = p.HoleCards
+		}
+		hand.Players = append(hand.Players, exported)
+	}
+
+	return hand, nil
+}
+
+// playerName 查询玩家显示名称（查不到时使用玩家 ID）
+func (s *HandHistoryService) playerName(ctx context.Context, playerID string, names map[string]string) string {
+	if name, ok := names[playerID]; ok {
+		return name
+	}
+	name := playerID
+	if id, err := uuid.Parse(playerID); err == nil && s.playerRepo != nil {
+		if player, err := s.playerRepo.GetByID(ctx, id); err == nil && player.DisplayName != "" {
+			name = player.DisplayName
+		}
+	}
+	names[playerID] = name
+	return name
+}
+
+// hasViewer 查看者是否参与了该手牌
+func (h *ExportedHand) hasViewer() bool {
+	for _, p := range h.Players {
+		if p.IsViewer {
+			return true
+		}
+	}
+	return false
+}
+
+// player 依 ID 查找导出的玩家
+func (h *ExportedHand) player(playerID string) *ExportedPlayer {
+	for i := range h.Players {
+		if h.Players[i].PlayerID == playerID {
+			return &h.Players[i]
+		}
+	}
+	return nil
+}
+
+// unmarshalOptional 解码可能为空的 JSONB 栏位
+func unmarshalOptional(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeHands 将多手牌编码为指定格式
+func encodeHands(hands []*ExportedHand, format ExportFormat) ([]byte, error) {
+	switch format {
+	case ExportFormatJSON:
+		return json.MarshalIndent(hands, "", "  ")
+	case ExportFormatPokerStars:
+		parts := make([]string, 0, len(hands))
+		for _, h := range hands {
+			parts = append(parts, FormatPokerStars(h))
+		}
+		// PokerStars 文件以空行分隔每手牌
+		return []byte(strings.Join(parts, "\n\n\n")), nil
+	default:
+		return nil, ErrUnsupportedExportFormat
+	}
+}
+
+// FormatPokerStars 将手牌格式化为 PokerStars 手牌历史文本
+// 筹码单位为分（cents），输出为美元金额
+func FormatPokerStars(h *ExportedHand) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "PokerStars Hand #%d: Hold'em No Limit (%s/%s USD) - %s UTC\n",
+		pokerStarsHandNumber(h.HandID), formatMoney(h.SmallBlind), formatMoney(h.BigBlind),
+		h.StartedAt.UTC().Format("2006/01/02 15:04:05"))
+
+	fmt.Fprintf(&b, "Table '%s' 9-max Seat #%d is the button\n", h.TableID, h.ButtonSeat)
+
+	for _, p := range h.Players {
+		fmt.Fprintf(&b, "Seat %d: %s (%s in chips)\n", p.Seat, p.Name, formatMoney(p.StartingChips))
+	}
+
+	street := ""
+	var streetBet int64                 // 本街最高下注
+	committed := make(map[string]int64) // 玩家本手累计投入
+	foldedOn := make(map[string]string) // 玩家弃牌的街道
+	board := h.Board
+	for _, a := range h.Actions {
+		name := a.PlayerID
+		allIn := ""
+		if p := h.player(a.PlayerID); p != nil {
+			name = p.Name
+			committed[a.PlayerID] += a.Amount
+			if a.Amount > 0 && committed[a.PlayerID] >= p.StartingChips {
+				allIn = " and is all-in"
+			}
+		}
+
+		switch a.Action {
+		case "SMALL_BLIND":
+			fmt.Fprintf(&b, "%s: posts small blind %s%s\n", name, formatMoney(a.Amount), allIn)
+			streetBet = max(streetBet, a.TotalBet)
+			continue
+		case "BIG_BLIND":
+			fmt.Fprintf(&b, "%s: posts big blind %s%s\n", name, formatMoney(a.Amount), allIn)
+			streetBet = max(streetBet, a.TotalBet)
+			continue
+		}
+
+		if a.Street != street {
+			if a.Street != "PREFLOP" {
+				streetBet = 0
+			}
+			street = a.Street
+			writeStreetHeader(&b, h, street, board)
+		}
+
+		switch a.Action {
+		case "FOLD":
+			fmt.Fprintf(&b, "%s: folds\n", name)
+			foldedOn[a.PlayerID] = street
+		case "CHECK":
+			fmt.Fprintf(&b, "%s: checks\n", name)
+		case "CALL":
+			fmt.Fprintf(&b, "%s: calls %s%s\n", name, formatMoney(a.Amount), allIn)
+		case "BET":
+			fmt.Fprintf(&b, "%s: bets %s%s\n", name, formatMoney(a.Amount), allIn)
+		case "RAISE":
+			fmt.Fprintf(&b, "%s: raises %s to %s%s\n", name, formatMoney(a.TotalBet-streetBet), formatMoney(a.TotalBet), allIn)
+		case "ALL_IN":
+			switch {
+			case a.TotalBet <= streetBet:
+				fmt.Fprintf(&b, "%s: calls %s and is all-in\n", name, formatMoney(a.Amount))
+			case streetBet == 0:
+				fmt.Fprintf(&b, "%s: bets %s and is all-in\n", name, formatMoney(a.Amount))
+			default:
+				fmt.Fprintf(&b, "%s: raises %s to %s and is all-in\n", name, formatMoney(a.TotalBet-streetBet), formatMoney(a.TotalBet))
+			}
+		}
+		streetBet = max(streetBet, a.TotalBet)
+	}
+
+	// 若所有人全下，剩余公共牌没有对应动作，补上街道标题
+	for _, s := range []string{"FLOP", "TURN", "RIVER"} {
+		if streetRank(s) > streetRank(street) && len(board) >= boardSize(s) {
+			writeStreetHeader(&b, h, s, board)
+		}
+	}
+
+	showdown := false
+	for _, p := range h.Players {
+		if p.ShowedDown {
+			showdown = true
+			break
+		}
+	}
+	if showdown {
+		b.WriteString("*** SHOW DOWN ***\n")
+		for _, p := range h.Players {
+			if p.ShowedDown && len(p.HoleCards) > 0 {
+				fmt.Fprintf(&b, "%s: shows [%s]\n", p.Name, strings.Join(p.HoleCards, " "))
+			}
+		}
+	}
+
+	won := make(map[string]int64)
+	var totalPot int64
+	for _, pot := range h.Pots {
+		totalPot += pot.Amount
+	}
+	for _, w := range h.Winners {
+		won[w.PlayerID] += w.Amount
+		name := w.PlayerID
+		if p := h.player(w.PlayerID); p != nil {
+			name = p.Name
+		}
+		fmt.Fprintf(&b, "%s collected %s from pot\n", name, formatMoney(w.Amount))
+	}
+
+	b.WriteString("*** SUMMARY ***\n")
+	fmt.Fprintf(&b, "Total pot %s | Rake %s\n", formatMoney(totalPot+h.Rake), formatMoney(h.Rake))
+	if len(board) > 0 {
+		fmt.Fprintf(&b, "Board [%s]\n", strings.Join(board, " "))
+	}
+	for _, p := range h.Players {
+		fmt.Fprintf(&b, "Seat %d: %s%s %s\n", p.Seat, p.Name, positionLabel(h, p), summaryResult(p, foldedOn, won))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeStreetHeader 输出街道标题（如 *** FLOP *** [Ah Kd 7c]）
+func writeStreetHeader(b *strings.Builder, h *ExportedHand, street string, board []string) {
+	switch street {
+	case "PREFLOP":
+		b.WriteString("*** HOLE CARDS ***\n")
+		for _, p := range h.Players {
+			if p.IsViewer && len(p.HoleCards) > 0 {
+				fmt.Fprintf(b, "Dealt to %s [%s]\n", p.Name, strings.Join(p.HoleCards, " "))
+			}
+		}
+	case "FLOP":
+		if len(board) >= 3 {
+			fmt.Fprintf(b, "*** FLOP *** [%s]\n", strings.Join(board[:3], " "))
+		}
+	case "TURN":
+		if len(board) >= 4 {
+			fmt.Fprintf(b, "*** TURN *** [%s] [%s]\n", strings.Join(board[:3], " "), board[3])
+		}
+	case "RIVER":
+		if len(board) >= 5 {
+			fmt.Fprintf(b, "*** RIVER *** [%s] [%s]\n", strings.Join(board[:4], " "), board[4])
+		}
+	}
+}
+
+// positionLabel 摘要中的位置标记
+func positionLabel(h *ExportedHand, p ExportedPlayer) string {
+	var label string
+	if p.IsDealer {
+		label += " (button)"
+	}
+	for _, a := range h.Actions {
+		if a.PlayerID != p.PlayerID {
+			continue
+		}
+		switch a.Action {
+		case "SMALL_BLIND":
+			label += " (small blind)"
+		case "BIG_BLIND":
+			label += " (big blind)"
+		}
+	}
+	return label
+}
+
+// summaryResult 摘要中玩家的结果描述
+func summaryResult(p ExportedPlayer, foldedOn map[string]string, won map[string]int64) string {
+	if street, folded := foldedOn[p.PlayerID]; folded {
+		if street == "PREFLOP" {
+			return "folded before Flop"
+		}
+		return "folded on the " + streetTitle(street)
+	}
+	cards := ""
+	if len(p.HoleCards) > 0 && p.ShowedDown {
+		cards = " [" + strings.Join(p.HoleCards, " ") + "]"
+	}
+	if amount, ok := won[p.PlayerID]; ok {
+		if p.ShowedDown {
+			return fmt.Sprintf("showed%s and won (%s)", cards, formatMoney(amount))
+		}
+		return fmt.Sprintf("collected (%s)", formatMoney(amount))
+	}
+	if p.ShowedDown {
+		return fmt.Sprintf("showed%s and lost", cards)
+	}
+	return "mucked"
+}
+
+// streetTitle 街道名称（PokerStars 格式）
+func streetTitle(street string) string {
+	switch street {
+	case "FLOP":
+		return "Flop"
+	case "TURN":
+		return "Turn"
+	case "RIVER":
+		return "River"
+	default:
+		return street
+	}
+}
+
+// streetRank 街道顺序
+func streetRank(street string) int {
+	switch street {
+	case "PREFLOP":
+		return 1
+	case "FLOP":
+		return 2
+	case "TURN":
+		return 3
+	case "RIVER":
+		return 4
+	default:
+		return 0
+	}
+}
+
+// boardSize 该街道需要的公共牌数量
+func boardSize(street string) int {
+	switch street {
+	case "FLOP":
+		return 3
+	case "TURN":
+		return 4
+	case "RIVER":
+		return 5
+	default:
+		return 0
+	}
+}
+
+// formatMoney 将分转为美元字串（如 1050 -> $10.50）
+func formatMoney(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	if cents%100 == 0 {
+		return fmt.Sprintf("%s$%d", sign, cents/100)
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
+
+// pokerStarsHandNumber 由 UUID 手牌 ID 推导出纯数字手牌编号（追踪软件要求数字）
+func pokerStarsHandNumber(handID string) uint64 {
+	id, err := uuid.Parse(handID)
+	if err != nil {
+		return 0
+	}
+	// 取前 8 bytes，限制在 15 位数以内
+	return binary.BigEndian.Uint64(id[:8]) % 1_000_000_000_000_000
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// stubHandHistoryRepo 以 map 儲存手牌歷史的測試用 Repository
+type stubHandHistoryRepo struct {
+	hands map[uuid.UUID]*repository.HandHistory
+}
+
+func (r *stubHandHistoryRepo) Create(ctx context.Context, history *repository.HandHistory) error {
+	r.hands[history.ID] = history
+	return nil
+}
+
+func (r *stubHandHistoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.HandHistory, error) {
+	if h, ok := r.hands[id]; ok {
+		return h, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *stubHandHistoryRepo) GetByGameSessionID(ctx context.Context, sessionID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	var result []*repository.HandHistory
+	for _, h := range r.hands {
+		result = append(result, h)
+	}
+	return result, nil
+}
+
+func (r *stubHandHistoryRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	return nil, nil
+}
+
+func (r *stubHandHistoryRepo) GetByTableID(ctx context.Context, tableID string, limit, offset int) ([]*repository.HandHistory, error) {
+	return nil, nil
+}
+
+// newShowdownHistory 建立一手三人牌局：hero 與 villain 攤牌，folder 翻牌前棄牌
+func newShowdownHistory(t *testing.T, hero, villain, folder uuid.UUID) *repository.HandHistory {
+	t.Helper()
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	players, _ := json.Marshal([]repository.HandHistoryPlayer{
+		{PlayerID: folder.String(), Seat: 0, StartingChips: 1000, EndingChips: 1000, HoleCards: []string{"7c", "2d"}, IsDealer: true},
+		{PlayerID: hero.String(), Seat: 1, StartingChips: 1000, EndingChips: 1120, HoleCards: []string{"As", "Ah"}, ShowedDown: true},
+		{PlayerID: villain.String(), Seat: 2, StartingChips: 1000, EndingChips: 880, HoleCards: []string{"Ks", "Kh"}, ShowedDown: true},
+	})
+	actions, _ := json.Marshal([]repository.HandHistoryAction{
+		{PlayerID: hero.String(), Street: "PREFLOP", Action: "SMALL_BLIND", Amount: 10, TotalBet: 10},
+		{PlayerID: villain.String(), Street: "PREFLOP", Action: "BIG_BLIND", Amount: 20, TotalBet: 20},
+		{PlayerID: folder.String(), Street: "PREFLOP", Action: "FOLD"},
+		{PlayerID: hero.String(), Street: "PREFLOP", Action: "RAISE", Amount: 50, TotalBet: 60},
+		{PlayerID: villain.String(), Street: "PREFLOP", Action: "CALL", Amount: 40, TotalBet: 60},
+		{PlayerID: hero.String(), Street: "FLOP", Action: "CHECK"},
+		{PlayerID: villain.String(), Street: "FLOP", Action: "CHECK"},
+		{PlayerID: hero.String(), Street: "TURN", Action: "CHECK"},
+		{PlayerID: villain.String(), Street: "TURN", Action: "CHECK"},
+		{PlayerID: hero.String(), Street: "RIVER", Action: "CHECK"},
+		{PlayerID: villain.String(), Street: "RIVER", Action: "CHECK"},
+	})
+
+	return &repository.HandHistory{
+		ID:             uuid.New(),
+		TableID:        "table-1",
+		HandNumber:     7,
+		SmallBlind:     10,
+		BigBlind:       20,
+		PlayersData:    players,
+		Actions:        actions,
+		Pots:           []byte(`[{"amount":120}]`),
+		CommunityCards: []byte(`["2c","3c","4d","Jc","Qh"]`),
+		Winners:        []byte(`[{"player_id":"` + hero.String() + `","amount":120}]`),
+		StartedAt:      start,
+	}
+}
+
+func newExportTestService(histories ...*repository.HandHistory) *HandHistoryService {
+	repo := &stubHandHistoryRepo{hands: make(map[uuid.UUID]*repository.HandHistory)}
+	for _, h := range histories {
+		repo.hands[h.ID] = h
+	}
+	return NewHandHistoryService(repo, nil, nil, zap.NewNop())
+}
+
+// TestExportHand_PokerStars 驗證 PokerStars 文本格式與底牌公開規則
+func TestExportHand_PokerStars(t *testing.T) {
+	hero, villain, folder := uuid.New(), uuid.New(), uuid.New()
+	history := newShowdownHistory(t, hero, villain, folder)
+	svc := newExportTestService(history)
+
+	data, err := svc.ExportHand(context.Background(), history.ID, hero, ExportFormatPokerStars)
+	if err != nil {
+		t.Fatalf("ExportHand failed: %v", err)
+	}
+	text := string(data)
+
+	expected := []string{
+		"Hold'em No Limit ($0.10/$0.20 USD) - 2026/01/02 03:04:05 UTC",
+		"Table 'table-1' 9-max Seat #1 is the button",
+		"Seat 2: " + hero.String() + " ($10 in chips)",
+		hero.String() + ": posts small blind $0.10",
+		"*** HOLE CARDS ***\nDealt to " + hero.String() + " [As Ah]",
+		hero.String() + ": raises $0.40 to $0.60",
+		villain.String() + ": calls $0.40",
+		"*** FLOP *** [2c 3c 4d]",
+		"*** TURN *** [2c 3c 4d] [Jc]",
+		"*** RIVER *** [2c 3c 4d Jc] [Qh]",
+		"*** SHOW DOWN ***",
+		villain.String() + ": shows [Ks Kh]",
+		hero.String() + " collected $1.20 from pot",
+		"Total pot $1.20 | Rake $0",
+		"Seat 1: " + folder.String() + " (button) folded before Flop",
+		"Seat 3: " + villain.String() + " (big blind) showed [Ks Kh] and lost",
+	}
+	for _, want := range expected {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q\n%s", want, text)
+		}
+	}
+
+	// 未亮牌的玩家底牌不得出現
+	if strings.Contains(text, "[7c 2d]") {
+		t.Errorf("Folded player's hole cards leaked:\n%s", text)
+	}
+}
+
+// TestExportHand_JSONHidesUnshownCards 驗證 JSON 格式只公開查看者與攤牌者的底牌
+func TestExportHand_JSONHidesUnshownCards(t *testing.T) {
+	hero, villain, folder := uuid.New(), uuid.New(), uuid.New()
+	history := newShowdownHistory(t, hero, villain, folder)
+	svc := newExportTestService(history)
+
+	// 以棄牌者視角查看：自己的底牌可見，攤牌者可見
+	data, err := svc.ExportHand(context.Background(), history.ID, folder, ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("ExportHand failed: %v", err)
+	}
+
+	var hands []ExportedHand
+	if err := json.Unmarshal(data, &hands); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(hands) != 1 {
+		t.Fatalf("Expected 1 hand, got %d", len(hands))
+	}
+	for _, p := range hands[0].Players {
+		if len(p.HoleCards) != 2 {
+			t.Errorf("Expected hole cards for %s (viewer or showed down)", p.PlayerID)
+		}
+	}
+
+	// 以攤牌者視角查看：棄牌者的底牌隱藏
+	data, err = svc.ExportHand(context.Background(), history.ID, villain, ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("ExportHand failed: %v", err)
+	}
+	hands = nil
+	if err := json.Unmarshal(data, &hands); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	for _, p := range hands[0].Players {
+		if p.PlayerID == folder.String() && len(p.HoleCards) != 0 {
+			t.Errorf("Folded player's hole cards should be hidden, got %v", p.HoleCards)
+		}
+	}
+}
+
+// TestExportHand_Forbidden 未參與手牌的玩家不能下載
+func TestExportHand_Forbidden(t *testing.T) {
+	history := newShowdownHistory(t, uuid.New(), uuid.New(), uuid.New())
+	svc := newExportTestService(history)
+
+	_, err := svc.ExportHand(context.Background(), history.ID, uuid.New(), ExportFormatPokerStars)
+	if !errors.Is(err, ErrHandHistoryForbidden) {
+		t.Errorf("Expected ErrHandHistoryForbidden, got %v", err)
+	}
+
+	_, err = svc.ExportHand(context.Background(), uuid.New(), uuid.New(), ExportFormatPokerStars)
+	if !errors.Is(err, ErrHandHistoryNotFound) {
+		t.Errorf("Expected ErrHandHistoryNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// ReplayHand 重播已保存的手牌并验证筹码变化（管理员/客服使用）
+func (s *HandHistoryService) ReplayHand(ctx context.Context, handID uuid.UUID) (*domain.ReplayResult, error) {
+	history, err := s.historyRepo.GetByID(ctx, handID)
+	if err != nil {
+		s.logger.Debug("hand history lookup failed",
+			zap.String("hand_id", handID.String()),
+			zap.Error(err),
+		)
+		return nil, ErrHandHistoryNotFound
+	}
+
+	record, err := toHandRecord(history)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := domain.ReplayHand(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Verified() {
+		s.logger.Warn("hand replay mismatch",
+			zap.String("hand_id", record.HandID),
+			zap.String("table_id", record.TableID),
+			zap.Strings("mismatches", result.Mismatches),
+		)
+	}
+
+	return result, nil
+}
+
+// toHandRecord 将 repository.HandHistory 还原为 domain.HandRecord（buildHistory 的反向操作）
+func toHandRecord(history *repository.HandHistory) (*domain.HandRecord, error) {
+	var players []repository.HandHistoryPlayer
+	if err := json.Unmarshal(history.PlayersData, &players); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal players: %w", err)
+	}
+	var actions []repository.HandHistoryAction
+	if err := unmarshalOptional(history.Actions, &actions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal actions: %w", err)
+	}
+	var board, deck []string
+	if err := unmarshalOptional(history.CommunityCards, &board); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal community cards: %w", err)
+	}
+	if err := unmarshalOptional(history.Deck, &deck); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deck: %w", err)
+	}
+
+	record := &domain.HandRecord{
+		HandID:     history.ID.String(),
+		HandNumber: history.HandNumber,
+		TableID:    history.TableID,
+		DealerPos:  history.DealerPos,
+		SmallBlind: history.SmallBlind,
+		BigBlind:   history.BigBlind,
+		Rake:       history.RakeAmount,
+		Players:    make([]*domain.HandPlayerRecord, 0, len(players)),
+		Actions:    make([]domain.HandActionRecord, 0, len(actions)),
+		StartedAt:  history.StartedAt,
+	}
+	if history.EndedAt != nil {
+		record.EndedAt = *history.EndedAt
+	}
+
+	var err error
+	if record.Board, err = parseCards(board); err != nil {
+		return nil, err
+	}
+	if record.Deck, err = parseCards(deck); err != nil {
+		return nil, err
+	}
+
+	for _, p := range players {
+		holeCards, err := parseCards(p.HoleCards)
+		if err != nil {
+			return nil, err
+		}
+		record.Players = append(record.Players, &domain.HandPlayerRecord{
+			PlayerID:      p.PlayerID,
+			SeatIdx:       p.Seat,
+			StartingChips: p.StartingChips,
+			EndingChips:   p.EndingChips,
+			HoleCards:     holeCards,
+			ShowedDown:    p.ShowedDown,
+		})
+	}
+
+	for _, a := range actions {
+		record.Actions = append(record.Actions, domain.HandActionRecord{
+			PlayerID:  a.PlayerID,
+			Street:    a.Street,
+			Action:    a.Action,
+			Amount:    a.Amount,
+			TotalBet:  a.TotalBet,
+			Reason:    a.Reason,
+			Timestamp: a.Timestamp,
+		})
+	}
+
+	return record, nil
+}
+
+// parseCards 将字串表示的牌转换为 domain.Card
+func parseCards(strs []string) ([]domain.Card, error) {
+	cards := make([]domain.Card, 0, len(strs))
+	for _, s := range strs {
+		c, err := domain.ParseCard(s)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
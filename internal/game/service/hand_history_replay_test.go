@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+)
+
+// TestReplayHand_FromPersistedHistory 保存後再讀出的紀錄應能重播並通過驗證
+func TestReplayHand_FromPersistedHistory(t *testing.T) {
+	table := domain.NewTable("replay-table")
+	ids := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	for i, id := range ids {
+		p := &domain.Player{ID: id, SeatIdx: i, Chips: 1000, Status: domain.StatusPlaying}
+		table.Seats[i] = p
+		table.Players[id] = p
+	}
+	table.DealerPos = 1
+
+	var record *domain.HandRecord
+	table.AddOnHandComplete(func(tb *domain.Table) {
+		record = tb.LastHand
+	})
+	table.StartHand()
+
+	// 透過 ActionCh 驅動 Run()：輪流替每位玩家送出跟注（未輪到者會被拒絕），直到手牌結束
+	go table.Run()
+	defer close(table.CloseCh)
+	for i := 0; i < 20 && record == nil; i++ {
+		for _, id := range ids {
+			resultCh := make(chan domain.ActionResult, 1)
+			table.ActionCh <- domain.PlayerAction{Type: domain.ActionCall, PlayerID: id, ResultCh: resultCh}
+			<-resultCh
+			if record != nil {
+				break
+			}
+		}
+	}
+	if record == nil {
+		t.Fatal("Expected hand to complete")
+	}
+
+	svc := newExportTestService()
+	history, err := svc.buildHistory(context.Background(), record)
+	if err != nil {
+		t.Fatalf("buildHistory failed: %v", err)
+	}
+	restored, err := toHandRecord(history)
+	if err != nil {
+		t.Fatalf("toHandRecord failed: %v", err)
+	}
+
+	result, err := domain.ReplayHand(restored)
+	if err != nil {
+		t.Fatalf("ReplayHand failed: %v", err)
+	}
+	if !result.Verified() {
+		t.Errorf("Expected persisted hand to replay cleanly, mismatches: %v", result.Mismatches)
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// HandHistoryService 手牌歷史服务，将 domain.HandRecord 持久化
+type HandHistoryService struct {
+	historyRepo repository.HandHistoryRepository
+	sessionRepo repository.GameSessionRepository
+	playerRepo  repository.PlayerRepository
+	logger      *zap.Logger
+}
+
+// NewHandHistoryService 创建手牌歷史服务
+func NewHandHistoryService(
+	historyRepo repository.HandHistoryRepository,
+	sessionRepo repository.GameSessionRepository,
+	playerRepo repository.PlayerRepository,
+	logger *zap.Logger,
+) *HandHistoryService {
+	return &HandHistoryService{
+		historyRepo: historyRepo,
+		sessionRepo: sessionRepo,
+		playerRepo:  playerRepo,
+		logger:      logger,
+	}
+}
+
+// RecordHand 保存一手牌的完整记录
+func (s *HandHistoryService) RecordHand(ctx context.Context, record *domain.HandRecord) error {
+	history, err := s.buildHistory(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	if err := s.historyRepo.Create(ctx, history); err != nil {
+		s.logger.Error("failed to save hand history",
+			zap.String("hand_id", record.HandID),
+			zap.String("table_id", record.TableID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Debug("hand history saved",
+		zap.String("hand_id", record.HandID),
+		zap.String("table_id", record.TableID),
+		zap.Int("hand_number", record.HandNumber),
+	)
+
+	return nil
+}
+
+// GetHand 根据手牌 ID 查询手牌历史
+func (s *HandHistoryService) GetHand(ctx context.Context, handID uuid.UUID) (*repository.HandHistory, error) {
+	return s.historyRepo.GetByID(ctx, handID)
+}
+
+// GetPlayerHands 查询玩家的手牌历史（分页）
+func (s *HandHistoryService) GetPlayerHands(ctx context.Context, playerID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	return s.historyRepo.GetByPlayerID(ctx, playerID, limit, offset)
+}
+
+// GetSessionHands 查询游戏会话内的手牌历史（分页）
+func (s *HandHistoryService) GetSessionHands(ctx context.Context, sessionID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	return s.historyRepo.GetByGameSessionID(ctx, sessionID, limit, offset)
+}
+
+// buildHistory 将 domain.HandRecord 转换为 repository.HandHistory
+func (s *HandHistoryService) buildHistory(ctx context.Context, record *domain.HandRecord) (*repository.HandHistory, error) {
+	handID, err := uuid.Parse(record.HandID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hand id %q: %w", record.HandID, err)
+	}
+
+	players := make([]repository.HandHistoryPlayer, 0, len(record.Players))
+	for _, p := range record.Players {
+		players = append(players, repository.HandHistoryPlayer{
+			PlayerID:      p.PlayerID,
+			GameSessionID: s.lookupSessionID(ctx, p.PlayerID),
+			Seat:          p.SeatIdx,
+			StartingChips: p.StartingChips,
+			EndingChips:   p.EndingChips,
+			HoleCards:     cardStrings(p.HoleCards),
+			ShowedDown:    p.ShowedDown,
+			IsDealer:      p.SeatIdx == record.DealerPos,
+		})
+	}
+
+	actions := make([]repository.HandHistoryAction, 0, len(record.Actions))
+	for _, a := range record.Actions {
+		actions = append(actions, repository.HandHistoryAction{
+			PlayerID:  a.PlayerID,
+			Street:    a.Street,
+			Action:    a.Action,
+			Amount:    a.Amount,
+			TotalBet:  a.TotalBet,
+			Reason:    a.Reason,
+			Timestamp: a.Timestamp,
+		})
+	}
+
+	pots := make([]repository.HandHistoryPot, 0, len(record.Pots))
+	for _, p := range record.Pots {
+		pots = append(pots, repository.HandHistoryPot{
+			Amount:       p.Amount,
+			Contributors: p.Contributors,
+		})
+	}
+
+	winners := make([]repository.HandHistoryWinner, 0, len(record.Winners))
+	for _, w := range record.Winners {
+		winners = append(winners, repository.HandHistoryWinner{
+			PlayerID: w.PlayerID,
+			Amount:   w.Amount,
+		})
+	}
+
+	history := &repository.HandHistory{
+		ID:         handID,
+		TableID:    record.TableID,
+		HandNumber: record.HandNumber,
+		DealerPos:  record.DealerPos,
+		SmallBlind: record.SmallBlind,
+		BigBlind:   record.BigBlind,
+		RakeAmount: record.Rake,
+		StartedAt:  record.StartedAt,
+		Duration:   int(record.Duration().Seconds()),
+	}
+	if !record.EndedAt.IsZero() {
+		endedAt := record.EndedAt
+		history.EndedAt = &endedAt
+	}
+
+	if history.PlayersData, err = json.Marshal(players); err != nil {
+		return nil, fmt.Errorf("failed to marshal players: %w", err)
+	}
+	if history.Actions, err = json.Marshal(actions); err != nil {
+		return nil, fmt.Errorf("failed to marshal actions: %w", err)
+	}
+	if history.Pots, err = json.Marshal(pots); err != nil {
+		return nil, fmt.Errorf("failed to marshal pots: %w", err)
+	}
+	if history.CommunityCards, err = json.Marshal(cardStrings(record.Board)); err != nil {
+		return nil, fmt.Errorf("failed to marshal community cards: %w", err)
+	}
+	if history.Deck, err = json.Marshal(cardStrings(record.Deck)); err != nil {
+		return nil, fmt.Errorf("failed to marshal deck: %w", err)
+	}
+	if history.Winners, err = json.Marshal(winners); err != nil {
+		return nil, fmt.Errorf("failed to marshal winners: %w", err)
+	}
+
+	return history, nil
+}
+
+// lookupSessionID 查询玩家当前的游戏会话 ID（找不到时返回空字串）
+func (s *HandHistoryService) lookupSessionID(ctx context.Context, playerID string) string {
+	id, err := uuid.Parse(playerID)
+	if err != nil || s.sessionRepo == nil {
+		return ""
+	}
+	session, err := s.sessionRepo.GetActiveByPlayerID(ctx, id)
+	if err != nil || session == nil {
+		return ""
+	}
+	return session.ID.String()
+}
+
+// cardStrings 将牌转换为字串表示
+func cardStrings(cards []domain.Card) []string {
+	strs := make([]string, len(cards))
+	for i, c := range cards {
+		strs[i] = c.String()
+	}
+	return strs
+}
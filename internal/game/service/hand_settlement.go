@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// HandSettlementKey 生成单手牌结算分录的幂等性键
+func HandSettlementKey(handID, playerID string) string {
+	return fmt.Sprintf("hand-%s-%s", handID, playerID)
+}
+
+// SettleHand 将一手牌的输赢写入钱包账本并调整托管金额
+// 每位净输赢不为零、且有活跃游戏会话的参与者产生一笔 game_win / game_loss 分录，
+// 以手牌 ID 作为 reference_id 并关联游戏会话；重复调用不会重复记账。
+// 只记入开局时玩家所属的会话：会话已兑现的玩家跳过（兑现时已按最终筹码补记差额），
+// 即使玩家之后重新买入也不会记入新会话。
+func (s *GameService) SettleHand(ctx context.Context, record *domain.HandRecord) error {
+	type settlement struct {
+		playerID  uuid.UUID
+		sessionID uuid.UUID
+		currency  string
+		amount    int64
+	}
+
+	settlements := make([]settlement, 0, len(record.Players))
+	for _, p := range record.Players {
+		net := p.NetResult()
+		if net == 0 {
+			continue
+		}
+		playerID, err := uuid.Parse(p.PlayerID)
+		if err != nil {
+			continue // 非正式玩家（如测试桌）无钱包
+		}
+
+		session := s.handSession(ctx, record, p, playerID)
+		if session == nil {
+			continue // 没有这手牌的托管资金
+		}
+		settlements = append(settlements, settlement{
+			playerID:  playerID,
+			sessionID: session.ID,
+			currency:  s.currencyOrDefault(session.Currency),
+			amount:    net,
+		})
+	}
+
+	if len(settlements) == 0 {
+		return nil
+	}
+
+	err := s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		for _, item := range settlements {
+			// 锁定钱包后再确认会话仍活跃，避免与兑现并发时重复调整
+			if _, err := s.walletRepo.GetWithLock(ctx, tx, item.playerID, item.currency); err != nil {
+				return fmt.Errorf("failed to lock wallet for player %s: %w", item.playerID, err)
+			}
+			if session, err := s.sessionRepo.GetByID(ctx, item.sessionID); err != nil || session.Status != "active" {
+				continue
+			}
+
+			err := s.walletRepo.RecordGameResult(
+				ctx,
+				tx,
+				item.playerID,
+				item.currency,
+				item.amount,
+				&item.sessionID,
+				record.HandID,
+				fmt.Sprintf("Hand #%d at table %s", record.HandNumber, record.TableID),
+				HandSettlementKey(record.HandID, item.playerID.String()),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to record result for player %s: %w", item.playerID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("hand settlement failed",
+			zap.String("hand_id", record.HandID),
+			zap.String("table_id", record.TableID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Debug("hand settled",
+		zap.String("hand_id", record.HandID),
+		zap.Int("entries", len(settlements)),
+	)
+
+	return nil
+}
+
+// handSession 查找玩家打这手牌时的游戏会话，找不到时返回 nil
+// 会话须属于这张桌且在开局前建立；未记录会话 ID 的手牌（旧快照复原）改查活跃会话。
+func (s *GameService) handSession(ctx context.Context, record *domain.HandRecord, p *domain.HandPlayerRecord, playerID uuid.UUID) *repository.GameSession {
+	var session *repository.GameSession
+	if p.SessionID != "" {
+		sessionID, err := uuid.Parse(p.SessionID)
+		if err != nil {
+			return nil
+		}
+		if session, err = s.sessionRepo.GetByID(ctx, sessionID); err != nil {
+			return nil
+		}
+	} else {
+		var err error
+		if session, err = s.sessionRepo.GetActiveByPlayerID(ctx, playerID); err != nil {
+			return nil
+		}
+	}
+
+	if session == nil || session.PlayerID != playerID || session.TableID != record.TableID ||
+		session.StartedAt.After(record.StartedAt) {
+		return nil
+	}
+	return session
+}
+
+// RefundVoidedHand 退还作废手牌中离桌玩家留在底池的筹码，返回退还金额
+// 会话仍活跃时不处理：该手不会结算，兑现时按开局前的筹码计算。
+// 会话已兑现时，兑现筹码低于开局筹码的部分（不超过已投入金额）记回钱包；以手牌与玩家做幂等。
+func (s *GameService) RefundVoidedHand(ctx context.Context, tableID, handID string, leaver domain.SnapshotLeaver) (int64, error) {
+	playerID, err := uuid.Parse(leaver.PlayerID)
+	if err != nil {
+		return 0, nil // 非正式玩家无钱包
+	}
+	sessionID, err := uuid.Parse(leaver.SessionID)
+	if err != nil {
+		return 0, nil // 没有记录托管资金的会话
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get session %s: %w", sessionID, err)
+	}
+	if session.PlayerID != playerID || session.TableID != tableID || session.Status == "active" {
+		return 0, nil
+	}
+
+	refund := leaver.Chips - session.CurrentChips
+	if refund > leaver.Contribution {
+		refund = leaver.Contribution
+	}
+	if refund <= 0 {
+		return 0, nil
+	}
+
+	currency := s.currencyOrDefault(session.Currency)
+	key := fmt.Sprintf("void-%s-%s", handID, playerID)
+	description := fmt.Sprintf("Refund of voided hand at table %s", tableID)
+	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		if _, err := s.walletRepo.GetWithLock(ctx, tx, playerID, currency); err != nil {
+			return fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		err := s.walletRepo.RecordGameResult(ctx, tx, playerID, currency, refund, &session.ID, handID, description, key)
+		if err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+		err = s.walletRepo.UnlockBalance(ctx, tx, playerID, currency, refund, &session.ID, repository.TransactionTypeRefund, description, key+"-unlock")
+		if err != nil {
+			return fmt.Errorf("failed to unlock refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("voided hand refund failed",
+			zap.String("hand_id", handID),
+			zap.String("player_id", leaver.PlayerID),
+			zap.Int64("refund", refund),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	s.logger.Info("refunded leaver of voided hand",
+		zap.String("hand_id", handID),
+		zap.String("player_id", leaver.PlayerID),
+		zap.Int64("refund", refund),
+	)
+	return refund, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type recordedResult struct {
+	playerID       uuid.UUID
+	amount         int64
+	sessionID      *uuid.UUID
+	referenceID    string
+	idempotencyKey string
+}
+
+// stubSettlementWalletRepo 只实现结算所需的方法，RecordGameResult 按幂等性键去重
+type stubSettlementWalletRepo struct {
+	repository.WalletRepository
+	results []recordedResult
+	seen    map[string]bool
+}
+
+func (r *stubSettlementWalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
+	return &repository.Wallet{PlayerID: playerID, Currency: currency}, nil
+}
+
+func (r *stubSettlementWalletRepo) RecordGameResult(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, referenceID, description, idempotencyKey string) error {
+	if r.seen[idempotencyKey] {
+		return nil
+	}
+	r.seen[idempotencyKey] = true
+	r.results = append(r.results, recordedResult{playerID, amount, gameSessionID, referenceID, idempotencyKey})
+	return nil
+}
+
+type stubSettlementSessionRepo struct {
+	repository.GameSessionRepository
+	sessions map[uuid.UUID]*repository.GameSession
+}
+
+func (r *stubSettlementSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.GameSession, error) {
+	for _, s := range r.sessions {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+func (r *stubSettlementSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid.UUID) (*repository.GameSession, error) {
+	if s, ok := r.sessions[playerID]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("session not found")
+}
+
+type stubUnitOfWork struct {
+	repository.UnitOfWork
+}
+
+func (stubUnitOfWork) WithTransaction(ctx context.Context, fn func(tx repository.Transaction) error) error {
+	return fn(nil)
+}
+
+func TestSettleHand(t *testing.T) {
+	winner, loser, folder, unseated := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	winnerSession, loserSession := uuid.New(), uuid.New()
+
+	walletRepo := &stubSettlementWalletRepo{seen: make(map[string]bool)}
+	sessionRepo := &stubSettlementSessionRepo{sessions: map[uuid.UUID]*repository.GameSession{
+		winner: {ID: winnerSession, PlayerID: winner, TableID: "table-1", Status: "active", StartedAt: time.Now().Add(-time.Hour)},
+		loser:  {ID: loserSession, PlayerID: loser, TableID: "table-1", Status: "active", StartedAt: time.Now().Add(-time.Hour)},
+	}}
+	svc := NewGameService(nil, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	record := &domain.HandRecord{
+		HandID:     uuid.New().String(),
+		HandNumber: 7,
+		TableID:    "table-1",
+		StartedAt:  time.Now(),
+		Players: []*domain.HandPlayerRecord{
+			{PlayerID: winner.String(), SessionID: winnerSession.String(), StartingChips: 1000, EndingChips: 1150},
+			{PlayerID: loser.String(), StartingChips: 1000, EndingChips: 850}, // 未记录会话 ID 时查活跃会话
+			{PlayerID: folder.String(), StartingChips: 1000, EndingChips: 1000},
+			{PlayerID: unseated.String(), StartingChips: 1000, EndingChips: 990}, // 无活跃会话
+			{PlayerID: "bot-1", StartingChips: 1000, EndingChips: 900},
+		},
+	}
+
+	if err := svc.SettleHand(context.Background(), record); err != nil {
+		t.Fatalf("SettleHand failed: %v", err)
+	}
+	// 重复结算不应产生新的分录
+	if err := svc.SettleHand(context.Background(), record); err != nil {
+		t.Fatalf("second SettleHand failed: %v", err)
+	}
+
+	if len(walletRepo.results) != 2 {
+		t.Fatalf("expected 2 ledger entries, got %d: %+v", len(walletRepo.results), walletRepo.results)
+	}
+
+	byPlayer := make(map[uuid.UUID]recordedResult)
+	for _, r := range walletRepo.results {
+		if r.referenceID != record.HandID {
+			t.Errorf("expected reference %s, got %s", record.HandID, r.referenceID)
+		}
+		if r.idempotencyKey != HandSettlementKey(record.HandID, r.playerID.String()) {
+			t.Errorf("unexpected idempotency key %s", r.idempotencyKey)
+		}
+		byPlayer[r.playerID] = r
+	}
+
+	if got := byPlayer[winner]; got.amount != 150 || got.sessionID == nil || *got.sessionID != winnerSession {
+		t.Errorf("unexpected winner entry: %+v", got)
+	}
+	if got := byPlayer[loser]; got.amount != -150 || got.sessionID == nil || *got.sessionID != loserSession {
+		t.Errorf("unexpected loser entry: %+v", got)
+	}
+	if _, ok := byPlayer[folder]; ok {
+		t.Error("player with zero net result should not get an entry")
+	}
+	if _, ok := byPlayer[unseated]; ok {
+		t.Error("player without an active session should not get an entry")
+	}
+}
+
+// TestSettleHand_SkipsCashedOutSession 会话已兑现时不再调整托管金额
+func TestSettleHand_SkipsCashedOutSession(t *testing.T) {
+	playerID := uuid.New()
+	session := &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "table-1", Status: "active", StartedAt: time.Now().Add(-time.Hour)}
+
+	walletRepo := &stubSettlementWalletRepo{seen: make(map[string]bool)}
+	sessionRepo := &stubSettlementSessionRepo{sessions: map[uuid.UUID]*repository.GameSession{playerID: session}}
+	// 模拟兑现在查询会话之后、事务锁定钱包之前完成
+	uow := cashOutBeforeSettleUoW{session: session}
+	svc := NewGameService(nil, walletRepo, sessionRepo, uow, zap.NewNop())
+
+	record := &domain.HandRecord{
+		HandID:    uuid.New().String(),
+		TableID:   "table-1",
+		StartedAt: time.Now(),
+		Players: []*domain.HandPlayerRecord{
+			{PlayerID: playerID.String(), SessionID: session.ID.String(), StartingChips: 1000, EndingChips: 1200},
+		},
+	}
+	if err := svc.SettleHand(context.Background(), record); err != nil {
+		t.Fatalf("SettleHand failed: %v", err)
+	}
+	if len(walletRepo.results) != 0 {
+		t.Errorf("expected no entries after cash-out, got %+v", walletRepo.results)
+	}
+}
+
+type cashOutBeforeSettleUoW struct {
+	repository.UnitOfWork
+	session *repository.GameSession
+}
+
+func (u cashOutBeforeSettleUoW) WithTransaction(ctx context.Context, fn func(tx repository.Transaction) error) error {
+	u.session.Status = "completed"
+	return fn(nil)
+}
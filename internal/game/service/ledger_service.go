@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// PlayerDiscrepancy 玩家钱包余额与账本不一致的明细
+type PlayerDiscrepancy struct {
+	PlayerID      uuid.UUID
+	Currency      string
+	WalletBalance int64 // wallets.balance
+	LedgerWallet  int64 // player_wallet 账户余额
+	LockedBalance int64 // wallets.locked_balance
+	LedgerEscrow  int64 // table_escrow 账户余额
+}
+
+// CurrencyReconciliation 单一币别的对账结果
+// 恒等式：钱包余额 + 托管 + 平台抽水 = 存款 - 提款 + 发放奖金 - 牌局清算余额
+// 牌局清算余额为玩家间输赢尚未结清的部分（例如机器人赢走的筹码），正常情况下应为 0；
+// 恒等式把它计入预期值，因此超出容许值时须单独视为资金滞留，否则会被恒等式掩盖。
+type CurrencyReconciliation struct {
+	Currency     string
+	TotalWallets int64 // 所有钱包可用余额合计（wallets 表）
+	TotalEscrow  int64 // 所有钱包锁定余额合计（wallets 表）
+	HouseRake    int64 // 平台抽水收入
+	GamePool     int64 // 牌局清算余额
+	Deposits     int64 // 外部存入合计
+	Withdrawals  int64 // 外部提出合计
+	Bonuses      int64 // 平台发放奖金合计
+
+	GamePoolTolerance int64 // 容许的牌局清算余额（绝对值）
+}
+
+// Actual 钱包余额 + 托管 + 平台抽水
+func (c *CurrencyReconciliation) Actual() int64 {
+	return c.TotalWallets + c.TotalEscrow + c.HouseRake
+}
+
+// Expected 存款 - 提款 + 奖金 - 牌局清算余额
+func (c *CurrencyReconciliation) Expected() int64 {
+	return c.Deposits - c.Withdrawals + c.Bonuses - c.GamePool
+}
+
+// GamePoolStranded 牌局清算余额超出容许值
+func (c *CurrencyReconciliation) GamePoolStranded() bool {
+	pool := c.GamePool
+	if pool < 0 {
+		pool = -pool
+	}
+	return pool > c.GamePoolTolerance
+}
+
+// LedgerReconciliation 钱包对账报告（各币别分别对账，不做汇率换算）
+type LedgerReconciliation struct {
+	GeneratedAt        time.Time
+	Currencies         []*CurrencyReconciliation // 按币别排序
+	UnbalancedJournals []uuid.UUID
+	Discrepancies      []*PlayerDiscrepancy
+}
+
+// Balanced 各币别恒等式成立且没有滞留的牌局清算余额、所有分录借贷平衡且没有玩家差异
+func (r *LedgerReconciliation) Balanced() bool {
+	for _, c := range r.Currencies {
+		if c.Actual() != c.Expected() || c.GamePoolStranded() {
+			return false
+		}
+	}
+	return len(r.UnbalancedJournals) == 0 && len(r.Discrepancies) == 0
+}
+
+// playerCurrency 玩家账户键（玩家 + 币别）
+type playerCurrency struct {
+	playerID uuid.UUID
+	currency string
+}
+
+// LedgerService 复式记账对账服务
+type LedgerService struct {
+	ledgerRepo        repository.LedgerRepository
+	walletRepo        repository.WalletRepository
+	gamePoolTolerance int64
+	logger            *zap.Logger
+}
+
+// NewLedgerService 创建对账服务
+func NewLedgerService(
+	ledgerRepo repository.LedgerRepository,
+	walletRepo repository.WalletRepository,
+	logger *zap.Logger,
+) *LedgerService {
+	return &LedgerService{
+		ledgerRepo: ledgerRepo,
+		walletRepo: walletRepo,
+		logger:     logger,
+	}
+}
+
+// SetGamePoolTolerance 设定每个币别容许的牌局清算余额（分），默认为 0
+func (s *LedgerService) SetGamePoolTolerance(tolerance int64) {
+	s.gamePoolTolerance = tolerance
+}
+
+// Reconcile 比对钱包表与账本，生成对账报告
+func (s *LedgerService) Reconcile(ctx context.Context) (*LedgerReconciliation, error) {
+	balances, err := s.ledgerRepo.GetAccountBalances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger balances: %w", err)
+	}
+	wallets, err := s.walletRepo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	unbalanced, err := s.ledgerRepo.GetUnbalancedJournals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unbalanced journals: %w", err)
+	}
+
+	report := &LedgerReconciliation{
+		GeneratedAt:        time.Now(),
+		UnbalancedJournals: unbalanced,
+	}
+
+	totals := make(map[string]*CurrencyReconciliation)
+	currencyTotals := func(currency string) *CurrencyReconciliation {
+		c, ok := totals[currency]
+		if !ok {
+			c = &CurrencyReconciliation{Currency: currency, GamePoolTolerance: s.gamePoolTolerance}
+			totals[currency] = c
+		}
+		return c
+	}
+
+	// 账本中的玩家账户余额
+	ledgerWallets := make(map[playerCurrency]int64)
+	ledgerEscrow := make(map[playerCurrency]int64)
+	for _, b := range balances {
+		c := currencyTotals(b.Account.Currency)
+		switch b.Account.Type {
+		case repository.LedgerPlayerWallet:
+			if b.Account.PlayerID != nil {
+				ledgerWallets[playerCurrency{*b.Account.PlayerID, b.Account.Currency}] += b.Balance()
+			}
+		case repository.LedgerTableEscrow:
+			if b.Account.PlayerID != nil {
+				ledgerEscrow[playerCurrency{*b.Account.PlayerID, b.Account.Currency}] += b.Balance()
+			}
+		case repository.LedgerHouseRake:
+			c.HouseRake += b.Balance()
+		case repository.LedgerGamePool:
+			c.GamePool += b.Balance()
+		case repository.LedgerBonusLiability:
+			c.Bonuses += b.Decreases - b.Increases
+		case repository.LedgerExternal:
+			// 存入时外部账户减少，提出时增加
+			c.Deposits += b.Decreases
+			c.Withdrawals += b.Increases
+		}
+	}
+
+	seen := make(map[playerCurrency]bool, len(wallets))
+	for _, w := range wallets {
+		key := playerCurrency{w.PlayerID, w.Currency}
+		seen[key] = true
+		c := currencyTotals(w.Currency)
+		c.TotalWallets += w.Balance
+		c.TotalEscrow += w.LockedBalance
+
+		if w.Balance != ledgerWallets[key] || w.LockedBalance != ledgerEscrow[key] {
+			report.Discrepancies = append(report.Discrepancies, &PlayerDiscrepancy{
+				PlayerID:      w.PlayerID,
+				Currency:      w.Currency,
+				WalletBalance: w.Balance,
+				LedgerWallet:  ledgerWallets[key],
+				LockedBalance: w.LockedBalance,
+				LedgerEscrow:  ledgerEscrow[key],
+			})
+		}
+	}
+
+	// 账本中有余额但钱包已不存在的玩家
+	for _, key := range ledgerOnlyPlayers(ledgerWallets, ledgerEscrow, seen) {
+		report.Discrepancies = append(report.Discrepancies, &PlayerDiscrepancy{
+			PlayerID:     key.playerID,
+			Currency:     key.currency,
+			LedgerWallet: ledgerWallets[key],
+			LedgerEscrow: ledgerEscrow[key],
+		})
+	}
+
+	for _, c := range totals {
+		report.Currencies = append(report.Currencies, c)
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool {
+		return report.Currencies[i].Currency < report.Currencies[j].Currency
+	})
+
+	if !report.Balanced() {
+		s.logger.Warn("ledger reconciliation found discrepancies",
+			zap.Int("unbalanced_journals", len(report.UnbalancedJournals)),
+			zap.Int("player_discrepancies", len(report.Discrepancies)),
+		)
+	}
+
+	return report, nil
+}
+
+// ledgerOnlyPlayers 回传账本余额非 0 但没有钱包记录的玩家账户
+func ledgerOnlyPlayers(ledgerWallets, ledgerEscrow map[playerCurrency]int64, seen map[playerCurrency]bool) []playerCurrency {
+	var keys []playerCurrency
+	add := func(balances map[playerCurrency]int64) {
+		for key, balance := range balances {
+			if balance != 0 && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	add(ledgerWallets)
+	add(ledgerEscrow)
+	return keys
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// stubLedgerRepo 以内存记录分录，按帐户汇总
+type stubLedgerRepo struct {
+	entries map[string]*repository.LedgerAccountBalance
+	journal []uuid.UUID
+}
+
+func newStubLedgerRepo() *stubLedgerRepo {
+	return &stubLedgerRepo{entries: make(map[string]*repository.LedgerAccountBalance)}
+}
+
+func (r *stubLedgerRepo) Transfer(ctx context.Context, tx repository.Transaction, from, to repository.LedgerAccount, amount int64, referenceID, description string) error {
+	r.account(from).Decreases += amount
+	r.account(to).Increases += amount
+	return nil
+}
+
+func (r *stubLedgerRepo) account(a repository.LedgerAccount) *repository.LedgerAccountBalance {
+	b, ok := r.entries[a.String()]
+	if !ok {
+		b = &repository.LedgerAccountBalance{Account: a}
+		r.entries[a.String()] = b
+	}
+	return b
+}
+
+func (r *stubLedgerRepo) GetAccountBalances(ctx context.Context) ([]*repository.LedgerAccountBalance, error) {
+	list := make([]*repository.LedgerAccountBalance, 0, len(r.entries))
+	for _, b := range r.entries {
+		list = append(list, b)
+	}
+	return list, nil
+}
+
+func (r *stubLedgerRepo) GetUnbalancedJournals(ctx context.Context) ([]uuid.UUID, error) {
+	return r.journal, nil
+}
+
+type listWalletRepo struct {
+	repository.WalletRepository
+	wallets []*repository.Wallet
+}
+
+func (r *listWalletRepo) ListAll(ctx context.Context) ([]*repository.Wallet, error) {
+	return r.wallets, nil
+}
+
+// seedLedger 两位玩家：各存入 5000，A 买入 2000 赢 400（抽水 20），B 买入 1000 输 420 后兑现
+func seedLedger(t *testing.T, a, b uuid.UUID) *stubLedgerRepo {
+	t.Helper()
+	ctx := context.Background()
+	ledger := newStubLedgerRepo()
+	external := repository.HouseAccount(repository.LedgerExternal, "USD")
+	pool := repository.HouseAccount(repository.LedgerGamePool, "USD")
+
+	transfers := []struct {
+		from, to repository.LedgerAccount
+		amount   int64
+	}{
+		{external, repository.PlayerWalletAccount(a, "USD"), 5000},
+		{external, repository.PlayerWalletAccount(b, "USD"), 5000},
+		{repository.HouseAccount(repository.LedgerBonusLiability, "USD"), repository.PlayerWalletAccount(a, "USD"), 100},
+		{repository.PlayerWalletAccount(a, "USD"), repository.TableEscrowAccount(a, "USD"), 2000},
+		{repository.PlayerWalletAccount(b, "USD"), repository.TableEscrowAccount(b, "USD"), 1000},
+		{repository.TableEscrowAccount(b, "USD"), pool, 420},
+		{pool, repository.TableEscrowAccount(a, "USD"), 400},
+		{pool, repository.HouseAccount(repository.LedgerHouseRake, "USD"), 20},
+		{repository.TableEscrowAccount(b, "USD"), repository.PlayerWalletAccount(b, "USD"), 580},
+		{repository.PlayerWalletAccount(b, "USD"), external, 1000},
+	}
+	for _, tr := range transfers {
+		if err := ledger.Transfer(ctx, nil, tr.from, tr.to, tr.amount, "", ""); err != nil {
+			t.Fatalf("Transfer failed: %v", err)
+		}
+	}
+	return ledger
+}
+
+func TestLedgerService_Reconcile_Balanced(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ledger := seedLedger(t, a, b)
+	wallets := &listWalletRepo{wallets: []*repository.Wallet{
+		{PlayerID: a, Currency: "USD", Balance: 3100, LockedBalance: 2400},
+		{PlayerID: b, Currency: "USD", Balance: 3580, LockedBalance: 0},
+	}}
+
+	report, err := NewLedgerService(ledger, wallets, zap.NewNop()).Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !report.Balanced() {
+		t.Fatalf("expected balanced report, got %+v (discrepancies %d)", report, len(report.Discrepancies))
+	}
+	if len(report.Currencies) != 1 {
+		t.Fatalf("expected 1 currency, got %d", len(report.Currencies))
+	}
+	usd := report.Currencies[0]
+	if usd.Currency != "USD" || usd.Deposits != 10000 || usd.Withdrawals != 1000 || usd.Bonuses != 100 || usd.HouseRake != 20 || usd.GamePool != 0 {
+		t.Errorf("unexpected totals: %+v", usd)
+	}
+	if usd.Actual() != 9100 {
+		t.Errorf("expected actual 9100, got %d", usd.Actual())
+	}
+}
+
+func TestLedgerService_Reconcile_ReportsPlayerDiscrepancy(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ledger := seedLedger(t, a, b)
+	// B 的钱包被直接改动了 50，没有对应的分录
+	wallets := &listWalletRepo{wallets: []*repository.Wallet{
+		{PlayerID: a, Currency: "USD", Balance: 3100, LockedBalance: 2400},
+		{PlayerID: b, Currency: "USD", Balance: 3630, LockedBalance: 0},
+	}}
+
+	report, err := NewLedgerService(ledger, wallets, zap.NewNop()).Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if report.Balanced() {
+		t.Fatal("expected unbalanced report")
+	}
+	usd := report.Currencies[0]
+	if usd.Actual()-usd.Expected() != 50 {
+		t.Errorf("expected a 50 difference, got actual=%d expected=%d", usd.Actual(), usd.Expected())
+	}
+	if len(report.Discrepancies) != 1 {
+		t.Fatalf("expected 1 discrepancy, got %d", len(report.Discrepancies))
+	}
+	d := report.Discrepancies[0]
+	if d.PlayerID != b || d.Currency != "USD" || d.WalletBalance != 3630 || d.LedgerWallet != 3580 {
+		t.Errorf("unexpected discrepancy: %+v", d)
+	}
+}
+
+func TestLedgerService_Reconcile_SeparatesCurrencies(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ledger := seedLedger(t, a, b)
+	// A 另有一个游戏币钱包，由奖金发放 10000，不能与 USD 混算
+	play := repository.PlayerWalletAccount(a, "PLAY")
+	if err := ledger.Transfer(context.Background(), nil, repository.HouseAccount(repository.LedgerBonusLiability, "PLAY"), play, 10000, "", ""); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	wallets := &listWalletRepo{wallets: []*repository.Wallet{
+		{PlayerID: a, Currency: "USD", Balance: 3100, LockedBalance: 2400},
+		{PlayerID: a, Currency: "PLAY", Balance: 10000},
+		{PlayerID: b, Currency: "USD", Balance: 3580, LockedBalance: 0},
+	}}
+
+	report, err := NewLedgerService(ledger, wallets, zap.NewNop()).Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !report.Balanced() {
+		t.Fatalf("expected balanced report, discrepancies %d", len(report.Discrepancies))
+	}
+	if len(report.Currencies) != 2 || report.Currencies[0].Currency != "PLAY" || report.Currencies[1].Currency != "USD" {
+		t.Fatalf("unexpected currencies: %+v", report.Currencies)
+	}
+	if p := report.Currencies[0]; p.Bonuses != 10000 || p.TotalWallets != 10000 {
+		t.Errorf("unexpected PLAY totals: %+v", p)
+	}
+	if usd := report.Currencies[1]; usd.Actual() != 9100 {
+		t.Errorf("expected USD actual 9100, got %d", usd.Actual())
+	}
+}
+
+func TestLedgerService_Reconcile_ReportsStrandedGamePool(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ledger := seedLedger(t, a, b)
+	// A 输给机器人 30：分录与钱包一致、恒等式成立，但资金滞留在牌局清算账户
+	pool := repository.HouseAccount(repository.LedgerGamePool, "USD")
+	if err := ledger.Transfer(context.Background(), nil, repository.TableEscrowAccount(a, "USD"), pool, 30, "", ""); err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	wallets := &listWalletRepo{wallets: []*repository.Wallet{
+		{PlayerID: a, Currency: "USD", Balance: 3100, LockedBalance: 2370},
+		{PlayerID: b, Currency: "USD", Balance: 3580, LockedBalance: 0},
+	}}
+
+	ledgerService := NewLedgerService(ledger, wallets, zap.NewNop())
+	report, err := ledgerService.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	usd := report.Currencies[0]
+	if usd.GamePool != 30 || usd.Actual() != usd.Expected() || len(report.Discrepancies) != 0 {
+		t.Fatalf("expected only a stranded game pool of 30, got %+v (discrepancies %d)", usd, len(report.Discrepancies))
+	}
+	if !usd.GamePoolStranded() || report.Balanced() {
+		t.Error("expected stranded game pool to unbalance the report")
+	}
+
+	ledgerService.SetGamePoolTolerance(50)
+	report, err = ledgerService.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if !report.Balanced() {
+		t.Error("expected game pool within tolerance to be balanced")
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidRakebackPeriod 返水统计期间无效
+var ErrInvalidRakebackPeriod = errors.New("invalid rakeback period")
+
+// Rakeback 玩家在期间内的返水计算结果
+type Rakeback struct {
+	PlayerID uuid.UUID
+	Currency string
+	From     time.Time
+	To       time.Time
+	Hands    int     // 有分摊抽水的手牌数
+	RakePaid int64   // 分摊的抽水总额
+	Percent  float64 // 返水比例
+	Amount   int64   // 应返金额（向下取整）
+}
+
+// RakeService 抽水服务：记录每手牌的抽水收入并计算玩家返水
+type RakeService struct {
+	rakeRepo        repository.RakeRepository
+	uow             repository.UnitOfWork
+	rakebackPercent float64
+	defaultCurrency string
+	logger          *zap.Logger
+}
+
+// NewRakeService 创建抽水服务
+func NewRakeService(
+	rakeRepo repository.RakeRepository,
+	uow repository.UnitOfWork,
+	logger *zap.Logger,
+) *RakeService {
+	return &RakeService{
+		rakeRepo:        rakeRepo,
+		uow:             uow,
+		defaultCurrency: "USD",
+		logger:          logger,
+	}
+}
+
+// SetDefaultCurrency 设定默认币别（查询未指定币别时使用）
+func (s *RakeService) SetDefaultCurrency(currency string) {
+	s.defaultCurrency = currency
+}
+
+// SetRakebackPercent 设定返水比例（0.2 = 返还分摊抽水的 20%）
+func (s *RakeService) SetRakebackPercent(percent float64) {
+	s.rakebackPercent = percent
+}
+
+// RecordRake 将一手牌的抽水记入平台收入账（牌桌币别），并按投入比例记录各玩家的分摊
+func (s *RakeService) RecordRake(ctx context.Context, record *domain.HandRecord, currency string) error {
+	if record.Rake <= 0 {
+		return nil
+	}
+
+	handID, err := uuid.Parse(record.HandID)
+	if err != nil {
+		return fmt.Errorf("invalid hand id %q: %w", record.HandID, err)
+	}
+
+	revenue := &repository.HouseRevenue{
+		HandID:   handID,
+		TableID:  record.TableID,
+		Currency: s.currencyOrDefault(currency),
+		Amount:   record.Rake,
+	}
+
+	contributions := make([]*repository.RakeContribution, 0, len(record.Players))
+	for playerID, amount := range record.RakeContributions() {
+		pid, err := uuid.Parse(playerID)
+		if err != nil || amount <= 0 {
+			continue // 非正式玩家不计返水
+		}
+		contributions = append(contributions, &repository.RakeContribution{
+			PlayerID: pid,
+			Amount:   amount,
+		})
+	}
+
+	err = s.uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		return s.rakeRepo.Record(ctx, tx, revenue, contributions)
+	})
+	if err != nil {
+		s.logger.Error("failed to record rake",
+			zap.String("hand_id", record.HandID),
+			zap.String("table_id", record.TableID),
+			zap.Int64("rake", record.Rake),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Debug("rake recorded",
+		zap.String("hand_id", record.HandID),
+		zap.Int64("rake", record.Rake),
+	)
+
+	return nil
+}
+
+// CalculateRakeback 计算玩家在 [from, to) 期间于指定币别牌桌的返水（空值使用默认币别）
+func (s *RakeService) CalculateRakeback(ctx context.Context, playerID uuid.UUID, currency string, from, to time.Time) (*Rakeback, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidRakebackPeriod
+	}
+
+	currency = s.currencyOrDefault(currency)
+	summary, err := s.rakeRepo.GetPlayerSummary(ctx, playerID, currency, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rake summary: %w", err)
+	}
+
+	return &Rakeback{
+		PlayerID: playerID,
+		Currency: currency,
+		From:     from,
+		To:       to,
+		Hands:    summary.Hands,
+		RakePaid: summary.TotalRake,
+		Percent:  s.rakebackPercent,
+		Amount:   int64(math.Floor(float64(summary.TotalRake)*s.rakebackPercent + 1e-9)),
+	}, nil
+}
+
+// GetRevenue 统计 [from, to) 期间指定币别的平台抽水收入（空值使用默认币别）
+func (s *RakeService) GetRevenue(ctx context.Context, currency string, from, to time.Time) (int64, error) {
+	if !from.Before(to) {
+		return 0, ErrInvalidRakebackPeriod
+	}
+	return s.rakeRepo.GetRevenueTotal(ctx, s.currencyOrDefault(currency), from, to)
+}
+
+// DefaultCurrency 回传默认币别
+func (s *RakeService) DefaultCurrency() string {
+	return s.defaultCurrency
+}
+
+// currencyOrDefault 回传币别，为空时使用默认币别
+func (s *RakeService) currencyOrDefault(currency string) string {
+	if currency == "" {
+		return s.defaultCurrency
+	}
+	return currency
+}
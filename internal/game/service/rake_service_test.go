@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type stubRakeRepo struct {
+	revenues      []*repository.HouseRevenue
+	contributions []*repository.RakeContribution
+}
+
+func (r *stubRakeRepo) Record(ctx context.Context, tx repository.Transaction, revenue *repository.HouseRevenue, contributions []*repository.RakeContribution) error {
+	r.revenues = append(r.revenues, revenue)
+	for _, c := range contributions {
+		c.HandID = revenue.HandID
+	}
+	r.contributions = append(r.contributions, contributions...)
+	return nil
+}
+
+func (r *stubRakeRepo) GetPlayerSummary(ctx context.Context, playerID uuid.UUID, currency string, from, to time.Time) (*repository.RakeSummary, error) {
+	handCurrency := make(map[uuid.UUID]string, len(r.revenues))
+	for _, rev := range r.revenues {
+		handCurrency[rev.HandID] = rev.Currency
+	}
+	summary := &repository.RakeSummary{PlayerID: playerID}
+	for _, c := range r.contributions {
+		if c.PlayerID == playerID && handCurrency[c.HandID] == currency {
+			summary.Hands++
+			summary.TotalRake += c.Amount
+		}
+	}
+	return summary, nil
+}
+
+func (r *stubRakeRepo) GetRevenueTotal(ctx context.Context, currency string, from, to time.Time) (int64, error) {
+	var total int64
+	for _, rev := range r.revenues {
+		if rev.Currency == currency {
+			total += rev.Amount
+		}
+	}
+	return total, nil
+}
+
+func TestRakeService_RecordAndRakeback(t *testing.T) {
+	repo := &stubRakeRepo{}
+	svc := NewRakeService(repo, stubUnitOfWork{}, zap.NewNop())
+	svc.SetRakebackPercent(0.25)
+
+	winner, loser := uuid.New(), uuid.New()
+	record := &domain.HandRecord{
+		HandID:  uuid.New().String(),
+		TableID: "table-1",
+		Rake:    12,
+		Players: []*domain.HandPlayerRecord{
+			{PlayerID: winner.String(), StartingChips: 1000, EndingChips: 1108},
+			{PlayerID: loser.String(), StartingChips: 1000, EndingChips: 880},
+		},
+		Winners: []domain.HandWinnerRecord{{PlayerID: winner.String(), Amount: 228}},
+	}
+
+	if err := svc.RecordRake(context.Background(), record, ""); err != nil {
+		t.Fatalf("RecordRake failed: %v", err)
+	}
+	if len(repo.revenues) != 1 || repo.revenues[0].Amount != 12 || repo.revenues[0].Currency != "USD" || repo.revenues[0].HandID.String() != record.HandID {
+		t.Fatalf("unexpected revenue: %+v", repo.revenues)
+	}
+	if len(repo.contributions) != 2 {
+		t.Fatalf("expected 2 contributions, got %d", len(repo.contributions))
+	}
+
+	// 无抽水的手牌不写入
+	if err := svc.RecordRake(context.Background(), &domain.HandRecord{HandID: uuid.New().String()}, ""); err != nil {
+		t.Fatalf("RecordRake without rake failed: %v", err)
+	}
+	if len(repo.revenues) != 1 {
+		t.Errorf("hand without rake should not be recorded, got %d revenues", len(repo.revenues))
+	}
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	rakeback, err := svc.CalculateRakeback(context.Background(), loser, "", from, to)
+	if err != nil {
+		t.Fatalf("CalculateRakeback failed: %v", err)
+	}
+	// 双方各投入 120，分摊 6；返水 25% 向下取整
+	if rakeback.Hands != 1 || rakeback.RakePaid != 6 || rakeback.Amount != 1 || rakeback.Currency != "USD" {
+		t.Errorf("unexpected rakeback: %+v", rakeback)
+	}
+
+	// 游戏币桌的抽水不计入 USD 返水与营收
+	playRecord := *record
+	playRecord.HandID = uuid.New().String()
+	if err := svc.RecordRake(context.Background(), &playRecord, "PLAY"); err != nil {
+		t.Fatalf("RecordRake failed: %v", err)
+	}
+	if revenue, _ := svc.GetRevenue(context.Background(), "USD", from, to); revenue != 12 {
+		t.Errorf("expected USD revenue 12, got %d", revenue)
+	}
+	if rakeback, _ := svc.CalculateRakeback(context.Background(), loser, "PLAY", from, to); rakeback.Hands != 1 || rakeback.Currency != "PLAY" {
+		t.Errorf("unexpected PLAY rakeback: %+v", rakeback)
+	}
+
+	if _, err := svc.CalculateRakeback(context.Background(), loser, "USD", to, from); !errors.Is(err, ErrInvalidRakebackPeriod) {
+		t.Errorf("expected ErrInvalidRakebackPeriod, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+// AuditActionSessionReconciled 对账清理孤儿会话的审计动作
+const AuditActionSessionReconciled = "session_reconciled"
+
+// SeatLocator 查询玩家是否仍坐在牌桌上（由 TableManager 实现）
+// 牌桌无回应时返回错误，调用方不可据此兑现
+type SeatLocator interface {
+	IsPlayerSeated(tableID, playerID string) (bool, error)
+}
+
+// ReconcileResult 一次对账的结果
+type ReconcileResult struct {
+	Checked    int // 检查的活跃会话数
+	Reconciled int // 兑现的孤儿会话数
+	Failed     int // 兑现失败的会话数
+}
+
+// SessionReconciler 会话对账：找出没有对应牌桌座位的活跃会话，按最后同步的筹码兑现
+// 补足 SessionManager 只能清理内存中会话的缺口（例如服务器重启后遗留的会话）。
+type SessionReconciler struct {
+	sessionRepo repository.GameSessionRepository
+	gameService *GameService
+	auditRepo   repository.AuditLogRepository
+	seats       SeatLocator
+	gracePeriod time.Duration
+	logger      *zap.Logger
+}
+
+// NewSessionReconciler 创建会话对账服务
+func NewSessionReconciler(
+	sessionRepo repository.GameSessionRepository,
+	gameService *GameService,
+	auditRepo repository.AuditLogRepository,
+	logger *zap.Logger,
+) *SessionReconciler {
+	return &SessionReconciler{
+		sessionRepo: sessionRepo,
+		gameService: gameService,
+		auditRepo:   auditRepo,
+		gracePeriod: 5 * time.Minute,
+		logger:      logger,
+	}
+}
+
+// SetSeatLocator 设定座位查询（未设定时视所有会话都没有座位）
+func (r *SessionReconciler) SetSeatLocator(seats SeatLocator) {
+	r.seats = seats
+}
+
+// SetGracePeriod 设定宽限期：最近更新过的会话不清理（避免买入后尚未入座即被兑现）
+func (r *SessionReconciler) SetGracePeriod(d time.Duration) {
+	r.gracePeriod = d
+}
+
+// Run 定期执行对账，直到 ctx 取消
+func (r *SessionReconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := r.Reconcile(ctx); err != nil {
+				r.logger.Error("session reconciliation failed", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reconcile 执行一次对账
+func (r *SessionReconciler) Reconcile(ctx context.Context) (*ReconcileResult, error) {
+	sessions, err := r.sessionRepo.ListActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+
+	result := &ReconcileResult{Checked: len(sessions)}
+	cutoff := time.Now().Add(-r.gracePeriod)
+	for _, session := range sessions {
+		if session.UpdatedAt.After(cutoff) {
+			continue
+		}
+		if r.seats != nil {
+			seated, err := r.seats.IsPlayerSeated(session.TableID, session.PlayerID.String())
+			if err != nil {
+				// 无法确认座位时保守跳过，下一轮再对账
+				r.logger.Warn("seat lookup failed, skipping session",
+					zap.String("session_id", session.ID.String()),
+					zap.String("player_id", session.PlayerID.String()),
+					zap.String("table_id", session.TableID),
+					zap.Error(err),
+				)
+				continue
+			}
+			if seated {
+				continue
+			}
+		}
+
+		if err := r.reconcileSession(ctx, session); err != nil {
+			r.logger.Error("failed to reconcile orphaned session",
+				zap.String("session_id", session.ID.String()),
+				zap.String("player_id", session.PlayerID.String()),
+				zap.String("table_id", session.TableID),
+				zap.Int64("chips", session.CurrentChips),
+				zap.Error(err),
+			)
+			result.Failed++
+			continue
+		}
+		result.Reconciled++
+	}
+
+	if result.Reconciled > 0 || result.Failed > 0 {
+		r.logger.Info("session reconciliation completed",
+			zap.Int("checked", result.Checked),
+			zap.Int("reconciled", result.Reconciled),
+			zap.Int("failed", result.Failed),
+		)
+	}
+
+	return result, nil
+}
+
+// reconcileSession 按最后同步的筹码兑现孤儿会话并写入审计记录
+func (r *SessionReconciler) reconcileSession(ctx context.Context, session *repository.GameSession) error {
+	response, err := r.gameService.CashOut(ctx, CashOutRequest{
+		PlayerID:  session.PlayerID,
+		SessionID: session.ID,
+		Chips:     session.CurrentChips,
+	})
+	if err != nil {
+		return err
+	}
+
+	changes, err := json.Marshal(map[string]interface{}{
+		"before": map[string]interface{}{
+			"status":        "active",
+			"table_id":      session.TableID,
+			"buy_in_amount": session.BuyInAmount,
+			"current_chips": session.CurrentChips,
+		},
+		"after": map[string]interface{}{
+			"status":          "completed",
+			"cash_out_amount": response.CashOutAmount,
+			"profit":          response.Profit,
+			"wallet_balance":  response.WalletBalance,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	// 兑现已完成，审计写入失败只记录日志，不视为对账失败
+	err = r.auditRepo.Create(ctx, &repository.AuditLog{
+		EntityType: "game_session",
+		EntityID:   session.ID,
+		Action:     AuditActionSessionReconciled,
+		Changes:    changes,
+		ActorType:  repository.AuditActorSystem,
+	})
+	if err != nil {
+		r.logger.Error("failed to write reconciliation audit log",
+			zap.String("session_id", session.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	r.logger.Info("orphaned session cashed out",
+		zap.String("session_id", session.ID.String()),
+		zap.String("player_id", session.PlayerID.String()),
+		zap.String("table_id", session.TableID),
+		zap.Int64("chips", session.CurrentChips),
+	)
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type stubAuditLogRepo struct {
+	logs []*repository.AuditLog
+}
+
+func (r *stubAuditLogRepo) Create(ctx context.Context, log *repository.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *stubAuditLogRepo) CreateWithTx(ctx context.Context, tx repository.Transaction, log *repository.AuditLog) error {
+	return r.Create(ctx, log)
+}
+
+func (r *stubAuditLogRepo) List(ctx context.Context, filter repository.AuditLogFilter) ([]*repository.AuditLog, int64, error) {
+	var matched []*repository.AuditLog
+	for _, log := range r.logs {
+		if (filter.EntityType == "" || log.EntityType == filter.EntityType) &&
+			(filter.Action == "" || log.Action == filter.Action) {
+			matched = append(matched, log)
+		}
+	}
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return nil, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+type stubSeatLocator map[string]bool // playerID -> 是否在座
+
+func (s stubSeatLocator) IsPlayerSeated(tableID, playerID string) (bool, error) {
+	return s[playerID], nil
+}
+
+// unreachableSeatLocator 模拟牌桌无回应
+type unreachableSeatLocator struct{}
+
+func (unreachableSeatLocator) IsPlayerSeated(tableID, playerID string) (bool, error) {
+	return false, errors.New("table did not respond")
+}
+
+func TestSessionReconciler_CashesOutOrphanedSessions(t *testing.T) {
+	ctx := context.Background()
+	orphan, seated, recent := uuid.New(), uuid.New(), uuid.New()
+	walletRepo := newEscrowWalletRepo(orphan, 0)
+	walletRepo.wallet.LockedBalance = 2000
+	stale := time.Now().Add(-time.Hour)
+
+	orphanSession := &repository.GameSession{ID: uuid.New(), PlayerID: orphan, TableID: "t1", BuyInAmount: 2000, CurrentChips: 2400, Status: "active", UpdatedAt: stale}
+	sessionRepo := &escrowSessionRepo{sessions: map[uuid.UUID]*repository.GameSession{
+		orphanSession.ID: orphanSession,
+		uuid.New():       {PlayerID: seated, TableID: "t1", BuyInAmount: 1000, CurrentChips: 1000, Status: "active", UpdatedAt: stale},
+		uuid.New():       {PlayerID: recent, TableID: "t2", BuyInAmount: 1000, CurrentChips: 1000, Status: "active", UpdatedAt: time.Now()},
+	}}
+	gameService := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+	auditRepo := &stubAuditLogRepo{}
+
+	reconciler := NewSessionReconciler(sessionRepo, gameService, auditRepo, zap.NewNop())
+	reconciler.SetSeatLocator(stubSeatLocator{seated.String(): true})
+
+	result, err := reconciler.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if result.Checked != 3 || result.Reconciled != 1 || result.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if orphanSession.Status != "completed" {
+		t.Errorf("expected orphaned session to be completed, got %s", orphanSession.Status)
+	}
+	// 以最后同步的 2400 兑现：未结算的 +400 补记后全数释放
+	if w := walletRepo.wallet; w.Balance != 2400 || w.LockedBalance != 0 {
+		t.Errorf("expected balance=2400 locked=0, got %+v", w)
+	}
+
+	if len(auditRepo.logs) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(auditRepo.logs))
+	}
+	log := auditRepo.logs[0]
+	if log.Action != AuditActionSessionReconciled || log.EntityID != orphanSession.ID || log.ActorType != repository.AuditActorSystem {
+		t.Errorf("unexpected audit log: %+v", log)
+	}
+	var changes struct {
+		Before map[string]interface{} `json:"before"`
+		After  map[string]interface{} `json:"after"`
+	}
+	if err := json.Unmarshal(log.Changes, &changes); err != nil {
+		t.Fatalf("invalid audit changes: %v", err)
+	}
+	if changes.Before["current_chips"] != float64(2400) || changes.After["cash_out_amount"] != float64(2400) {
+		t.Errorf("unexpected audit changes: %s", log.Changes)
+	}
+
+	// 再次对账不应重复兑现
+	if result, _ := reconciler.Reconcile(ctx); result.Reconciled != 0 {
+		t.Errorf("expected no further reconciliation, got %+v", result)
+	}
+}
+
+// TestSessionReconciler_SkipsWhenSeatLookupFails 牌桌无回应时不可视为离座而兑现
+func TestSessionReconciler_SkipsWhenSeatLookupFails(t *testing.T) {
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 0)
+	walletRepo.wallet.LockedBalance = 1000
+	session := &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "t1", BuyInAmount: 1000, CurrentChips: 1000, Status: "active", UpdatedAt: time.Now().Add(-time.Hour)}
+	sessionRepo := &escrowSessionRepo{sessions: map[uuid.UUID]*repository.GameSession{session.ID: session}}
+	gameService := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	reconciler := NewSessionReconciler(sessionRepo, gameService, &stubAuditLogRepo{}, zap.NewNop())
+	reconciler.SetSeatLocator(unreachableSeatLocator{})
+
+	result, err := reconciler.Reconcile(context.Background())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if result.Reconciled != 0 || session.Status != "active" {
+		t.Fatalf("expected session to stay active, got %+v status=%s", result, session.Status)
+	}
+}
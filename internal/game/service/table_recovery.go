@@ -0,0 +1,452 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidRecoveryPolicy 未知的崩溃复原策略
+	ErrInvalidRecoveryPolicy = errors.New("invalid recovery policy")
+	// ErrRecoveryQueueFull 写入队列已满，写入被丢弃
+	ErrRecoveryQueueFull = errors.New("recovery queue full")
+)
+
+// RecoveryPolicy 服务器重启后如何处理中断的牌桌
+// 两种策略都会作废进行中的手牌，玩家筹码回到该手开始前（即退还本手已投入的筹码）。
+type RecoveryPolicy string
+
+const (
+	// RecoveryPolicyRestore 按快照重建牌桌，玩家保留座位与筹码，会话保持活跃
+	RecoveryPolicyRestore RecoveryPolicy = "restore"
+	// RecoveryPolicyRefund 不重建牌桌，按快照筹码为每位玩家兑现并结束会话
+	RecoveryPolicyRefund RecoveryPolicy = "refund"
+)
+
+// ParseRecoveryPolicy 解析复原策略（空字符串默认为 restore）
+func ParseRecoveryPolicy(s string) (RecoveryPolicy, error) {
+	switch RecoveryPolicy(s) {
+	case "", RecoveryPolicyRestore:
+		return RecoveryPolicyRestore, nil
+	case RecoveryPolicyRefund:
+		return RecoveryPolicyRefund, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidRecoveryPolicy, s)
+	}
+}
+
+// RecoveredTable 单张牌桌的复原结果
+type RecoveredTable struct {
+	Snapshot     *domain.TableSnapshot
+	Restore      bool             // 是否需要由 TableManager 重建
+	VoidedHandID string           // 被作废的进行中手牌（空表示停在手牌边界）
+	Refunds      map[string]int64 // 作废手牌中各玩家已投入、已退还的筹码（依动作日志）
+	CashedOut    int              // refund 策略下兑现的会话数
+	LeaverRefund int64            // 退还给已离桌玩家的筹码合计
+}
+
+// RecoveryReport 启动复原结果
+type RecoveryReport struct {
+	Policy RecoveryPolicy
+	Tables []*RecoveredTable
+}
+
+const (
+	recoveryQueueSize    = 1024
+	recoveryWriteTimeout = 5 * time.Second
+)
+
+// RecoveryService 崩溃复原服务
+// 运行期间异步保存牌桌快照与进行中手牌的动作日志；启动时依策略处理中断的牌桌。
+type RecoveryService struct {
+	recoveryRepo repository.TableRecoveryRepository
+	gameService  *GameService
+	policy       RecoveryPolicy
+	logger       *zap.Logger
+
+	// 单一写入协程，保证同一手牌的动作日志与收尾删除按顺序执行
+	queue  chan func(ctx context.Context) error
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewRecoveryService 创建崩溃复原服务
+func NewRecoveryService(
+	recoveryRepo repository.TableRecoveryRepository,
+	gameService *GameService,
+	logger *zap.Logger,
+) *RecoveryService {
+	return &RecoveryService{
+		recoveryRepo: recoveryRepo,
+		gameService:  gameService,
+		policy:       RecoveryPolicyRestore,
+		logger:       logger,
+		queue:        make(chan func(ctx context.Context) error, recoveryQueueSize),
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+// SetPolicy 设定复原策略
+func (s *RecoveryService) SetPolicy(policy RecoveryPolicy) {
+	s.policy = policy
+}
+
+// Policy 返回复原策略
+func (s *RecoveryService) Policy() RecoveryPolicy {
+	return s.policy
+}
+
+// Start 启动异步写入协程
+func (s *RecoveryService) Start() {
+	go s.writeLoop()
+}
+
+// Stop 停止写入协程，尽量写完已排队的快照与日志
+func (s *RecoveryService) Stop(ctx context.Context) {
+	close(s.stopCh)
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		s.logger.Warn("recovery writer did not drain before shutdown", zap.Int("pending", len(s.queue)))
+	}
+}
+
+// SaveSnapshot 异步保存牌桌快照（无玩家时删除快照）
+func (s *RecoveryService) SaveSnapshot(snapshot *domain.TableSnapshot) {
+	s.enqueue(func(ctx context.Context) error {
+		return s.saveSnapshot(ctx, snapshot)
+	})
+}
+
+// LogAction 异步写入进行中手牌的动作
+func (s *RecoveryService) LogAction(tableID, handID string, action domain.HandActionRecord) {
+	s.enqueue(func(ctx context.Context) error {
+		hid, err := uuid.Parse(handID)
+		if err != nil {
+			return fmt.Errorf("invalid hand id %q: %w", handID, err)
+		}
+		return s.recoveryRepo.AppendAction(ctx, &repository.TableActionLog{
+			TableID:   tableID,
+			HandID:    hid,
+			PlayerID:  action.PlayerID,
+			Street:    action.Street,
+			Action:    action.Action,
+			Amount:    action.Amount,
+			TotalBet:  action.TotalBet,
+			CreatedAt: action.Timestamp,
+		})
+	})
+}
+
+// CompleteHand 手牌完成：保存手牌边界快照并删除该手的动作日志
+// 返回的 channel 在快照写入后收到结果；手牌结算须等快照落盘后再提交，
+// 否则崩溃重启会以开局前的筹码重建牌桌，而账本已记入该手输赢。
+func (s *RecoveryService) CompleteHand(snapshot *domain.TableSnapshot, handID string) <-chan error {
+	saved := make(chan error, 1)
+	queued := s.enqueue(func(ctx context.Context) error {
+		err := s.saveSnapshot(ctx, snapshot)
+		saved <- err
+		if err != nil {
+			return err
+		}
+		hid, err := uuid.Parse(handID)
+		if err != nil {
+			return fmt.Errorf("invalid hand id %q: %w", handID, err)
+		}
+		return s.recoveryRepo.DeleteActionsByHand(ctx, hid)
+	})
+	if !queued {
+		saved <- ErrRecoveryQueueFull
+	}
+	return saved
+}
+
+// Recover 启动时处理上次运行遗留的牌桌快照
+// restore：返回需要重建的牌桌；refund：按快照筹码兑现每位玩家的活跃会话并删除快照。
+// 两种策略都会清除被作废手牌的动作日志，并退还已离桌玩家留在底池的筹码。应在建表与启动写入协程之前调用。
+func (s *RecoveryService) Recover(ctx context.Context) (*RecoveryReport, error) {
+	records, err := s.recoveryRepo.ListSnapshots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list table snapshots: %w", err)
+	}
+
+	report := &RecoveryReport{Policy: s.policy}
+	for _, record := range records {
+		snapshot, err := toDomainSnapshot(record)
+		if err != nil {
+			s.logger.Error("skipping unreadable table snapshot",
+				zap.String("table_id", record.TableID), zap.Error(err))
+			continue
+		}
+		if len(snapshot.Players) == 0 && len(snapshot.Leavers) == 0 {
+			if err := s.recoveryRepo.DeleteSnapshot(ctx, snapshot.TableID); err != nil {
+				s.logger.Warn("failed to delete empty table snapshot",
+					zap.String("table_id", snapshot.TableID), zap.Error(err))
+			}
+			continue
+		}
+
+		table := &RecoveredTable{Snapshot: snapshot}
+		leaversRefunded := true
+		if snapshot.HandInProgress && record.HandID != nil {
+			table.VoidedHandID = snapshot.HandID
+			table.Refunds, err = s.handContributions(ctx, *record.HandID)
+			if err != nil {
+				return nil, err
+			}
+			s.logger.Warn("voiding hand interrupted by restart",
+				zap.String("table_id", snapshot.TableID),
+				zap.String("hand_id", snapshot.HandID),
+				zap.Any("refunds", table.Refunds),
+			)
+			leaversRefunded = s.refundLeavers(ctx, table)
+		}
+
+		switch s.policy {
+		case RecoveryPolicyRefund:
+			if !s.refundTable(ctx, table) || !leaversRefunded {
+				// 保留快照与日志，下次启动重试
+				report.Tables = append(report.Tables, table)
+				continue
+			}
+			if err := s.recoveryRepo.DeleteSnapshot(ctx, snapshot.TableID); err != nil {
+				s.logger.Warn("failed to delete refunded table snapshot",
+					zap.String("table_id", snapshot.TableID), zap.Error(err))
+			}
+		default:
+			// 只剩离桌玩家的牌桌无需重建；退款失败时保留快照，下次启动重试
+			table.Restore = len(snapshot.Players) > 0
+			if !table.Restore && leaversRefunded {
+				if err := s.recoveryRepo.DeleteSnapshot(ctx, snapshot.TableID); err != nil {
+					s.logger.Warn("failed to delete empty table snapshot",
+						zap.String("table_id", snapshot.TableID), zap.Error(err))
+				}
+			}
+		}
+
+		if record.HandID != nil {
+			if err := s.recoveryRepo.DeleteActionsByHand(ctx, *record.HandID); err != nil {
+				s.logger.Warn("failed to delete voided hand actions",
+					zap.String("hand_id", snapshot.HandID), zap.Error(err))
+			}
+		}
+		report.Tables = append(report.Tables, table)
+	}
+
+	s.logger.Info("table recovery completed",
+		zap.String("policy", string(s.policy)),
+		zap.Int("tables", len(report.Tables)),
+	)
+
+	return report, nil
+}
+
+// refundTable 按快照筹码兑现牌桌上每位玩家的活跃会话，全部成功时返回 true
+func (s *RecoveryService) refundTable(ctx context.Context, table *RecoveredTable) bool {
+	ok := true
+	for _, p := range table.Snapshot.Players {
+		playerID, err := uuid.Parse(p.PlayerID)
+		if err != nil {
+			continue // 非正式玩家没有资金会话
+		}
+
+		session, err := s.gameService.GetActiveSession(ctx, playerID)
+		if err != nil || session == nil || session.TableID != table.Snapshot.TableID {
+			continue // 已兑现或不在这张桌
+		}
+
+		_, err = s.gameService.CashOut(ctx, CashOutRequest{
+			PlayerID:  playerID,
+			SessionID: session.ID,
+			Chips:     p.Chips,
+		})
+		if err != nil {
+			s.logger.Error("failed to refund player after restart",
+				zap.String("table_id", table.Snapshot.TableID),
+				zap.String("player_id", p.PlayerID),
+				zap.Int64("chips", p.Chips),
+				zap.Error(err),
+			)
+			ok = false
+			continue
+		}
+		table.CashedOut++
+	}
+	return ok
+}
+
+// refundLeavers 退还作废手牌中已离桌玩家留在底池的筹码，全部成功时返回 true
+func (s *RecoveryService) refundLeavers(ctx context.Context, table *RecoveredTable) bool {
+	ok := true
+	for _, l := range table.Snapshot.Leavers {
+		refund, err := s.gameService.RefundVoidedHand(ctx, table.Snapshot.TableID, table.VoidedHandID, l)
+		if err != nil {
+			s.logger.Error("failed to refund leaver of voided hand",
+				zap.String("table_id", table.Snapshot.TableID),
+				zap.String("player_id", l.PlayerID),
+				zap.Int64("contribution", l.Contribution),
+				zap.Error(err),
+			)
+			ok = false
+			continue
+		}
+		table.LeaverRefund += refund
+	}
+	return ok
+}
+
+// handContributions 依动作日志加总手牌中各玩家已投入的筹码
+func (s *RecoveryService) handContributions(ctx context.Context, handID uuid.UUID) (map[string]int64, error) {
+	entries, err := s.recoveryRepo.GetActionsByHand(ctx, handID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hand actions: %w", err)
+	}
+
+	contributions := make(map[string]int64)
+	for _, e := range entries {
+		if e.Amount > 0 {
+			contributions[e.PlayerID] += e.Amount
+		}
+	}
+	return contributions, nil
+}
+
+func (s *RecoveryService) saveSnapshot(ctx context.Context, snapshot *domain.TableSnapshot) error {
+	if len(snapshot.Players) == 0 && len(snapshot.Leavers) == 0 {
+		return s.recoveryRepo.DeleteSnapshot(ctx, snapshot.TableID)
+	}
+	record, err := toRepoSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.recoveryRepo.SaveSnapshot(ctx, record)
+}
+
+// enqueue 将写入排入队列；队列已满时丢弃（不阻塞牌桌协程），下一次快照会覆盖
+func (s *RecoveryService) enqueue(job func(ctx context.Context) error) bool {
+	select {
+	case s.queue <- job:
+		return true
+	default:
+		s.logger.Warn("recovery queue full, dropping write")
+		return false
+	}
+}
+
+func (s *RecoveryService) writeLoop() {
+	defer close(s.doneCh)
+	for {
+		select {
+		case job := <-s.queue:
+			s.runJob(job)
+		case <-s.stopCh:
+			for {
+				select {
+				case job := <-s.queue:
+					s.runJob(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *RecoveryService) runJob(job func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), recoveryWriteTimeout)
+	defer cancel()
+	if err := job(ctx); err != nil {
+		s.logger.Error("failed to write recovery data", zap.Error(err))
+	}
+}
+
+// toRepoSnapshot 将 domain.TableSnapshot 转换为持久化模型
+func toRepoSnapshot(snapshot *domain.TableSnapshot) (*repository.TableSnapshot, error) {
+	players := make([]repository.TableSnapshotPlayer, 0, len(snapshot.Players)+len(snapshot.Leavers))
+	for _, p := range snapshot.Players {
+		players = append(players, repository.TableSnapshotPlayer{
+			PlayerID:  p.PlayerID,
+			SessionID: p.SessionID,
+			Seat:      p.SeatIdx,
+			Chips:     p.Chips,
+		})
+	}
+	for _, l := range snapshot.Leavers {
+		players = append(players, repository.TableSnapshotPlayer{
+			PlayerID:     l.PlayerID,
+			SessionID:    l.SessionID,
+			Seat:         -1,
+			Chips:        l.Chips,
+			Left:         true,
+			Contribution: l.Contribution,
+		})
+	}
+	data, err := json.Marshal(players)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot players: %w", err)
+	}
+
+	record := &repository.TableSnapshot{
+		TableID:        snapshot.TableID,
+		HandNumber:     snapshot.HandNumber,
+		DealerPos:      snapshot.DealerPos,
+		HandInProgress: snapshot.HandInProgress,
+		Players:        data,
+		TakenAt:        snapshot.TakenAt,
+	}
+	if snapshot.HandInProgress {
+		handID, err := uuid.Parse(snapshot.HandID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hand id %q: %w", snapshot.HandID, err)
+		}
+		record.HandID = &handID
+	}
+	return record, nil
+}
+
+// toDomainSnapshot 将持久化模型转换回 domain.TableSnapshot
+func toDomainSnapshot(record *repository.TableSnapshot) (*domain.TableSnapshot, error) {
+	var players []repository.TableSnapshotPlayer
+	if err := json.Unmarshal(record.Players, &players); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot players: %w", err)
+	}
+
+	snapshot := &domain.TableSnapshot{
+		TableID:        record.TableID,
+		HandNumber:     record.HandNumber,
+		DealerPos:      record.DealerPos,
+		HandInProgress: record.HandInProgress,
+		Players:        make([]domain.SnapshotPlayer, 0, len(players)),
+		TakenAt:        record.TakenAt,
+	}
+	if record.HandID != nil {
+		snapshot.HandID = record.HandID.String()
+	}
+	for _, p := range players {
+		if p.Left {
+			snapshot.Leavers = append(snapshot.Leavers, domain.SnapshotLeaver{
+				PlayerID:     p.PlayerID,
+				SessionID:    p.SessionID,
+				Chips:        p.Chips,
+				Contribution: p.Contribution,
+			})
+			continue
+		}
+		snapshot.Players = append(snapshot.Players, domain.SnapshotPlayer{
+			PlayerID:  p.PlayerID,
+			SessionID: p.SessionID,
+			SeatIdx:   p.Seat,
+			Chips:     p.Chips,
+		})
+	}
+	return snapshot, nil
+}
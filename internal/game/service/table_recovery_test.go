@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type stubRecoveryRepo struct {
+	snapshots map[string]*repository.TableSnapshot
+	actions   []*repository.TableActionLog
+}
+
+func newStubRecoveryRepo() *stubRecoveryRepo {
+	return &stubRecoveryRepo{snapshots: make(map[string]*repository.TableSnapshot)}
+}
+
+func (r *stubRecoveryRepo) SaveSnapshot(ctx context.Context, snapshot *repository.TableSnapshot) error {
+	r.snapshots[snapshot.TableID] = snapshot
+	return nil
+}
+
+func (r *stubRecoveryRepo) ListSnapshots(ctx context.Context) ([]*repository.TableSnapshot, error) {
+	list := make([]*repository.TableSnapshot, 0, len(r.snapshots))
+	for _, s := range r.snapshots {
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func (r *stubRecoveryRepo) DeleteSnapshot(ctx context.Context, tableID string) error {
+	delete(r.snapshots, tableID)
+	return nil
+}
+
+func (r *stubRecoveryRepo) AppendAction(ctx context.Context, entry *repository.TableActionLog) error {
+	r.actions = append(r.actions, entry)
+	return nil
+}
+
+func (r *stubRecoveryRepo) GetActionsByHand(ctx context.Context, handID uuid.UUID) ([]*repository.TableActionLog, error) {
+	var entries []*repository.TableActionLog
+	for _, a := range r.actions {
+		if a.HandID == handID {
+			entries = append(entries, a)
+		}
+	}
+	return entries, nil
+}
+
+func (r *stubRecoveryRepo) DeleteActionsByHand(ctx context.Context, handID uuid.UUID) error {
+	kept := r.actions[:0]
+	for _, a := range r.actions {
+		if a.HandID != handID {
+			kept = append(kept, a)
+		}
+	}
+	r.actions = kept
+	return nil
+}
+
+// seedInterruptedHand 写入一张手牌进行中被中断的牌桌：玩家开局前 2000 筹码，已投入 20 + 100
+func seedInterruptedHand(t *testing.T, svc *RecoveryService, repo *stubRecoveryRepo, playerID string) string {
+	t.Helper()
+	handID := uuid.New().String()
+	svc.SaveSnapshot(&domain.TableSnapshot{
+		TableID:        "table-1",
+		HandNumber:     3,
+		DealerPos:      2,
+		HandInProgress: true,
+		HandID:         handID,
+		Players: []domain.SnapshotPlayer{
+			{PlayerID: playerID, SessionID: "session-" + playerID, SeatIdx: 2, Chips: 2000},
+			{PlayerID: "bot-1", SeatIdx: 5, Chips: 500},
+		},
+		TakenAt: time.Now(),
+	})
+	svc.LogAction("table-1", handID, domain.HandActionRecord{PlayerID: playerID, Action: "BIG_BLIND", Amount: 20})
+	svc.LogAction("table-1", handID, domain.HandActionRecord{PlayerID: "bot-1", Action: "CALL", Amount: 20})
+	svc.LogAction("table-1", handID, domain.HandActionRecord{PlayerID: playerID, Action: "RAISE", Amount: 100})
+
+	// 另一张已完成的手牌：收尾时清除其动作日志
+	doneHand := uuid.New().String()
+	svc.LogAction("table-2", doneHand, domain.HandActionRecord{PlayerID: "bot-2", Action: "CALL", Amount: 20})
+	svc.CompleteHand(&domain.TableSnapshot{TableID: "table-2", TakenAt: time.Now()}, doneHand)
+
+	svc.Start()
+	svc.Stop(context.Background())
+
+	if len(repo.actions) != 3 {
+		t.Fatalf("expected 3 logged actions for the interrupted hand, got %d", len(repo.actions))
+	}
+	if _, ok := repo.snapshots["table-2"]; ok {
+		t.Fatal("empty table should not keep a snapshot")
+	}
+	return handID
+}
+
+func TestRecoveryService_Restore(t *testing.T) {
+	repo := newStubRecoveryRepo()
+	svc := NewRecoveryService(repo, nil, zap.NewNop())
+	playerID := uuid.New().String()
+	handID := seedInterruptedHand(t, svc, repo, playerID)
+
+	report, err := svc.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if report.Policy != RecoveryPolicyRestore || len(report.Tables) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	table := report.Tables[0]
+	if !table.Restore || table.VoidedHandID != handID {
+		t.Errorf("expected restorable table voiding %s, got %+v", handID, table)
+	}
+	if table.Refunds[playerID] != 120 || table.Refunds["bot-1"] != 20 {
+		t.Errorf("unexpected refunds: %v", table.Refunds)
+	}
+	if p := table.Snapshot.Players[0]; p.PlayerID != playerID || p.SessionID != "session-"+playerID || p.SeatIdx != 2 || p.Chips != 2000 {
+		t.Errorf("unexpected snapshot player: %+v", p)
+	}
+	if len(repo.actions) != 0 {
+		t.Errorf("voided hand actions should be deleted, got %d", len(repo.actions))
+	}
+	if _, ok := repo.snapshots["table-1"]; !ok {
+		t.Error("restore policy should keep the snapshot until the table saves a new one")
+	}
+}
+
+func TestRecoveryService_Refund(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 5000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	gameService := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	buyIn, err := gameService.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "table-1", GameType: "poker", Amount: 2000})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+
+	repo := newStubRecoveryRepo()
+	svc := NewRecoveryService(repo, gameService, zap.NewNop())
+	svc.SetPolicy(RecoveryPolicyRefund)
+	seedInterruptedHand(t, svc, repo, playerID.String())
+
+	report, err := svc.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	if len(report.Tables) != 1 || report.Tables[0].Restore || report.Tables[0].CashedOut != 1 {
+		t.Fatalf("unexpected report: %+v", report.Tables)
+	}
+	if s := sessionRepo.sessions[buyIn.SessionID]; s.Status != "completed" {
+		t.Errorf("expected session to be completed, got %s", s.Status)
+	}
+	// 作废的手牌不计输赢：以开局前筹码兑现，资金全数回到可用余额
+	if w := walletRepo.wallet; w.Balance != 5000 || w.LockedBalance != 0 {
+		t.Errorf("expected balance=5000 locked=0, got %+v", w)
+	}
+	if len(repo.snapshots) != 0 || len(repo.actions) != 0 {
+		t.Errorf("refunded table should leave no recovery data, got %d snapshots %d actions", len(repo.snapshots), len(repo.actions))
+	}
+}
+
+// TestRecoveryService_RefundsVoidedHandLeaver 玩家在手牌中被踢并兑现后重启，作废该手时退还留在底池的筹码
+func TestRecoveryService_RefundsVoidedHandLeaver(t *testing.T) {
+	ctx := context.Background()
+	playerID := uuid.New()
+	walletRepo := newEscrowWalletRepo(playerID, 5000)
+	sessionRepo := &escrowSessionRepo{sessions: make(map[uuid.UUID]*repository.GameSession)}
+	gameService := NewGameService(stubPlayerRepo{}, walletRepo, sessionRepo, stubUnitOfWork{}, zap.NewNop())
+
+	buyIn, err := gameService.BuyIn(ctx, BuyInRequest{PlayerID: playerID, TableID: "table-1", GameType: "poker", Amount: 2000})
+	if err != nil {
+		t.Fatalf("BuyIn failed: %v", err)
+	}
+	// 已投入 120，以剩余 1880 兑现：输额在兑现时补记
+	if _, err := gameService.CashOut(ctx, CashOutRequest{PlayerID: playerID, SessionID: buyIn.SessionID, Chips: 1880}); err != nil {
+		t.Fatalf("CashOut failed: %v", err)
+	}
+	if w := walletRepo.wallet; w.Balance != 4880 || w.LockedBalance != 0 {
+		t.Fatalf("unexpected wallet after cash-out: %+v", w)
+	}
+
+	repo := newStubRecoveryRepo()
+	svc := NewRecoveryService(repo, gameService, zap.NewNop())
+	handID := uuid.New().String()
+	svc.SaveSnapshot(&domain.TableSnapshot{
+		TableID:        "table-1",
+		HandNumber:     3,
+		HandInProgress: true,
+		HandID:         handID,
+		Players:        []domain.SnapshotPlayer{{PlayerID: "bot-1", SeatIdx: 5, Chips: 500}},
+		Leavers: []domain.SnapshotLeaver{
+			{PlayerID: playerID.String(), SessionID: buyIn.SessionID.String(), Chips: 2000, Contribution: 120},
+		},
+		TakenAt: time.Now(),
+	})
+	svc.Start()
+	svc.Stop(ctx)
+
+	// 快照在重启后仍保留（restore 策略），重复复原不会重复退款
+	for i := 0; i < 2; i++ {
+		report, err := svc.Recover(ctx)
+		if err != nil {
+			t.Fatalf("Recover failed: %v", err)
+		}
+		if len(report.Tables) != 1 || !report.Tables[0].Restore || report.Tables[0].LeaverRefund != 120 {
+			t.Fatalf("unexpected report (run %d): %+v", i+1, report.Tables[0])
+		}
+		if leavers := report.Tables[0].Snapshot.Leavers; len(leavers) != 1 || leavers[0].Contribution != 120 {
+			t.Errorf("expected leaver to round-trip through the snapshot, got %+v", leavers)
+		}
+		if w := walletRepo.wallet; w.Balance != 5000 || w.LockedBalance != 0 {
+			t.Errorf("expected voided hand refunded to balance=5000 locked=0, got %+v", w)
+		}
+	}
+}
+
+func TestParseRecoveryPolicy(t *testing.T) {
+	if p, err := ParseRecoveryPolicy(""); err != nil || p != RecoveryPolicyRestore {
+		t.Errorf("expected default restore policy, got %q %v", p, err)
+	}
+	if p, err := ParseRecoveryPolicy("refund"); err != nil || p != RecoveryPolicyRefund {
+		t.Errorf("expected refund policy, got %q %v", p, err)
+	}
+	if _, err := ParseRecoveryPolicy("replay"); !errors.Is(err, ErrInvalidRecoveryPolicy) {
+		t.Errorf("expected ErrInvalidRecoveryPolicy, got %v", err)
+	}
+}
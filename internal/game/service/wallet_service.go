@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultWalletPageLimit 交易记录与对账单的默认分页大小
+	DefaultWalletPageLimit = 50
+	// MaxWalletPageLimit 交易记录与对账单的最大分页大小
+	MaxWalletPageLimit = 200
+)
+
+var (
+	// ErrInvalidTransactionType 交易类型无效
+	ErrInvalidTransactionType = errors.New("invalid transaction type")
+	// ErrInvalidWalletPeriod 查询期间无效（from 必须早于 to）
+	ErrInvalidWalletPeriod = errors.New("invalid wallet period")
+	// ErrInvalidPagination 分页参数无效
+	ErrInvalidPagination = errors.New("invalid pagination")
+)
+
+// validTransactionTypes 可供查询的交易类型
+var validTransactionTypes = map[repository.TransactionType]bool{
+	repository.TransactionTypeDeposit:  true,
+	repository.TransactionTypeWithdraw: true,
+	repository.TransactionTypeWin:      true,
+	repository.TransactionTypeLoss:     true,
+	repository.TransactionTypeBuyIn:    true,
+	repository.TransactionTypeCashOut:  true,
+	repository.TransactionTypeRefund:   true,
+	repository.TransactionTypeBonus:    true,
+}
+
+// TransactionPage 交易记录分页结果
+type TransactionPage struct {
+	Transactions []*repository.WalletTransaction
+	Total        int64
+	Limit        int
+	Offset       int
+}
+
+// SessionStatementLine 对账单中的单个游戏会话
+type SessionStatementLine struct {
+	Session *repository.GameSession
+	CashOut *int64 // 已结束会话的兑现金额，进行中为 nil
+	Profit  int64  // 已结束为实际盈亏，进行中为按当前筹码计算的浮动盈亏
+}
+
+// SessionStatement 玩家在期间内的游戏会话盈亏对账单
+type SessionStatement struct {
+	PlayerID  uuid.UUID
+	Currency  string // 空表示所有币别
+	From      time.Time
+	To        time.Time
+	Summaries []*repository.GameSessionSummary // 整个期间按币别的汇总（不受分页影响）
+	Lines     []*SessionStatementLine
+	Limit     int
+	Offset    int
+}
+
+// TotalSessions 期间内的会话总数（用于分页）
+func (s *SessionStatement) TotalSessions() int {
+	total := 0
+	for _, summary := range s.Summaries {
+		total += summary.Sessions
+	}
+	return total
+}
+
+// WalletService 钱包查询服务：余额、交易记录与会话对账单
+type WalletService struct {
+	walletRepo  repository.WalletRepository
+	txRepo      repository.TransactionRepository
+	sessionRepo repository.GameSessionRepository
+	logger      *zap.Logger
+}
+
+// NewWalletService 创建钱包查询服务
+func NewWalletService(
+	walletRepo repository.WalletRepository,
+	txRepo repository.TransactionRepository,
+	sessionRepo repository.GameSessionRepository,
+	logger *zap.Logger,
+) *WalletService {
+	return &WalletService{
+		walletRepo:  walletRepo,
+		txRepo:      txRepo,
+		sessionRepo: sessionRepo,
+		logger:      logger,
+	}
+}
+
+// GetBalances 查询玩家所有币别的钱包
+func (s *WalletService) GetBalances(ctx context.Context, playerID uuid.UUID) ([]*repository.Wallet, error) {
+	wallets, err := s.walletRepo.ListByPlayerID(ctx, playerID)
+	if err != nil {
+		s.logger.Error("failed to list wallets",
+			zap.String("player_id", playerID.String()),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	if len(wallets) == 0 {
+		return nil, ErrWalletNotFound
+	}
+	return wallets, nil
+}
+
+// ListTransactions 按类型与期间分页查询玩家的交易记录（Limit 为 0 时使用默认值）
+func (s *WalletService) ListTransactions(ctx context.Context, filter repository.TransactionFilter) (*TransactionPage, error) {
+	for _, txType := range filter.Types {
+		if !validTransactionTypes[txType] {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTransactionType, txType)
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, ErrInvalidWalletPeriod
+	}
+	limit, err := normalizePageLimit(filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	transactions, total, err := s.txRepo.ListByPlayer(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	return &TransactionPage{
+		Transactions: transactions,
+		Total:        total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}, nil
+}
+
+// GetStatement 生成玩家在 [from, to) 期间内的会话盈亏对账单（currency 为空时包含所有币别，按币别分别汇总）
+func (s *WalletService) GetStatement(ctx context.Context, playerID uuid.UUID, currency string, from, to time.Time, limit, offset int) (*SessionStatement, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidWalletPeriod
+	}
+	limit, err := normalizePageLimit(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.GameSessionFilter{
+		PlayerID: playerID,
+		Currency: currency,
+		From:     &from,
+		To:       &to,
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	summaries, err := s.sessionRepo.SummarizeByPlayer(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize sessions: %w", err)
+	}
+	sessions, err := s.sessionRepo.ListByPlayer(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	statement := &SessionStatement{
+		PlayerID:  playerID,
+		Currency:  currency,
+		From:      from,
+		To:        to,
+		Summaries: summaries,
+		Lines:     make([]*SessionStatementLine, 0, len(sessions)),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	for _, session := range sessions {
+		line := &SessionStatementLine{
+			Session: session,
+			Profit:  session.NetProfitLoss(),
+		}
+		if session.Status != "active" {
+			cashOut := session.CurrentChips
+			line.CashOut = &cashOut
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	return statement, nil
+}
+
+// normalizePageLimit 校验分页参数，limit 为 0 时回传默认值
+func normalizePageLimit(limit, offset int) (int, error) {
+	if limit == 0 {
+		limit = DefaultWalletPageLimit
+	}
+	if limit < 0 || limit > MaxWalletPageLimit || offset < 0 {
+		return 0, ErrInvalidPagination
+	}
+	return limit, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+type stubTransactionRepo struct {
+	repository.TransactionRepository
+	filter repository.TransactionFilter
+}
+
+func (r *stubTransactionRepo) ListByPlayer(ctx context.Context, filter repository.TransactionFilter) ([]*repository.WalletTransaction, int64, error) {
+	r.filter = filter
+	return []*repository.WalletTransaction{{ID: uuid.New(), Type: repository.TransactionTypeDeposit, Amount: 500}}, 7, nil
+}
+
+type statementSessionRepo struct {
+	repository.GameSessionRepository
+	sessions []*repository.GameSession
+	filter   repository.GameSessionFilter
+}
+
+func (r *statementSessionRepo) ListByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSession, error) {
+	r.filter = filter
+	return r.sessions, nil
+}
+
+func (r *statementSessionRepo) SummarizeByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSessionSummary, error) {
+	return []*repository.GameSessionSummary{
+		{Currency: "PLAY", Sessions: 4, TotalBuyIn: 10000, TotalCashOut: 12000, NetProfit: 2000},
+		{Currency: "USD", Sessions: 8, TotalBuyIn: 20000, TotalCashOut: 13000, NetProfit: -5000},
+	}, nil
+}
+
+func TestWalletService_ListTransactions(t *testing.T) {
+	txRepo := &stubTransactionRepo{}
+	svc := NewWalletService(nil, txRepo, nil, zap.NewNop())
+	playerID := uuid.New()
+
+	page, err := svc.ListTransactions(context.Background(), repository.TransactionFilter{
+		PlayerID: playerID,
+		Types:    []repository.TransactionType{repository.TransactionTypeDeposit, repository.TransactionTypeWithdraw},
+	})
+	if err != nil {
+		t.Fatalf("ListTransactions failed: %v", err)
+	}
+	if page.Total != 7 || len(page.Transactions) != 1 || page.Limit != DefaultWalletPageLimit {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if txRepo.filter.PlayerID != playerID || txRepo.filter.Limit != DefaultWalletPageLimit || len(txRepo.filter.Types) != 2 {
+		t.Errorf("unexpected filter passed to repository: %+v", txRepo.filter)
+	}
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, -1, 0)
+	cases := []struct {
+		name   string
+		filter repository.TransactionFilter
+		want   error
+	}{
+		{"unknown type", repository.TransactionFilter{Types: []repository.TransactionType{"jackpot"}}, ErrInvalidTransactionType},
+		{"reversed period", repository.TransactionFilter{From: &from, To: &to}, ErrInvalidWalletPeriod},
+		{"limit too large", repository.TransactionFilter{Limit: MaxWalletPageLimit + 1}, ErrInvalidPagination},
+		{"negative offset", repository.TransactionFilter{Offset: -1}, ErrInvalidPagination},
+	}
+	for _, tc := range cases {
+		if _, err := svc.ListTransactions(context.Background(), tc.filter); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestWalletService_GetStatement(t *testing.T) {
+	ended := time.Now()
+	sessionRepo := &statementSessionRepo{sessions: []*repository.GameSession{
+		{ID: uuid.New(), TableID: "t1", BuyInAmount: 2000, CurrentChips: 2600, Status: "active"},
+		{ID: uuid.New(), TableID: "t2", BuyInAmount: 1000, CurrentChips: 400, Status: "completed", EndedAt: &ended},
+	}}
+	svc := NewWalletService(nil, nil, sessionRepo, zap.NewNop())
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	statement, err := svc.GetStatement(context.Background(), uuid.New(), "", from, to, 0, 10)
+	if err != nil {
+		t.Fatalf("GetStatement failed: %v", err)
+	}
+	if statement.TotalSessions() != 12 || len(statement.Summaries) != 2 || statement.Limit != DefaultWalletPageLimit || statement.Offset != 10 {
+		t.Errorf("unexpected statement: %+v", statement)
+	}
+	if !sessionRepo.filter.From.Equal(from) || !sessionRepo.filter.To.Equal(to) {
+		t.Errorf("unexpected period passed to repository: %+v", sessionRepo.filter)
+	}
+	if len(statement.Lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(statement.Lines))
+	}
+
+	// 进行中的会话按当前筹码计算浮动盈亏，没有兑现金额
+	if l := statement.Lines[0]; l.CashOut != nil || l.Profit != 600 {
+		t.Errorf("unexpected active line: cashOut=%v profit=%d", l.CashOut, l.Profit)
+	}
+	if l := statement.Lines[1]; l.CashOut == nil || *l.CashOut != 400 || l.Profit != -600 {
+		t.Errorf("unexpected completed line: cashOut=%v profit=%d", l.CashOut, l.Profit)
+	}
+
+	if _, err := svc.GetStatement(context.Background(), uuid.New(), "USD", to, from, 0, 0); !errors.Is(err, ErrInvalidWalletPeriod) {
+		t.Errorf("expected ErrInvalidWalletPeriod, got %v", err)
+	}
+}
@@ -36,6 +36,10 @@ func (m *mockSessionRepo) Create(ctx context.Context, session *repository.GameSe
 	return nil
 }
 
+func (m *mockSessionRepo) CreateWithTx(ctx context.Context, tx repository.Transaction, session *repository.GameSession) error {
+	return m.Create(ctx, session)
+}
+
 func (m *mockSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.GameSession, error) {
 	if s, ok := m.activeSessions[id]; ok {
 		return s, nil
@@ -50,6 +54,22 @@ func (m *mockSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid
 	return nil, fmt.Errorf("session not found")
 }
 
+func (m *mockSessionRepo) ListActive(ctx context.Context) ([]*repository.GameSession, error) {
+	sessions := make([]*repository.GameSession, 0, len(m.activeSessions))
+	for _, s := range m.activeSessions {
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepo) ListByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSession, error) {
+	return nil, nil
+}
+
+func (m *mockSessionRepo) SummarizeByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSessionSummary, error) {
+	return nil, nil
+}
+
 func (m *mockSessionRepo) Update(ctx context.Context, session *repository.GameSession) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -61,6 +81,10 @@ func (m *mockSessionRepo) End(ctx context.Context, id uuid.UUID, finalChips int6
 	return nil
 }
 
+func (m *mockSessionRepo) EndWithTx(ctx context.Context, tx repository.Transaction, id uuid.UUID, finalChips int64) error {
+	return nil
+}
+
 // Implement other interface methods to satisfy interface
 // Since we don't trigger them in this test, empty imp is fine?
 // But Go interface satisfaction requires method signature.
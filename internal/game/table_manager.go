@@ -2,6 +2,9 @@ package game
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,10 +14,36 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrTableNotFound 牌桌不存在
+	ErrTableNotFound = errors.New("table not found")
+	// ErrKickCashOutFailed 玩家已被移出牌桌，但遊戲會話兌現失敗（由會話對帳補兌現）
+	ErrKickCashOutFailed = errors.New("player removed but cash-out failed")
+)
+
+// KickResult 管理員踢人的結果
+type KickResult struct {
+	Seat    domain.SnapshotPlayer    // 移除前的座位與離座時的剩餘籌碼
+	CashOut *service.CashOutResponse // 兌現結果；玩家沒有遊戲會話時為 nil
+}
+
 type TableManager struct {
 	tables      map[string]*domain.Table
 	mu          sync.RWMutex
 	gameService *service.GameService
+	handHistory *service.HandHistoryService // 可為 nil（不記錄手牌歷史）
+	rakeService *service.RakeService        // 可為 nil（不記錄抽水收入）
+	rakeConfig  domain.RakeConfig           // 套用到每張新桌的抽水規則
+	recovery    *service.RecoveryService    // 可為 nil（不保存快照與動作日誌）
+
+	// 每張牌桌一條結算佇列，依手牌順序寫入帳本
+	settlers         map[string]*handSettler
+	settleRetryDelay time.Duration
+
+	// 牌桌幣別：tableCurrencies 未列出的牌桌使用 defaultCurrency
+	defaultCurrency string
+	tableCurrencies map[string]string
+
 	logger      *zap.Logger
 	tableLogger domain.Logger // 注入到每張 Table
 
@@ -23,12 +52,15 @@ type TableManager struct {
 
 	// WS 層籌碼同步回調（由 main.go 注入，更新 PlayerSession.Chips）
 	onSessionChipsUpdate func(playerID string, chips int64)
+	onSessionCashedOut   func(playerID string)
 }
 
 func NewTableManager(gs *service.GameService) *TableManager {
 	return &TableManager{
-		tables:      make(map[string]*domain.Table),
-		gameService: gs,
+		tables:           make(map[string]*domain.Table),
+		gameService:      gs,
+		settlers:         make(map[string]*handSettler),
+		settleRetryDelay: time.Second,
 	}
 }
 
@@ -38,11 +70,54 @@ func (tm *TableManager) SetLogger(logger *zap.Logger) {
 	tm.tableLogger = &zapDomainLogger{logger: logger}
 }
 
+// SetHandHistoryService 設定手牌歷史服務（應在建表前呼叫）
+func (tm *TableManager) SetHandHistoryService(hs *service.HandHistoryService) {
+	tm.handHistory = hs
+}
+
+// SetRakeService 設定抽水服務（應在建表前呼叫）
+func (tm *TableManager) SetRakeService(rs *service.RakeService) {
+	tm.rakeService = rs
+}
+
+// SetRakeConfig 設定抽水規則（應在建表前呼叫）
+func (tm *TableManager) SetRakeConfig(cfg domain.RakeConfig) {
+	tm.rakeConfig = cfg
+}
+
+// SetTableCurrencies 設定牌桌幣別（未列出的牌桌使用 defaultCurrency）
+func (tm *TableManager) SetTableCurrencies(defaultCurrency string, tables map[string]string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.defaultCurrency = defaultCurrency
+	tm.tableCurrencies = tables
+}
+
+// TableCurrency 回傳牌桌使用的幣別；買入、兌現與抽水都以此幣別的錢包結算
+func (tm *TableManager) TableCurrency(tableID string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if currency, ok := tm.tableCurrencies[tableID]; ok {
+		return currency
+	}
+	return tm.defaultCurrency
+}
+
+// SetRecoveryService 設定崩潰復原服務（應在建表前呼叫）
+func (tm *TableManager) SetRecoveryService(rs *service.RecoveryService) {
+	tm.recovery = rs
+}
+
 // SetOnTableEvent 設定遊戲事件回調（應在建表前呼叫）
 func (tm *TableManager) SetOnTableEvent(fn func(event domain.TableEvent)) {
 	tm.onTableEvent = fn
 }
 
+// SetOnSessionCashedOut 設定 WS 層會話清理回調（玩家被踢出並兌現後呼叫）
+func (tm *TableManager) SetOnSessionCashedOut(fn func(playerID string)) {
+	tm.onSessionCashedOut = fn
+}
+
 // SetOnSessionChipsUpdate 設定 WS 層籌碼同步回調（應在建表前呼叫）
 func (tm *TableManager) SetOnSessionChipsUpdate(fn func(playerID string, chips int64)) {
 	tm.onSessionChipsUpdate = fn
@@ -56,19 +131,270 @@ func (tm *TableManager) GetOrCreateTable(id string) *domain.Table {
 		return t
 	}
 
+	t := tm.newTable(id)
+	tm.tables[id] = t
+	go t.Run()
+	return t
+}
+
+// RestoreTable 以快照重建牌桌（伺服器重啟後呼叫，應在 SetOnTableEvent 等設定之後）
+func (tm *TableManager) RestoreTable(snapshot *domain.TableSnapshot) (*domain.Table, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if _, ok := tm.tables[snapshot.TableID]; ok {
+		return nil, fmt.Errorf("table %s already exists", snapshot.TableID)
+	}
+
+	t := tm.newTable(snapshot.TableID)
+	if err := t.Restore(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to restore table %s: %w", snapshot.TableID, err)
+	}
+	tm.tables[t.ID] = t
+
+	// 以手牌邊界的狀態覆蓋舊快照（Run() 尚未啟動，可安全讀取）
+	if tm.recovery != nil {
+		tm.recovery.SaveSnapshot(t.Snapshot())
+	}
+
+	go t.Run()
+	return t, nil
+}
+
+// newTable 建立牌桌並套用共用設定與回調（呼叫者需持有 tm.mu）
+func (tm *TableManager) newTable(id string) *domain.Table {
 	t := domain.NewTable(id)
+	t.Rake = tm.rakeConfig
+	if _, ok := tm.settlers[id]; !ok {
+		tm.settlers[id] = tm.startHandSettler()
+	}
 	t.AddOnHandComplete(tm.onHandComplete)
 	if tm.onTableEvent != nil {
 		t.AddOnEvent(tm.onTableEvent)
 	}
+	if tm.recovery != nil {
+		t.AddOnAction(func(handID string, action domain.HandActionRecord) {
+			tm.recovery.LogAction(t.ID, handID, action)
+		})
+		// 每手開始時保存快照，記錄進行中手牌的 ID 與開局前籌碼
+		t.AddOnEvent(func(event domain.TableEvent) {
+			if event.Type == domain.EventHandStart {
+				tm.recovery.SaveSnapshot(t.Snapshot())
+			}
+		})
+	}
 	if tm.tableLogger != nil {
 		t.Logger = tm.tableLogger
 	}
-	tm.tables[id] = t
-	go t.Run()
 	return t
 }
 
+// RunSnapshots 定期保存所有牌桌的快照，直到 ctx 取消
+// 手牌之間的入座、離桌也因此能在重啟後回復。
+func (tm *TableManager) RunSnapshots(ctx context.Context, interval time.Duration) {
+	if tm.recovery == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tm.snapshotTables()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// snapshotTables 向每張桌請求快照並保存
+func (tm *TableManager) snapshotTables() {
+	for _, id := range tm.TableIDs() {
+		snapshot, err := tm.TableSnapshot(id)
+		if err != nil {
+			tm.logWarn("snapshot failed", zap.String("table_id", id), zap.Error(err))
+			continue
+		}
+		tm.recovery.SaveSnapshot(snapshot)
+	}
+}
+
+// TableSnapshot 透過 ActionCh 取得牌桌快照（在該桌的 Run() goroutine 中產生）
+func (tm *TableManager) TableSnapshot(id string) (*domain.TableSnapshot, error) {
+	t := tm.GetTable(id)
+	if t == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, id)
+	}
+
+	resultCh := make(chan domain.ActionResult, 1)
+	select {
+	case t.ActionCh <- domain.PlayerAction{Type: domain.ActionSnapshot, ResultCh: resultCh}:
+	default:
+		return nil, errors.New("action queue full")
+	}
+
+	select {
+	case result := <-resultCh:
+		return result.Snapshot, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("table did not respond")
+	}
+}
+
+// PlayerView 透過 ActionCh 取得玩家視角的牌桌狀態（在該桌的 Run() goroutine 中產生）
+// 與先前送出的命令（例如重連通知）依序處理，取得的狀態必定反映這些命令。
+func (tm *TableManager) PlayerView(tableID, playerID string) (*domain.TableView, error) {
+	t := tm.GetTable(tableID)
+	if t == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotFound, tableID)
+	}
+
+	resultCh := make(chan domain.ActionResult, 1)
+	select {
+	case t.ActionCh <- domain.PlayerAction{Type: domain.ActionPlayerView, PlayerID: playerID, ResultCh: resultCh}:
+	default:
+		return nil, errors.New("action queue full")
+	}
+
+	select {
+	case result := <-resultCh:
+		return result.View, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("table did not respond")
+	}
+}
+
+// IsPlayerSeated 查詢玩家是否坐在指定牌桌上（實現 service.SeatLocator）
+// 牌桌無回應時回傳錯誤，由呼叫端決定是否略過，避免誤兌現。
+func (tm *TableManager) IsPlayerSeated(tableID, playerID string) (bool, error) {
+	if tm.GetTable(tableID) == nil {
+		return false, nil
+	}
+	snapshot, err := tm.TableSnapshot(tableID)
+	if err != nil {
+		return false, fmt.Errorf("seat lookup at table %s: %w", tableID, err)
+	}
+	for _, p := range snapshot.Players {
+		if p.PlayerID == playerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// KickPlayer 將玩家移出牌桌（管理員操作）並以剩餘籌碼兌現其遊戲會話
+// 手牌進行中會先蓋牌，本手已下注的籌碼留在底池，只兌現牌桌回報的剩餘籌碼。
+// 兌現失敗時仍回傳結果並附帶 ErrKickCashOutFailed，
+// 該會話已無座位，會由會話對帳補兌現。
+func (tm *TableManager) KickPlayer(tableID, playerID string) (*KickResult, error) {
+	t := tm.GetTable(tableID)
+	if t == nil {
+		return nil, ErrTableNotFound
+	}
+
+	snapshot, err := tm.TableSnapshot(tableID)
+	if err != nil {
+		return nil, err
+	}
+	var seated *domain.SnapshotPlayer
+	for i := range snapshot.Players {
+		if snapshot.Players[i].PlayerID == playerID {
+			seated = &snapshot.Players[i]
+			break
+		}
+	}
+	if seated == nil {
+		return nil, domain.ErrPlayerNotFound
+	}
+
+	resultCh := make(chan domain.ActionResult, 1)
+	select {
+	case t.ActionCh <- domain.PlayerAction{Type: domain.ActionLeaveTable, PlayerID: playerID, ResultCh: resultCh}:
+	default:
+		return nil, errors.New("action queue full")
+	}
+
+	var left domain.ActionResult
+	select {
+	case left = <-resultCh:
+		if left.Err != nil {
+			return nil, left.Err
+		}
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("table did not respond")
+	}
+
+	// 快照在手牌進行中回報開局籌碼，實際剩餘籌碼以牌桌 goroutine 回傳為準
+	seat := *seated
+	seat.Chips = left.Chips
+
+	// 兌現前先保存快照記下留在底池的籌碼，該手因重啟作廢時才能退還
+	if tm.recovery != nil {
+		if snapshot, err := tm.TableSnapshot(tableID); err == nil {
+			tm.recovery.SaveSnapshot(snapshot)
+		}
+	}
+
+	tm.logInfo("player kicked from table",
+		zap.String("table_id", tableID), zap.String("player_id", playerID), zap.Int64("chips", seat.Chips))
+
+	result := &KickResult{Seat: seat}
+	cashOut, err := tm.cashOutKicked(tableID, playerID, seat.Chips)
+	if err != nil {
+		tm.logError("failed to cash out kicked player",
+			zap.String("table_id", tableID), zap.String("player_id", playerID), zap.Error(err))
+		return result, fmt.Errorf("%w: %v", ErrKickCashOutFailed, err)
+	}
+	result.CashOut = cashOut
+	return result, nil
+}
+
+// cashOutKicked 以離座時的籌碼兌現被踢玩家在該桌的遊戲會話
+// 玩家沒有該桌的活躍會話（例如測試玩家）時回傳 nil
+func (tm *TableManager) cashOutKicked(tableID, playerID string, chips int64) (*service.CashOutResponse, error) {
+	playerUUID, err := uuid.Parse(playerID)
+	if err != nil {
+		return nil, nil // 非正式玩家無錢包
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := tm.gameService.GetActiveSession(ctx, playerUUID)
+	if err != nil || session.TableID != tableID {
+		return nil, nil
+	}
+
+	response, err := tm.gameService.CashOut(ctx, service.CashOutRequest{
+		PlayerID:  playerUUID,
+		SessionID: session.ID,
+		Chips:     chips,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tm.onSessionCashedOut != nil {
+		tm.onSessionCashedOut(playerID)
+	}
+	return response, nil
+}
+
+// TableIDs 目前所有牌桌的 ID（依字母排序）
+func (tm *TableManager) TableIDs() []string {
+	tm.mu.RLock()
+	ids := make([]string, 0, len(tm.tables))
+	for id := range tm.tables {
+		ids = append(ids, id)
+	}
+	tm.mu.RUnlock()
+
+	sort.Strings(ids)
+	return ids
+}
+
 func (tm *TableManager) GetTable(id string) *domain.Table {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
@@ -119,6 +445,22 @@ func (tm *TableManager) onHandComplete(t *domain.Table) {
 	// 異步同步到資料庫，不阻塞 Table.Run()
 	go tm.syncPlayerChips(playerChips)
 
+	// 異步保存手牌歷史（LastHand 完成後不再被 Table 修改）
+	if tm.handHistory != nil && t.LastHand != nil {
+		go tm.saveHandHistory(t.LastHand)
+	}
+
+	if t.LastHand != nil {
+		// 保存手牌邊界快照並清除本手的動作日誌
+		var boundarySaved <-chan error
+		if tm.recovery != nil {
+			boundarySaved = tm.recovery.CompleteHand(t.Snapshot(), t.LastHand.HandID)
+		}
+
+		// 快照落盤後依序寫入每位參與者的輸贏分錄（以手牌 ID 做冪等），抽水收入另行記錄
+		tm.enqueueSettlement(t.ID, settlementJob{record: t.LastHand, boundarySaved: boundarySaved})
+	}
+
 	// 同步更新 WS 層的 PlayerSession.Chips
 	if tm.onSessionChipsUpdate != nil {
 		for id, chips := range playerChips {
@@ -153,6 +495,57 @@ func (tm *TableManager) syncPlayerChips(playerChips map[string]int64) {
 	}
 }
 
+// enqueueSettlement 將手牌排入牌桌的結算佇列
+func (tm *TableManager) enqueueSettlement(tableID string, job settlementJob) {
+	tm.mu.RLock()
+	settler := tm.settlers[tableID]
+	tm.mu.RUnlock()
+
+	if settler == nil || !settler.enqueue(job) {
+		tm.logError("settlement queue unavailable, results will be booked at cash-out",
+			zap.String("table_id", tableID),
+			zap.String("hand_id", job.record.HandID))
+		// 兌現時的差額補記不含抽水，抽水仍須另行入帳
+		tm.recordRakeAsync(job.record)
+	}
+}
+
+// settleHand 將手牌輸贏寫入錢包帳本
+func (tm *TableManager) settleHand(record *domain.HandRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return tm.gameService.SettleHand(ctx, record)
+}
+
+// recordRake 將抽水記入平台收入帳（同一手牌重複記錄時忽略）
+func (tm *TableManager) recordRake(record *domain.HandRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return tm.rakeService.RecordRake(ctx, record, tm.TableCurrency(record.TableID))
+}
+
+// saveHandHistory 異步將手牌紀錄保存到資料庫
+func (tm *TableManager) saveHandHistory(record *domain.HandRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := tm.handHistory.RecordHand(ctx, record); err != nil {
+		tm.logError("failed to save hand history",
+			zap.String("table_id", record.TableID),
+			zap.String("hand_id", record.HandID),
+			zap.Error(err))
+	}
+}
+
+// logInfo 安全地記錄資訊（logger 可能為 nil）
+func (tm *TableManager) logInfo(msg string, fields ...zap.Field) {
+	if tm.logger != nil {
+		tm.logger.Info(msg, fields...)
+	}
+}
+
 // logWarn 安全地記錄警告（logger 可能為 nil）
 func (tm *TableManager) logWarn(msg string, fields ...zap.Field) {
 	if tm.logger != nil {
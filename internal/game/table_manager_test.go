@@ -0,0 +1,148 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
+	"github.com/shinjuwu/TheNuts/internal/game/service"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"go.uber.org/zap"
+)
+
+func (r *escrowWalletRepo) SumGameResultsBySession(ctx context.Context, tx repository.Transaction, sessionID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (r *escrowWalletRepo) UnlockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType repository.TransactionType, description, idempotencyKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked < amount {
+		return errors.New("insufficient locked balance")
+	}
+	r.locked -= amount
+	return nil
+}
+
+// endingSessionRepo 兌現時將會話標記為已結束
+type endingSessionRepo struct {
+	*mockSessionRepo
+}
+
+func (r endingSessionRepo) EndWithTx(ctx context.Context, tx repository.Transaction, id uuid.UUID, finalChips int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.activeSessions[id].Status = "completed"
+	r.activeSessions[id].CurrentChips = finalChips
+	return nil
+}
+
+// TestKickPlayer_CashesOutSession 踢人後以剩餘籌碼兌現遊戲會話並通知 WS 層
+func TestKickPlayer_CashesOutSession(t *testing.T) {
+	playerID := uuid.New()
+	sessions := endingSessionRepo{newMockRepo()}
+	session := &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "kick-test", BuyInAmount: 1000, Status: "active"}
+	sessions.Create(context.Background(), session)
+	wallets := &escrowWalletRepo{locked: 1000}
+
+	tm := NewTableManager(service.NewGameService(nil, wallets, sessions, passThroughUnitOfWork{}, zap.NewNop()))
+	var cleared string
+	tm.SetOnSessionCashedOut(func(id string) { cleared = id })
+
+	table := tm.GetOrCreateTable("kick-test")
+	resultCh := make(chan domain.ActionResult, 1)
+	table.ActionCh <- domain.PlayerAction{
+		Type:     domain.ActionJoinTable,
+		PlayerID: playerID.String(),
+		Player:   &domain.Player{ID: playerID.String(), SeatIdx: 3, Chips: 800},
+		SeatIdx:  3,
+		ResultCh: resultCh,
+	}
+	if result := <-resultCh; result.Err != nil {
+		t.Fatalf("join failed: %v", result.Err)
+	}
+
+	kicked, err := tm.KickPlayer("kick-test", playerID.String())
+	if err != nil {
+		t.Fatalf("KickPlayer failed: %v", err)
+	}
+	if kicked.Seat.SeatIdx != 3 || kicked.Seat.Chips != 800 {
+		t.Errorf("unexpected seat: %+v", kicked.Seat)
+	}
+	if kicked.CashOut == nil || kicked.CashOut.CashOutAmount != 800 {
+		t.Fatalf("expected cash-out of 800, got %+v", kicked.CashOut)
+	}
+	if session.Status != "completed" {
+		t.Errorf("expected session completed, got %s", session.Status)
+	}
+	// 託管 1000：補記 -200 後釋放 800（mock 的 RecordGameResult 直接調整託管）
+	if wallets.locked != 0 {
+		t.Errorf("expected escrow released, got %d", wallets.locked)
+	}
+	if cleared != playerID.String() {
+		t.Errorf("expected WS session cleared for %s, got %q", playerID, cleared)
+	}
+}
+
+// TestKickPlayer_MidHandCashesOutRemainingChips 手牌進行中踢人只兌現扣除本手下注後的籌碼
+func TestKickPlayer_MidHandCashesOutRemainingChips(t *testing.T) {
+	playerID := uuid.New()
+	opponentID := uuid.New()
+	sessions := endingSessionRepo{newMockRepo()}
+	session := &repository.GameSession{ID: uuid.New(), PlayerID: playerID, TableID: "kick-hand", BuyInAmount: 1000, Status: "active"}
+	sessions.Create(context.Background(), session)
+	wallets := &escrowWalletRepo{locked: 1000}
+
+	tm := NewTableManager(service.NewGameService(nil, wallets, sessions, passThroughUnitOfWork{}, zap.NewNop()))
+	table := tm.GetOrCreateTable("kick-hand")
+	for i, id := range []uuid.UUID{playerID, opponentID} {
+		resultCh := make(chan domain.ActionResult, 1)
+		table.ActionCh <- domain.PlayerAction{
+			Type:     domain.ActionJoinTable,
+			PlayerID: id.String(),
+			Player:   &domain.Player{ID: id.String(), SeatIdx: i, Chips: 1000, Status: domain.StatusPlaying},
+			SeatIdx:  i,
+			ResultCh: resultCh,
+		}
+		if result := <-resultCh; result.Err != nil {
+			t.Fatalf("join failed: %v", result.Err)
+		}
+	}
+
+	// 牌桌每秒嘗試開局，等待盲注下完
+	var bet int64
+	deadline := time.Now().Add(3 * time.Second)
+	for bet == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("hand did not start")
+		}
+		time.Sleep(50 * time.Millisecond)
+		view, err := tm.PlayerView("kick-hand", playerID.String())
+		if err != nil {
+			t.Fatalf("PlayerView failed: %v", err)
+		}
+		for _, seat := range view.Seats {
+			if seat.PlayerID == playerID.String() {
+				bet = seat.CurrentBet
+			}
+		}
+	}
+
+	kicked, err := tm.KickPlayer("kick-hand", playerID.String())
+	if err != nil {
+		t.Fatalf("KickPlayer failed: %v", err)
+	}
+	if kicked.Seat.Chips != 1000-bet {
+		t.Errorf("expected remaining chips %d, got %d", 1000-bet, kicked.Seat.Chips)
+	}
+	if kicked.CashOut == nil || kicked.CashOut.CashOutAmount != 1000-bet {
+		t.Fatalf("expected cash-out of %d, got %+v", 1000-bet, kicked.CashOut)
+	}
+	// 已下的盲注補記為輸額，託管全數釋放且未多兌現
+	if wallets.locked != 0 {
+		t.Errorf("expected escrow released, got %d", wallets.locked)
+	}
+}
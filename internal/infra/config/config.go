@@ -12,23 +12,184 @@ type Config struct {
 		Port           string   `yaml:"port"`
 		Host           string   `yaml:"host"`
 		AllowedOrigins []string `yaml:"allowed_origins"` // WebSocket Origin 白名單，空表示允許所有（開發模式）
+		TrustedProxies []string `yaml:"trusted_proxies"` // 可信任的反向代理（IP 或 CIDR），只有來自這些地址的 X-Forwarded-For 才採信
 	} `yaml:"server"`
 	Auth struct {
 		JWTSecret        string `yaml:"jwt_secret"`
 		TicketTTLSeconds int    `yaml:"ticket_ttl_seconds"`
+
+		AccessTokenTTLSeconds int `yaml:"access_token_ttl_seconds"` // Access Token 有效期（秒）
+		RefreshTokenTTLHours  int `yaml:"refresh_token_ttl_hours"`  // Refresh Token 有效期（小時，每次輪替後重新計算）
+
+		JWTActiveKeyID string         `yaml:"jwt_active_kid"` // 簽名使用的金鑰 ID
+		JWTKeys        []JWTKeyConfig `yaml:"jwt_keys"`       // JWT 金鑰（未設定時使用 jwt_secret）
 	} `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Mail      MailConfig      `yaml:"mail"`
+	Wallet    struct {
+		ApprovalThreshold int64  `yaml:"approval_threshold"`  // 管理員錢包調整超過此金額（分）需另一位管理員核准
+		ApprovalWindow    string `yaml:"approval_window"`     // 門檻以此期間內同一管理員對同一玩家免審核調整的累計金額計算（例如 "24h"）
+		GamePoolTolerance int64  `yaml:"game_pool_tolerance"` // 對帳時每個幣別容許的牌局清算餘額（分），超過即視為資金滯留
+	} `yaml:"wallet"`
 	Database struct {
 		Postgres PostgresConfig `yaml:"postgres"`
 		Redis    RedisConfig    `yaml:"redis"`
 	} `yaml:"database"`
 	Game struct {
-		MinPlayers      int    `yaml:"min_players"`
-		MaxPlayers      int    `yaml:"max_players"`
-		DefaultChips    int64  `yaml:"default_chips"`
-		DefaultCurrency string `yaml:"default_currency"` // Default wallet currency (e.g., USD, CNY)
+		MinPlayers      int              `yaml:"min_players"`
+		MaxPlayers      int              `yaml:"max_players"`
+		DefaultChips    int64            `yaml:"default_chips"`
+		DefaultCurrency string           `yaml:"default_currency"` // Default wallet currency (e.g., USD, CNY)
+		Rake            RakeConfig       `yaml:"rake"`
+		Recovery        RecoveryConfig   `yaml:"recovery"`
+		Reconciliation  ReconcileConfig  `yaml:"reconciliation"`
+		Currencies      []CurrencyConfig `yaml:"currencies"` // 錢包幣別（未設定時只有默認貨幣）
+		Tables          []TableConfig    `yaml:"tables"`     // 牌桌設定（未列出的牌桌使用默認貨幣）
 	} `yaml:"game"`
 }
 
+// RakeConfig 定義抽水與返水配置（金額單位為分）
+type RakeConfig struct {
+	Percent         float64         `yaml:"percent"`          // 抽水比例（0.05 = 5%），0 表示不抽水
+	Cap             int64           `yaml:"cap"`              // 每手抽水上限，0 表示無上限
+	CapsByPlayers   []RakeCapConfig `yaml:"caps_by_players"`  // 依發牌人數調整的上限（優先於 cap）
+	NoFlopNoDrop    bool            `yaml:"no_flop_no_drop"`  // 未發翻牌即結束的手牌不抽水
+	RakebackPercent float64         `yaml:"rakeback_percent"` // 返水比例（0.2 = 返還分攤抽水的 20%）
+}
+
+// RakeCapConfig 發牌人數達到 MinPlayers 時適用的抽水上限
+type RakeCapConfig struct {
+	MinPlayers int   `yaml:"min_players"`
+	Cap        int64 `yaml:"cap"`
+}
+
+// RecoveryConfig 定義崩潰復原配置
+type RecoveryConfig struct {
+	Policy           string `yaml:"policy"`            // restore（重建牌桌）或 refund（兌現並結束會話），預設 restore
+	SnapshotInterval string `yaml:"snapshot_interval"` // 定期快照間隔（例如 "10s"）
+}
+
+// GetSnapshotInterval 將字串轉換為 time.Duration
+func (r *RecoveryConfig) GetSnapshotInterval() time.Duration {
+	d, err := time.ParseDuration(r.SnapshotInterval)
+	if err != nil || d <= 0 {
+		return 10 * time.Second // 默認值
+	}
+	return d
+}
+
+// ReconcileConfig 定義孤兒會話對帳配置
+type ReconcileConfig struct {
+	Interval    string `yaml:"interval"`     // 定期對帳間隔（例如 "5m"）
+	GracePeriod string `yaml:"grace_period"` // 最近更新過的會話不清理（例如 "5m"）
+}
+
+// GetInterval 將字串轉換為 time.Duration
+func (r *ReconcileConfig) GetInterval() time.Duration {
+	d, err := time.ParseDuration(r.Interval)
+	if err != nil || d <= 0 {
+		return 5 * time.Minute // 默認值
+	}
+	return d
+}
+
+// GetGracePeriod 將字串轉換為 time.Duration
+func (r *ReconcileConfig) GetGracePeriod() time.Duration {
+	d, err := time.ParseDuration(r.GracePeriod)
+	if err != nil || d < 0 {
+		return 5 * time.Minute // 默認值
+	}
+	return d
+}
+
+// CurrencyConfig 定義錢包幣別
+type CurrencyConfig struct {
+	Code           string `yaml:"code"`            // 幣別代碼（例如 USD、PLAY）
+	PlayMoney      bool   `yaml:"play_money"`      // 遊戲幣：不可存提，與真錢錢包完全隔離
+	InitialBalance int64  `yaml:"initial_balance"` // 註冊時贈送的餘額（分）
+}
+
+// JWTKeyConfig 定義 JWT 簽名金鑰
+// HS256 使用 secret；EdDSA 使用 PEM 私鑰簽名，只設定公鑰時僅用於驗證（輪替中的舊金鑰）
+type JWTKeyConfig struct {
+	ID             string `yaml:"kid"`
+	Algorithm      string `yaml:"algorithm"`        // HS256 或 EdDSA，預設 HS256
+	Secret         string `yaml:"secret"`           // HS256 密鑰
+	PrivateKeyFile string `yaml:"private_key_file"` // EdDSA 私鑰（PKCS#8 PEM）
+	PublicKeyFile  string `yaml:"public_key_file"`  // EdDSA 公鑰（PKIX PEM）
+}
+
+// RateLimitConfig 定義限流設定（令牌桶）
+type RateLimitConfig struct {
+	Driver    string        `yaml:"driver"`    // redis（多實例共用）、memory 或 disabled，預設 redis
+	Auth      RateLimitRule `yaml:"auth"`      // 登入、註冊等未登入的認證端點（依 IP）
+	Account   RateLimitRule `yaml:"account"`   // 已登入的認證端點，例如兩步驟驗證、修改密碼（依玩家）
+	WebSocket RateLimitRule `yaml:"websocket"` // WebSocket 訊息（依玩家）
+}
+
+// RateLimitRule 定義令牌桶參數
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`  // 每秒補充的令牌數
+	Burst int     `yaml:"burst"` // 最多累積的令牌數（允許的突發請求數）
+}
+
+// withDefaults 未設定的欄位使用預設值
+func (r RateLimitRule) withDefaults(rate float64, burst int) RateLimitRule {
+	if r.Rate <= 0 {
+		r.Rate = rate
+	}
+	if r.Burst <= 0 {
+		r.Burst = burst
+	}
+	return r
+}
+
+// GetAuth 取得認證端點的限流參數，預設每 5 秒 1 次、突發 10 次
+func (c *RateLimitConfig) GetAuth() RateLimitRule {
+	return c.Auth.withDefaults(0.2, 10)
+}
+
+// GetAccount 取得已登入認證端點的限流參數，預設每秒 1 次、突發 10 次
+func (c *RateLimitConfig) GetAccount() RateLimitRule {
+	return c.Account.withDefaults(1, 10)
+}
+
+// GetWebSocket 取得 WebSocket 訊息的限流參數，預設每秒 10 則、突發 20 則
+func (c *RateLimitConfig) GetWebSocket() RateLimitRule {
+	return c.WebSocket.withDefaults(10, 20)
+}
+
+// MailConfig 定義寄信設定（電子郵件驗證、密碼重設）
+type MailConfig struct {
+	Driver      string     `yaml:"driver"`        // smtp、file（寫入目錄）或 memory，預設 file
+	From        string     `yaml:"from"`          // 寄件人
+	LinkBaseURL string     `yaml:"link_base_url"` // 郵件連結的前端網址
+	FileDir     string     `yaml:"file_dir"`      // file 模式的輸出目錄
+	SMTP        SMTPConfig `yaml:"smtp"`
+}
+
+// SMTPConfig 定義 SMTP 連接配置
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// GetMailFileDir 取得 file 模式的輸出目錄，未設定時為 ./tmp/mail
+func (m *MailConfig) GetMailFileDir() string {
+	if m.FileDir == "" {
+		return "./tmp/mail"
+	}
+	return m.FileDir
+}
+
+// TableConfig 定義牌桌設定
+type TableConfig struct {
+	ID       string `yaml:"id"`
+	Currency string `yaml:"currency"` // 買入與結算使用的幣別
+}
+
 // PostgresConfig 定義 PostgreSQL 連接配置
 type PostgresConfig struct {
 	Host            string `yaml:"host"`
@@ -68,6 +229,50 @@ type RedisConfig struct {
 	PoolSize int    `yaml:"pool_size"`
 }
 
+// GetApprovalThreshold 取得錢包調整的雙人核准門檻（分），未設定時為 100000
+func (c *Config) GetApprovalThreshold() int64 {
+	if c.Wallet.ApprovalThreshold <= 0 {
+		return 100000 // 1000.00
+	}
+	return c.Wallet.ApprovalThreshold
+}
+
+// GetApprovalWindow 取得錢包調整門檻的累計期間，未設定時為 24 小時
+func (c *Config) GetApprovalWindow() time.Duration {
+	d, err := time.ParseDuration(c.Wallet.ApprovalWindow)
+	if err != nil || d <= 0 {
+		return 24 * time.Hour
+	}
+	return d
+}
+
+// GetAccessTokenTTL 取得 Access Token 有效期，未設定時為 15 分鐘
+func (c *Config) GetAccessTokenTTL() time.Duration {
+	if c.Auth.AccessTokenTTLSeconds <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(c.Auth.AccessTokenTTLSeconds) * time.Second
+}
+
+// GetRefreshTokenTTL 取得 Refresh Token 有效期，未設定時為 30 天
+func (c *Config) GetRefreshTokenTTL() time.Duration {
+	if c.Auth.RefreshTokenTTLHours <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.Auth.RefreshTokenTTLHours) * time.Hour
+}
+
+// GetPlayMoneyCurrencies 取得遊戲幣幣別代碼
+func (c *Config) GetPlayMoneyCurrencies() []string {
+	var codes []string
+	for _, currency := range c.GetCurrencies() {
+		if currency.PlayMoney {
+			codes = append(codes, currency.Code)
+		}
+	}
+	return codes
+}
+
 // GetDefaultCurrency 取得默認貨幣，如果未設定則返回 "USD"
 func (c *Config) GetDefaultCurrency() string {
 	if c.Game.DefaultCurrency == "" {
@@ -76,6 +281,25 @@ func (c *Config) GetDefaultCurrency() string {
 	return c.Game.DefaultCurrency
 }
 
+// GetCurrencies 取得錢包幣別，未設定時只有默認貨幣（註冊贈送 1000.00）
+func (c *Config) GetCurrencies() []CurrencyConfig {
+	if len(c.Game.Currencies) == 0 {
+		return []CurrencyConfig{{Code: c.GetDefaultCurrency(), InitialBalance: 100000}}
+	}
+	return c.Game.Currencies
+}
+
+// GetTableCurrencies 取得牌桌 ID 對應的幣別
+func (c *Config) GetTableCurrencies() map[string]string {
+	currencies := make(map[string]string, len(c.Game.Tables))
+	for _, t := range c.Game.Tables {
+		if t.Currency != "" {
+			currencies[t.ID] = t.Currency
+		}
+	}
+	return currencies
+}
+
 func LoadConfig(path string) (*Config, error) {
 	f, err := os.Open(path)
 	if err != nil {
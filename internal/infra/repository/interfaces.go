@@ -24,6 +24,12 @@ type AccountRepository interface {
 	// Update 更新帳號信息
 	Update(ctx context.Context, account *Account) error
 
+	// UpdateStatus 更新帳號狀態（active、suspended、banned）
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+
+	// UpdateRoles 更新帳號角色
+	UpdateRoles(ctx context.Context, id uuid.UUID, roles []string) error
+
 	// UpdateLastLogin 更新最後登入時間和 IP
 	UpdateLastLogin(ctx context.Context, id uuid.UUID, ip string) error
 
@@ -60,28 +66,44 @@ type PlayerRepository interface {
 
 // WalletRepository 定義錢包相關的資料庫操作（最重要）
 type WalletRepository interface {
-	// Create 創建新錢包
+	// Create 創建新錢包（每位玩家每種幣別一個）
 	Create(ctx context.Context, wallet *Wallet) error
 
-	// GetByPlayerID 根據玩家 ID 查詢錢包
-	GetByPlayerID(ctx context.Context, playerID uuid.UUID) (*Wallet, error)
+	// GetByPlayerID 根據玩家 ID 與幣別查詢錢包
+	GetByPlayerID(ctx context.Context, playerID uuid.UUID, currency string) (*Wallet, error)
+
+	// ListByPlayerID 查詢玩家的所有錢包
+	ListByPlayerID(ctx context.Context, playerID uuid.UUID) ([]*Wallet, error)
 
 	// GetWithLock 使用行鎖查詢錢包（用於事務中）
-	GetWithLock(ctx context.Context, tx Transaction, playerID uuid.UUID) (*Wallet, error)
+	GetWithLock(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string) (*Wallet, error)
 
 	// Credit 入帳（加錢）
 	// idempotencyKey 用於防止重複入帳
-	Credit(ctx context.Context, tx Transaction, playerID uuid.UUID, amount int64, txType TransactionType, description string, idempotencyKey string) error
+	Credit(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string, amount int64, txType TransactionType, description string, idempotencyKey string) error
 
 	// Debit 出帳（扣錢）
 	// idempotencyKey 用於防止重複扣款
-	Debit(ctx context.Context, tx Transaction, playerID uuid.UUID, amount int64, txType TransactionType, description string, idempotencyKey string) error
+	Debit(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string, amount int64, txType TransactionType, description string, idempotencyKey string) error
+
+	// LockBalance 鎖定餘額（買入時將資金移入託管，總餘額不變）
+	// idempotencyKey 用於防止重複鎖定
+	LockBalance(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType TransactionType, description string, idempotencyKey string) error
 
-	// LockBalance 鎖定餘額（用於下注等場景）
-	LockBalance(ctx context.Context, tx Transaction, playerID uuid.UUID, amount int64) error
+	// UnlockBalance 解鎖餘額（兌現時將託管資金釋放回可用餘額）
+	// idempotencyKey 用於防止重複釋放
+	UnlockBalance(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType TransactionType, description string, idempotencyKey string) error
 
-	// UnlockBalance 解鎖餘額（遊戲結束後）
-	UnlockBalance(ctx context.Context, tx Transaction, playerID uuid.UUID, amount int64) error
+	// RecordGameResult 記錄單手牌的輸贏並調整鎖定餘額（可用餘額不變）
+	// amount 為淨輸贏：正數記為 game_win，負數記為 game_loss
+	// idempotencyKey 用於防止同一手牌重複記錄
+	RecordGameResult(ctx context.Context, tx Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, referenceID string, description string, idempotencyKey string) error
+
+	// SumGameResultsBySession 加總遊戲會話已記錄的輸贏（託管金額 = 買入 + 此值）
+	SumGameResultsBySession(ctx context.Context, tx Transaction, sessionID uuid.UUID) (int64, error)
+
+	// ListAll 查詢所有錢包（用於對帳）
+	ListAll(ctx context.Context) ([]*Wallet, error)
 }
 
 // TransactionRepository 定義交易記錄相關的資料庫操作
@@ -100,6 +122,12 @@ type TransactionRepository interface {
 
 	// GetByPlayerID 根據玩家 ID 查詢交易記錄（分頁）
 	GetByPlayerID(ctx context.Context, playerID uuid.UUID, limit, offset int) ([]*WalletTransaction, error)
+
+	// GetByGameSessionID 根據遊戲會話 ID 查詢交易記錄（按時間順序）
+	GetByGameSessionID(ctx context.Context, sessionID uuid.UUID) ([]*WalletTransaction, error)
+
+	// ListByPlayer 依條件查詢玩家的交易記錄（分頁，新到舊），同時回傳符合條件的總筆數
+	ListByPlayer(ctx context.Context, filter TransactionFilter) ([]*WalletTransaction, int64, error)
 }
 
 // GameSessionRepository 定義遊戲會話相關的資料庫操作
@@ -107,17 +135,32 @@ type GameSessionRepository interface {
 	// Create 創建新遊戲會話
 	Create(ctx context.Context, session *GameSession) error
 
+	// CreateWithTx 在事務中創建會話（與買入的資金鎖定一起提交或回滾）
+	CreateWithTx(ctx context.Context, tx Transaction, session *GameSession) error
+
 	// GetByID 根據 ID 查詢會話
 	GetByID(ctx context.Context, id uuid.UUID) (*GameSession, error)
 
 	// GetActiveByPlayerID 查詢玩家當前活躍的會話
 	GetActiveByPlayerID(ctx context.Context, playerID uuid.UUID) (*GameSession, error)
 
+	// ListActive 查詢所有活躍的會話（用於對帳清理）
+	ListActive(ctx context.Context) ([]*GameSession, error)
+
 	// Update 更新會話信息
 	Update(ctx context.Context, session *GameSession) error
 
 	// End 結束會話
 	End(ctx context.Context, id uuid.UUID, finalChips int64) error
+
+	// EndWithTx 在事務中結束會話（與兌現的資金解鎖一起提交或回滾）
+	EndWithTx(ctx context.Context, tx Transaction, id uuid.UUID, finalChips int64) error
+
+	// ListByPlayer 依條件查詢玩家的會話（分頁，新到舊）
+	ListByPlayer(ctx context.Context, filter GameSessionFilter) ([]*GameSession, error)
+
+	// SummarizeByPlayer 按幣別彙總玩家在條件期間內的會話
+	SummarizeByPlayer(ctx context.Context, filter GameSessionFilter) ([]*GameSessionSummary, error)
 }
 
 // HandHistoryRepository 定義手牌歷史相關的資料庫操作
@@ -133,6 +176,91 @@ type HandHistoryRepository interface {
 
 	// GetByPlayerID 根據玩家 ID 查詢手牌歷史
 	GetByPlayerID(ctx context.Context, playerID uuid.UUID, limit, offset int) ([]*HandHistory, error)
+
+	// GetByTableID 根據桌子 ID 查詢手牌歷史
+	GetByTableID(ctx context.Context, tableID string, limit, offset int) ([]*HandHistory, error)
+}
+
+// RakeRepository 定義抽水收入相關的資料庫操作
+type RakeRepository interface {
+	// Record 在事務中記錄一手牌的抽水收入與玩家分攤（同一手牌重複記錄時忽略）
+	Record(ctx context.Context, tx Transaction, revenue *HouseRevenue, contributions []*RakeContribution) error
+
+	// GetPlayerSummary 統計玩家在 [from, to) 期間於指定幣別牌桌分攤的抽水
+	GetPlayerSummary(ctx context.Context, playerID uuid.UUID, currency string, from, to time.Time) (*RakeSummary, error)
+
+	// GetRevenueTotal 統計 [from, to) 期間指定幣別的抽水收入總額
+	GetRevenueTotal(ctx context.Context, currency string, from, to time.Time) (int64, error)
+}
+
+// LedgerRepository 定義複式記帳相關的資料庫操作
+// 錢包與抽水的資金異動由對應 Repository 在同一事務中自動記帳，此介面供額外轉帳與對帳使用
+type LedgerRepository interface {
+	// Transfer 在事務中記錄一筆借貸平衡的轉帳（from 減少、to 增加 amount）
+	Transfer(ctx context.Context, tx Transaction, from, to LedgerAccount, amount int64, referenceID, description string) error
+
+	// GetAccountBalances 彙總所有帳戶的分錄
+	GetAccountBalances(ctx context.Context) ([]*LedgerAccountBalance, error)
+
+	// GetUnbalancedJournals 查詢金額合計不為 0 的分錄組（正常應為空）
+	GetUnbalancedJournals(ctx context.Context) ([]uuid.UUID, error)
+}
+
+// TableRecoveryRepository 定義牌桌快照與動作日誌的資料庫操作（崩潰復原用）
+type TableRecoveryRepository interface {
+	// SaveSnapshot 保存牌桌快照（每張桌只保留最新一筆）
+	SaveSnapshot(ctx context.Context, snapshot *TableSnapshot) error
+
+	// ListSnapshots 查詢所有牌桌快照
+	ListSnapshots(ctx context.Context) ([]*TableSnapshot, error)
+
+	// DeleteSnapshot 刪除牌桌快照
+	DeleteSnapshot(ctx context.Context, tableID string) error
+
+	// AppendAction 附加一筆手牌動作到日誌
+	AppendAction(ctx context.Context, entry *TableActionLog) error
+
+	// GetActionsByHand 查詢手牌的動作日誌（按寫入順序）
+	GetActionsByHand(ctx context.Context, handID uuid.UUID) ([]*TableActionLog, error)
+
+	// DeleteActionsByHand 刪除手牌的動作日誌
+	DeleteActionsByHand(ctx context.Context, handID uuid.UUID) error
+}
+
+// AuditLogRepository 定義審計日誌相關的資料庫操作
+type AuditLogRepository interface {
+	// Create 寫入一筆審計日誌
+	Create(ctx context.Context, log *AuditLog) error
+
+	// CreateWithTx 在事務中寫入審計日誌（與被審計的異動一起提交或回滾）
+	CreateWithTx(ctx context.Context, tx Transaction, log *AuditLog) error
+
+	// List 依條件查詢審計日誌（分頁，新到舊），同時回傳符合條件的總筆數
+	List(ctx context.Context, filter AuditLogFilter) ([]*AuditLog, int64, error)
+}
+
+// WalletAdjustmentRepository 定義管理員錢包調整相關的資料庫操作
+type WalletAdjustmentRepository interface {
+	// Create 在事務中寫入調整申請；冪等性鍵已存在時不寫入並回傳 false
+	Create(ctx context.Context, tx Transaction, adjustment *WalletAdjustment) (bool, error)
+
+	// GetByID 根據 ID 查詢（不存在時回傳 nil, nil）
+	GetByID(ctx context.Context, id uuid.UUID) (*WalletAdjustment, error)
+
+	// GetByIdempotencyKey 根據冪等性鍵查詢（不存在時回傳 nil, nil）
+	GetByIdempotencyKey(ctx context.Context, key string) (*WalletAdjustment, error)
+
+	// GetForUpdate 在事務中查詢並鎖定，避免重複審核（不存在時回傳 nil, nil）
+	GetForUpdate(ctx context.Context, tx Transaction, id uuid.UUID) (*WalletAdjustment, error)
+
+	// UpdateReview 在事務中記錄審核結果
+	UpdateReview(ctx context.Context, tx Transaction, adjustment *WalletAdjustment) error
+
+	// SumSelfApproved 在事務中加總申請人自 since 起對同一玩家、同一幣別免審核完成的調整金額
+	SumSelfApproved(ctx context.Context, tx Transaction, requestedBy, playerID uuid.UUID, currency string, since time.Time) (int64, error)
+
+	// List 按狀態分頁查詢（status 為空表示全部，按建立時間倒序）
+	List(ctx context.Context, status string, limit, offset int) ([]*WalletAdjustment, error)
 }
 
 // Transaction 定義資料庫事務介面
@@ -144,6 +272,65 @@ type Transaction interface {
 	Rollback(ctx context.Context) error
 }
 
+// AuthSessionRepository 定義登入會話相關的資料庫操作
+type AuthSessionRepository interface {
+	// Create 建立登入會話
+	Create(ctx context.Context, session *AuthSession) error
+
+	// GetByID 根據 ID 查詢（不存在時回傳 nil, nil）
+	GetByID(ctx context.Context, id string) (*AuthSession, error)
+
+	// GetByTokenHash 以目前或上一個 refresh token 雜湊查詢（不存在時回傳 nil, nil）
+	GetByTokenHash(ctx context.Context, tokenHash string) (*AuthSession, error)
+
+	// Rotate 以 currentHash 為條件輪替 refresh token（session 帶新的雜湊、到期時間與來源）
+	// 會話已撤銷或 token 已被輪替時不更新並回傳 false
+	Rotate(ctx context.Context, session *AuthSession, currentHash string) (bool, error)
+
+	// Revoke 撤銷會話；已撤銷時回傳 false
+	Revoke(ctx context.Context, id, reason string) (bool, error)
+
+	// RevokeAllByPlayer 撤銷玩家所有未撤銷的會話，回傳撤銷筆數
+	RevokeAllByPlayer(ctx context.Context, playerID uuid.UUID, reason string) (int64, error)
+
+	// ListActiveByPlayer 列出玩家未撤銷且未過期的會話（最近使用的在前）
+	ListActiveByPlayer(ctx context.Context, playerID uuid.UUID) ([]*AuthSession, error)
+}
+
+// AccountTokenRepository 定義帳號一次性 Token 相關的資料庫操作
+type AccountTokenRepository interface {
+	// Create 建立 Token，並使同一帳號同用途的未使用 Token 失效（只有最新寄出的有效）
+	Create(ctx context.Context, token *AccountToken) error
+
+	// Consume 以雜湊取用未使用且未過期的 Token 並標記為已使用（單次有效）
+	// 不存在、已使用或已過期時回傳 nil, nil
+	Consume(ctx context.Context, tokenHash, purpose string) (*AccountToken, error)
+}
+
+// TwoFactorRepository 定義 TOTP 兩步驟驗證相關的資料庫操作
+type TwoFactorRepository interface {
+	// GetByAccountID 查詢帳號的 TOTP 設定（不存在時回傳 nil, nil）
+	GetByAccountID(ctx context.Context, accountID uuid.UUID) (*AccountTOTP, error)
+
+	// SavePending 保存綁定中的金鑰（已啟用時不覆蓋並回傳 false）
+	SavePending(ctx context.Context, totp *AccountTOTP) (bool, error)
+
+	// Enable 完成綁定：啟用 TOTP、記錄已使用的時間步長並替換恢復碼
+	Enable(ctx context.Context, accountID uuid.UUID, step int64, recoveryCodeHashes []string) error
+
+	// UseStep 記錄已使用的時間步長；step 不大於上次使用的值（重放）時回傳 false
+	UseStep(ctx context.Context, accountID uuid.UUID, step int64) (bool, error)
+
+	// ReplaceRecoveryCodes 以新的恢復碼取代所有舊恢復碼
+	ReplaceRecoveryCodes(ctx context.Context, accountID uuid.UUID, codeHashes []string) error
+
+	// ConsumeRecoveryCode 使用恢復碼（單次有效），不存在或已使用時回傳 false
+	ConsumeRecoveryCode(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error)
+
+	// Delete 停用兩步驟驗證，刪除金鑰與恢復碼
+	Delete(ctx context.Context, accountID uuid.UUID) error
+}
+
 // UnitOfWork 定義工作單元模式（用於事務管理）
 type UnitOfWork interface {
 	// Begin 開始新事務
@@ -13,6 +13,7 @@ type Account struct {
 	Email               string     `db:"email"`
 	PasswordHash        string     `db:"password_hash"`
 	Status              string     `db:"status"` // active, suspended, banned
+	Roles               []string   `db:"roles"`  // 管理角色，一般玩家為空
 	EmailVerified       bool       `db:"email_verified"`
 	FailedLoginAttempts int        `db:"failed_login_attempts"`
 	LockedUntil         *time.Time `db:"locked_until"`
@@ -22,6 +23,39 @@ type Account struct {
 	UpdatedAt           time.Time  `db:"updated_at"`
 }
 
+// 帳號狀態
+const (
+	AccountStatusActive    = "active"
+	AccountStatusSuspended = "suspended"
+	AccountStatusBanned    = "banned"
+)
+
+// 帳號角色
+const (
+	RoleAdmin   = "admin"   // 全部管理 API
+	RoleSupport = "support" // 客服：玩家查詢、停權、牌桌管理
+	RoleFinance = "finance" // 財務：錢包調整、營收
+)
+
+// IsValidRole 是否為已定義的角色
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleSupport, RoleFinance:
+		return true
+	}
+	return false
+}
+
+// HasRole 帳號是否具有指定角色
+func (a *Account) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // Player 代表玩家資料
 type Player struct {
 	ID               uuid.UUID  `db:"id"`
@@ -73,6 +107,7 @@ type WalletTransaction struct {
 	Description    string          `db:"description"`
 	IdempotencyKey *string         `db:"idempotency_key"` // 冪等性鍵
 	GameSessionID  *uuid.UUID      `db:"game_session_id"` // 關聯的遊戲會話
+	ReferenceID    *string         `db:"reference_id"`    // 外部參考（如手牌 ID）
 	CreatedAt      time.Time       `db:"created_at"`
 }
 
@@ -82,9 +117,10 @@ type GameSession struct {
 	PlayerID     uuid.UUID  `db:"player_id"`
 	GameType     string     `db:"game_type"` // poker, slot, etc.
 	TableID      string     `db:"table_id"`
+	Currency     string     `db:"currency"`      // 買入幣別（兌現與結算只動用同幣別錢包）
 	BuyInAmount  int64      `db:"buy_in_amount"` // 買入金額（分）
 	CurrentChips int64      `db:"current_chips"` // 當前籌碼
-	Status       string     `db:"status"`        // active, ended
+	Status       string     `db:"status"`        // active, completed, abandoned
 	StartedAt    time.Time  `db:"started_at"`
 	EndedAt      *time.Time `db:"ended_at"`
 	CreatedAt    time.Time  `db:"created_at"`
@@ -99,31 +135,330 @@ func (g *GameSession) NetProfitLoss() int64 {
 	return g.CurrentChips - g.BuyInAmount
 }
 
+// TransactionFilter 交易記錄查詢條件
+type TransactionFilter struct {
+	PlayerID uuid.UUID
+	Types    []TransactionType // 空表示不限類型
+	From     *time.Time        // 起始時間（含）
+	To       *time.Time        // 結束時間（不含）
+	Limit    int
+	Offset   int
+}
+
+// GameSessionFilter 遊戲會話查詢條件（以 started_at 篩選期間）
+type GameSessionFilter struct {
+	PlayerID uuid.UUID
+	Currency string     // 空表示不限幣別
+	From     *time.Time // 起始時間（含）
+	To       *time.Time // 結束時間（不含）
+	Limit    int
+	Offset   int
+}
+
+// GameSessionSummary 單一幣別的遊戲會話彙總（已結束的會話才計入兌現與盈虧）
+type GameSessionSummary struct {
+	Currency     string
+	Sessions     int   // 會話數（含進行中）
+	TotalBuyIn   int64 // 買入合計
+	TotalCashOut int64 // 已結束會話的兌現合計
+	NetProfit    int64 // 已結束會話的淨盈虧
+}
+
 // HandHistory 代表手牌歷史記錄
+// 一手牌涉及多位玩家的遊戲會話，各玩家的 game_session_id 記錄在 Players JSONB 中
 type HandHistory struct {
-	ID             uuid.UUID `db:"id"`
-	GameSessionID  uuid.UUID `db:"game_session_id"`
-	HandNumber     int       `db:"hand_number"`
-	PlayersData    []byte    `db:"players_data"`    // JSONB
-	CommunityCards []byte    `db:"community_cards"` // JSONB
-	Actions        []byte    `db:"actions"`         // JSONB
-	Pots           []byte    `db:"pots"`            // JSONB
-	Winners        []byte    `db:"winners"`         // JSONB
-	RakeAmount     int64     `db:"rake_amount"`
-	Duration       int       `db:"duration"` // 秒
-	CreatedAt      time.Time `db:"created_at"`
-}
-
-// AuditLog 代表審計日誌
+	ID             uuid.UUID  `db:"id"` // 即 domain.HandRecord.HandID
+	TableID        string     `db:"table_id"`
+	HandNumber     int        `db:"hand_number"`
+	DealerPos      int        `db:"dealer_pos"`
+	SmallBlind     int64      `db:"small_blind"`
+	BigBlind       int64      `db:"big_blind"`
+	PlayersData    []byte     `db:"players"`         // JSONB: []HandHistoryPlayer
+	Actions        []byte     `db:"actions"`         // JSONB: []HandHistoryAction
+	Pots           []byte     `db:"pots"`            // JSONB: []HandHistoryPot
+	CommunityCards []byte     `db:"community_cards"` // JSONB: []string
+	Deck           []byte     `db:"deck"`            // JSONB: []string，發牌前牌序（重播用）
+	Winners        []byte     `db:"winners"`         // JSONB: []HandHistoryWinner
+	RakeAmount     int64      `db:"rake_amount"`
+	StartedAt      time.Time  `db:"started_at"`
+	EndedAt        *time.Time `db:"ended_at"`
+	Duration       int        `db:"duration_seconds"` // 秒
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+// HandHistoryPlayer 手牌歷史中的玩家資料（players JSONB 元素）
+type HandHistoryPlayer struct {
+	PlayerID      string   `json:"player_id"`
+	GameSessionID string   `json:"game_session_id,omitempty"`
+	Seat          int      `json:"seat"`
+	StartingChips int64    `json:"starting_chips"`
+	EndingChips   int64    `json:"ending_chips"`
+	HoleCards     []string `json:"hole_cards"`
+	ShowedDown    bool     `json:"showed_down"`
+	IsDealer      bool     `json:"is_dealer,omitempty"`
+}
+
+// HandHistoryAction 手牌歷史中的動作（actions JSONB 元素）
+type HandHistoryAction struct {
+	PlayerID  string    `json:"player_id"`
+	Street    string    `json:"street"`
+	Action    string    `json:"action"`
+	Amount    int64     `json:"amount"`
+	TotalBet  int64     `json:"total_bet"`
+	Reason    string    `json:"reason,omitempty"` // STAND_UP / LEAVE_TABLE（非輪次內的棄牌）
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HandHistoryPot 手牌歷史中的底池（pots JSONB 元素）
+type HandHistoryPot struct {
+	Amount       int64    `json:"amount"`
+	Contributors []string `json:"contributors"`
+}
+
+// HandHistoryWinner 手牌歷史中的贏家（winners JSONB 元素）
+type HandHistoryWinner struct {
+	PlayerID string `json:"player_id"`
+	Amount   int64  `json:"amount"`
+}
+
+// HouseRevenue 代表一手牌的抽水收入
+type HouseRevenue struct {
+	ID        uuid.UUID `db:"id"`
+	HandID    uuid.UUID `db:"hand_id"`
+	TableID   string    `db:"table_id"`
+	Currency  string    `db:"currency"`
+	Amount    int64     `db:"amount"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// RakeContribution 代表玩家在一手牌中分攤的抽水
+type RakeContribution struct {
+	HandID    uuid.UUID `db:"hand_id"`
+	PlayerID  uuid.UUID `db:"player_id"`
+	Amount    int64     `db:"amount"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// RakeSummary 玩家在期間內分攤的抽水統計
+type RakeSummary struct {
+	PlayerID  uuid.UUID
+	Hands     int   // 有分攤抽水的手牌數
+	TotalRake int64 // 分攤抽水總額
+}
+
+// LedgerAccountType 複式記帳的帳戶類型
+type LedgerAccountType string
+
+const (
+	LedgerPlayerWallet   LedgerAccountType = "player_wallet"   // 玩家可用餘額
+	LedgerTableEscrow    LedgerAccountType = "table_escrow"    // 玩家在桌上的託管籌碼（鎖定餘額）
+	LedgerGamePool       LedgerAccountType = "game_pool"       // 牌局清算：玩家間輸贏的對手方，抽水入帳後應結清
+	LedgerHouseRake      LedgerAccountType = "house_rake"      // 平台抽水收入
+	LedgerBonusLiability LedgerAccountType = "bonus_liability" // 平台發放的獎金
+	LedgerExternal       LedgerAccountType = "external"        // 外部資金：存款與提款的對手方
+)
+
+// LedgerAccount 記帳帳戶（玩家帳戶帶 PlayerID，平台帳戶為 nil；各幣別分開記帳）
+type LedgerAccount struct {
+	Type     LedgerAccountType
+	PlayerID *uuid.UUID
+	Currency string
+}
+
+// PlayerWalletAccount 玩家可用餘額帳戶
+func PlayerWalletAccount(playerID uuid.UUID, currency string) LedgerAccount {
+	return LedgerAccount{Type: LedgerPlayerWallet, PlayerID: &playerID, Currency: currency}
+}
+
+// TableEscrowAccount 玩家託管帳戶
+func TableEscrowAccount(playerID uuid.UUID, currency string) LedgerAccount {
+	return LedgerAccount{Type: LedgerTableEscrow, PlayerID: &playerID, Currency: currency}
+}
+
+// HouseAccount 平台帳戶（game_pool、house_rake、bonus_liability、external）
+func HouseAccount(accountType LedgerAccountType, currency string) LedgerAccount {
+	return LedgerAccount{Type: accountType, Currency: currency}
+}
+
+// String 回傳帳戶名稱（例如 player_wallet:<uuid>:USD）
+func (a LedgerAccount) String() string {
+	if a.PlayerID == nil {
+		return string(a.Type) + ":" + a.Currency
+	}
+	return string(a.Type) + ":" + a.PlayerID.String() + ":" + a.Currency
+}
+
+// LedgerEntry 代表一筆記帳分錄
+// 同一筆資金異動的分錄共用 JournalID，金額合計為 0
+type LedgerEntry struct {
+	ID            int64             `db:"id"`
+	JournalID     uuid.UUID         `db:"journal_id"`
+	AccountType   LedgerAccountType `db:"account_type"`
+	PlayerID      *uuid.UUID        `db:"player_id"`
+	Currency      string            `db:"currency"`
+	Amount        int64             `db:"amount"`         // 帳戶餘額變動（正數增加、負數減少）
+	TransactionID *uuid.UUID        `db:"transaction_id"` // 對應的錢包交易記錄
+	ReferenceID   *string           `db:"reference_id"`   // 手牌 ID 等業務參照
+	Description   string            `db:"description"`
+	CreatedAt     time.Time         `db:"created_at"`
+}
+
+// LedgerAccountBalance 帳戶的分錄彙總
+type LedgerAccountBalance struct {
+	Account   LedgerAccount
+	Increases int64 // 正數分錄合計
+	Decreases int64 // 負數分錄合計（取絕對值）
+}
+
+// Balance 回傳帳戶餘額
+func (b *LedgerAccountBalance) Balance() int64 {
+	return b.Increases - b.Decreases
+}
+
+// TableSnapshot 代表牌桌在手牌邊界的快照（崩潰復原用，每張桌一筆）
+type TableSnapshot struct {
+	TableID        string     `db:"table_id"`
+	HandNumber     int        `db:"hand_number"`
+	DealerPos      int        `db:"dealer_pos"`
+	HandInProgress bool       `db:"hand_in_progress"`
+	HandID         *uuid.UUID `db:"hand_id"` // 進行中手牌的 ID
+	Players        []byte     `db:"players"` // JSONB: []TableSnapshotPlayer
+	TakenAt        time.Time  `db:"taken_at"`
+}
+
+// TableSnapshotPlayer 快照中的入座玩家（players JSONB 元素）
+// 手牌進行中離桌的玩家以 Left 標記，Seat 為 -1，Chips 為該手開始前的籌碼。
+type TableSnapshotPlayer struct {
+	PlayerID     string `json:"player_id"`
+	SessionID    string `json:"session_id,omitempty"`
+	Seat         int    `json:"seat"`
+	Chips        int64  `json:"chips"`
+	Left         bool   `json:"left,omitempty"`
+	Contribution int64  `json:"contribution,omitempty"` // 離桌前已投入該手的籌碼
+}
+
+// TableActionLog 代表進行中手牌的動作日誌（手牌完成後刪除）
+type TableActionLog struct {
+	ID        int64     `db:"id"`
+	TableID   string    `db:"table_id"`
+	HandID    uuid.UUID `db:"hand_id"`
+	PlayerID  string    `db:"player_id"`
+	Street    string    `db:"street"`
+	Action    string    `db:"action"`
+	Amount    int64     `db:"amount"`
+	TotalBet  int64     `db:"total_bet"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditLog 代表審計日誌（對應 audit_logs 表）
 type AuditLog struct {
-	ID         uuid.UUID `db:"id"`
-	UserID     uuid.UUID `db:"user_id"`
-	Action     string    `db:"action"`      // login, buy_in, cash_out, etc.
-	EntityType string    `db:"entity_type"` // account, wallet, game, etc.
-	EntityID   uuid.UUID `db:"entity_id"`
-	OldValue   []byte    `db:"old_value"` // JSONB
-	NewValue   []byte    `db:"new_value"` // JSONB
-	IPAddress  string    `db:"ip_address"`
-	UserAgent  string    `db:"user_agent"`
-	CreatedAt  time.Time `db:"created_at"`
+	ID         int64      `db:"id"`
+	EntityType string     `db:"entity_type"` // account, wallet, game_session, etc.
+	EntityID   uuid.UUID  `db:"entity_id"`
+	Action     string     `db:"action"`     // login, buy_in, cash_out, etc.
+	Changes    []byte     `db:"changes"`    // JSONB: {before: {...}, after: {...}}
+	IPAddress  string     `db:"ip_address"` // 空字串表示無（系統操作）
+	UserAgent  string     `db:"user_agent"`
+	ActorID    *uuid.UUID `db:"actor_id"`   // 執行者帳號 ID，系統操作為 nil
+	ActorType  string     `db:"actor_type"` // user, system, admin
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// 審計日誌的執行者類型
+const (
+	AuditActorUser   = "user"
+	AuditActorSystem = "system"
+	AuditActorAdmin  = "admin"
+)
+
+// AuditLogFilter 審計日誌查詢條件（空值表示不限）
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   *uuid.UUID
+	ActorID    *uuid.UUID // 執行者帳號 ID
+	Action     string
+	From       *time.Time // 起始時間（含）
+	To         *time.Time // 結束時間（不含）
+	Limit      int
+	Offset     int
+}
+
+// WalletAdjustment 代表管理員錢包調整（對應 wallet_adjustments 表）
+type WalletAdjustment struct {
+	ID             uuid.UUID       `db:"id"`
+	PlayerID       uuid.UUID       `db:"player_id"`
+	Currency       string          `db:"currency"`
+	Type           TransactionType `db:"type"`   // deposit, withdraw, bonus, refund
+	Amount         int64           `db:"amount"` // 正數（分），方向由 Type 決定
+	ReasonCode     string          `db:"reason_code"`
+	Note           string          `db:"note"`
+	IdempotencyKey string          `db:"idempotency_key"` // 呼叫端提供
+	Status         string          `db:"status"`          // pending, completed, rejected
+	RequestedBy    uuid.UUID       `db:"requested_by"`    // 申請的管理員玩家 ID
+	ReviewedBy     *uuid.UUID      `db:"reviewed_by"`     // 核准/駁回的管理員，免審核時為 nil
+	ReviewNote     string          `db:"review_note"`
+	CreatedAt      time.Time       `db:"created_at"`
+	ReviewedAt     *time.Time      `db:"reviewed_at"`
+}
+
+// 錢包調整狀態
+const (
+	AdjustmentStatusPending   = "pending"
+	AdjustmentStatusCompleted = "completed"
+	AdjustmentStatusRejected  = "rejected"
+)
+
+// AuthSession 代表登入會話（對應 sessions 表）
+type AuthSession struct {
+	ID                string     `db:"id"`
+	PlayerID          uuid.UUID  `db:"player_id"`
+	Data              []byte     `db:"data"`                // JSONB: {username}
+	RefreshTokenHash  string     `db:"refresh_token_hash"`  // 目前 refresh token 的 SHA-256（hex）
+	PreviousTokenHash string     `db:"previous_token_hash"` // 上一個 refresh token，用於偵測重放
+	IPAddress         string     `db:"ip_address"`          // 最後使用的 IP
+	UserAgent         string     `db:"user_agent"`
+	ExpiresAt         time.Time  `db:"expires_at"`
+	LastUsedAt        *time.Time `db:"last_used_at"`
+	RevokedAt         *time.Time `db:"revoked_at"`
+	RevokedReason     string     `db:"revoked_reason"` // logout, logout_all, revoked_by_user, password_changed, refresh_token_reused 等
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
+
+// IsActive 會話未撤銷且未過期
+func (s *AuthSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AccountToken 代表帳號一次性 Token（電子郵件驗證、密碼重設）
+type AccountToken struct {
+	ID        uuid.UUID  `db:"id"`
+	AccountID uuid.UUID  `db:"account_id"`
+	Purpose   string     `db:"purpose"`    // email_verification, password_reset
+	TokenHash string     `db:"token_hash"` // token 的 SHA-256（hex），明文不落地
+	Email     string     `db:"email"`      // 簽發時的信箱
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// 帳號 Token 用途
+const (
+	AccountTokenEmailVerification = "email_verification"
+	AccountTokenPasswordReset     = "password_reset"
+)
+
+// AccountTOTP 代表帳號的 TOTP 兩步驟驗證設定
+type AccountTOTP struct {
+	AccountID    uuid.UUID  `db:"account_id"`
+	Secret       string     `db:"secret"`         // Base32 金鑰
+	ConfirmedAt  *time.Time `db:"confirmed_at"`   // 綁定完成時間，nil 表示尚未啟用
+	LastUsedStep int64      `db:"last_used_step"` // 最後使用的時間步長（防重放）
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+}
+
+// IsEnabled 兩步驟驗證是否已啟用
+func (t *AccountTOTP) IsEnabled() bool {
+	return t != nil && t.ConfirmedAt != nil
 }
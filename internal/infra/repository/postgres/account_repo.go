@@ -63,7 +63,7 @@ func (r *AccountRepo) Create(ctx context.Context, account *repository.Account) e
 func (r *AccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Account, error) {
 	query := `
 		SELECT 
-			id, username, email, password_hash, status, 
+			id, username, email, password_hash, status, roles,
 			email_verified, failed_login_attempts, locked_until,
 			last_login_at, last_login_ip, created_at, updated_at
 		FROM accounts
@@ -77,6 +77,7 @@ func (r *AccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Ac
 		&account.Email,
 		&account.PasswordHash,
 		&account.Status,
+		&account.Roles,
 		&account.EmailVerified,
 		&account.FailedLoginAttempts,
 		&account.LockedUntil,
@@ -100,7 +101,7 @@ func (r *AccountRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.Ac
 func (r *AccountRepo) GetByUsername(ctx context.Context, username string) (*repository.Account, error) {
 	query := `
 		SELECT 
-			id, username, email, password_hash, status, 
+			id, username, email, password_hash, status, roles,
 			email_verified, failed_login_attempts, locked_until,
 			last_login_at, last_login_ip, created_at, updated_at
 		FROM accounts
@@ -114,6 +115,7 @@ func (r *AccountRepo) GetByUsername(ctx context.Context, username string) (*repo
 		&account.Email,
 		&account.PasswordHash,
 		&account.Status,
+		&account.Roles,
 		&account.EmailVerified,
 		&account.FailedLoginAttempts,
 		&account.LockedUntil,
@@ -137,7 +139,7 @@ func (r *AccountRepo) GetByUsername(ctx context.Context, username string) (*repo
 func (r *AccountRepo) GetByEmail(ctx context.Context, email string) (*repository.Account, error) {
 	query := `
 		SELECT 
-			id, username, email, password_hash, status, 
+			id, username, email, password_hash, status, roles,
 			email_verified, failed_login_attempts, locked_until,
 			last_login_at, last_login_ip, created_at, updated_at
 		FROM accounts
@@ -151,6 +153,7 @@ func (r *AccountRepo) GetByEmail(ctx context.Context, email string) (*repository
 		&account.Email,
 		&account.PasswordHash,
 		&account.Status,
+		&account.Roles,
 		&account.EmailVerified,
 		&account.FailedLoginAttempts,
 		&account.LockedUntil,
@@ -206,6 +209,51 @@ func (r *AccountRepo) Update(ctx context.Context, account *repository.Account) e
 	return nil
 }
 
+// UpdateStatus 更新帳號狀態（active、suspended、banned）
+func (r *AccountRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	query := `
+		UPDATE accounts SET
+			status = $2,
+			updated_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, status, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("account not found")
+	}
+
+	return nil
+}
+
+// UpdateRoles 更新帳號角色
+func (r *AccountRepo) UpdateRoles(ctx context.Context, id uuid.UUID, roles []string) error {
+	query := `
+		UPDATE accounts SET
+			roles = $2,
+			updated_at = $3
+		WHERE id = $1
+	`
+
+	if roles == nil {
+		roles = []string{}
+	}
+	result, err := r.pool.Exec(ctx, query, id, roles, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update account roles: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("account not found")
+	}
+
+	return nil
+}
+
 // UpdateLastLogin 更新最後登入時間和 IP
 func (r *AccountRepo) UpdateLastLogin(ctx context.Context, id uuid.UUID, ip string) error {
 	query := `
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// AccountTokenRepo 實作 repository.AccountTokenRepository
+type AccountTokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAccountTokenRepository 創建新的 AccountToken Repository
+func NewAccountTokenRepository(pool *pgxpool.Pool) repository.AccountTokenRepository {
+	return &AccountTokenRepo{pool: pool}
+}
+
+// Create 建立 Token，並使同一帳號同用途的未使用 Token 失效
+func (r *AccountTokenRepo) Create(ctx context.Context, token *repository.AccountToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE account_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, token.AccountID, token.Purpose)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO account_tokens (id, account_id, purpose, token_hash, email, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		token.ID,
+		token.AccountID,
+		token.Purpose,
+		token.TokenHash,
+		token.Email,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create account token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Consume 取用未使用且未過期的 Token 並標記為已使用，不存在時回傳 nil, nil
+func (r *AccountTokenRepo) Consume(ctx context.Context, tokenHash, purpose string) (*repository.AccountToken, error) {
+	token := &repository.AccountToken{}
+	err := r.pool.QueryRow(ctx, `
+		UPDATE account_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, account_id, purpose, token_hash, email, expires_at, used_at, created_at
+	`, tokenHash, purpose).Scan(
+		&token.ID,
+		&token.AccountID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume account token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// AuditLogRepo 實作 repository.AuditLogRepository
+type AuditLogRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditLogRepository 創建新的 AuditLog Repository
+func NewAuditLogRepository(pool *pgxpool.Pool) repository.AuditLogRepository {
+	return &AuditLogRepo{pool: pool}
+}
+
+// Create 寫入一筆審計日誌
+func (r *AuditLogRepo) Create(ctx context.Context, log *repository.AuditLog) error {
+	return r.insert(ctx, r.pool.QueryRow, log)
+}
+
+// CreateWithTx 在事務中寫入審計日誌
+func (r *AuditLogRepo) CreateWithTx(ctx context.Context, tx repository.Transaction, log *repository.AuditLog) error {
+	return r.insert(ctx, tx.(*PgTransaction).GetTx().QueryRow, log)
+}
+
+// insert 以指定的連線（連接池或事務）寫入審計日誌
+func (r *AuditLogRepo) insert(ctx context.Context, queryRow func(ctx context.Context, sql string, args ...any) pgx.Row, log *repository.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (
+			entity_type, entity_id, action, changes,
+			ip_address, user_agent, actor_id, actor_type, created_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, '')::inet, NULLIF($6, ''), $7, $8, $9
+		)
+		RETURNING id
+	`
+
+	if log.ActorType == "" {
+		log.ActorType = repository.AuditActorSystem
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
+	err := queryRow(ctx, query,
+		log.EntityType,
+		log.EntityID,
+		log.Action,
+		log.Changes,
+		log.IPAddress,
+		log.UserAgent,
+		log.ActorID,
+		log.ActorType,
+		log.CreatedAt,
+	).Scan(&log.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// auditLogFilterClause 審計日誌查詢條件（參數 $1-$6）
+const auditLogFilterClause = `
+	WHERE ($1 = '' OR entity_type = $1)
+		AND ($2::uuid IS NULL OR entity_id = $2)
+		AND ($3::uuid IS NULL OR actor_id = $3)
+		AND ($4 = '' OR action = $4)
+		AND ($5::timestamptz IS NULL OR created_at >= $5)
+		AND ($6::timestamptz IS NULL OR created_at < $6)
+`
+
+// List 依條件查詢審計日誌（分頁，新到舊），同時回傳符合條件的總筆數
+func (r *AuditLogRepo) List(ctx context.Context, filter repository.AuditLogFilter) ([]*repository.AuditLog, int64, error) {
+	query := `
+		SELECT
+			id, entity_type, entity_id, action, changes,
+			COALESCE(host(ip_address), ''), COALESCE(user_agent, ''),
+			actor_id, actor_type, created_at,
+			COUNT(*) OVER ()
+		FROM audit_logs
+	` + auditLogFilterClause + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	args := []any{filter.EntityType, filter.EntityID, filter.ActorID, filter.Action, filter.From, filter.To}
+	rows, err := r.pool.Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*repository.AuditLog
+	var total int64
+	for rows.Next() {
+		log := &repository.AuditLog{}
+		err := rows.Scan(
+			&log.ID,
+			&log.EntityType,
+			&log.EntityID,
+			&log.Action,
+			&log.Changes,
+			&log.IPAddress,
+			&log.UserAgent,
+			&log.ActorID,
+			&log.ActorType,
+			&log.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	// 超出最後一頁時視窗函數沒有資料列，另外計算總筆數
+	if len(logs) == 0 && filter.Offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM audit_logs` + auditLogFilterClause
+		if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+		}
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+const authSessionColumns = `
+	id, player_id, data, COALESCE(refresh_token_hash, ''), COALESCE(previous_token_hash, ''),
+	COALESCE(host(ip_address), ''), COALESCE(user_agent, ''),
+	expires_at, last_used_at, revoked_at, COALESCE(revoked_reason, ''),
+	created_at, updated_at
+`
+
+// AuthSessionRepo 實作 repository.AuthSessionRepository
+type AuthSessionRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuthSessionRepository 創建新的 AuthSession Repository
+func NewAuthSessionRepository(pool *pgxpool.Pool) repository.AuthSessionRepository {
+	return &AuthSessionRepo{pool: pool}
+}
+
+// Create 建立登入會話
+func (r *AuthSessionRepo) Create(ctx context.Context, session *repository.AuthSession) error {
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+	if session.Data == nil {
+		session.Data = []byte("{}")
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO sessions (
+			id, player_id, data, refresh_token_hash, ip_address, user_agent,
+			expires_at, last_used_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, '')::inet, NULLIF($6, ''), $7, $8, $9, $10
+		)
+	`,
+		session.ID,
+		session.PlayerID,
+		session.Data,
+		session.RefreshTokenHash,
+		session.IPAddress,
+		session.UserAgent,
+		session.ExpiresAt,
+		session.LastUsedAt,
+		session.CreatedAt,
+		session.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根據 ID 查詢（不存在時回傳 nil, nil）
+func (r *AuthSessionRepo) GetByID(ctx context.Context, id string) (*repository.AuthSession, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+authSessionColumns+` FROM sessions WHERE id = $1`, id)
+	return scanAuthSession(row)
+}
+
+// GetByTokenHash 以目前或上一個 refresh token 雜湊查詢（不存在時回傳 nil, nil）
+func (r *AuthSessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*repository.AuthSession, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+authSessionColumns+`
+		FROM sessions
+		WHERE refresh_token_hash = $1 OR previous_token_hash = $1
+		LIMIT 1
+	`, tokenHash)
+	return scanAuthSession(row)
+}
+
+// Rotate 以 currentHash 為條件輪替 refresh token，會話已撤銷或 token 已被輪替時回傳 false
+func (r *AuthSessionRepo) Rotate(ctx context.Context, session *repository.AuthSession, currentHash string) (bool, error) {
+	session.UpdatedAt = time.Now()
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE sessions SET
+			refresh_token_hash = $2,
+			previous_token_hash = $3,
+			ip_address = NULLIF($4, '')::inet,
+			user_agent = NULLIF($5, ''),
+			expires_at = $6,
+			last_used_at = $7,
+			updated_at = $8
+		WHERE id = $1 AND refresh_token_hash = $3 AND revoked_at IS NULL
+	`,
+		session.ID,
+		session.RefreshTokenHash,
+		currentHash,
+		session.IPAddress,
+		session.UserAgent,
+		session.ExpiresAt,
+		session.LastUsedAt,
+		session.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Revoke 撤銷會話，已撤銷時回傳 false
+func (r *AuthSessionRepo) Revoke(ctx context.Context, id, reason string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2, updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeAllByPlayer 撤銷玩家所有未撤銷的會話，回傳撤銷筆數
+func (r *AuthSessionRepo) RevokeAllByPlayer(ctx context.Context, playerID uuid.UUID, reason string) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2, updated_at = NOW()
+		WHERE player_id = $1 AND revoked_at IS NULL
+	`, playerID, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke player sessions: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// ListActiveByPlayer 列出玩家未撤銷且未過期的會話（最近使用的在前）
+func (r *AuthSessionRepo) ListActiveByPlayer(ctx context.Context, playerID uuid.UUID) ([]*repository.AuthSession, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+authSessionColumns+`
+		FROM sessions
+		WHERE player_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY COALESCE(last_used_at, created_at) DESC
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*repository.AuthSession
+	for rows.Next() {
+		session, err := scanAuthSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// scanAuthSession 掃描單筆會話，查無資料時回傳 nil, nil
+func scanAuthSession(row pgx.Row) (*repository.AuthSession, error) {
+	session := &repository.AuthSession{}
+	err := row.Scan(
+		&session.ID,
+		&session.PlayerID,
+		&session.Data,
+		&session.RefreshTokenHash,
+		&session.PreviousTokenHash,
+		&session.IPAddress,
+		&session.UserAgent,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&session.RevokedAt,
+		&session.RevokedReason,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan session: %w", err)
+	}
+	return session, nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// HandHistoryRepo 實作 repository.HandHistoryRepository
+type HandHistoryRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewHandHistoryRepository 創建新的 HandHistory Repository
+func NewHandHistoryRepository(pool *pgxpool.Pool) repository.HandHistoryRepository {
+	return &HandHistoryRepo{pool: pool}
+}
+
+const handHistoryColumns = `
+	id, table_id, hand_number, dealer_pos, small_blind, big_blind,
+	players, actions, pots, community_cards, deck, winners,
+	rake_amount, started_at, ended_at, duration_seconds, created_at
+`
+
+// Create 創建手牌歷史記錄
+// 同一手牌（相同 ID）重複寫入時忽略，保證異步重試的冪等性
+func (r *HandHistoryRepo) Create(ctx context.Context, history *repository.HandHistory) error {
+	query := `
+		INSERT INTO hand_history (
+			id, table_id, hand_number, dealer_pos, small_blind, big_blind,
+			players, actions, pots, community_cards, deck, winners,
+			rake_amount, started_at, ended_at, duration_seconds, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+		)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	if history.ID == uuid.Nil {
+		history.ID = uuid.New()
+	}
+	history.CreatedAt = time.Now()
+	if history.Deck == nil {
+		history.Deck = []byte(`[]`)
+	}
+
+	_, err := r.pool.Exec(ctx, query,
+		history.ID,
+		history.TableID,
+		history.HandNumber,
+		history.DealerPos,
+		history.SmallBlind,
+		history.BigBlind,
+		history.PlayersData,
+		history.Actions,
+		history.Pots,
+		history.CommunityCards,
+		history.Deck,
+		history.Winners,
+		history.RakeAmount,
+		history.StartedAt,
+		history.EndedAt,
+		history.Duration,
+		history.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create hand history: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根據 ID 查詢手牌歷史
+func (r *HandHistoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.HandHistory, error) {
+	query := `SELECT ` + handHistoryColumns + ` FROM hand_history WHERE id = $1`
+
+	history, err := scanHandHistory(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("hand history not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get hand history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetByGameSessionID 根據遊戲會話 ID 查詢手牌歷史
+// 會話 ID 存在 players JSONB 中，透過 GIN 索引做包含查詢
+func (r *HandHistoryRepo) GetByGameSessionID(ctx context.Context, sessionID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	query := `SELECT ` + handHistoryColumns + `
+		FROM hand_history
+		WHERE players @> jsonb_build_array(jsonb_build_object('game_session_id', $1::text))
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryList(ctx, query, sessionID.String(), limit, offset)
+}
+
+// GetByPlayerID 根據玩家 ID 查詢手牌歷史
+func (r *HandHistoryRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID, limit, offset int) ([]*repository.HandHistory, error) {
+	query := `SELECT ` + handHistoryColumns + `
+		FROM hand_history
+		WHERE players @> jsonb_build_array(jsonb_build_object('player_id', $1::text))
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryList(ctx, query, playerID.String(), limit, offset)
+}
+
+// GetByTableID 根據桌子 ID 查詢手牌歷史
+func (r *HandHistoryRepo) GetByTableID(ctx context.Context, tableID string, limit, offset int) ([]*repository.HandHistory, error) {
+	query := `SELECT ` + handHistoryColumns + `
+		FROM hand_history
+		WHERE table_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryList(ctx, query, tableID, limit, offset)
+}
+
+// queryList 執行分頁查詢並掃描結果
+func (r *HandHistoryRepo) queryList(ctx context.Context, query string, args ...interface{}) ([]*repository.HandHistory, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hand history: %w", err)
+	}
+	defer rows.Close()
+
+	var histories []*repository.HandHistory
+	for rows.Next() {
+		history, err := scanHandHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hand history: %w", err)
+		}
+		histories = append(histories, history)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return histories, nil
+}
+
+// scanHandHistory 掃描單筆手牌歷史
+func scanHandHistory(row pgx.Row) (*repository.HandHistory, error) {
+	history := &repository.HandHistory{}
+	var duration *int
+	err := row.Scan(
+		&history.ID,
+		&history.TableID,
+		&history.HandNumber,
+		&history.DealerPos,
+		&history.SmallBlind,
+		&history.BigBlind,
+		&history.PlayersData,
+		&history.Actions,
+		&history.Pots,
+		&history.CommunityCards,
+		&history.Deck,
+		&history.Winners,
+		&history.RakeAmount,
+		&history.StartedAt,
+		&history.EndedAt,
+		&duration,
+		&history.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if duration != nil {
+		history.Duration = *duration
+	}
+	return history, nil
+}
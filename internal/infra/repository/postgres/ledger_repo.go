@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// LedgerRepo 實作 repository.LedgerRepository
+type LedgerRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewLedgerRepository 創建新的 Ledger Repository
+func NewLedgerRepository(pool *pgxpool.Pool) repository.LedgerRepository {
+	return &LedgerRepo{pool: pool}
+}
+
+// Transfer 在事務中記錄一筆借貸平衡的轉帳
+func (r *LedgerRepo) Transfer(ctx context.Context, tx repository.Transaction, from, to repository.LedgerAccount, amount int64, referenceID, description string) error {
+	pgTx := tx.(*PgTransaction).GetTx()
+	return postTransfer(ctx, pgTx, from, to, amount, nil, referenceID, description)
+}
+
+// GetAccountBalances 彙總所有帳戶的分錄
+func (r *LedgerRepo) GetAccountBalances(ctx context.Context) ([]*repository.LedgerAccountBalance, error) {
+	query := `
+		SELECT
+			account_type, player_id, currency,
+			COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0),
+			COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0)
+		FROM ledger_entries
+		GROUP BY currency, account_type, player_id
+		ORDER BY currency, account_type, player_id
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*repository.LedgerAccountBalance
+	for rows.Next() {
+		balance := &repository.LedgerAccountBalance{}
+		err := rows.Scan(
+			&balance.Account.Type,
+			&balance.Account.PlayerID,
+			&balance.Account.Currency,
+			&balance.Increases,
+			&balance.Decreases,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return balances, nil
+}
+
+// GetUnbalancedJournals 查詢金額合計不為 0 的分錄組
+func (r *LedgerRepo) GetUnbalancedJournals(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT journal_id
+		FROM ledger_entries
+		GROUP BY journal_id
+		HAVING SUM(amount) <> 0
+		ORDER BY journal_id
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unbalanced journals: %w", err)
+	}
+	defer rows.Close()
+
+	var journals []uuid.UUID
+	for rows.Next() {
+		var journalID uuid.UUID
+		if err := rows.Scan(&journalID); err != nil {
+			return nil, fmt.Errorf("failed to scan journal id: %w", err)
+		}
+		journals = append(journals, journalID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return journals, nil
+}
+
+// postTransfer 寫入一組借貸平衡的分錄：from 減少 amount、to 增加 amount
+// 必須在與資金異動相同的事務中呼叫，借貸平衡由資料庫在提交時再次檢查
+func postTransfer(ctx context.Context, pgTx pgx.Tx, from, to repository.LedgerAccount, amount int64, transactionID *uuid.UUID, referenceID, description string) error {
+	if amount <= 0 {
+		return fmt.Errorf("ledger transfer amount must be positive")
+	}
+	if from.Currency == "" || from.Currency != to.Currency {
+		return fmt.Errorf("ledger transfer %s -> %s must use a single currency", from, to)
+	}
+
+	query := `
+		INSERT INTO ledger_entries (
+			journal_id, account_type, player_id, amount,
+			transaction_id, reference_id, description, created_at, currency
+		) VALUES
+			($1, $2, $3, $4, $8, $9, $10, $11, $12),
+			($1, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	var ref *string
+	if referenceID != "" {
+		ref = &referenceID
+	}
+
+	_, err := pgTx.Exec(ctx, query,
+		uuid.New(),
+		from.Type, from.PlayerID, -amount,
+		to.Type, to.PlayerID, amount,
+		transactionID,
+		ref,
+		description,
+		time.Now(),
+		from.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to post ledger transfer %s -> %s: %w", from, to, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// RakeRepo 實作 repository.RakeRepository
+type RakeRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewRakeRepository 創建新的 Rake Repository
+func NewRakeRepository(pool *pgxpool.Pool) repository.RakeRepository {
+	return &RakeRepo{pool: pool}
+}
+
+// Record 在事務中記錄一手牌的抽水收入與玩家分攤
+// 以 hand_id 做冪等：收入已存在時整筆略過，不重複寫入分攤
+func (r *RakeRepo) Record(ctx context.Context, tx repository.Transaction, revenue *repository.HouseRevenue, contributions []*repository.RakeContribution) error {
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	if revenue.ID == uuid.Nil {
+		revenue.ID = uuid.New()
+	}
+	if revenue.CreatedAt.IsZero() {
+		revenue.CreatedAt = time.Now()
+	}
+
+	tag, err := pgTx.Exec(ctx, `
+		INSERT INTO house_revenue (id, hand_id, table_id, currency, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (hand_id) DO NOTHING
+	`, revenue.ID, revenue.HandID, revenue.TableID, revenue.Currency, revenue.Amount, revenue.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record house revenue: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil // 已記錄過
+	}
+
+	// 抽水由牌局清算帳戶轉入平台收入
+	err = postTransfer(ctx, pgTx,
+		repository.HouseAccount(repository.LedgerGamePool, revenue.Currency),
+		repository.HouseAccount(repository.LedgerHouseRake, revenue.Currency),
+		revenue.Amount, nil, revenue.HandID.String(), "Rake")
+	if err != nil {
+		return err
+	}
+
+	for _, c := range contributions {
+		c.HandID = revenue.HandID
+		c.CreatedAt = revenue.CreatedAt
+		_, err := pgTx.Exec(ctx, `
+			INSERT INTO rake_contributions (hand_id, player_id, amount, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (hand_id, player_id) DO NOTHING
+		`, c.HandID, c.PlayerID, c.Amount, c.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record rake contribution: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetPlayerSummary 統計玩家在 [from, to) 期間於指定幣別牌桌分攤的抽水
+func (r *RakeRepo) GetPlayerSummary(ctx context.Context, playerID uuid.UUID, currency string, from, to time.Time) (*repository.RakeSummary, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(c.amount), 0)
+		FROM rake_contributions c
+		INNER JOIN house_revenue h ON h.hand_id = c.hand_id
+		WHERE c.player_id = $1 AND h.currency = $2 AND c.created_at >= $3 AND c.created_at < $4
+	`
+
+	summary := &repository.RakeSummary{PlayerID: playerID}
+	if err := r.pool.QueryRow(ctx, query, playerID, currency, from, to).Scan(&summary.Hands, &summary.TotalRake); err != nil {
+		return nil, fmt.Errorf("failed to get rake summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetRevenueTotal 統計 [from, to) 期間指定幣別的抽水收入總額
+func (r *RakeRepo) GetRevenueTotal(ctx context.Context, currency string, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM house_revenue
+		WHERE currency = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, query, currency, from, to).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get revenue total: %w", err)
+	}
+
+	return total, nil
+}
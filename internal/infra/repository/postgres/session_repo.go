@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shinjuwu/TheNuts/internal/infra/repository"
 )
@@ -17,6 +18,9 @@ type GameSessionRepo struct {
 	pool *pgxpool.Pool
 }
 
+// execFunc 连接池或事务的 Exec
+type execFunc func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+
 // NewGameSessionRepository 创建新的 GameSession Repository
 func NewGameSessionRepository(pool *pgxpool.Pool) repository.GameSessionRepository {
 	return &GameSessionRepo{pool: pool}
@@ -24,12 +28,22 @@ func NewGameSessionRepository(pool *pgxpool.Pool) repository.GameSessionReposito
 
 // Create 创建新游戏会话
 func (r *GameSessionRepo) Create(ctx context.Context, session *repository.GameSession) error {
+	return r.create(ctx, r.pool.Exec, session)
+}
+
+// CreateWithTx 在事务中创建游戏会话
+func (r *GameSessionRepo) CreateWithTx(ctx context.Context, tx repository.Transaction, session *repository.GameSession) error {
+	return r.create(ctx, tx.(*PgTransaction).GetTx().Exec, session)
+}
+
+// create 以指定的连接（连接池或事务）写入会话
+func (r *GameSessionRepo) create(ctx context.Context, exec execFunc, session *repository.GameSession) error {
 	query := `
 		INSERT INTO game_sessions (
-			id, player_id, game_type, table_id, buy_in_amount,
+			id, player_id, game_type, table_id, currency, buy_in_amount,
 			current_chips, status, started_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
@@ -41,11 +55,12 @@ func (r *GameSessionRepo) Create(ctx context.Context, session *repository.GameSe
 		session.ID = uuid.New()
 	}
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err := exec(ctx, query,
 		session.ID,
 		session.PlayerID,
 		session.GameType,
 		session.TableID,
+		session.Currency,
 		session.BuyInAmount,
 		session.CurrentChips,
 		session.Status,
@@ -65,7 +80,7 @@ func (r *GameSessionRepo) Create(ctx context.Context, session *repository.GameSe
 func (r *GameSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.GameSession, error) {
 	query := `
 		SELECT 
-			id, player_id, game_type, table_id, buy_in_amount,
+			id, player_id, game_type, table_id, currency, buy_in_amount,
 			current_chips, status, started_at, ended_at,
 			created_at, updated_at
 		FROM game_sessions
@@ -78,6 +93,7 @@ func (r *GameSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repositor
 		&session.PlayerID,
 		&session.GameType,
 		&session.TableID,
+		&session.Currency,
 		&session.BuyInAmount,
 		&session.CurrentChips,
 		&session.Status,
@@ -101,7 +117,7 @@ func (r *GameSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repositor
 func (r *GameSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid.UUID) (*repository.GameSession, error) {
 	query := `
 		SELECT 
-			id, player_id, game_type, table_id, buy_in_amount,
+			id, player_id, game_type, table_id, currency, buy_in_amount,
 			current_chips, status, started_at, ended_at,
 			created_at, updated_at
 		FROM game_sessions
@@ -116,6 +132,7 @@ func (r *GameSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid
 		&session.PlayerID,
 		&session.GameType,
 		&session.TableID,
+		&session.Currency,
 		&session.BuyInAmount,
 		&session.CurrentChips,
 		&session.Status,
@@ -135,6 +152,54 @@ func (r *GameSessionRepo) GetActiveByPlayerID(ctx context.Context, playerID uuid
 	return session, nil
 }
 
+// ListActive 查询所有活跃的会话
+func (r *GameSessionRepo) ListActive(ctx context.Context) ([]*repository.GameSession, error) {
+	query := `
+		SELECT 
+			id, player_id, game_type, table_id, currency, buy_in_amount,
+			current_chips, status, started_at, ended_at,
+			created_at, updated_at
+		FROM game_sessions
+		WHERE status = 'active'
+		ORDER BY started_at
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*repository.GameSession
+	for rows.Next() {
+		session := &repository.GameSession{}
+		err := rows.Scan(
+			&session.ID,
+			&session.PlayerID,
+			&session.GameType,
+			&session.TableID,
+			&session.Currency,
+			&session.BuyInAmount,
+			&session.CurrentChips,
+			&session.Status,
+			&session.StartedAt,
+			&session.EndedAt,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sessions, nil
+}
+
 // Update 更新会话信息
 func (r *GameSessionRepo) Update(ctx context.Context, session *repository.GameSession) error {
 	query := `
@@ -167,17 +232,27 @@ func (r *GameSessionRepo) Update(ctx context.Context, session *repository.GameSe
 
 // End 结束会话
 func (r *GameSessionRepo) End(ctx context.Context, id uuid.UUID, finalChips int64) error {
+	return r.end(ctx, r.pool.Exec, id, finalChips)
+}
+
+// EndWithTx 在事务中结束会话
+func (r *GameSessionRepo) EndWithTx(ctx context.Context, tx repository.Transaction, id uuid.UUID, finalChips int64) error {
+	return r.end(ctx, tx.(*PgTransaction).GetTx().Exec, id, finalChips)
+}
+
+// end 以指定的连接（连接池或事务）结束会话
+func (r *GameSessionRepo) end(ctx context.Context, exec execFunc, id uuid.UUID, finalChips int64) error {
 	query := `
 		UPDATE game_sessions SET
 			current_chips = $2,
-			status = 'ended',
+			status = 'completed',
 			ended_at = $3,
 			updated_at = $3
 		WHERE id = $1
 	`
 
 	now := time.Now()
-	result, err := r.pool.Exec(ctx, query, id, finalChips, now)
+	result, err := exec(ctx, query, id, finalChips, now)
 	if err != nil {
 		return fmt.Errorf("failed to end game session: %w", err)
 	}
@@ -188,3 +263,102 @@ func (r *GameSessionRepo) End(ctx context.Context, id uuid.UUID, finalChips int6
 
 	return nil
 }
+
+// ListByPlayer 依条件查询玩家的会话（分页，新到旧）
+func (r *GameSessionRepo) ListByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSession, error) {
+	query := `
+		SELECT 
+			id, player_id, game_type, table_id, currency, buy_in_amount,
+			current_chips, status, started_at, ended_at,
+			created_at, updated_at
+		FROM game_sessions
+		WHERE player_id = $1
+			AND ($2 = '' OR currency = $2)
+			AND ($3::timestamptz IS NULL OR started_at >= $3)
+			AND ($4::timestamptz IS NULL OR started_at < $4)
+		ORDER BY started_at DESC, id
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.pool.Query(ctx, query, filter.PlayerID, filter.Currency, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*repository.GameSession
+	for rows.Next() {
+		session := &repository.GameSession{}
+		err := rows.Scan(
+			&session.ID,
+			&session.PlayerID,
+			&session.GameType,
+			&session.TableID,
+			&session.Currency,
+			&session.BuyInAmount,
+			&session.CurrentChips,
+			&session.Status,
+			&session.StartedAt,
+			&session.EndedAt,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// SummarizeByPlayer 按币别汇总玩家在条件期间内的会话
+func (r *GameSessionRepo) SummarizeByPlayer(ctx context.Context, filter repository.GameSessionFilter) ([]*repository.GameSessionSummary, error) {
+	query := `
+		SELECT
+			currency,
+			COUNT(*),
+			COALESCE(SUM(buy_in_amount), 0),
+			COALESCE(SUM(current_chips) FILTER (WHERE status <> 'active'), 0),
+			COALESCE(SUM(current_chips - buy_in_amount) FILTER (WHERE status <> 'active'), 0)
+		FROM game_sessions
+		WHERE player_id = $1
+			AND ($2 = '' OR currency = $2)
+			AND ($3::timestamptz IS NULL OR started_at >= $3)
+			AND ($4::timestamptz IS NULL OR started_at < $4)
+		GROUP BY currency
+		ORDER BY currency
+	`
+
+	rows, err := r.pool.Query(ctx, query, filter.PlayerID, filter.Currency, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize game sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*repository.GameSessionSummary
+	for rows.Next() {
+		summary := &repository.GameSessionSummary{}
+		err := rows.Scan(
+			&summary.Currency,
+			&summary.Sessions,
+			&summary.TotalBuyIn,
+			&summary.TotalCashOut,
+			&summary.NetProfit,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// TableRecoveryRepo 實作 repository.TableRecoveryRepository
+type TableRecoveryRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewTableRecoveryRepository 創建新的 TableRecovery Repository
+func NewTableRecoveryRepository(pool *pgxpool.Pool) repository.TableRecoveryRepository {
+	return &TableRecoveryRepo{pool: pool}
+}
+
+// SaveSnapshot 保存牌桌快照（以 table_id upsert）
+func (r *TableRecoveryRepo) SaveSnapshot(ctx context.Context, snapshot *repository.TableSnapshot) error {
+	query := `
+		INSERT INTO table_snapshots (
+			table_id, hand_number, dealer_pos, hand_in_progress, hand_id, players, taken_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+		ON CONFLICT (table_id) DO UPDATE SET
+			hand_number = EXCLUDED.hand_number,
+			dealer_pos = EXCLUDED.dealer_pos,
+			hand_in_progress = EXCLUDED.hand_in_progress,
+			hand_id = EXCLUDED.hand_id,
+			players = EXCLUDED.players,
+			taken_at = EXCLUDED.taken_at
+		WHERE table_snapshots.taken_at <= EXCLUDED.taken_at
+	`
+
+	if snapshot.TakenAt.IsZero() {
+		snapshot.TakenAt = time.Now()
+	}
+	if snapshot.Players == nil {
+		snapshot.Players = []byte(`[]`)
+	}
+
+	_, err := r.pool.Exec(ctx, query,
+		snapshot.TableID,
+		snapshot.HandNumber,
+		snapshot.DealerPos,
+		snapshot.HandInProgress,
+		snapshot.HandID,
+		snapshot.Players,
+		snapshot.TakenAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save table snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ListSnapshots 查詢所有牌桌快照
+func (r *TableRecoveryRepo) ListSnapshots(ctx context.Context) ([]*repository.TableSnapshot, error) {
+	query := `
+		SELECT table_id, hand_number, dealer_pos, hand_in_progress, hand_id, players, taken_at
+		FROM table_snapshots
+		ORDER BY table_id
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*repository.TableSnapshot
+	for rows.Next() {
+		snapshot := &repository.TableSnapshot{}
+		err := rows.Scan(
+			&snapshot.TableID,
+			&snapshot.HandNumber,
+			&snapshot.DealerPos,
+			&snapshot.HandInProgress,
+			&snapshot.HandID,
+			&snapshot.Players,
+			&snapshot.TakenAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteSnapshot 刪除牌桌快照
+func (r *TableRecoveryRepo) DeleteSnapshot(ctx context.Context, tableID string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM table_snapshots WHERE table_id = $1`, tableID); err != nil {
+		return fmt.Errorf("failed to delete table snapshot: %w", err)
+	}
+	return nil
+}
+
+// AppendAction 附加一筆手牌動作到日誌
+func (r *TableRecoveryRepo) AppendAction(ctx context.Context, entry *repository.TableActionLog) error {
+	query := `
+		INSERT INTO table_action_log (
+			table_id, hand_id, player_id, street, action, amount, total_bet, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+		RETURNING id
+	`
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	err := r.pool.QueryRow(ctx, query,
+		entry.TableID,
+		entry.HandID,
+		entry.PlayerID,
+		entry.Street,
+		entry.Action,
+		entry.Amount,
+		entry.TotalBet,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to append table action: %w", err)
+	}
+
+	return nil
+}
+
+// GetActionsByHand 查詢手牌的動作日誌（按寫入順序）
+func (r *TableRecoveryRepo) GetActionsByHand(ctx context.Context, handID uuid.UUID) ([]*repository.TableActionLog, error) {
+	query := `
+		SELECT id, table_id, hand_id, player_id, street, action, amount, total_bet, created_at
+		FROM table_action_log
+		WHERE hand_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.pool.Query(ctx, query, handID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table actions: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*repository.TableActionLog
+	for rows.Next() {
+		entry := &repository.TableActionLog{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TableID,
+			&entry.HandID,
+			&entry.PlayerID,
+			&entry.Street,
+			&entry.Action,
+			&entry.Amount,
+			&entry.TotalBet,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table action: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteActionsByHand 刪除手牌的動作日誌
+func (r *TableRecoveryRepo) DeleteActionsByHand(ctx context.Context, handID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM table_action_log WHERE hand_id = $1`, handID); err != nil {
+		return fmt.Errorf("failed to delete table actions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository/postgres"
+)
+
+// TestHandHistoryRepository 測試手牌歷史的寫入與查詢
+func TestHandHistoryRepository(t *testing.T) {
+	db, _, _, _, _, _ := setupTest(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	historyRepo := postgres.NewHandHistoryRepository(db.Pool)
+
+	playerID := uuid.New()
+	sessionID := uuid.New()
+	tableID := fmt.Sprintf("test_table_%d", time.Now().UnixNano())
+
+	players, _ := json.Marshal([]repository.HandHistoryPlayer{
+		{PlayerID: playerID.String(), GameSessionID: sessionID.String(), Seat: 0, StartingChips: 1000, EndingChips: 1120},
+		{PlayerID: uuid.New().String(), Seat: 1, StartingChips: 1000, EndingChips: 880},
+	})
+	endedAt := time.Now()
+	history := &repository.HandHistory{
+		ID:             uuid.New(),
+		TableID:        tableID,
+		HandNumber:     1,
+		SmallBlind:     10,
+		BigBlind:       20,
+		PlayersData:    players,
+		Actions:        []byte(`[]`),
+		Pots:           []byte(`[{"amount":240}]`),
+		CommunityCards: []byte(`["Ac","Kd","7h","2s","9c"]`),
+		Deck:           []byte(`[]`),
+		Winners:        []byte(`[{"amount":240}]`),
+		StartedAt:      endedAt.Add(-30 * time.Second),
+		EndedAt:        &endedAt,
+		Duration:       30,
+	}
+
+	defer func() {
+		if _, err := db.Pool.Exec(ctx, "DELETE FROM hand_history WHERE table_id = $1", tableID); err != nil {
+			t.Logf("Cleanup warning: %v", err)
+		}
+	}()
+
+	if err := historyRepo.Create(ctx, history); err != nil {
+		t.Fatalf("Failed to create hand history: %v", err)
+	}
+
+	// 重複寫入同一手牌應被忽略
+	if err := historyRepo.Create(ctx, history); err != nil {
+		t.Fatalf("Duplicate create should be ignored, got: %v", err)
+	}
+
+	got, err := historyRepo.GetByID(ctx, history.ID)
+	if err != nil {
+		t.Fatalf("Failed to get hand history: %v", err)
+	}
+	if got.TableID != tableID || got.HandNumber != 1 || got.Duration != 30 {
+		t.Errorf("Unexpected hand history: %+v", got)
+	}
+
+	byTable, err := historyRepo.GetByTableID(ctx, tableID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get by table: %v", err)
+	}
+	if len(byTable) != 1 {
+		t.Errorf("Expected 1 hand for table, got %d", len(byTable))
+	}
+
+	byPlayer, err := historyRepo.GetByPlayerID(ctx, playerID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get by player: %v", err)
+	}
+	if len(byPlayer) != 1 {
+		t.Errorf("Expected 1 hand for player, got %d", len(byPlayer))
+	}
+
+	bySession, err := historyRepo.GetByGameSessionID(ctx, sessionID, 10, 0)
+	if err != nil {
+		t.Fatalf("Failed to get by session: %v", err)
+	}
+	if len(bySession) != 1 {
+		t.Errorf("Expected 1 hand for session, got %d", len(bySession))
+	}
+}
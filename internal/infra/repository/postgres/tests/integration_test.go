@@ -60,6 +60,7 @@ func cleanupTest(t *testing.T, db *database.PostgresDB, playerID uuid.UUID) {
 
 	// 刪除測試資料（按外鍵依賴順序）
 	queries := []string{
+		"DELETE FROM ledger_entries WHERE journal_id IN (SELECT journal_id FROM ledger_entries WHERE player_id = $1)",
 		"DELETE FROM transactions WHERE wallet_id IN (SELECT id FROM wallets WHERE player_id = $1)",
 		"DELETE FROM wallets WHERE player_id = $1",
 		"DELETE FROM players WHERE id = $1",
@@ -146,14 +147,14 @@ func TestFullUserFlow(t *testing.T) {
 	buyInAmount := int64(10000) // $100.00 in cents
 
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Credit(ctx, tx, player.ID, buyInAmount, repository.TransactionTypeBuyIn, "Buy-in $100", "buyin_001")
+		return walletRepo.Credit(ctx, tx, player.ID, "USD", buyInAmount, repository.TransactionTypeBuyIn, "Buy-in $100", "buyin_001")
 	})
 	if err != nil {
 		t.Fatalf("Failed to buy-in: %v", err)
 	}
 
 	// 驗證餘額
-	walletAfterBuyIn, err := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterBuyIn, err := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	if err != nil {
 		t.Fatalf("Failed to get wallet: %v", err)
 	}
@@ -168,13 +169,13 @@ func TestFullUserFlow(t *testing.T) {
 	winAmount := int64(5000) // $50.00
 
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Credit(ctx, tx, player.ID, winAmount, repository.TransactionTypeWin, "Won a hand", "")
+		return walletRepo.Credit(ctx, tx, player.ID, "USD", winAmount, repository.TransactionTypeWin, "Won a hand", "")
 	})
 	if err != nil {
 		t.Fatalf("Failed to credit winnings: %v", err)
 	}
 
-	walletAfterWin, err := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterWin, err := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	if err != nil {
 		t.Fatalf("Failed to get wallet: %v", err)
 	}
@@ -190,13 +191,13 @@ func TestFullUserFlow(t *testing.T) {
 	lossAmount := int64(3000) // $30.00
 
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Debit(ctx, tx, player.ID, lossAmount, repository.TransactionTypeLoss, "Lost a hand", "")
+		return walletRepo.Debit(ctx, tx, player.ID, "USD", lossAmount, repository.TransactionTypeLoss, "Lost a hand", "")
 	})
 	if err != nil {
 		t.Fatalf("Failed to debit loss: %v", err)
 	}
 
-	walletAfterLoss, err := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterLoss, err := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	if err != nil {
 		t.Fatalf("Failed to get wallet: %v", err)
 	}
@@ -212,13 +213,13 @@ func TestFullUserFlow(t *testing.T) {
 	cashOutAmount := int64(12000) // $120.00
 
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Debit(ctx, tx, player.ID, cashOutAmount, repository.TransactionTypeCashOut, "Cash-out $120", "cashout_001")
+		return walletRepo.Debit(ctx, tx, player.ID, "USD", cashOutAmount, repository.TransactionTypeCashOut, "Cash-out $120", "cashout_001")
 	})
 	if err != nil {
 		t.Fatalf("Failed to cash-out: %v", err)
 	}
 
-	walletFinal, err := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletFinal, err := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	if err != nil {
 		t.Fatalf("Failed to get wallet: %v", err)
 	}
@@ -287,7 +288,7 @@ func TestInsufficientBalance(t *testing.T) {
 	// 嘗試扣除超過餘額的金額
 	t.Log("=== Testing Insufficient Balance ===")
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Debit(ctx, tx, player.ID, 2000, repository.TransactionTypeLoss, "Try to debit $20", "")
+		return walletRepo.Debit(ctx, tx, player.ID, "USD", 2000, repository.TransactionTypeLoss, "Try to debit $20", "")
 	})
 
 	if err == nil {
@@ -345,24 +346,24 @@ func TestIdempotency(t *testing.T) {
 
 	// 第一次買入
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Credit(ctx, tx, player.ID, amount, repository.TransactionTypeBuyIn, "Buy-in $100", idempotencyKey)
+		return walletRepo.Credit(ctx, tx, player.ID, "USD", amount, repository.TransactionTypeBuyIn, "Buy-in $100", idempotencyKey)
 	})
 	if err != nil {
 		t.Fatalf("First buy-in failed: %v", err)
 	}
 
-	walletAfterFirst, _ := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterFirst, _ := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	t.Logf("After first buy-in: $%.2f", float64(walletAfterFirst.Balance)/100)
 
 	// 第二次買入（使用相同的冪等性鍵）
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.Credit(ctx, tx, player.ID, amount, repository.TransactionTypeBuyIn, "Buy-in $100 (duplicate)", idempotencyKey)
+		return walletRepo.Credit(ctx, tx, player.ID, "USD", amount, repository.TransactionTypeBuyIn, "Buy-in $100 (duplicate)", idempotencyKey)
 	})
 	if err != nil {
 		t.Fatalf("Second buy-in failed: %v", err)
 	}
 
-	walletAfterSecond, _ := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterSecond, _ := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	t.Logf("After second buy-in: $%.2f", float64(walletAfterSecond.Balance)/100)
 
 	// 驗證餘額只增加了一次
@@ -430,7 +431,7 @@ func TestConcurrentTransactions(t *testing.T) {
 		go func(idx int) {
 			defer wg.Done()
 			err := uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-				return walletRepo.Debit(ctx, tx, player.ID, debitAmount, repository.TransactionTypeLoss, fmt.Sprintf("Concurrent debit #%d", idx), "")
+				return walletRepo.Debit(ctx, tx, player.ID, "USD", debitAmount, repository.TransactionTypeLoss, fmt.Sprintf("Concurrent debit #%d", idx), "")
 			})
 			if err != nil {
 				errors <- err
@@ -447,7 +448,7 @@ func TestConcurrentTransactions(t *testing.T) {
 	}
 
 	// 驗證最終餘額
-	walletFinal, _ := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletFinal, _ := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	expectedBalance := wallet.Balance - (debitAmount * int64(concurrency))
 	t.Logf("Final balance: $%.2f", float64(walletFinal.Balance)/100)
 	t.Logf("Expected balance: $%.2f", float64(expectedBalance)/100)
@@ -506,13 +507,13 @@ func TestLockAndUnlockBalance(t *testing.T) {
 
 	// 鎖定餘額
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.LockBalance(ctx, tx, player.ID, lockAmount)
+		return walletRepo.LockBalance(ctx, tx, player.ID, "USD", lockAmount, nil, repository.TransactionTypeBuyIn, "Escrow test", "")
 	})
 	if err != nil {
 		t.Fatalf("Failed to lock balance: %v", err)
 	}
 
-	walletAfterLock, _ := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterLock, _ := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	t.Logf("After lock - Balance: $%.2f, Locked: $%.2f",
 		float64(walletAfterLock.Balance)/100,
 		float64(walletAfterLock.LockedBalance)/100)
@@ -524,13 +525,13 @@ func TestLockAndUnlockBalance(t *testing.T) {
 
 	// 解鎖餘額
 	err = uow.WithTransaction(ctx, func(tx repository.Transaction) error {
-		return walletRepo.UnlockBalance(ctx, tx, player.ID, lockAmount)
+		return walletRepo.UnlockBalance(ctx, tx, player.ID, "USD", lockAmount, nil, repository.TransactionTypeCashOut, "Escrow test", "")
 	})
 	if err != nil {
 		t.Fatalf("Failed to unlock balance: %v", err)
 	}
 
-	walletAfterUnlock, _ := walletRepo.GetByPlayerID(ctx, player.ID)
+	walletAfterUnlock, _ := walletRepo.GetByPlayerID(ctx, player.ID, "USD")
 	t.Logf("After unlock - Balance: $%.2f, Locked: $%.2f",
 		float64(walletAfterUnlock.Balance)/100,
 		float64(walletAfterUnlock.LockedBalance)/100)
@@ -542,3 +543,79 @@ func TestLockAndUnlockBalance(t *testing.T) {
 
 	t.Logf("✅ Lock/Unlock works correctly!")
 }
+
+// TestMultiCurrencyWallets 測試同一玩家的多幣別錢包互不影響
+func TestMultiCurrencyWallets(t *testing.T) {
+	db, uow, _, accountRepo, playerRepo, walletRepo := setupTest(t)
+	ctx := context.Background()
+
+	timestamp := time.Now().UnixNano()
+	account := &repository.Account{
+		ID:           uuid.New(),
+		Username:     fmt.Sprintf("testuser_fx_%d", timestamp),
+		Email:        fmt.Sprintf("test_fx_%d@example.com", timestamp),
+		PasswordHash: "hash",
+		Status:       "active",
+	}
+	if err := accountRepo.Create(ctx, account); err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+
+	player := &repository.Player{
+		ID:          uuid.New(),
+		AccountID:   account.ID,
+		DisplayName: "Test Player FX",
+		Level:       1,
+	}
+	if err := playerRepo.Create(ctx, player); err != nil {
+		t.Fatalf("Failed to create player: %v", err)
+	}
+
+	defer cleanupTest(t, db, player.ID)
+
+	for _, w := range []*repository.Wallet{
+		{ID: uuid.New(), PlayerID: player.ID, Balance: 10000, Currency: "USD"},
+		{ID: uuid.New(), PlayerID: player.ID, Balance: 50000, Currency: "PLAY"},
+	} {
+		if err := walletRepo.Create(ctx, w); err != nil {
+			t.Fatalf("Failed to create %s wallet: %v", w.Currency, err)
+		}
+	}
+
+	// 同一幣別不能重複開立
+	if err := walletRepo.Create(ctx, &repository.Wallet{ID: uuid.New(), PlayerID: player.ID, Currency: "PLAY"}); err == nil {
+		t.Error("Expected duplicate PLAY wallet to be rejected")
+	}
+
+	// 鎖定遊戲幣，不應影響 USD 錢包
+	err := uow.WithTransaction(ctx, func(tx repository.Transaction) error {
+		return walletRepo.LockBalance(ctx, tx, player.ID, "PLAY", 20000, nil, repository.TransactionTypeBuyIn, "Play-money buy-in", "")
+	})
+	if err != nil {
+		t.Fatalf("Failed to lock PLAY balance: %v", err)
+	}
+
+	wallets, err := walletRepo.ListByPlayerID(ctx, player.ID)
+	if err != nil {
+		t.Fatalf("Failed to list wallets: %v", err)
+	}
+	if len(wallets) != 2 {
+		t.Fatalf("Expected 2 wallets, got %d", len(wallets))
+	}
+	for _, w := range wallets {
+		switch w.Currency {
+		case "USD":
+			if w.Balance != 10000 || w.LockedBalance != 0 {
+				t.Errorf("USD wallet must be untouched, got balance=%d locked=%d", w.Balance, w.LockedBalance)
+			}
+		case "PLAY":
+			if w.Balance != 30000 || w.LockedBalance != 20000 {
+				t.Errorf("Expected PLAY balance=30000 locked=20000, got balance=%d locked=%d", w.Balance, w.LockedBalance)
+			}
+		default:
+			t.Errorf("Unexpected wallet currency %q", w.Currency)
+		}
+	}
+
+	t.Logf("✅ Multi-currency wallets are isolated!")
+}
@@ -32,9 +32,9 @@ func (r *TransactionRepo) CreateWithTx(ctx context.Context, executor interface{}
 	query := `
 		INSERT INTO transactions (
 			id, wallet_id, type, amount, balance_before, balance_after,
-			description, idempotency_key, game_session_id, created_at
+			description, idempotency_key, game_session_id, reference_id, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
@@ -58,6 +58,7 @@ func (r *TransactionRepo) CreateWithTx(ctx context.Context, executor interface{}
 			transaction.Description,
 			transaction.IdempotencyKey,
 			transaction.GameSessionID,
+			transaction.ReferenceID,
 			transaction.CreatedAt,
 		)
 	case pgx.Tx:
@@ -71,6 +72,7 @@ func (r *TransactionRepo) CreateWithTx(ctx context.Context, executor interface{}
 			transaction.Description,
 			transaction.IdempotencyKey,
 			transaction.GameSessionID,
+			transaction.ReferenceID,
 			transaction.CreatedAt,
 		)
 	default:
@@ -89,7 +91,7 @@ func (r *TransactionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repositor
 	query := `
 		SELECT 
 			id, wallet_id, type, amount, balance_before, balance_after,
-			description, idempotency_key, game_session_id, created_at
+			description, idempotency_key, game_session_id, reference_id, created_at
 		FROM transactions
 		WHERE id = $1
 	`
@@ -105,6 +107,7 @@ func (r *TransactionRepo) GetByID(ctx context.Context, id uuid.UUID) (*repositor
 		&tx.Description,
 		&tx.IdempotencyKey,
 		&tx.GameSessionID,
+		&tx.ReferenceID,
 		&tx.CreatedAt,
 	)
 
@@ -123,7 +126,7 @@ func (r *TransactionRepo) GetByWalletID(ctx context.Context, walletID uuid.UUID,
 	query := `
 		SELECT 
 			id, wallet_id, type, amount, balance_before, balance_after,
-			description, idempotency_key, game_session_id, created_at
+			description, idempotency_key, game_session_id, reference_id, created_at
 		FROM transactions
 		WHERE wallet_id = $1
 		ORDER BY created_at DESC
@@ -149,6 +152,7 @@ func (r *TransactionRepo) GetByWalletID(ctx context.Context, walletID uuid.UUID,
 			&tx.Description,
 			&tx.IdempotencyKey,
 			&tx.GameSessionID,
+			&tx.ReferenceID,
 			&tx.CreatedAt,
 		)
 		if err != nil {
@@ -179,7 +183,7 @@ func (r *TransactionRepo) getByIdempotencyKeyWithExecutor(ctx context.Context, e
 	query := `
 		SELECT 
 			id, wallet_id, type, amount, balance_before, balance_after,
-			description, idempotency_key, game_session_id, created_at
+			description, idempotency_key, game_session_id, reference_id, created_at
 		FROM transactions
 		WHERE idempotency_key = $1
 	`
@@ -199,6 +203,7 @@ func (r *TransactionRepo) getByIdempotencyKeyWithExecutor(ctx context.Context, e
 			&tx.Description,
 			&tx.IdempotencyKey,
 			&tx.GameSessionID,
+			&tx.ReferenceID,
 			&tx.CreatedAt,
 		)
 	case pgx.Tx:
@@ -212,6 +217,7 @@ func (r *TransactionRepo) getByIdempotencyKeyWithExecutor(ctx context.Context, e
 			&tx.Description,
 			&tx.IdempotencyKey,
 			&tx.GameSessionID,
+			&tx.ReferenceID,
 			&tx.CreatedAt,
 		)
 	default:
@@ -233,7 +239,7 @@ func (r *TransactionRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID,
 	query := `
 		SELECT 
 			t.id, t.wallet_id, t.type, t.amount, t.balance_before, t.balance_after,
-			t.description, t.idempotency_key, t.game_session_id, t.created_at
+			t.description, t.idempotency_key, t.game_session_id, t.reference_id, t.created_at
 		FROM transactions t
 		INNER JOIN wallets w ON w.id = t.wallet_id
 		WHERE w.player_id = $1
@@ -260,6 +266,7 @@ func (r *TransactionRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID,
 			&tx.Description,
 			&tx.IdempotencyKey,
 			&tx.GameSessionID,
+			&tx.ReferenceID,
 			&tx.CreatedAt,
 		)
 		if err != nil {
@@ -274,3 +281,124 @@ func (r *TransactionRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID,
 
 	return transactions, nil
 }
+
+// GetByGameSessionID 根據遊戲會話 ID 查詢交易記錄（按時間順序）
+func (r *TransactionRepo) GetByGameSessionID(ctx context.Context, sessionID uuid.UUID) ([]*repository.WalletTransaction, error) {
+	query := `
+		SELECT 
+			id, wallet_id, type, amount, balance_before, balance_after,
+			description, idempotency_key, game_session_id, reference_id, created_at
+		FROM transactions
+		WHERE game_session_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*repository.WalletTransaction
+	for rows.Next() {
+		tx := &repository.WalletTransaction{}
+		err := rows.Scan(
+			&tx.ID,
+			&tx.WalletID,
+			&tx.Type,
+			&tx.Amount,
+			&tx.BalanceBefore,
+			&tx.BalanceAfter,
+			&tx.Description,
+			&tx.IdempotencyKey,
+			&tx.GameSessionID,
+			&tx.ReferenceID,
+			&tx.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// ListByPlayer 依條件查詢玩家的交易記錄（分頁，新到舊），同時回傳符合條件的總筆數
+func (r *TransactionRepo) ListByPlayer(ctx context.Context, filter repository.TransactionFilter) ([]*repository.WalletTransaction, int64, error) {
+	query := `
+		SELECT 
+			t.id, t.wallet_id, t.type, t.amount, t.balance_before, t.balance_after,
+			t.description, t.idempotency_key, t.game_session_id, t.reference_id, t.created_at,
+			COUNT(*) OVER ()
+		FROM transactions t
+		INNER JOIN wallets w ON w.id = t.wallet_id
+		WHERE w.player_id = $1
+			AND ($2::text[] IS NULL OR t.type = ANY($2))
+			AND ($3::timestamptz IS NULL OR t.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR t.created_at < $4)
+		ORDER BY t.created_at DESC, t.id
+		LIMIT $5 OFFSET $6
+	`
+
+	var types []string
+	for _, txType := range filter.Types {
+		types = append(types, string(txType))
+	}
+
+	rows, err := r.pool.Query(ctx, query, filter.PlayerID, types, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*repository.WalletTransaction
+	var total int64
+	for rows.Next() {
+		tx := &repository.WalletTransaction{}
+		err := rows.Scan(
+			&tx.ID,
+			&tx.WalletID,
+			&tx.Type,
+			&tx.Amount,
+			&tx.BalanceBefore,
+			&tx.BalanceAfter,
+			&tx.Description,
+			&tx.IdempotencyKey,
+			&tx.GameSessionID,
+			&tx.ReferenceID,
+			&tx.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	// 超出最後一頁時視窗函數沒有資料列，另外計算總筆數
+	if len(transactions) == 0 && filter.Offset > 0 {
+		countQuery := `
+			SELECT COUNT(*)
+			FROM transactions t
+			INNER JOIN wallets w ON w.id = t.wallet_id
+			WHERE w.player_id = $1
+				AND ($2::text[] IS NULL OR t.type = ANY($2))
+				AND ($3::timestamptz IS NULL OR t.created_at >= $3)
+				AND ($4::timestamptz IS NULL OR t.created_at < $4)
+		`
+		if err := r.pool.QueryRow(ctx, countQuery, filter.PlayerID, types, filter.From, filter.To).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
+		}
+	}
+
+	return transactions, total, nil
+}
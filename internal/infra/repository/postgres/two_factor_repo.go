@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+// TwoFactorRepo 實作 repository.TwoFactorRepository
+type TwoFactorRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewTwoFactorRepository 創建新的 TwoFactor Repository
+func NewTwoFactorRepository(pool *pgxpool.Pool) repository.TwoFactorRepository {
+	return &TwoFactorRepo{pool: pool}
+}
+
+// GetByAccountID 查詢帳號的 TOTP 設定（不存在時回傳 nil, nil）
+func (r *TwoFactorRepo) GetByAccountID(ctx context.Context, accountID uuid.UUID) (*repository.AccountTOTP, error) {
+	totp := &repository.AccountTOTP{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT account_id, secret, confirmed_at, last_used_step, created_at, updated_at
+		FROM account_totp
+		WHERE account_id = $1
+	`, accountID).Scan(
+		&totp.AccountID,
+		&totp.Secret,
+		&totp.ConfirmedAt,
+		&totp.LastUsedStep,
+		&totp.CreatedAt,
+		&totp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+	return totp, nil
+}
+
+// SavePending 保存綁定中的金鑰，已啟用時不覆蓋並回傳 false
+func (r *TwoFactorRepo) SavePending(ctx context.Context, totp *repository.AccountTOTP) (bool, error) {
+	now := time.Now()
+	totp.CreatedAt = now
+	totp.UpdatedAt = now
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO account_totp (account_id, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (account_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			last_used_step = 0,
+			updated_at = EXCLUDED.updated_at
+		WHERE account_totp.confirmed_at IS NULL
+	`, totp.AccountID, totp.Secret, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to save totp secret: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Enable 啟用 TOTP 並替換恢復碼
+func (r *TwoFactorRepo) Enable(ctx context.Context, accountID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE account_totp SET confirmed_at = NOW(), last_used_step = $2, updated_at = NOW()
+		WHERE account_id = $1 AND confirmed_at IS NULL
+	`, accountID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("totp enrollment not found")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, accountID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UseStep 以 last_used_step 為條件記錄時間步長，重放時回傳 false
+func (r *TwoFactorRepo) UseStep(ctx context.Context, accountID uuid.UUID, step int64) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE account_totp SET last_used_step = $2, updated_at = NOW()
+		WHERE account_id = $1 AND last_used_step < $2
+	`, accountID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes 以新的恢復碼取代所有舊恢復碼
+func (r *TwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, accountID uuid.UUID, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, accountID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode 使用恢復碼，不存在或已使用時回傳 false
+func (r *TwoFactorRepo) ConsumeRecoveryCode(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE account_recovery_codes SET used_at = NOW()
+		WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, accountID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Delete 刪除金鑰與恢復碼
+func (r *TwoFactorRepo) Delete(ctx context.Context, accountID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM account_recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM account_totp WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 在事務中刪除舊恢復碼並寫入新恢復碼
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM account_recovery_codes WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO account_recovery_codes (account_id, code_hash) VALUES ($1, $2)
+		`, accountID, hash)
+		if err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shinjuwu/TheNuts/internal/infra/repository"
+)
+
+const walletAdjustmentColumns = `
+	id, player_id, currency, type, amount, reason_code, note,
+	idempotency_key, status, requested_by, reviewed_by, review_note,
+	created_at, reviewed_at
+`
+
+// WalletAdjustmentRepo 實作 repository.WalletAdjustmentRepository
+type WalletAdjustmentRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewWalletAdjustmentRepository 創建新的 WalletAdjustment Repository
+func NewWalletAdjustmentRepository(pool *pgxpool.Pool) repository.WalletAdjustmentRepository {
+	return &WalletAdjustmentRepo{pool: pool}
+}
+
+// Create 在事務中寫入調整申請，冪等性鍵衝突時不寫入並回傳 false
+func (r *WalletAdjustmentRepo) Create(ctx context.Context, tx repository.Transaction, adjustment *repository.WalletAdjustment) (bool, error) {
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	if adjustment.ID == uuid.Nil {
+		adjustment.ID = uuid.New()
+	}
+	if adjustment.Status == "" {
+		adjustment.Status = repository.AdjustmentStatusPending
+	}
+	if adjustment.CreatedAt.IsZero() {
+		adjustment.CreatedAt = time.Now()
+	}
+
+	tag, err := pgTx.Exec(ctx, `
+		INSERT INTO wallet_adjustments (`+walletAdjustmentColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`,
+		adjustment.ID,
+		adjustment.PlayerID,
+		adjustment.Currency,
+		adjustment.Type,
+		adjustment.Amount,
+		adjustment.ReasonCode,
+		adjustment.Note,
+		adjustment.IdempotencyKey,
+		adjustment.Status,
+		adjustment.RequestedBy,
+		adjustment.ReviewedBy,
+		adjustment.ReviewNote,
+		adjustment.CreatedAt,
+		adjustment.ReviewedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create wallet adjustment: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetByID 根據 ID 查詢（不存在時回傳 nil, nil）
+func (r *WalletAdjustmentRepo) GetByID(ctx context.Context, id uuid.UUID) (*repository.WalletAdjustment, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+walletAdjustmentColumns+` FROM wallet_adjustments WHERE id = $1`, id)
+	return scanWalletAdjustment(row)
+}
+
+// GetByIdempotencyKey 根據冪等性鍵查詢（不存在時回傳 nil, nil）
+func (r *WalletAdjustmentRepo) GetByIdempotencyKey(ctx context.Context, key string) (*repository.WalletAdjustment, error) {
+	row := r.pool.QueryRow(ctx, `SELECT `+walletAdjustmentColumns+` FROM wallet_adjustments WHERE idempotency_key = $1`, key)
+	return scanWalletAdjustment(row)
+}
+
+// GetForUpdate 在事務中查詢並鎖定（不存在時回傳 nil, nil）
+func (r *WalletAdjustmentRepo) GetForUpdate(ctx context.Context, tx repository.Transaction, id uuid.UUID) (*repository.WalletAdjustment, error) {
+	pgTx := tx.(*PgTransaction).GetTx()
+	row := pgTx.QueryRow(ctx, `SELECT `+walletAdjustmentColumns+` FROM wallet_adjustments WHERE id = $1 FOR UPDATE`, id)
+	return scanWalletAdjustment(row)
+}
+
+// UpdateReview 在事務中記錄審核結果（只能更新待審核的申請）
+func (r *WalletAdjustmentRepo) UpdateReview(ctx context.Context, tx repository.Transaction, adjustment *repository.WalletAdjustment) error {
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	tag, err := pgTx.Exec(ctx, `
+		UPDATE wallet_adjustments SET
+			status = $2,
+			reviewed_by = $3,
+			review_note = $4,
+			reviewed_at = $5
+		WHERE id = $1 AND status = 'pending'
+	`,
+		adjustment.ID,
+		adjustment.Status,
+		adjustment.ReviewedBy,
+		adjustment.ReviewNote,
+		adjustment.ReviewedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update wallet adjustment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("wallet adjustment %s is not pending", adjustment.ID)
+	}
+
+	return nil
+}
+
+// SumSelfApproved 在事務中加總申請人自 since 起對同一玩家、同一幣別免審核完成的調整金額
+func (r *WalletAdjustmentRepo) SumSelfApproved(ctx context.Context, tx repository.Transaction, requestedBy, playerID uuid.UUID, currency string, since time.Time) (int64, error) {
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	var total int64
+	err := pgTx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM wallet_adjustments
+		WHERE requested_by = $1 AND player_id = $2 AND currency = $3
+		  AND status = 'completed' AND reviewed_by IS NULL AND created_at >= $4
+	`, requestedBy, playerID, currency, since).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum self-approved adjustments: %w", err)
+	}
+	return total, nil
+}
+
+// List 按狀態分頁查詢（status 為空表示全部，按建立時間倒序）
+func (r *WalletAdjustmentRepo) List(ctx context.Context, status string, limit, offset int) ([]*repository.WalletAdjustment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+walletAdjustmentColumns+`
+		FROM wallet_adjustments
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*repository.WalletAdjustment
+	for rows.Next() {
+		adjustment, err := scanWalletAdjustment(rows)
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// scanWalletAdjustment 掃描單筆錢包調整（查無資料時回傳 nil, nil）
+func scanWalletAdjustment(row pgx.Row) (*repository.WalletAdjustment, error) {
+	adjustment := &repository.WalletAdjustment{}
+	err := row.Scan(
+		&adjustment.ID,
+		&adjustment.PlayerID,
+		&adjustment.Currency,
+		&adjustment.Type,
+		&adjustment.Amount,
+		&adjustment.ReasonCode,
+		&adjustment.Note,
+		&adjustment.IdempotencyKey,
+		&adjustment.Status,
+		&adjustment.RequestedBy,
+		&adjustment.ReviewedBy,
+		&adjustment.ReviewNote,
+		&adjustment.CreatedAt,
+		&adjustment.ReviewedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to scan wallet adjustment: %w", err)
+	}
+	return adjustment, nil
+}
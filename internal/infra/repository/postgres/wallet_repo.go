@@ -50,7 +50,13 @@ func (r *WalletRepo) Create(ctx context.Context, wallet *repository.Wallet) erro
 		wallet.ID = uuid.New()
 	}
 
-	_, err := r.pool.Exec(ctx, query,
+	pgTx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer pgTx.Rollback(ctx)
+
+	_, err = pgTx.Exec(ctx, query,
 		wallet.ID,
 		wallet.PlayerID,
 		wallet.Balance,
@@ -65,21 +71,38 @@ func (r *WalletRepo) Create(ctx context.Context, wallet *repository.Wallet) erro
 		return fmt.Errorf("failed to create wallet: %w", err)
 	}
 
+	// 開戶贈送的初始餘額由平台獎金帳戶入帳
+	bonus := repository.HouseAccount(repository.LedgerBonusLiability, wallet.Currency)
+	if wallet.Balance > 0 {
+		if err := postTransfer(ctx, pgTx, bonus, repository.PlayerWalletAccount(wallet.PlayerID, wallet.Currency), wallet.Balance, nil, "", "Initial wallet balance"); err != nil {
+			return err
+		}
+	}
+	if wallet.LockedBalance > 0 {
+		if err := postTransfer(ctx, pgTx, bonus, repository.TableEscrowAccount(wallet.PlayerID, wallet.Currency), wallet.LockedBalance, nil, "", "Initial locked balance"); err != nil {
+			return err
+		}
+	}
+
+	if err := pgTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit wallet creation: %w", err)
+	}
+
 	return nil
 }
 
-// GetByPlayerID 根據玩家 ID 查詢錢包
-func (r *WalletRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID) (*repository.Wallet, error) {
+// GetByPlayerID 根據玩家 ID 與幣別查詢錢包
+func (r *WalletRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
 	query := `
 		SELECT 
 			id, player_id, balance, locked_balance, 
 			currency, version, created_at, updated_at
 		FROM wallets
-		WHERE player_id = $1
+		WHERE player_id = $1 AND currency = $2
 	`
 
 	wallet := &repository.Wallet{}
-	err := r.pool.QueryRow(ctx, query, playerID).Scan(
+	err := r.pool.QueryRow(ctx, query, playerID, currency).Scan(
 		&wallet.ID,
 		&wallet.PlayerID,
 		&wallet.Balance,
@@ -100,21 +123,44 @@ func (r *WalletRepo) GetByPlayerID(ctx context.Context, playerID uuid.UUID) (*re
 	return wallet, nil
 }
 
+// ListAll 查詢所有錢包
+func (r *WalletRepo) ListAll(ctx context.Context) ([]*repository.Wallet, error) {
+	return r.listWallets(ctx, `
+		SELECT 
+			id, player_id, balance, locked_balance, 
+			currency, version, created_at, updated_at
+		FROM wallets
+		ORDER BY player_id, currency
+	`)
+}
+
+// ListByPlayerID 查詢玩家的所有錢包（依幣別排序）
+func (r *WalletRepo) ListByPlayerID(ctx context.Context, playerID uuid.UUID) ([]*repository.Wallet, error) {
+	return r.listWallets(ctx, `
+		SELECT 
+			id, player_id, balance, locked_balance, 
+			currency, version, created_at, updated_at
+		FROM wallets
+		WHERE player_id = $1
+		ORDER BY currency
+	`, playerID)
+}
+
 // GetWithLock 使用行鎖查詢錢包（用於事務中）
-func (r *WalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction, playerID uuid.UUID) (*repository.Wallet, error) {
+func (r *WalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string) (*repository.Wallet, error) {
 	query := `
 		SELECT 
 			id, player_id, balance, locked_balance, 
 			currency, version, created_at, updated_at
 		FROM wallets
-		WHERE player_id = $1
+		WHERE player_id = $1 AND currency = $2
 		FOR UPDATE
 	`
 
 	pgTx := tx.(*PgTransaction).GetTx()
 
 	wallet := &repository.Wallet{}
-	err := pgTx.QueryRow(ctx, query, playerID).Scan(
+	err := pgTx.QueryRow(ctx, query, playerID, currency).Scan(
 		&wallet.ID,
 		&wallet.PlayerID,
 		&wallet.Balance,
@@ -136,13 +182,13 @@ func (r *WalletRepo) GetWithLock(ctx context.Context, tx repository.Transaction,
 }
 
 // Credit 入帳（加錢）
-func (r *WalletRepo) Credit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, amount int64, txType repository.TransactionType, description string, idempotencyKey string) error {
+func (r *WalletRepo) Credit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, txType repository.TransactionType, description string, idempotencyKey string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
 	// 鎖定錢包（必須先鎖定才檢查冪等性，避免 race condition）
-	wallet, err := r.GetWithLock(ctx, tx, playerID)
+	wallet, err := r.GetWithLock(ctx, tx, playerID, currency)
 	if err != nil {
 		return err
 	}
@@ -166,12 +212,12 @@ func (r *WalletRepo) Credit(ctx context.Context, tx repository.Transaction, play
 			balance = balance + $2,
 			version = version + 1,
 			updated_at = $3
-		WHERE player_id = $1 AND version = $4
+		WHERE id = $1 AND version = $4
 	`
 
 	pgTx := tx.(*PgTransaction).GetTx()
 	result, err := pgTx.Exec(ctx, updateQuery,
-		playerID,
+		wallet.ID,
 		amount,
 		time.Now(),
 		wallet.Version,
@@ -185,37 +231,18 @@ func (r *WalletRepo) Credit(ctx context.Context, tx repository.Transaction, play
 		return fmt.Errorf("wallet version conflict (optimistic lock)")
 	}
 
-	// 創建交易記錄
-	transaction := &repository.WalletTransaction{
-		ID:            uuid.New(),
-		WalletID:      wallet.ID,
-		Type:          txType,
-		Amount:        amount,
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  balanceBefore + amount,
-		Description:   description,
-		CreatedAt:     time.Now(),
-	}
-
-	if idempotencyKey != "" {
-		transaction.IdempotencyKey = &idempotencyKey
-	}
-
-	if err := r.txRepo.CreateWithTx(ctx, pgTx, transaction); err != nil {
-		return fmt.Errorf("failed to create transaction record: %w", err)
-	}
-
-	return nil
+	return r.recordTransaction(ctx, pgTx, wallet, txType, amount, balanceBefore+amount, nil, "", description, idempotencyKey,
+		counterAccount(txType, currency), repository.PlayerWalletAccount(playerID, currency))
 }
 
 // Debit 出帳（扣錢）
-func (r *WalletRepo) Debit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, amount int64, txType repository.TransactionType, description string, idempotencyKey string) error {
+func (r *WalletRepo) Debit(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, txType repository.TransactionType, description string, idempotencyKey string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
 	// 鎖定錢包（必須先鎖定才檢查冪等性，避免 race condition）
-	wallet, err := r.GetWithLock(ctx, tx, playerID)
+	wallet, err := r.GetWithLock(ctx, tx, playerID, currency)
 	if err != nil {
 		return err
 	}
@@ -244,12 +271,12 @@ func (r *WalletRepo) Debit(ctx context.Context, tx repository.Transaction, playe
 			balance = balance - $2,
 			version = version + 1,
 			updated_at = $3
-		WHERE player_id = $1 AND version = $4 AND balance >= $2
+		WHERE id = $1 AND version = $4 AND balance >= $2
 	`
 
 	pgTx := tx.(*PgTransaction).GetTx()
 	result, err := pgTx.Exec(ctx, updateQuery,
-		playerID,
+		wallet.ID,
 		amount,
 		time.Now(),
 		wallet.Version,
@@ -263,41 +290,34 @@ func (r *WalletRepo) Debit(ctx context.Context, tx repository.Transaction, playe
 		return fmt.Errorf("wallet version conflict or insufficient balance")
 	}
 
-	// 創建交易記錄
-	transaction := &repository.WalletTransaction{
-		ID:            uuid.New(),
-		WalletID:      wallet.ID,
-		Type:          txType,
-		Amount:        -amount, // 負數表示扣款
-		BalanceBefore: balanceBefore,
-		BalanceAfter:  balanceBefore - amount,
-		Description:   description,
-		CreatedAt:     time.Now(),
-	}
-
-	if idempotencyKey != "" {
-		transaction.IdempotencyKey = &idempotencyKey
-	}
-
-	if err := r.txRepo.CreateWithTx(ctx, pgTx, transaction); err != nil {
-		return fmt.Errorf("failed to create transaction record: %w", err)
-	}
-
-	return nil
+	// 負數表示扣款
+	return r.recordTransaction(ctx, pgTx, wallet, txType, -amount, balanceBefore-amount, nil, "", description, idempotencyKey,
+		repository.PlayerWalletAccount(playerID, currency), counterAccount(txType, currency))
 }
 
-// LockBalance 鎖定餘額（用於下注等場景）
-func (r *WalletRepo) LockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, amount int64) error {
+// LockBalance 鎖定餘額（買入時將資金移入託管）
+// 可用餘額轉入鎖定餘額，總餘額不變；交易記錄以可用餘額計算前後值
+func (r *WalletRepo) LockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType repository.TransactionType, description string, idempotencyKey string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
-	// 鎖定錢包
-	wallet, err := r.GetWithLock(ctx, tx, playerID)
+	// 鎖定錢包（必須先鎖定才檢查冪等性，避免 race condition）
+	wallet, err := r.GetWithLock(ctx, tx, playerID, currency)
 	if err != nil {
 		return err
 	}
 
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	// 檢查冪等性（在鎖定後檢查，確保唯一性）
+	if idempotencyKey != "" {
+		existing, err := r.txRepo.GetByIdempotencyKeyWithTx(ctx, pgTx, idempotencyKey)
+		if err == nil && existing != nil {
+			return nil
+		}
+	}
+
 	// 檢查餘額充足
 	if wallet.Balance < amount {
 		return fmt.Errorf("insufficient balance: have %d, need %d", wallet.Balance, amount)
@@ -310,12 +330,11 @@ func (r *WalletRepo) LockBalance(ctx context.Context, tx repository.Transaction,
 			locked_balance = locked_balance + $2,
 			version = version + 1,
 			updated_at = $3
-		WHERE player_id = $1 AND version = $4 AND balance >= $2
+		WHERE id = $1 AND version = $4 AND balance >= $2
 	`
 
-	pgTx := tx.(*PgTransaction).GetTx()
 	result, err := pgTx.Exec(ctx, updateQuery,
-		playerID,
+		wallet.ID,
 		amount,
 		time.Now(),
 		wallet.Version,
@@ -329,21 +348,32 @@ func (r *WalletRepo) LockBalance(ctx context.Context, tx repository.Transaction,
 		return fmt.Errorf("wallet version conflict or insufficient balance")
 	}
 
-	return nil
+	return r.recordTransaction(ctx, pgTx, wallet, txType, -amount, wallet.Balance-amount, gameSessionID, "", description, idempotencyKey,
+		repository.PlayerWalletAccount(playerID, currency), repository.TableEscrowAccount(playerID, currency))
 }
 
-// UnlockBalance 解鎖餘額（遊戲結束後）
-func (r *WalletRepo) UnlockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, amount int64) error {
+// UnlockBalance 解鎖餘額（兌現時將託管資金釋放回可用餘額）
+func (r *WalletRepo) UnlockBalance(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, txType repository.TransactionType, description string, idempotencyKey string) error {
 	if amount <= 0 {
 		return fmt.Errorf("amount must be positive")
 	}
 
-	// 鎖定錢包
-	wallet, err := r.GetWithLock(ctx, tx, playerID)
+	// 鎖定錢包（必須先鎖定才檢查冪等性，避免 race condition）
+	wallet, err := r.GetWithLock(ctx, tx, playerID, currency)
 	if err != nil {
 		return err
 	}
 
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	// 檢查冪等性（在鎖定後檢查，確保唯一性）
+	if idempotencyKey != "" {
+		existing, err := r.txRepo.GetByIdempotencyKeyWithTx(ctx, pgTx, idempotencyKey)
+		if err == nil && existing != nil {
+			return nil
+		}
+	}
+
 	// 檢查鎖定餘額充足
 	if wallet.LockedBalance < amount {
 		return fmt.Errorf("insufficient locked balance: have %d, need %d", wallet.LockedBalance, amount)
@@ -356,12 +386,11 @@ func (r *WalletRepo) UnlockBalance(ctx context.Context, tx repository.Transactio
 			locked_balance = locked_balance - $2,
 			version = version + 1,
 			updated_at = $3
-		WHERE player_id = $1 AND version = $4 AND locked_balance >= $2
+		WHERE id = $1 AND version = $4 AND locked_balance >= $2
 	`
 
-	pgTx := tx.(*PgTransaction).GetTx()
 	result, err := pgTx.Exec(ctx, updateQuery,
-		playerID,
+		wallet.ID,
 		amount,
 		time.Now(),
 		wallet.Version,
@@ -375,5 +404,165 @@ func (r *WalletRepo) UnlockBalance(ctx context.Context, tx repository.Transactio
 		return fmt.Errorf("wallet version conflict or insufficient locked balance")
 	}
 
-	return nil
+	return r.recordTransaction(ctx, pgTx, wallet, txType, amount, wallet.Balance+amount, gameSessionID, "", description, idempotencyKey,
+		repository.TableEscrowAccount(playerID, currency), repository.PlayerWalletAccount(playerID, currency))
+}
+
+// RecordGameResult 記錄單手牌的輸贏並調整託管中的鎖定餘額
+// 可用餘額不變（交易記錄的前後值相同），贏錢增加、輸錢減少鎖定餘額
+func (r *WalletRepo) RecordGameResult(ctx context.Context, tx repository.Transaction, playerID uuid.UUID, currency string, amount int64, gameSessionID *uuid.UUID, referenceID string, description string, idempotencyKey string) error {
+	if amount == 0 {
+		return fmt.Errorf("amount must not be zero")
+	}
+
+	// 鎖定錢包（必須先鎖定才檢查冪等性，避免 race condition）
+	wallet, err := r.GetWithLock(ctx, tx, playerID, currency)
+	if err != nil {
+		return err
+	}
+
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	// 檢查冪等性（在鎖定後檢查，確保唯一性）
+	if idempotencyKey != "" {
+		existing, err := r.txRepo.GetByIdempotencyKeyWithTx(ctx, pgTx, idempotencyKey)
+		if err == nil && existing != nil {
+			// 分錄已存在，直接返回（冪等性保證）
+			return nil
+		}
+	}
+
+	if wallet.LockedBalance+amount < 0 {
+		return fmt.Errorf("insufficient locked balance: have %d, need %d", wallet.LockedBalance, -amount)
+	}
+
+	updateQuery := `
+		UPDATE wallets SET
+			locked_balance = locked_balance + $2,
+			version = version + 1,
+			updated_at = $3
+		WHERE id = $1 AND version = $4 AND locked_balance + $2 >= 0
+	`
+
+	result, err := pgTx.Exec(ctx, updateQuery,
+		wallet.ID,
+		amount,
+		time.Now(),
+		wallet.Version,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to adjust locked balance: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("wallet version conflict or insufficient locked balance")
+	}
+
+	// 輸贏的對手方為牌局清算帳戶
+	txType := repository.TransactionTypeWin
+	from, to := repository.HouseAccount(repository.LedgerGamePool, currency), repository.TableEscrowAccount(playerID, currency)
+	if amount < 0 {
+		txType = repository.TransactionTypeLoss
+		from, to = to, from
+	}
+
+	return r.recordTransaction(ctx, pgTx, wallet, txType, amount, wallet.Balance, gameSessionID, referenceID, description, idempotencyKey, from, to)
+}
+
+// SumGameResultsBySession 加總遊戲會話已記錄的輸贏分錄（用於計算託管金額）
+func (r *WalletRepo) SumGameResultsBySession(ctx context.Context, tx repository.Transaction, sessionID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE game_session_id = $1 AND type IN ($2, $3)
+	`
+
+	pgTx := tx.(*PgTransaction).GetTx()
+
+	var total int64
+	err := pgTx.QueryRow(ctx, query, sessionID, repository.TransactionTypeWin, repository.TransactionTypeLoss).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum game results: %w", err)
+	}
+
+	return total, nil
+}
+
+// recordTransaction 寫入一筆交易記錄（balanceAfter 為可用餘額），並記錄 from -> to 的複式分錄
+func (r *WalletRepo) recordTransaction(ctx context.Context, pgTx pgx.Tx, wallet *repository.Wallet, txType repository.TransactionType, amount, balanceAfter int64, gameSessionID *uuid.UUID, referenceID, description, idempotencyKey string, from, to repository.LedgerAccount) error {
+	transaction := &repository.WalletTransaction{
+		ID:            uuid.New(),
+		WalletID:      wallet.ID,
+		Type:          txType,
+		Amount:        amount,
+		BalanceBefore: wallet.Balance,
+		BalanceAfter:  balanceAfter,
+		Description:   description,
+		GameSessionID: gameSessionID,
+		CreatedAt:     time.Now(),
+	}
+
+	if referenceID != "" {
+		transaction.ReferenceID = &referenceID
+	}
+	if idempotencyKey != "" {
+		transaction.IdempotencyKey = &idempotencyKey
+	}
+
+	if err := r.txRepo.CreateWithTx(ctx, pgTx, transaction); err != nil {
+		return fmt.Errorf("failed to create transaction record: %w", err)
+	}
+
+	if amount < 0 {
+		amount = -amount
+	}
+	return postTransfer(ctx, pgTx, from, to, amount, &transaction.ID, referenceID, description)
+}
+
+// counterAccount 回傳錢包入帳/出帳類型對應的對手帳戶
+func counterAccount(txType repository.TransactionType, currency string) repository.LedgerAccount {
+	switch txType {
+	case repository.TransactionTypeBonus:
+		return repository.HouseAccount(repository.LedgerBonusLiability, currency)
+	case repository.TransactionTypeWin, repository.TransactionTypeLoss:
+		return repository.HouseAccount(repository.LedgerGamePool, currency)
+	default:
+		// 存款、提款、退款等與外部資金往來
+		return repository.HouseAccount(repository.LedgerExternal, currency)
+	}
+}
+
+// listWallets 執行錢包列表查詢
+func (r *WalletRepo) listWallets(ctx context.Context, query string, args ...interface{}) ([]*repository.Wallet, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets: %w", err)
+	}
+	defer rows.Close()
+
+	var wallets []*repository.Wallet
+	for rows.Next() {
+		wallet := &repository.Wallet{}
+		err := rows.Scan(
+			&wallet.ID,
+			&wallet.PlayerID,
+			&wallet.Balance,
+			&wallet.LockedBalance,
+			&wallet.Currency,
+			&wallet.Version,
+			&wallet.CreatedAt,
+			&wallet.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return wallets, nil
 }
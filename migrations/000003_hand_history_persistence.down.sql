@@ -0,0 +1,9 @@
+-- Revert 000003_hand_history_persistence.up.sql
+DROP INDEX IF EXISTS idx_hand_history_table_hand_number;
+
+ALTER TABLE hand_history ADD CONSTRAINT uq_hand_number UNIQUE(table_id, hand_number);
+
+ALTER TABLE hand_history DROP COLUMN IF EXISTS rake_amount;
+
+DELETE FROM hand_history WHERE game_session_id IS NULL;
+ALTER TABLE hand_history ALTER COLUMN game_session_id SET NOT NULL;
@@ -0,0 +1,19 @@
+-- ============================================================================
+-- 手牌歷史持久化調整
+-- - 一手牌涉及多位玩家的遊戲會話，會話 ID 改存於 players JSONB 中
+-- - 新增抽水欄位（000001 的 hand_history 沒有此欄位；模型已對應 rake_amount，
+--   若環境已手動補上則略過）
+-- - 手牌編號在伺服器重啟後會重新計數，改用 id (hand_id) 作為唯一鍵
+-- ============================================================================
+
+ALTER TABLE hand_history ALTER COLUMN game_session_id DROP NOT NULL;
+
+ALTER TABLE hand_history ADD COLUMN IF NOT EXISTS rake_amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE hand_history DROP CONSTRAINT uq_hand_number;
+
+CREATE INDEX idx_hand_history_table_hand_number ON hand_history(table_id, hand_number);
+
+COMMENT ON COLUMN hand_history.players IS 'JSONB array: [{player_id, game_session_id, seat, starting_chips, ending_chips, hole_cards, showed_down}]';
+COMMENT ON COLUMN hand_history.actions IS 'JSONB array: [{player_id, street, action, amount, total_bet, timestamp}]';
+COMMENT ON COLUMN hand_history.rake_amount IS '本手牌抽水金額（分）';
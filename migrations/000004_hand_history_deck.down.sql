@@ -0,0 +1,3 @@
+-- Revert 000004_hand_history_deck.up.sql
+ALTER TABLE hand_history DROP COLUMN IF EXISTS deck;
+ALTER TABLE hand_history DROP COLUMN IF EXISTS dealer_pos;
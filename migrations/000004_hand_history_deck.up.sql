@@ -0,0 +1,10 @@
+-- ============================================================================
+-- 手牌歷史保存發牌前的完整牌序與莊家位置，供爭議手牌重播驗證
+-- ============================================================================
+
+ALTER TABLE hand_history ADD COLUMN deck JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE hand_history ADD COLUMN dealer_pos SMALLINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN hand_history.deck IS 'JSONB array: 發牌前的 52 張牌序 (如 ["Ah","Kd",...])，僅供管理員重播';
+COMMENT ON COLUMN hand_history.dealer_pos IS '莊家座位索引 (0-8)';
@@ -0,0 +1,3 @@
+-- Revert 000005_rake_revenue.up.sql
+DROP TABLE IF EXISTS rake_contributions;
+DROP TABLE IF EXISTS house_revenue;
@@ -0,0 +1,33 @@
+-- ============================================================================
+-- 抽水收入帳
+-- - house_revenue: 每手牌的抽水收入（以 hand_id 做冪等）
+-- - rake_contributions: 每位玩家分攤的抽水（依投入比例），用於計算返水
+-- ============================================================================
+
+CREATE TABLE house_revenue (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hand_id UUID NOT NULL,
+    table_id VARCHAR(100) NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_house_revenue_hand UNIQUE (hand_id),
+    CONSTRAINT chk_house_revenue_amount CHECK (amount > 0)
+);
+
+CREATE INDEX idx_house_revenue_created_at ON house_revenue(created_at);
+
+CREATE TABLE rake_contributions (
+    hand_id UUID NOT NULL,
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (hand_id, player_id),
+    CONSTRAINT chk_rake_contribution_amount CHECK (amount > 0)
+);
+
+CREATE INDEX idx_rake_contributions_player_created ON rake_contributions(player_id, created_at);
+
+COMMENT ON TABLE house_revenue IS '抽水收入（分），每手牌一筆';
+COMMENT ON TABLE rake_contributions IS '玩家分攤的抽水（分），依投入底池比例計算';
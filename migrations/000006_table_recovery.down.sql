@@ -0,0 +1,3 @@
+-- Revert 000006_table_recovery.up.sql
+DROP TABLE IF EXISTS table_action_log;
+DROP TABLE IF EXISTS table_snapshots;
@@ -0,0 +1,34 @@
+-- ============================================================================
+-- 崩潰復原
+-- - table_snapshots: 每張牌桌在手牌邊界的最新快照（定期及每手開始/結束時更新）
+-- - table_action_log: 進行中手牌的動作日誌，手牌完成後刪除；
+--   重啟時用於計算被作廢手牌中各玩家已投入的籌碼
+-- ============================================================================
+
+CREATE TABLE table_snapshots (
+    table_id VARCHAR(100) PRIMARY KEY,
+    hand_number INT NOT NULL DEFAULT 0,
+    dealer_pos INT NOT NULL DEFAULT 0,
+    hand_in_progress BOOLEAN NOT NULL DEFAULT FALSE,
+    hand_id UUID,
+    players JSONB NOT NULL DEFAULT '[]',
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE table_action_log (
+    id BIGSERIAL PRIMARY KEY,
+    table_id VARCHAR(100) NOT NULL,
+    hand_id UUID NOT NULL,
+    player_id VARCHAR(100) NOT NULL,
+    street VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    total_bet BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_table_action_log_hand ON table_action_log(hand_id, id);
+
+COMMENT ON TABLE table_snapshots IS '牌桌快照（崩潰復原用），籌碼為手牌邊界的數值';
+COMMENT ON COLUMN table_snapshots.players IS 'JSONB array: [{player_id, seat, chips}]';
+COMMENT ON TABLE table_action_log IS '進行中手牌的動作日誌（分），手牌完成後刪除';
@@ -0,0 +1,2 @@
+-- Revert 000007_game_session_chips.up.sql
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS current_chips;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- 遊戲會話籌碼同步
+-- - current_chips: 每手牌結束後同步的最新籌碼，對帳清理孤兒會話時以此兌現
+-- ============================================================================
+
+ALTER TABLE game_sessions ADD COLUMN current_chips BIGINT NOT NULL DEFAULT 0;
+
+UPDATE game_sessions SET current_chips = COALESCE(cash_out_amount, buy_in_amount);
+
+COMMENT ON COLUMN game_sessions.current_chips IS '最近一次同步的籌碼（分）';
@@ -0,0 +1,3 @@
+-- Revert 000008_double_entry_ledger.up.sql
+DROP TABLE IF EXISTS ledger_entries;
+DROP FUNCTION IF EXISTS check_ledger_journal_balanced();
@@ -0,0 +1,67 @@
+-- ============================================================================
+-- 複式記帳分錄
+-- - 每筆資金異動寫入同一 journal_id 的一組分錄，金額合計必須為 0
+-- - 帳戶：player_wallet / table_escrow（玩家帳戶，帶 player_id）
+--         game_pool / house_rake / bonus_liability / external（平台帳戶）
+-- - 分錄只新增不修改：更正以反向分錄處理
+-- ============================================================================
+
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id UUID NOT NULL,
+    account_type VARCHAR(30) NOT NULL,
+    player_id UUID REFERENCES players(id) ON DELETE RESTRICT,
+    amount BIGINT NOT NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE RESTRICT,
+    reference_id VARCHAR(100),
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ledger_amount_not_zero CHECK (amount != 0),
+    CONSTRAINT chk_ledger_account_type CHECK (account_type IN (
+        'player_wallet', 'table_escrow', 'game_pool', 'house_rake', 'bonus_liability', 'external'
+    )),
+    CONSTRAINT chk_ledger_player_account CHECK (
+        (account_type IN ('player_wallet', 'table_escrow')) = (player_id IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_ledger_entries_journal ON ledger_entries(journal_id);
+CREATE INDEX idx_ledger_entries_account ON ledger_entries(account_type, player_id);
+CREATE INDEX idx_ledger_entries_transaction ON ledger_entries(transaction_id);
+
+-- 期初餘額：以 external 為對手方，將現有錢包餘額與鎖定餘額入帳
+WITH opening AS (
+    SELECT gen_random_uuid() AS journal_id, player_id, balance, locked_balance
+    FROM wallets
+    WHERE balance <> 0 OR locked_balance <> 0
+)
+INSERT INTO ledger_entries (journal_id, account_type, player_id, amount, reference_id, description)
+SELECT journal_id, 'player_wallet', player_id, balance, 'opening-balance', 'Opening balance'
+FROM opening WHERE balance <> 0
+UNION ALL
+SELECT journal_id, 'table_escrow', player_id, locked_balance, 'opening-balance', 'Opening balance'
+FROM opening WHERE locked_balance <> 0
+UNION ALL
+SELECT journal_id, 'external', NULL, -(balance + locked_balance), 'opening-balance', 'Opening balance'
+FROM opening;
+
+-- 借貸平衡檢查：延遲到事務提交時執行，允許同一事務內分批寫入分錄
+CREATE OR REPLACE FUNCTION check_ledger_journal_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE journal_id = NEW.journal_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger journal % is not balanced', NEW.journal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_ledger_journal_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    EXECUTE FUNCTION check_ledger_journal_balanced();
+
+COMMENT ON TABLE ledger_entries IS '複式記帳分錄（分），同一 journal_id 合計為 0';
+COMMENT ON COLUMN ledger_entries.amount IS '帳戶餘額變動：正數增加、負數減少';
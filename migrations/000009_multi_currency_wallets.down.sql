@@ -0,0 +1,24 @@
+-- Revert 000009_multi_currency_wallets.up.sql
+-- 注意：玩家有多個錢包時無法還原 player_id 唯一約束，需先手動合併或刪除多餘錢包
+CREATE OR REPLACE FUNCTION check_ledger_journal_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE journal_id = NEW.journal_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger journal % is not balanced', NEW.journal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_ledger_entries_account;
+CREATE INDEX idx_ledger_entries_account ON ledger_entries(account_type, player_id);
+
+DROP INDEX IF EXISTS idx_house_revenue_currency_created_at;
+CREATE INDEX idx_house_revenue_created_at ON house_revenue(created_at);
+ALTER TABLE house_revenue DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS currency;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS uq_wallets_player_currency;
+ALTER TABLE wallets ADD CONSTRAINT wallets_player_id_key UNIQUE (player_id);
@@ -0,0 +1,55 @@
+-- ============================================================================
+-- 多幣別錢包
+-- - wallets: 每位玩家每種幣別一個錢包（例如遊戲幣 PLAY 與真錢 USD）
+-- - game_sessions.currency: 買入使用的幣別，兌現與結算只動用同幣別錢包
+-- - ledger_entries.currency: 分錄幣別，同一組分錄必須為同一幣別
+-- - house_revenue.currency: 抽水幣別（依牌桌幣別），收入與返水按幣別統計
+-- ============================================================================
+
+ALTER TABLE wallets DROP CONSTRAINT wallets_player_id_key;
+ALTER TABLE wallets ADD CONSTRAINT uq_wallets_player_currency UNIQUE (player_id, currency);
+
+-- 既有資料每位玩家只有一個錢包，以該錢包幣別回填
+ALTER TABLE game_sessions ADD COLUMN currency VARCHAR(10);
+UPDATE game_sessions gs SET currency = w.currency
+FROM wallets w
+WHERE w.player_id = gs.player_id;
+UPDATE game_sessions SET currency = 'USD' WHERE currency IS NULL;
+ALTER TABLE game_sessions ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE ledger_entries ADD COLUMN currency VARCHAR(10);
+UPDATE ledger_entries le SET currency = j.currency
+FROM (
+    SELECT DISTINCT ON (e.journal_id) e.journal_id, w.currency
+    FROM ledger_entries e
+    INNER JOIN wallets w ON w.player_id = e.player_id
+) j
+WHERE le.journal_id = j.journal_id;
+UPDATE ledger_entries SET currency = 'USD' WHERE currency IS NULL;
+ALTER TABLE ledger_entries ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE house_revenue ADD COLUMN currency VARCHAR(10) NOT NULL DEFAULT 'USD';
+ALTER TABLE house_revenue ALTER COLUMN currency DROP DEFAULT;
+DROP INDEX idx_house_revenue_created_at;
+CREATE INDEX idx_house_revenue_currency_created_at ON house_revenue(currency, created_at);
+
+DROP INDEX idx_ledger_entries_account;
+CREATE INDEX idx_ledger_entries_account ON ledger_entries(currency, account_type, player_id);
+
+-- 借貸平衡檢查：同一組分錄金額合計為 0 且只有一種幣別
+CREATE OR REPLACE FUNCTION check_ledger_journal_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE journal_id = NEW.journal_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger journal % is not balanced', NEW.journal_id;
+    END IF;
+    IF (SELECT COUNT(DISTINCT currency) FROM ledger_entries WHERE journal_id = NEW.journal_id) > 1 THEN
+        RAISE EXCEPTION 'ledger journal % mixes currencies', NEW.journal_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON COLUMN game_sessions.currency IS '買入幣別';
+COMMENT ON COLUMN ledger_entries.currency IS '分錄幣別';
+COMMENT ON COLUMN house_revenue.currency IS '抽水幣別';
@@ -0,0 +1,2 @@
+-- Revert 000010_wallet_adjustments.up.sql
+DROP TABLE IF EXISTS wallet_adjustments;
@@ -0,0 +1,34 @@
+-- ============================================================================
+-- 管理員錢包調整（存款、提款、獎金、退款）
+-- - 由呼叫端提供冪等性鍵，重送同一請求不會重複入帳
+-- - 超過門檻的金額需另一位管理員核准後才執行（requested_by <> reviewed_by）
+-- - 執行時寫入的錢包交易冪等性鍵為 adjustment-<id>
+-- ============================================================================
+
+CREATE TABLE wallet_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id UUID NOT NULL REFERENCES players(id) ON DELETE RESTRICT,
+    currency VARCHAR(10) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL,
+    reason_code VARCHAR(50) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by UUID NOT NULL REFERENCES players(id),
+    reviewed_by UUID REFERENCES players(id),
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+
+    CONSTRAINT uq_wallet_adjustments_idempotency_key UNIQUE (idempotency_key),
+    CONSTRAINT chk_wallet_adjustment_amount CHECK (amount > 0),
+    CONSTRAINT chk_wallet_adjustment_type CHECK (type IN ('deposit', 'withdraw', 'bonus', 'refund')),
+    CONSTRAINT chk_wallet_adjustment_status CHECK (status IN ('pending', 'completed', 'rejected')),
+    CONSTRAINT chk_wallet_adjustment_reviewer CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+CREATE INDEX idx_wallet_adjustments_status_created_at ON wallet_adjustments(status, created_at DESC);
+CREATE INDEX idx_wallet_adjustments_player_id ON wallet_adjustments(player_id);
+
+COMMENT ON TABLE wallet_adjustments IS '管理員錢包調整（分），超過門檻需雙人核准';
@@ -0,0 +1,17 @@
+-- Revert 000011_auth_sessions.up.sql
+DROP INDEX IF EXISTS idx_sessions_player_active;
+DROP INDEX IF EXISTS idx_sessions_previous_token_hash;
+DROP INDEX IF EXISTS idx_sessions_refresh_token_hash;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS revoked_reason,
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS previous_token_hash,
+    DROP COLUMN IF EXISTS refresh_token_hash,
+    ALTER COLUMN data DROP DEFAULT;
+
+COMMENT ON TABLE sessions IS 'Redis Session 的持久化備份';
+COMMENT ON COLUMN sessions.data IS NULL;
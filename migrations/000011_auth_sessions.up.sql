@@ -0,0 +1,26 @@
+-- ============================================================================
+-- 登入會話（refresh token 輪替與撤銷）
+-- - 每次登入建立一列，Access Token 以 sid 聲明指向該列
+-- - 只保存 refresh token 的 SHA-256 雜湊；每次刷新輪替，前一個雜湊保留以偵測重放
+-- - revoked_at 不為空時，該會話的 Access Token 與 Refresh Token 都失效
+-- ============================================================================
+
+ALTER TABLE sessions
+    ALTER COLUMN data SET DEFAULT '{}'::jsonb,
+    ADD COLUMN refresh_token_hash CHAR(64),
+    ADD COLUMN previous_token_hash CHAR(64),
+    ADD COLUMN ip_address INET,
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN last_used_at TIMESTAMPTZ,
+    ADD COLUMN revoked_at TIMESTAMPTZ,
+    ADD COLUMN revoked_reason VARCHAR(50);
+
+CREATE UNIQUE INDEX idx_sessions_refresh_token_hash ON sessions(refresh_token_hash);
+CREATE INDEX idx_sessions_previous_token_hash ON sessions(previous_token_hash)
+    WHERE previous_token_hash IS NOT NULL;
+CREATE INDEX idx_sessions_player_active ON sessions(player_id)
+    WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE sessions IS '登入會話（refresh token 輪替與撤銷）';
+COMMENT ON COLUMN sessions.data IS 'JSONB: {username}，刷新時簽發 Access Token 使用';
+COMMENT ON COLUMN sessions.previous_token_hash IS '上一個 refresh token 的雜湊，再次出現表示遭重放，整個會話撤銷';
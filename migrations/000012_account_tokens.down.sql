@@ -0,0 +1,2 @@
+-- Revert 000012_account_tokens.up.sql
+DROP TABLE IF EXISTS account_tokens;
@@ -0,0 +1,26 @@
+-- ============================================================================
+-- 帳號一次性 Token（電子郵件驗證、密碼重設）
+-- - 只保存 token 的 SHA-256 雜湊，明文只出現在寄出的郵件中
+-- - 使用後寫入 used_at，過期或已使用的 token 一律無效
+-- - email 記錄簽發時的信箱，驗證時信箱已變更則不生效
+-- ============================================================================
+
+CREATE TABLE account_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_account_tokens_token_hash UNIQUE (token_hash),
+    CONSTRAINT chk_account_token_purpose CHECK (purpose IN ('email_verification', 'password_reset'))
+);
+
+CREATE INDEX idx_account_tokens_account_purpose ON account_tokens(account_id, purpose)
+    WHERE used_at IS NULL;
+
+COMMENT ON TABLE account_tokens IS '帳號一次性 Token（電子郵件驗證、密碼重設），只保存雜湊';
+COMMENT ON COLUMN account_tokens.email IS '簽發時的信箱，驗證時須與帳號目前信箱一致';
@@ -0,0 +1,3 @@
+-- Revert 000013_two_factor.up.sql
+DROP TABLE IF EXISTS account_recovery_codes;
+DROP TABLE IF EXISTS account_totp;
@@ -0,0 +1,29 @@
+-- ============================================================================
+-- TOTP 兩步驟驗證
+-- - account_totp：每個帳號一組金鑰；confirmed_at 為空表示尚在綁定中（未啟用）
+-- - last_used_step 記錄最後使用的時間步長，同一驗證碼不可重複使用
+-- - account_recovery_codes：只保存恢復碼的 SHA-256 雜湊，每組單次有效
+-- ============================================================================
+
+CREATE TABLE account_totp (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE account_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_account_recovery_codes UNIQUE (account_id, code_hash)
+);
+
+COMMENT ON TABLE account_totp IS 'TOTP 兩步驟驗證金鑰，confirmed_at 不為空時啟用';
+COMMENT ON COLUMN account_totp.last_used_step IS '最後使用的時間步長（30 秒），防止驗證碼重放';
+COMMENT ON TABLE account_recovery_codes IS '兩步驟驗證恢復碼（SHA-256 雜湊，單次有效）';
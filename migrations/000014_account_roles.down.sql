@@ -0,0 +1,5 @@
+-- Revert 000014_account_roles.up.sql
+DROP INDEX IF EXISTS idx_accounts_roles;
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS chk_account_roles,
+    DROP COLUMN IF EXISTS roles;
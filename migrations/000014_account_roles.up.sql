@@ -0,0 +1,19 @@
+-- ============================================================================
+-- 帳號角色（RBAC）
+-- - admin：全部管理 API（含角色指派）
+-- - support：玩家查詢、停權/封禁、牌桌管理、手牌重播
+-- - finance：錢包調整、營收報表
+-- 角色寫入 Access Token，變更角色時撤銷該玩家所有登入會話
+--
+-- 第一位管理員需直接以 SQL 指派，之後可透過 /api/admin/accounts/{id}/roles 管理：
+--   UPDATE accounts SET roles = ARRAY['admin'] WHERE username = '...';
+-- ============================================================================
+
+ALTER TABLE accounts
+    ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT chk_account_roles CHECK (roles <@ ARRAY['admin', 'support', 'finance']::TEXT[]);
+
+CREATE INDEX idx_accounts_roles ON accounts USING GIN (roles)
+    WHERE roles <> '{}';
+
+COMMENT ON COLUMN accounts.roles IS '管理角色（admin/support/finance），一般玩家為空陣列';
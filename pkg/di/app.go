@@ -5,6 +5,7 @@ import (
 
 	"github.com/shinjuwu/TheNuts/internal/auth"
 	"github.com/shinjuwu/TheNuts/internal/game"
+	"github.com/shinjuwu/TheNuts/internal/game/adapter/rest"
 	"github.com/shinjuwu/TheNuts/internal/game/adapter/ws"
 	"github.com/shinjuwu/TheNuts/internal/game/service"
 	"github.com/shinjuwu/TheNuts/internal/infra/config"
@@ -22,11 +23,22 @@ type App struct {
 	Hub          *ws.Hub
 	WSHandler    *ws.Handler
 
+	// HTTP API 相關
+	HandHistoryHandler  *rest.HandHistoryHandler
+	RakeHandler         *rest.RakeHandler
+	WalletHandler       *rest.WalletHandler
+	AdjustmentHandler   *rest.AdjustmentHandler
+	AuditHandler        *rest.AuditHandler
+	TableAdminHandler   *rest.TableAdminHandler
+	AccountAdminHandler *rest.AccountAdminHandler
+
 	// 認證相關
-	JWTService  *auth.JWTService
-	TicketStore auth.TicketStore
-	AuthService *auth.AuthService
-	AuthHandler *auth.Handler
+	JWTService     *auth.JWTService
+	TicketStore    auth.TicketStore
+	SessionService *auth.SessionService
+	AuthService    *auth.AuthService
+	AuthHandler    *auth.Handler
+	RateLimiters   *RateLimiters
 
 	// 資料庫相關
 	PostgresDB  *database.PostgresDB
@@ -34,15 +46,30 @@ type App struct {
 	UnitOfWork  repository.UnitOfWork
 
 	// Repository 相關
-	AccountRepo     repository.AccountRepository
-	PlayerRepo      repository.PlayerRepository
-	WalletRepo      repository.WalletRepository
-	TransactionRepo *postgres.TransactionRepo
-	SessionRepo     repository.GameSessionRepository
+	AccountRepo      repository.AccountRepository
+	PlayerRepo       repository.PlayerRepository
+	WalletRepo       repository.WalletRepository
+	TransactionRepo  *postgres.TransactionRepo
+	SessionRepo      repository.GameSessionRepository
+	HandHistoryRepo  repository.HandHistoryRepository
+	RakeRepo         repository.RakeRepository
+	RecoveryRepo     repository.TableRecoveryRepository
+	AuditLogRepo     repository.AuditLogRepository
+	AdjustmentRepo   repository.WalletAdjustmentRepository
+	AuthSessionRepo  repository.AuthSessionRepository
+	AccountTokenRepo repository.AccountTokenRepository
+	TwoFactorRepo    repository.TwoFactorRepository
 
 	// Service 相關
-	GameService    *service.GameService
-	SessionManager *ws.SessionManager
+	GameService        *service.GameService
+	HandHistoryService *service.HandHistoryService
+	RakeService        *service.RakeService
+	RecoveryService    *service.RecoveryService
+	SessionReconciler  *service.SessionReconciler
+	WalletService      *service.WalletService
+	AdjustmentService  *service.AdjustmentService
+	AuditService       *service.AuditService
+	SessionManager     *ws.SessionManager
 }
 
 func (a *App) Stop(ctx context.Context) {
@@ -51,6 +78,11 @@ func (a *App) Stop(ctx context.Context) {
 		a.SessionManager.Stop()
 	}
 
+	// 寫完排隊中的牌桌快照與動作日誌
+	if a.RecoveryService != nil {
+		a.RecoveryService.Stop(ctx)
+	}
+
 	// 關閉票券儲存
 	if a.TicketStore != nil {
 		_ = a.TicketStore.Close()
@@ -2,12 +2,16 @@ package di
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/google/wire"
 	"github.com/shinjuwu/TheNuts/internal/auth"
 	"github.com/shinjuwu/TheNuts/internal/game"
+	"github.com/shinjuwu/TheNuts/internal/game/adapter/rest"
 	"github.com/shinjuwu/TheNuts/internal/game/adapter/ws"
+	"github.com/shinjuwu/TheNuts/internal/game/domain"
 	"github.com/shinjuwu/TheNuts/internal/game/service"
 	"github.com/shinjuwu/TheNuts/internal/infra/config"
 	"github.com/shinjuwu/TheNuts/internal/infra/database"
@@ -37,12 +41,23 @@ var RepositorySet = wire.NewSet(
 	ProvideTransactionRepository,
 	ProvideWalletRepository,
 	ProvideGameSessionRepository,
+	ProvideHandHistoryRepository,
+	ProvideRakeRepository,
+	ProvideTableRecoveryRepository,
+	ProvideAuditLogRepository,
+	ProvideWalletAdjustmentRepository,
+	ProvideAuthSessionRepository,
+	ProvideAccountTokenRepository,
+	ProvideTwoFactorRepository,
 )
 
 // AuthSet 包含認證模組的 Providers
 var AuthSet = wire.NewSet(
 	ProvideJWTService,
 	ProvideTicketStore,
+	ProvideSessionService,
+	ProvideMailer,
+	ProvideRateLimiters,
 	ProvideAuthService,
 	ProvideAuthHandler,
 )
@@ -50,6 +65,14 @@ var AuthSet = wire.NewSet(
 // ServiceSet 包含业务服务的 Providers
 var ServiceSet = wire.NewSet(
 	ProvideGameService,
+	ProvideHandHistoryService,
+	ProvideRakeService,
+	ProvideRecoveryService,
+	ProvideSessionReconciler,
+	ProvideWalletService,
+	ProvideAdjustmentService,
+	ProvideAuditService,
+	ProvideAccountAdminService,
 )
 
 var GameSet = wire.NewSet(
@@ -58,16 +81,91 @@ var GameSet = wire.NewSet(
 	ProvideTableManager,
 	ws.NewHub,
 	ProvideWSHandler,
+	ProvideHandHistoryHandler,
+	ProvideRakeHandler,
+	ProvideWalletHandler,
+	ProvideAdjustmentHandler,
+	ProvideAuditHandler,
+	ProvideTableAdminHandler,
+	ProvideAccountAdminHandler,
 )
 
 // ProvideTableManager 提供 Table Manager (主要為了注入依賴)
-func ProvideTableManager(gs *service.GameService) *game.TableManager {
-	return game.NewTableManager(gs)
+func ProvideTableManager(
+	gs *service.GameService,
+	hs *service.HandHistoryService,
+	rs *service.RakeService,
+	recovery *service.RecoveryService,
+	cfg *config.Config,
+) *game.TableManager {
+	tm := game.NewTableManager(gs)
+	tm.SetHandHistoryService(hs)
+	tm.SetRakeService(rs)
+	tm.SetRecoveryService(recovery)
+	tm.SetRakeConfig(toDomainRakeConfig(cfg.Game.Rake))
+	tm.SetTableCurrencies(cfg.GetDefaultCurrency(), cfg.GetTableCurrencies())
+	return tm
+}
+
+// toDomainRakeConfig 將設定檔的抽水配置轉換為 domain.RakeConfig
+func toDomainRakeConfig(cfg config.RakeConfig) domain.RakeConfig {
+	rake := domain.RakeConfig{
+		Percent:      cfg.Percent,
+		Cap:          cfg.Cap,
+		NoFlopNoDrop: cfg.NoFlopNoDrop,
+	}
+	for _, c := range cfg.CapsByPlayers {
+		rake.PlayerCaps = append(rake.PlayerCaps, domain.RakeCap{MinPlayers: c.MinPlayers, Cap: c.Cap})
+	}
+	return rake
+}
+
+// ProvideJWTService 提供 JWT 服務（設定 jwt_keys 時支援 kid 金鑰輪替）
+func ProvideJWTService(cfg *config.Config) (*auth.JWTService, error) {
+	if len(cfg.Auth.JWTKeys) == 0 {
+		return auth.NewJWTService(cfg.Auth.JWTSecret), nil
+	}
+
+	keys := make([]*auth.SigningKey, 0, len(cfg.Auth.JWTKeys))
+	for _, kc := range cfg.Auth.JWTKeys {
+		key, err := loadJWTKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return auth.NewJWTServiceWithKeys(cfg.Auth.JWTActiveKeyID, keys...)
 }
 
-// ProvideJWTService 提供 JWT 服務
-func ProvideJWTService(cfg *config.Config) *auth.JWTService {
-	return auth.NewJWTService(cfg.Auth.JWTSecret)
+// loadJWTKey 依設定載入 JWT 金鑰
+func loadJWTKey(kc config.JWTKeyConfig) (*auth.SigningKey, error) {
+	switch kc.Algorithm {
+	case "", auth.AlgHS256:
+		return auth.NewHMACKey(kc.ID, kc.Secret)
+	case auth.AlgEdDSA:
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read jwt key %s: %w", kc.ID, err)
+			}
+			privateKey, err := auth.ParseEd25519PrivateKeyPEM(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load jwt key %s: %w", kc.ID, err)
+			}
+			return auth.NewEd25519Key(kc.ID, privateKey)
+		}
+		data, err := os.ReadFile(kc.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt key %s: %w", kc.ID, err)
+		}
+		publicKey, err := auth.ParseEd25519PublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jwt key %s: %w", kc.ID, err)
+		}
+		return auth.NewEd25519VerifyKey(kc.ID, publicKey)
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported algorithm %q", kc.ID, kc.Algorithm)
+	}
 }
 
 // ProvideTicketStore 提供票券儲存（使用 Redis）
@@ -75,25 +173,109 @@ func ProvideTicketStore(redisClient *database.RedisClient) auth.TicketStore {
 	return auth.NewRedisTicketStore(redisClient.Client)
 }
 
+// ProvideSessionService 提供登入會話服務（Access Token 與 Refresh Token）
+func ProvideSessionService(
+	sessionRepo repository.AuthSessionRepository,
+	jwtService *auth.JWTService,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *auth.SessionService {
+	ss := auth.NewSessionService(sessionRepo, jwtService, logger)
+	ss.SetTokenTTL(cfg.GetAccessTokenTTL(), cfg.GetRefreshTokenTTL())
+	return ss
+}
+
+// ProvideMailer 提供寄信器（依 mail.driver 選擇 SMTP、檔案或記憶體）
+func ProvideMailer(cfg *config.Config) (auth.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		smtpCfg := cfg.Mail.SMTP
+		return auth.NewSMTPMailer(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, cfg.Mail.From), nil
+	case "", "file":
+		return auth.NewFileMailer(cfg.Mail.GetMailFileDir(), cfg.Mail.From), nil
+	case "memory":
+		return auth.NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.Mail.Driver)
+	}
+}
+
+// RateLimiters 各類請求的限流器（rate_limit.driver 為 disabled 時皆為 nil）
+type RateLimiters struct {
+	Auth      auth.RateLimiter // 未登入的認證端點（依 IP）
+	Account   auth.RateLimiter // 已登入的認證端點（依玩家）
+	WebSocket auth.RateLimiter // WebSocket 訊息（依玩家）
+}
+
+// ProvideRateLimiters 提供限流器（依 rate_limit.driver 選擇 Redis 或記憶體）
+func ProvideRateLimiters(cfg *config.Config, redisClient *database.RedisClient) (*RateLimiters, error) {
+	rules := map[string]config.RateLimitRule{
+		"auth":    cfg.RateLimit.GetAuth(),
+		"account": cfg.RateLimit.GetAccount(),
+		"ws":      cfg.RateLimit.GetWebSocket(),
+	}
+
+	var newLimiter func(name string) auth.RateLimiter
+	switch cfg.RateLimit.Driver {
+	case "", "redis":
+		newLimiter = func(name string) auth.RateLimiter {
+			return auth.NewRedisRateLimiter(redisClient.Client, name, auth.RateLimit(rules[name]))
+		}
+	case "memory":
+		newLimiter = func(name string) auth.RateLimiter {
+			return auth.NewMemoryRateLimiter(auth.RateLimit(rules[name]))
+		}
+	case "disabled":
+		return &RateLimiters{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit driver %q", cfg.RateLimit.Driver)
+	}
+
+	return &RateLimiters{
+		Auth:      newLimiter("auth"),
+		Account:   newLimiter("account"),
+		WebSocket: newLimiter("ws"),
+	}, nil
+}
+
 // ProvideAuthService 提供认证服务
 func ProvideAuthService(
 	accountRepo repository.AccountRepository,
 	playerRepo repository.PlayerRepository,
 	walletRepo repository.WalletRepository,
+	auditRepo repository.AuditLogRepository,
+	tokenRepo repository.AccountTokenRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	sessionService *auth.SessionService,
+	mailer auth.Mailer,
+	cfg *config.Config,
 	logger *zap.Logger,
 ) *auth.AuthService {
-	return auth.NewAuthService(accountRepo, playerRepo, walletRepo, logger)
+	as := auth.NewAuthService(accountRepo, playerRepo, walletRepo, logger)
+	as.SetAuditLogRepository(auditRepo)
+	as.SetSessionService(sessionService)
+	as.SetAccountTokenRepository(tokenRepo)
+	as.SetTwoFactorRepository(twoFactorRepo)
+	as.SetMailer(mailer, cfg.Mail.LinkBaseURL)
+
+	// 註冊時為每個設定的幣別開立錢包
+	var wallets []auth.InitialWallet
+	for _, c := range cfg.GetCurrencies() {
+		wallets = append(wallets, auth.InitialWallet{Currency: c.Code, Balance: c.InitialBalance})
+	}
+	as.SetInitialWallets(wallets)
+	return as
 }
 
 // ProvideAuthHandler 提供認證 Handler
 func ProvideAuthHandler(
-	jwtService *auth.JWTService,
+	sessionService *auth.SessionService,
 	ticketStore auth.TicketStore,
 	authService *auth.AuthService,
 	cfg *config.Config,
 	logger *zap.Logger,
 ) *auth.Handler {
-	handler := auth.NewHandler(jwtService, ticketStore, authService, logger)
+	handler := auth.NewHandler(sessionService, ticketStore, authService, logger)
 
 	// 設定票券 TTL
 	if cfg.Auth.TicketTTLSeconds > 0 {
@@ -153,23 +335,215 @@ func ProvideGameSessionRepository(db *database.PostgresDB) repository.GameSessio
 	return postgres.NewGameSessionRepository(db.Pool)
 }
 
+// ProvideHandHistoryRepository 提供 HandHistory Repository
+func ProvideHandHistoryRepository(db *database.PostgresDB) repository.HandHistoryRepository {
+	return postgres.NewHandHistoryRepository(db.Pool)
+}
+
+// ProvideRakeRepository 提供 Rake Repository
+func ProvideRakeRepository(db *database.PostgresDB) repository.RakeRepository {
+	return postgres.NewRakeRepository(db.Pool)
+}
+
+// ProvideTableRecoveryRepository 提供 TableRecovery Repository
+func ProvideTableRecoveryRepository(db *database.PostgresDB) repository.TableRecoveryRepository {
+	return postgres.NewTableRecoveryRepository(db.Pool)
+}
+
+// ProvideAuditLogRepository 提供 AuditLog Repository
+func ProvideAuditLogRepository(db *database.PostgresDB) repository.AuditLogRepository {
+	return postgres.NewAuditLogRepository(db.Pool)
+}
+
+// ProvideWalletAdjustmentRepository 提供 WalletAdjustment Repository
+func ProvideWalletAdjustmentRepository(db *database.PostgresDB) repository.WalletAdjustmentRepository {
+	return postgres.NewWalletAdjustmentRepository(db.Pool)
+}
+
+// ProvideAuthSessionRepository 提供登入會話 Repository
+func ProvideAuthSessionRepository(db *database.PostgresDB) repository.AuthSessionRepository {
+	return postgres.NewAuthSessionRepository(db.Pool)
+}
+
+// ProvideAccountTokenRepository 提供帳號一次性 Token Repository
+func ProvideAccountTokenRepository(db *database.PostgresDB) repository.AccountTokenRepository {
+	return postgres.NewAccountTokenRepository(db.Pool)
+}
+
+// ProvideTwoFactorRepository 提供兩步驗證 Repository
+func ProvideTwoFactorRepository(db *database.PostgresDB) repository.TwoFactorRepository {
+	return postgres.NewTwoFactorRepository(db.Pool)
+}
+
 // ProvideGameService 提供 Game Service
 func ProvideGameService(
 	playerRepo repository.PlayerRepository,
+	accountRepo repository.AccountRepository,
 	walletRepo repository.WalletRepository,
 	sessionRepo repository.GameSessionRepository,
 	uow repository.UnitOfWork,
+	auditRepo repository.AuditLogRepository,
+	cfg *config.Config,
 	logger *zap.Logger,
 ) *service.GameService {
-	return service.NewGameService(playerRepo, walletRepo, sessionRepo, uow, logger)
+	gs := service.NewGameService(playerRepo, walletRepo, sessionRepo, uow, logger)
+	gs.SetDefaultCurrency(cfg.GetDefaultCurrency())
+	gs.SetAuditLogRepository(auditRepo)
+	// 真錢買入需驗證信箱，遊戲幣不限制
+	gs.SetAccountRepository(accountRepo)
+	gs.SetPlayMoneyCurrencies(cfg.GetPlayMoneyCurrencies())
+	return gs
+}
+
+// ProvideHandHistoryService 提供手牌歷史服務
+func ProvideHandHistoryService(
+	historyRepo repository.HandHistoryRepository,
+	sessionRepo repository.GameSessionRepository,
+	playerRepo repository.PlayerRepository,
+	logger *zap.Logger,
+) *service.HandHistoryService {
+	return service.NewHandHistoryService(historyRepo, sessionRepo, playerRepo, logger)
+}
+
+// ProvideRakeService 提供抽水服務
+func ProvideRakeService(
+	rakeRepo repository.RakeRepository,
+	uow repository.UnitOfWork,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *service.RakeService {
+	rs := service.NewRakeService(rakeRepo, uow, logger)
+	rs.SetRakebackPercent(cfg.Game.Rake.RakebackPercent)
+	rs.SetDefaultCurrency(cfg.GetDefaultCurrency())
+	return rs
+}
+
+// ProvideRecoveryService 提供崩潰復原服務
+func ProvideRecoveryService(
+	recoveryRepo repository.TableRecoveryRepository,
+	gameService *service.GameService,
+	cfg *config.Config,
+	logger *zap.Logger,
+) (*service.RecoveryService, error) {
+	policy, err := service.ParseRecoveryPolicy(cfg.Game.Recovery.Policy)
+	if err != nil {
+		return nil, err
+	}
+	rs := service.NewRecoveryService(recoveryRepo, gameService, logger)
+	rs.SetPolicy(policy)
+	return rs, nil
+}
+
+// ProvideSessionReconciler 提供孤兒會話對帳服務（座位查詢由 main.go 注入 TableManager）
+func ProvideSessionReconciler(
+	sessionRepo repository.GameSessionRepository,
+	gameService *service.GameService,
+	auditRepo repository.AuditLogRepository,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *service.SessionReconciler {
+	r := service.NewSessionReconciler(sessionRepo, gameService, auditRepo, logger)
+	r.SetGracePeriod(cfg.Game.Reconciliation.GetGracePeriod())
+	return r
+}
+
+// ProvideWalletService 提供錢包查詢服務
+func ProvideWalletService(
+	walletRepo repository.WalletRepository,
+	txRepo *postgres.TransactionRepo,
+	sessionRepo repository.GameSessionRepository,
+	logger *zap.Logger,
+) *service.WalletService {
+	return service.NewWalletService(walletRepo, txRepo, sessionRepo, logger)
+}
+
+// ProvideAdjustmentService 提供管理員錢包調整服務
+func ProvideAdjustmentService(
+	adjustmentRepo repository.WalletAdjustmentRepository,
+	walletRepo repository.WalletRepository,
+	playerRepo repository.PlayerRepository,
+	auditRepo repository.AuditLogRepository,
+	uow repository.UnitOfWork,
+	cfg *config.Config,
+	logger *zap.Logger,
+) *service.AdjustmentService {
+	as := service.NewAdjustmentService(adjustmentRepo, walletRepo, playerRepo, auditRepo, uow, logger)
+	as.SetApprovalThreshold(cfg.GetApprovalThreshold())
+	as.SetApprovalWindow(cfg.GetApprovalWindow())
+	as.SetPlayMoneyCurrencies(cfg.GetPlayMoneyCurrencies())
+	as.SetDefaultCurrency(cfg.GetDefaultCurrency())
+	return as
+}
+
+// ProvideAuditService 提供審計日誌服務
+func ProvideAuditService(
+	auditRepo repository.AuditLogRepository,
+	playerRepo repository.PlayerRepository,
+	logger *zap.Logger,
+) *service.AuditService {
+	return service.NewAuditService(auditRepo, playerRepo, logger)
+}
+
+// ProvideAuditHandler 提供審計日誌查詢 Handler
+func ProvideAuditHandler(auditService *service.AuditService, logger *zap.Logger) *rest.AuditHandler {
+	return rest.NewAuditHandler(auditService, logger)
+}
+
+// ProvideTableAdminHandler 提供牌桌管理 Handler
+func ProvideTableAdminHandler(tableMgr *game.TableManager, auditService *service.AuditService, logger *zap.Logger) *rest.TableAdminHandler {
+	return rest.NewTableAdminHandler(tableMgr, auditService, logger)
+}
+
+// ProvideAccountAdminService 提供帳號管理服務（狀態或角色變更後撤銷登入會話）
+func ProvideAccountAdminService(
+	accountRepo repository.AccountRepository,
+	playerRepo repository.PlayerRepository,
+	walletRepo repository.WalletRepository,
+	sessionRepo repository.GameSessionRepository,
+	auditService *service.AuditService,
+	sessionService *auth.SessionService,
+	logger *zap.Logger,
+) *service.AccountAdminService {
+	s := service.NewAccountAdminService(accountRepo, playerRepo, walletRepo, sessionRepo, auditService, logger)
+	s.SetSessionRevoker(sessionService)
+	return s
+}
+
+// ProvideAccountAdminHandler 提供玩家查詢與帳號管理 Handler
+func ProvideAccountAdminHandler(accountService *service.AccountAdminService, logger *zap.Logger) *rest.AccountAdminHandler {
+	return rest.NewAccountAdminHandler(accountService, logger)
+}
+
+// ProvideAdjustmentHandler 提供管理員錢包調整 Handler
+func ProvideAdjustmentHandler(adjustmentService *service.AdjustmentService, logger *zap.Logger) *rest.AdjustmentHandler {
+	return rest.NewAdjustmentHandler(adjustmentService, logger)
+}
+
+// ProvideWalletHandler 提供錢包查詢 Handler
+func ProvideWalletHandler(walletService *service.WalletService, logger *zap.Logger) *rest.WalletHandler {
+	return rest.NewWalletHandler(walletService, logger)
+}
+
+// ProvideRakeHandler 提供抽水與返水查詢 Handler
+func ProvideRakeHandler(rakeService *service.RakeService, logger *zap.Logger) *rest.RakeHandler {
+	return rest.NewRakeHandler(rakeService, logger)
 }
 
-// ProvideSessionManager 提供 Session Manager
+// ProvideHandHistoryHandler 提供手牌歷史下載 Handler
+func ProvideHandHistoryHandler(historyService *service.HandHistoryService, logger *zap.Logger) *rest.HandHistoryHandler {
+	return rest.NewHandHistoryHandler(historyService, logger)
+}
+
+// ProvideSessionManager 提供 Session Manager（登入會話撤銷時斷開對應的 WebSocket 連線）
 func ProvideSessionManager(
 	gameService *service.GameService,
+	sessionService *auth.SessionService,
 	logger *zap.Logger,
 ) *ws.SessionManager {
-	return ws.NewSessionManager(gameService, logger)
+	sm := ws.NewSessionManager(gameService, logger)
+	sm.SetAuthSessionValidator(sessionService)
+	sessionService.SetRevocationListener(sm)
+	return sm
 }
 
 // ProvideWSHandler 提供 WebSocket Handler
@@ -179,7 +553,12 @@ func ProvideWSHandler(
 	sessionMgr *ws.SessionManager,
 	gameService *service.GameService,
 	ticketStore auth.TicketStore,
+	rateLimiters *RateLimiters,
 	logger *zap.Logger,
 ) *ws.Handler {
-	return ws.NewHandler(hub, tableMgr, sessionMgr, gameService, ticketStore, logger)
+	handler := ws.NewHandler(hub, tableMgr, sessionMgr, gameService, ticketStore, logger)
+	if rateLimiters.WebSocket != nil {
+		handler.SetRateLimiter(rateLimiters.WebSocket)
+	}
+	return handler
 }
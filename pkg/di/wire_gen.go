@@ -28,44 +28,106 @@ func InitApp(configPath string) (*App, error) {
 		return nil, err
 	}
 	playerRepository := ProvidePlayerRepository(postgresDB)
+	accountRepository := ProvideAccountRepository(postgresDB)
 	transactionRepo := ProvideTransactionRepository(postgresDB)
 	walletRepository := ProvideWalletRepository(postgresDB, transactionRepo)
 	gameSessionRepository := ProvideGameSessionRepository(postgresDB)
 	unitOfWork := ProvideUnitOfWork(postgresDB)
-	gameService := ProvideGameService(playerRepository, walletRepository, gameSessionRepository, unitOfWork, zapLogger)
-	tableManager := ProvideTableManager(gameService)
-	sessionManager := ProvideSessionManager(gameService, zapLogger)
+	auditLogRepository := ProvideAuditLogRepository(postgresDB)
+	gameService := ProvideGameService(playerRepository, accountRepository, walletRepository, gameSessionRepository, unitOfWork, auditLogRepository, configConfig, zapLogger)
+	handHistoryRepository := ProvideHandHistoryRepository(postgresDB)
+	handHistoryService := ProvideHandHistoryService(handHistoryRepository, gameSessionRepository, playerRepository, zapLogger)
+	rakeRepository := ProvideRakeRepository(postgresDB)
+	rakeService := ProvideRakeService(rakeRepository, unitOfWork, configConfig, zapLogger)
+	tableRecoveryRepository := ProvideTableRecoveryRepository(postgresDB)
+	recoveryService, err := ProvideRecoveryService(tableRecoveryRepository, gameService, configConfig, zapLogger)
+	if err != nil {
+		return nil, err
+	}
+	tableManager := ProvideTableManager(gameService, handHistoryService, rakeService, recoveryService, configConfig)
+	authSessionRepository := ProvideAuthSessionRepository(postgresDB)
+	jwtService, err := ProvideJWTService(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	sessionService := ProvideSessionService(authSessionRepository, jwtService, configConfig, zapLogger)
+	sessionManager := ProvideSessionManager(gameService, sessionService, zapLogger)
 	hub := ws.NewHub(sessionManager, zapLogger)
 	redisClient, err := ProvideRedisClient(configConfig, zapLogger)
 	if err != nil {
 		return nil, err
 	}
 	ticketStore := ProvideTicketStore(redisClient)
-	handler := ProvideWSHandler(hub, tableManager, sessionManager, gameService, ticketStore, zapLogger)
-	jwtService := ProvideJWTService(configConfig)
-	accountRepository := ProvideAccountRepository(postgresDB)
-	authService := ProvideAuthService(accountRepository, playerRepository, walletRepository, zapLogger)
-	authHandler := ProvideAuthHandler(jwtService, ticketStore, authService, configConfig, zapLogger)
+	rateLimiters, err := ProvideRateLimiters(configConfig, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	handler := ProvideWSHandler(hub, tableManager, sessionManager, gameService, ticketStore, rateLimiters, zapLogger)
+	handHistoryHandler := ProvideHandHistoryHandler(handHistoryService, zapLogger)
+	rakeHandler := ProvideRakeHandler(rakeService, zapLogger)
+	walletService := ProvideWalletService(walletRepository, transactionRepo, gameSessionRepository, zapLogger)
+	walletHandler := ProvideWalletHandler(walletService, zapLogger)
+	walletAdjustmentRepository := ProvideWalletAdjustmentRepository(postgresDB)
+	adjustmentService := ProvideAdjustmentService(walletAdjustmentRepository, walletRepository, playerRepository, auditLogRepository, unitOfWork, configConfig, zapLogger)
+	adjustmentHandler := ProvideAdjustmentHandler(adjustmentService, zapLogger)
+	auditService := ProvideAuditService(auditLogRepository, playerRepository, zapLogger)
+	auditHandler := ProvideAuditHandler(auditService, zapLogger)
+	tableAdminHandler := ProvideTableAdminHandler(tableManager, auditService, zapLogger)
+	accountAdminService := ProvideAccountAdminService(accountRepository, playerRepository, walletRepository, gameSessionRepository, auditService, sessionService, zapLogger)
+	accountAdminHandler := ProvideAccountAdminHandler(accountAdminService, zapLogger)
+	accountTokenRepository := ProvideAccountTokenRepository(postgresDB)
+	twoFactorRepository := ProvideTwoFactorRepository(postgresDB)
+	mailer, err := ProvideMailer(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	authService := ProvideAuthService(accountRepository, playerRepository, walletRepository, auditLogRepository, accountTokenRepository, twoFactorRepository, sessionService, mailer, configConfig, zapLogger)
+	authHandler := ProvideAuthHandler(sessionService, ticketStore, authService, configConfig, zapLogger)
+	sessionReconciler := ProvideSessionReconciler(gameSessionRepository, gameService, auditLogRepository, configConfig, zapLogger)
 	app := &App{
-		Config:          configConfig,
-		Logger:          zapLogger,
-		TableManager:    tableManager,
-		Hub:             hub,
-		WSHandler:       handler,
-		JWTService:      jwtService,
-		TicketStore:     ticketStore,
-		AuthService:     authService,
-		AuthHandler:     authHandler,
-		PostgresDB:      postgresDB,
-		RedisClient:     redisClient,
-		UnitOfWork:      unitOfWork,
-		AccountRepo:     accountRepository,
-		PlayerRepo:      playerRepository,
-		WalletRepo:      walletRepository,
-		TransactionRepo: transactionRepo,
-		SessionRepo:     gameSessionRepository,
-		GameService:     gameService,
-		SessionManager:  sessionManager,
+		Config:              configConfig,
+		Logger:              zapLogger,
+		TableManager:        tableManager,
+		Hub:                 hub,
+		WSHandler:           handler,
+		HandHistoryHandler:  handHistoryHandler,
+		RakeHandler:         rakeHandler,
+		WalletHandler:       walletHandler,
+		AdjustmentHandler:   adjustmentHandler,
+		AuditHandler:        auditHandler,
+		TableAdminHandler:   tableAdminHandler,
+		AccountAdminHandler: accountAdminHandler,
+		JWTService:          jwtService,
+		TicketStore:         ticketStore,
+		SessionService:      sessionService,
+		AuthService:         authService,
+		AuthHandler:         authHandler,
+		RateLimiters:        rateLimiters,
+		PostgresDB:          postgresDB,
+		RedisClient:         redisClient,
+		UnitOfWork:          unitOfWork,
+		AccountRepo:         accountRepository,
+		PlayerRepo:          playerRepository,
+		WalletRepo:          walletRepository,
+		TransactionRepo:     transactionRepo,
+		SessionRepo:         gameSessionRepository,
+		HandHistoryRepo:     handHistoryRepository,
+		RakeRepo:            rakeRepository,
+		RecoveryRepo:        tableRecoveryRepository,
+		AuditLogRepo:        auditLogRepository,
+		AdjustmentRepo:      walletAdjustmentRepository,
+		AuthSessionRepo:     authSessionRepository,
+		AccountTokenRepo:    accountTokenRepository,
+		TwoFactorRepo:       twoFactorRepository,
+		GameService:         gameService,
+		HandHistoryService:  handHistoryService,
+		RakeService:         rakeService,
+		RecoveryService:     recoveryService,
+		SessionReconciler:   sessionReconciler,
+		WalletService:       walletService,
+		AdjustmentService:   adjustmentService,
+		AuditService:        auditService,
+		SessionManager:      sessionManager,
 	}
 	return app, nil
 }